@@ -779,8 +779,15 @@ func (a *Asset) Copy() *Asset {
 	if a.ScriptKey.TweakedScriptKey != nil {
 		assetCopy.ScriptKey.TweakedScriptKey = &TweakedScriptKey{}
 		assetCopy.ScriptKey.RawKey = a.ScriptKey.RawKey
-		assetCopy.ScriptKey.Tweak = make([]byte, len(a.ScriptKey.Tweak))
-		copy(assetCopy.ScriptKey.Tweak, a.ScriptKey.Tweak)
+
+		// A nil tweak signals a BIP 86 tweak, so we only copy the tweak
+		// over if it was set.
+		if a.ScriptKey.Tweak != nil {
+			assetCopy.ScriptKey.Tweak = make(
+				[]byte, len(a.ScriptKey.Tweak),
+			)
+			copy(assetCopy.ScriptKey.Tweak, a.ScriptKey.Tweak)
+		}
 	}
 
 	if a.GroupKey != nil {
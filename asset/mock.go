@@ -3,6 +3,7 @@ package asset
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"math"
 	"testing"
 
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -22,8 +23,10 @@ func RandGenesis(t testing.TB, assetType Type) Genesis {
 		FirstPrevOut: test.RandOp(t),
 		Tag:          hex.EncodeToString(metadata),
 		Metadata:     metadata,
-		OutputIndex:  uint32(test.RandInt[int32]()),
-		Type:         assetType,
+		// The output index is stored in a signed column, so we'll keep
+		// it within the range of non-negative int32 values.
+		OutputIndex: test.RandInt[uint32]() % math.MaxInt32,
+		Type:        assetType,
 	}
 }
 
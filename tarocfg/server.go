@@ -22,6 +22,7 @@ import (
 type databaseBackend interface {
 	tarodb.BatchedQuerier
 	WithTx(tx *sql.Tx) *sqlc.Queries
+	WithPreparedUpsertsTx(tx *sql.Tx) *sqlc.Queries
 }
 
 // CreateServerFromConfig creates a new Taro server from the given CLI config.
@@ -72,10 +73,27 @@ func CreateServerFromConfig(cfg *Config, cfgLogger btclog.Logger,
 			return db.WithTx(tx)
 		},
 	)
-	assetMintingStore := tarodb.NewAssetMintingStore(mintingStore)
 
+	// Minting batches and imports may conflict with concurrent
+	// transactions on Postgres, in which case we'll retry them instead of
+	// failing the whole operation.
+	serializationRetryCfg := tarodb.DefaultSerializationRetryConfig()
+	assetMintingStore := tarodb.NewAssetMintingStore(
+		tarodb.NewSerializationRetryPendingAssetStore(
+			mintingStore, serializationRetryCfg,
+		),
+	)
+
+	// On Postgres, asset imports can prepare the queries they execute once
+	// per asset, so they're only parsed once per transaction.
+	preparedUpserts := cfg.DatabaseBackend == DatabaseBackendPostgres &&
+		cfg.Postgres.PreparedUpserts
 	assetDB := tarodb.NewTransactionExecutor[tarodb.ActiveAssetsStore](
 		db, func(tx *sql.Tx) tarodb.ActiveAssetsStore {
+			if preparedUpserts {
+				return db.WithPreparedUpsertsTx(tx)
+			}
+
 			return db.WithTx(tx)
 		},
 	)
@@ -112,7 +130,11 @@ func CreateServerFromConfig(cfg *Config, cfgLogger btclog.Logger,
 		Chain:        taroChainParams,
 	})
 
-	assetStore := tarodb.NewAssetStore(assetDB)
+	assetStore := tarodb.NewAssetStore(
+		tarodb.NewSerializationRetryAssetStore(
+			assetDB, serializationRetryCfg,
+		),
+	)
 
 	proofFileStore, err := proof.NewFileArchiver(cfg.networkDir)
 	if err != nil {
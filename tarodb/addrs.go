@@ -57,6 +57,18 @@ type (
 
 	// Genesis is a type alias for fetching the genesis asset information.
 	Genesis = sqlc.FetchGenesisByIDRow
+
+	// GenesisOutpointQuery is used to fetch a genesis asset by its genesis
+	// point and output index.
+	GenesisOutpointQuery = sqlc.FetchGenesisByOutpointParams
+
+	// GenesisByOutpoint is a genesis asset fetched by its genesis point
+	// and output index.
+	GenesisByOutpoint = sqlc.FetchGenesisByOutpointRow
+
+	// GenesisWithID is a genesis asset fetched along with its primary key
+	// as part of a set of genesis assets.
+	GenesisWithID = sqlc.FetchGenesesByIDsRow
 )
 
 // AddrBook is an interface that represents the storage backed needed to create
@@ -101,10 +113,6 @@ type AddrBook interface {
 	UpsertManagedUTXO(ctx context.Context, arg RawManagedUTXO) (int32,
 		error)
 
-	// UpsertChainTx inserts a new or updates an existing chain tx into the
-	// DB.
-	UpsertChainTx(ctx context.Context, arg ChainTx) (int32, error)
-
 	// UpsertAddrEvent inserts a new or updates an existing address event
 	// and returns the primary key.
 	UpsertAddrEvent(ctx context.Context, arg UpsertAddrEvent) (int32, error)
@@ -158,16 +166,22 @@ type BatchedAddrBook interface {
 type TaroAddressBook struct {
 	db     BatchedAddrBook
 	params *address.ChainParams
+
+	// upsertOpts are the policies applied when storing the genesis of an
+	// address.
+	upsertOpts *upsertOptions
 }
 
 // NewTaroAddressBook creates a new TaroAddressBook instance given a open
-// BatchedAddrBook storage backend.
-func NewTaroAddressBook(db BatchedAddrBook,
-	params *address.ChainParams) *TaroAddressBook {
+// BatchedAddrBook storage backend. The passed options modify the policies
+// applied when storing the genesis of an address.
+func NewTaroAddressBook(db BatchedAddrBook, params *address.ChainParams,
+	opts ...UpsertOption) *TaroAddressBook {
 
 	return &TaroAddressBook{
-		db:     db,
-		params: params,
+		db:         db,
+		params:     params,
+		upsertOpts: newUpsertOptions(opts...),
 	}
 }
 
@@ -203,7 +217,8 @@ func (t *TaroAddressBook) InsertAddrs(ctx context.Context,
 					"point: %w", err)
 			}
 			genAssetID, err := upsertGenesis(
-				ctx, db, genesisPointID, addr.Genesis,
+				ctx, db, t.upsertOpts, genesisPointID,
+				addr.Genesis, MetadataKeepExisting,
 			)
 			if err != nil {
 				return fmt.Errorf("unable to insert genesis: "+
@@ -221,6 +236,10 @@ func (t *TaroAddressBook) InsertAddrs(ctx context.Context,
 				InternalKeyID:    rawScriptKeyID,
 				TweakedScriptKey: addr.ScriptKey.SerializeCompressed(),
 				Tweak:            addr.ScriptKeyTweak.Tweak,
+				IsKnownRaw:       true,
+				KeyType: int16(knownScriptKeyType(
+					addr.ScriptKeyTweak.Tweak,
+				)),
 			})
 			if err != nil {
 				return fmt.Errorf("unable to insert script "+
@@ -300,16 +319,25 @@ func (t *TaroAddressBook) QueryAddrs(ctx context.Context,
 			return err
 		}
 
+		// We'll fetch the genesis assets of all addresses at once,
+		// rather than issuing a query for each of them.
+		genAssetIDs := fMap(dbAddrs, func(a Addresses) int32 {
+			return a.GenesisAssetID
+		})
+		geneses, err := fetchGenesesByIDs(ctx, db, genAssetIDs)
+		if err != nil {
+			return fmt.Errorf("error fetching geneses: %w", err)
+		}
+
 		// Next, we'll need to map each of the addresses into an
 		// AddrWithKeyInfo struct that can be used in a general
 		// context.
 		for _, addr := range dbAddrs {
-			assetGenesis, err := fetchGenesis(
-				ctx, db, addr.GenesisAssetID,
-			)
-			if err != nil {
-				return fmt.Errorf("error fetching genesis: %w",
-					err)
+			assetGenesis, ok := geneses[addr.GenesisAssetID]
+			if !ok {
+				return fmt.Errorf("error fetching genesis %d: "+
+					"%w", addr.GenesisAssetID,
+					ErrGenesisNotFound)
 			}
 
 			var groupKey *btcec.PublicKey
@@ -433,7 +461,7 @@ func fetchAddr(ctx context.Context, db AddrBook, params *address.ChainParams,
 		return nil, err
 	}
 
-	genesis, err := fetchGenesis(ctx, db, dbAddr.GenesisAssetID)
+	genesis, _, err := fetchGenesis(ctx, db, dbAddr.GenesisAssetID)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching genesis: %w", err)
 	}
@@ -708,9 +736,13 @@ func fetchEvent(ctx context.Context, db AddrBook, eventID int32,
 	if err != nil {
 		return nil, fmt.Errorf("error parsing txid: %w", err)
 	}
+	outputIndex, err := parseOutputIndex(dbEvent.OutputIndex)
+	if err != nil {
+		return nil, err
+	}
 	op := wire.OutPoint{
 		Hash:  *hash,
-		Index: uint32(dbEvent.OutputIndex),
+		Index: outputIndex,
 	}
 
 	return &address.Event{
@@ -0,0 +1,900 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightningnetwork/lnd/keychain"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The asset graph is serialized as the AssetGraph protobuf message defined in
+// asset_graph.proto. The field numbers below must match that schema.
+const (
+	graphAnchorsField protowire.Number = 1
+	graphAssetsField  protowire.Number = 2
+
+	anchorTxField               protowire.Number = 1
+	anchorOutputIndexField      protowire.Number = 2
+	anchorOutputValueField      protowire.Number = 3
+	anchorInternalKeyField      protowire.Number = 4
+	anchorTaroRootField         protowire.Number = 5
+	anchorTapscriptSiblingField protowire.Number = 6
+
+	assetTLVField            protowire.Number = 1
+	assetAnchorIndexField    protowire.Number = 2
+	assetScriptKeyField      protowire.Number = 3
+	assetScriptKeyTweakField protowire.Number = 4
+	assetGroupKeyField       protowire.Number = 5
+	assetSpentField          protowire.Number = 6
+
+	keyRawKeyField protowire.Number = 1
+	keyFamilyField protowire.Number = 2
+	keyIndexField  protowire.Number = 3
+)
+
+// errInvalidWireType is returned when a field of the asset graph is encoded
+// with an unexpected protobuf wire type.
+var errInvalidWireType = errors.New("invalid wire type")
+
+// GraphAsset is an asset of the asset graph, along with a reference to the
+// on-chain output that anchors it.
+type GraphAsset struct {
+	*asset.Asset
+
+	// AnchorIndex is the index of the anchor of the asset within the
+	// anchors of the asset graph. It is nil if the asset isn't anchored
+	// yet.
+	AnchorIndex *uint32
+
+	// Spent is true if the asset was spent.
+	Spent bool
+}
+
+// AssetGraphProto is a set of assets, including their genesis, the keys they
+// commit to, their asset groups and the on-chain outputs that anchor them. It
+// can be serialized as a single protobuf message to transport the assets, and
+// imported again into another database.
+type AssetGraphProto struct {
+	// Anchors is the set of on-chain outputs that anchor the assets.
+	Anchors []*AnchorUTXO
+
+	// Assets is the set of assets of the graph.
+	Assets []*GraphAsset
+}
+
+// graphAssetKey identifies an asset of the asset graph. An asset is unique by
+// its ID, its script key and the on-chain output that anchors it, which is
+// zero for assets that aren't anchored yet.
+type graphAssetKey struct {
+	id        asset.ID
+	scriptKey asset.SerializedKey
+	anchor    wire.OutPoint
+}
+
+// newGraphAssetKey returns the key of an asset anchored in the given output.
+func newGraphAssetKey(a *asset.Asset, anchor wire.OutPoint) graphAssetKey {
+	return graphAssetKey{
+		id:        a.ID(),
+		scriptKey: asset.ToSerialized(a.ScriptKey.PubKey),
+		anchor:    anchor,
+	}
+}
+
+// storedGraphAsset is an asset on disk, along with the state of the asset that
+// isn't part of the asset itself.
+type storedGraphAsset struct {
+	*ChainAsset
+
+	// primaryKey is the primary key of the asset on disk.
+	primaryKey int32
+
+	// spent is true if the asset was spent.
+	spent bool
+}
+
+// fetchGraphAssets fetches all the assets on disk, including spent assets and
+// assets that aren't anchored yet.
+func fetchGraphAssets(ctx context.Context,
+	q ActiveAssetsStore) ([]*storedGraphAsset, error) {
+
+	dbAssets, witnesses, err := fetchAssetsWithWitness(
+		ctx, q, QueryAssetFilters{
+			IncludeSpent:      sqlBool(true),
+			IncludeUnanchored: sqlBool(true),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch assets: %w", err)
+	}
+
+	chainAssets, err := dbAssetsToChainAssets(dbAssets, witnesses)
+	if err != nil {
+		return nil, err
+	}
+
+	// The asset rows don't carry the spent flag, so we'll look up the
+	// unspent assets separately.
+	unspentAssets, err := q.QueryAssets(ctx, QueryAssetFilters{
+		IncludeUnanchored: sqlBool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch unspent assets: %w",
+			err)
+	}
+	unspent := make(map[int32]struct{}, len(unspentAssets))
+	for _, unspentAsset := range unspentAssets {
+		unspent[unspentAsset.AssetPrimaryKey] = struct{}{}
+	}
+
+	storedAssets := make([]*storedGraphAsset, len(chainAssets))
+	for i, chainAsset := range chainAssets {
+		primaryKey := dbAssets[i].AssetPrimaryKey
+		_, isUnspent := unspent[primaryKey]
+
+		storedAssets[i] = &storedGraphAsset{
+			ChainAsset: chainAsset,
+			primaryKey: primaryKey,
+			spent:      !isUnspent,
+		}
+	}
+
+	return storedAssets, nil
+}
+
+// ExportAssetGraph exports all the assets on disk, including spent assets and
+// assets that aren't anchored yet, along with the on-chain outputs that anchor
+// them, as an asset graph. The assets and anchors are sorted, so the same set
+// of assets always results in the same graph, independent of the database
+// they're exported from.
+func (a *AssetStore) ExportAssetGraph(
+	ctx context.Context) (*AssetGraphProto, error) {
+
+	var (
+		storedAssets []*storedGraphAsset
+		utxos        []*ManagedUTXO
+	)
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		storedAssets, err = fetchGraphAssets(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		dbUtxos, err := q.FetchManagedUTXOs(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to fetch managed utxos: %w",
+				err)
+		}
+		utxos, err = parseManagedUTXOs(dbUtxos)
+
+		return err
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	managedUtxos := make(map[wire.OutPoint]*ManagedUTXO, len(utxos))
+	for _, utxo := range utxos {
+		managedUtxos[utxo.OutPoint] = utxo
+	}
+
+	// We'll only export the anchors that are referenced by an asset, in
+	// the order of their outpoints.
+	anchors := make(map[wire.OutPoint]*AnchorUTXO)
+	for _, storedAsset := range storedAssets {
+		if storedAsset.AnchorTx == nil {
+			continue
+		}
+
+		anchorPoint := storedAsset.AnchorOutpoint
+		if _, ok := anchors[anchorPoint]; ok {
+			continue
+		}
+
+		utxo, ok := managedUtxos[anchorPoint]
+		if !ok {
+			return nil, fmt.Errorf("managed utxo %v not found",
+				anchorPoint)
+		}
+
+		anchors[anchorPoint] = &AnchorUTXO{
+			ManagedUTXO: *utxo,
+			AnchorTx:    storedAsset.AnchorTx,
+		}
+	}
+
+	graph := &AssetGraphProto{
+		Anchors: make([]*AnchorUTXO, 0, len(anchors)),
+		Assets:  make([]*GraphAsset, 0, len(storedAssets)),
+	}
+	for _, anchor := range anchors {
+		graph.Anchors = append(graph.Anchors, anchor)
+	}
+	sort.Slice(graph.Anchors, func(i, j int) bool {
+		return graph.Anchors[i].OutPoint.String() <
+			graph.Anchors[j].OutPoint.String()
+	})
+
+	anchorIndexes := make(map[wire.OutPoint]uint32, len(anchors))
+	for i, anchor := range graph.Anchors {
+		anchorIndexes[anchor.OutPoint] = uint32(i)
+	}
+	for _, storedAsset := range storedAssets {
+		graphAsset := &GraphAsset{
+			Asset: storedAsset.Asset,
+			Spent: storedAsset.spent,
+		}
+		if storedAsset.AnchorTx != nil {
+			anchorIndex := anchorIndexes[storedAsset.AnchorOutpoint]
+			graphAsset.AnchorIndex = &anchorIndex
+		}
+
+		graph.Assets = append(graph.Assets, graphAsset)
+	}
+
+	// Finally, we'll sort the assets by their ID and script key, which
+	// together with their anchor uniquely identify an asset. Assets that
+	// aren't anchored yet come last.
+	sort.Slice(graph.Assets, func(i, j int) bool {
+		assetI, assetJ := graph.Assets[i], graph.Assets[j]
+		idI, idJ := assetI.ID(), assetJ.ID()
+		if c := bytes.Compare(idI[:], idJ[:]); c != 0 {
+			return c < 0
+		}
+
+		c := bytes.Compare(
+			assetI.ScriptKey.PubKey.SerializeCompressed(),
+			assetJ.ScriptKey.PubKey.SerializeCompressed(),
+		)
+		if c != 0 {
+			return c < 0
+		}
+
+		switch {
+		case assetI.AnchorIndex == nil:
+			return false
+
+		case assetJ.AnchorIndex == nil:
+			return true
+
+		default:
+			return *assetI.AnchorIndex < *assetJ.AnchorIndex
+		}
+	})
+
+	return graph, nil
+}
+
+// key returns the key that identifies the given asset of the graph.
+func (g *AssetGraphProto) key(graphAsset *GraphAsset) graphAssetKey {
+	var anchorPoint wire.OutPoint
+	if graphAsset.AnchorIndex != nil {
+		anchorPoint = g.Anchors[*graphAsset.AnchorIndex].OutPoint
+	}
+
+	return newGraphAssetKey(graphAsset.Asset, anchorPoint)
+}
+
+// ImportAssetGraph imports all the assets of the given asset graph, along
+// with the on-chain outputs that anchor them, in a single database
+// transaction. The import is idempotent: assets that are on disk already
+// aren't imported again, but are marked as spent if they were spent in the
+// graph.
+func (a *AssetStore) ImportAssetGraph(ctx context.Context,
+	graph *AssetGraphProto) error {
+
+	// The assets are inserted along with their genesis, so we'll group
+	// them by their genesis point first.
+	var genesisPoints []wire.OutPoint
+	genesisAssets := make(map[wire.OutPoint][]*GraphAsset)
+	for _, graphAsset := range graph.Assets {
+		anchorIndex := graphAsset.AnchorIndex
+		if anchorIndex != nil &&
+			int(*anchorIndex) >= len(graph.Anchors) {
+
+			return fmt.Errorf("invalid anchor index %v",
+				*anchorIndex)
+		}
+
+		genesisPoint := graphAsset.Genesis.FirstPrevOut
+		if _, ok := genesisAssets[genesisPoint]; !ok {
+			genesisPoints = append(genesisPoints, genesisPoint)
+		}
+
+		genesisAssets[genesisPoint] = append(
+			genesisAssets[genesisPoint], graphAsset,
+		)
+	}
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		storedAssets, err := fetchGraphAssets(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		knownAssets := make(
+			map[graphAssetKey]*storedGraphAsset, len(storedAssets),
+		)
+		for _, storedAsset := range storedAssets {
+			key := newGraphAssetKey(
+				storedAsset.Asset, storedAsset.AnchorOutpoint,
+			)
+			knownAssets[key] = storedAsset
+		}
+
+		anchorUtxoIDs := make(map[uint32]int32)
+		for _, genesisPoint := range genesisPoints {
+			err := a.importGraphAssets(
+				ctx, q, graph, genesisPoint,
+				genesisAssets[genesisPoint], knownAssets,
+				anchorUtxoIDs,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// importGraphAssets imports the given assets of the asset graph that share the
+// same genesis point. Assets that are known already are skipped, while the
+// anchors of the new assets are upserted once, and cached in the passed map of
+// anchor indexes to anchor UTXO IDs.
+func (a *AssetStore) importGraphAssets(ctx context.Context,
+	q ActiveAssetsStore, graph *AssetGraphProto, genesisPoint wire.OutPoint,
+	graphAssets []*GraphAsset,
+	knownAssets map[graphAssetKey]*storedGraphAsset,
+	anchorUtxoIDs map[uint32]int32) error {
+
+	var (
+		newAssets      []*asset.Asset
+		newAnchorIDs   []sql.NullInt32
+		newSpent       []bool
+		spentAssetIDs  []int32
+		importedAssets = make(map[graphAssetKey]struct{})
+	)
+	for _, graphAsset := range graphAssets {
+		key := graph.key(graphAsset)
+		if _, ok := importedAssets[key]; ok {
+			continue
+		}
+		importedAssets[key] = struct{}{}
+
+		// An asset that is known already was imported before, but
+		// might have been spent since.
+		if knownAsset, ok := knownAssets[key]; ok {
+			if graphAsset.Spent && !knownAsset.spent {
+				spentAssetIDs = append(
+					spentAssetIDs, knownAsset.primaryKey,
+				)
+			}
+
+			continue
+		}
+
+		var anchorUtxoID sql.NullInt32
+		if graphAsset.AnchorIndex != nil {
+			anchorIndex := *graphAsset.AnchorIndex
+			utxoID, ok := anchorUtxoIDs[anchorIndex]
+			if !ok {
+				var err error
+				utxoID, err = upsertAnchorUTXO(
+					ctx, q, a.upsertOpts,
+					*graph.Anchors[anchorIndex],
+				)
+				if err != nil {
+					return err
+				}
+				anchorUtxoIDs[anchorIndex] = utxoID
+			}
+
+			anchorUtxoID = sqlInt32(utxoID)
+		}
+
+		newAssets = append(newAssets, graphAsset.Asset)
+		newAnchorIDs = append(newAnchorIDs, anchorUtxoID)
+		newSpent = append(newSpent, graphAsset.Spent)
+	}
+
+	if len(newAssets) > 0 {
+		_, assetIDs, err := upsertAssetsWithGenesis(
+			ctx, q, a.upsertOpts, genesisPoint, newAssets,
+			newAnchorIDs,
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting assets with "+
+				"genesis: %w", err)
+		}
+
+		for i, newAsset := range newAssets {
+			err := a.insertAssetWitnesses(
+				ctx, q, assetIDs[i], newAsset.PrevWitnesses,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to insert asset "+
+					"witness: %w", err)
+			}
+
+			if newSpent[i] {
+				spentAssetIDs = append(
+					spentAssetIDs, assetIDs[i],
+				)
+			}
+		}
+	}
+
+	if len(spentAssetIDs) == 0 {
+		return nil
+	}
+
+	for _, assetID := range spentAssetIDs {
+		if _, err := q.SetAssetSpent(ctx, assetID); err != nil {
+			return fmt.Errorf("unable to mark asset as spent: %w",
+				err)
+		}
+	}
+
+	return a.upsertOpts.auditAssetUpdates(ctx, q, spentAssetIDs)
+}
+
+// Marshal serializes the asset graph as a protobuf message.
+func (g *AssetGraphProto) Marshal() ([]byte, error) {
+	var b []byte
+	for _, anchor := range g.Anchors {
+		anchorBytes, err := marshalGraphAnchor(anchor)
+		if err != nil {
+			return nil, err
+		}
+
+		b = protowire.AppendTag(
+			b, graphAnchorsField, protowire.BytesType,
+		)
+		b = protowire.AppendBytes(b, anchorBytes)
+	}
+
+	for _, graphAsset := range g.Assets {
+		assetBytes, err := marshalGraphAsset(graphAsset)
+		if err != nil {
+			return nil, err
+		}
+
+		b = protowire.AppendTag(
+			b, graphAssetsField, protowire.BytesType,
+		)
+		b = protowire.AppendBytes(b, assetBytes)
+	}
+
+	return b, nil
+}
+
+// Unmarshal deserializes the asset graph from a protobuf message.
+func (g *AssetGraphProto) Unmarshal(b []byte) error {
+	return unmarshalFields(b, func(num protowire.Number,
+		typ protowire.Type, b []byte) (int, error) {
+
+		switch num {
+		case graphAnchorsField:
+			anchorBytes, n, err := consumeBytes(typ, b)
+			if err != nil {
+				return 0, err
+			}
+
+			anchor, err := unmarshalGraphAnchor(anchorBytes)
+			if err != nil {
+				return 0, err
+			}
+			g.Anchors = append(g.Anchors, anchor)
+
+			return n, nil
+
+		case graphAssetsField:
+			assetBytes, n, err := consumeBytes(typ, b)
+			if err != nil {
+				return 0, err
+			}
+
+			graphAsset, err := unmarshalGraphAsset(assetBytes)
+			if err != nil {
+				return 0, err
+			}
+			g.Assets = append(g.Assets, graphAsset)
+
+			return n, nil
+
+		default:
+			return consumeUnknownField(num, typ, b)
+		}
+	})
+}
+
+// marshalGraphAnchor serializes an anchor of the asset graph.
+func marshalGraphAnchor(anchor *AnchorUTXO) ([]byte, error) {
+	if anchor.AnchorTx == nil {
+		return nil, fmt.Errorf("anchor tx for %v missing",
+			anchor.OutPoint)
+	}
+
+	var anchorTx bytes.Buffer
+	if err := anchor.AnchorTx.Serialize(&anchorTx); err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	b = protowire.AppendTag(b, anchorTxField, protowire.BytesType)
+	b = protowire.AppendBytes(b, anchorTx.Bytes())
+	b = protowire.AppendTag(b, anchorOutputIndexField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(anchor.OutPoint.Index))
+	b = protowire.AppendTag(b, anchorOutputValueField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(anchor.OutputValue))
+	b = protowire.AppendTag(b, anchorInternalKeyField, protowire.BytesType)
+	b = protowire.AppendBytes(b, marshalKeyDescriptor(anchor.InternalKey))
+	b = protowire.AppendTag(b, anchorTaroRootField, protowire.BytesType)
+	b = protowire.AppendBytes(b, anchor.TaroRoot)
+
+	if len(anchor.TapscriptSibling) > 0 {
+		b = protowire.AppendTag(
+			b, anchorTapscriptSiblingField, protowire.BytesType,
+		)
+		b = protowire.AppendBytes(b, anchor.TapscriptSibling)
+	}
+
+	return b, nil
+}
+
+// unmarshalGraphAnchor deserializes an anchor of the asset graph.
+func unmarshalGraphAnchor(b []byte) (*AnchorUTXO, error) {
+	var anchor AnchorUTXO
+	err := unmarshalFields(b, func(num protowire.Number,
+		typ protowire.Type, b []byte) (int, error) {
+
+		switch num {
+		case anchorTxField:
+			txBytes, n, err := consumeBytes(typ, b)
+			if err != nil {
+				return 0, err
+			}
+
+			anchor.AnchorTx = wire.NewMsgTx(2)
+			err = anchor.AnchorTx.Deserialize(bytes.NewReader(
+				txBytes,
+			))
+			if err != nil {
+				return 0, fmt.Errorf("unable to decode anchor "+
+					"tx: %w", err)
+			}
+
+			return n, nil
+
+		case anchorOutputIndexField:
+			index, n, err := consumeVarint(typ, b)
+			anchor.OutPoint.Index = uint32(index)
+			return n, err
+
+		case anchorOutputValueField:
+			value, n, err := consumeVarint(typ, b)
+			anchor.OutputValue = btcutil.Amount(value)
+			return n, err
+
+		case anchorInternalKeyField:
+			keyBytes, n, err := consumeBytes(typ, b)
+			if err != nil {
+				return 0, err
+			}
+
+			anchor.InternalKey, err = unmarshalKeyDescriptor(
+				keyBytes,
+			)
+			return n, err
+
+		case anchorTaroRootField:
+			taroRoot, n, err := consumeBytes(typ, b)
+			anchor.TaroRoot = taroRoot
+			return n, err
+
+		case anchorTapscriptSiblingField:
+			sibling, n, err := consumeBytes(typ, b)
+			anchor.TapscriptSibling = sibling
+			return n, err
+
+		default:
+			return consumeUnknownField(num, typ, b)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if anchor.AnchorTx == nil {
+		return nil, fmt.Errorf("anchor tx missing")
+	}
+	anchor.OutPoint.Hash = anchor.AnchorTx.TxHash()
+
+	return &anchor, nil
+}
+
+// marshalGraphAsset serializes an asset of the asset graph.
+func marshalGraphAsset(graphAsset *GraphAsset) ([]byte, error) {
+	var assetTLV bytes.Buffer
+	if err := graphAsset.Encode(&assetTLV); err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	b = protowire.AppendTag(b, assetTLVField, protowire.BytesType)
+	b = protowire.AppendBytes(b, assetTLV.Bytes())
+
+	if graphAsset.AnchorIndex != nil {
+		b = protowire.AppendTag(
+			b, assetAnchorIndexField, protowire.VarintType,
+		)
+		b = protowire.AppendVarint(b, uint64(*graphAsset.AnchorIndex))
+	}
+
+	// The TLV encoding of the asset only contains the tweaked script and
+	// group keys, so we'll also add the raw keys they were derived from.
+	if graphAsset.ScriptKey.TweakedScriptKey != nil {
+		scriptKey := graphAsset.ScriptKey.TweakedScriptKey
+
+		b = protowire.AppendTag(
+			b, assetScriptKeyField, protowire.BytesType,
+		)
+		b = protowire.AppendBytes(
+			b, marshalKeyDescriptor(scriptKey.RawKey),
+		)
+
+		// An empty tweak is distinct from no tweak at all, so we'll
+		// add the field if there is a tweak, even if it is empty.
+		if scriptKey.Tweak != nil {
+			b = protowire.AppendTag(
+				b, assetScriptKeyTweakField,
+				protowire.BytesType,
+			)
+			b = protowire.AppendBytes(b, scriptKey.Tweak)
+		}
+	}
+
+	if graphAsset.GroupKey != nil {
+		b = protowire.AppendTag(
+			b, assetGroupKeyField, protowire.BytesType,
+		)
+		b = protowire.AppendBytes(
+			b, marshalKeyDescriptor(graphAsset.GroupKey.RawKey),
+		)
+	}
+
+	if graphAsset.Spent {
+		b = protowire.AppendTag(
+			b, assetSpentField, protowire.VarintType,
+		)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(true))
+	}
+
+	return b, nil
+}
+
+// unmarshalGraphAsset deserializes an asset of the asset graph.
+func unmarshalGraphAsset(b []byte) (*GraphAsset, error) {
+	var (
+		graphAsset = GraphAsset{
+			Asset: &asset.Asset{},
+		}
+		scriptKey *asset.TweakedScriptKey
+		groupKey  *keychain.KeyDescriptor
+	)
+	err := unmarshalFields(b, func(num protowire.Number,
+		typ protowire.Type, b []byte) (int, error) {
+
+		switch num {
+		case assetTLVField:
+			assetTLV, n, err := consumeBytes(typ, b)
+			if err != nil {
+				return 0, err
+			}
+
+			err = graphAsset.Decode(bytes.NewReader(assetTLV))
+			if err != nil {
+				return 0, fmt.Errorf("unable to decode asset: "+
+					"%w", err)
+			}
+
+			return n, nil
+
+		case assetAnchorIndexField:
+			index, n, err := consumeVarint(typ, b)
+			anchorIndex := uint32(index)
+			graphAsset.AnchorIndex = &anchorIndex
+			return n, err
+
+		case assetScriptKeyField:
+			keyBytes, n, err := consumeBytes(typ, b)
+			if err != nil {
+				return 0, err
+			}
+
+			if scriptKey == nil {
+				scriptKey = &asset.TweakedScriptKey{}
+			}
+			scriptKey.RawKey, err = unmarshalKeyDescriptor(
+				keyBytes,
+			)
+			return n, err
+
+		case assetScriptKeyTweakField:
+			tweak, n, err := consumeBytes(typ, b)
+			if scriptKey == nil {
+				scriptKey = &asset.TweakedScriptKey{}
+			}
+
+			// The field is only present if there is a tweak, so an
+			// empty value is an empty tweak rather than none.
+			if tweak == nil {
+				tweak = []byte{}
+			}
+			scriptKey.Tweak = tweak
+			return n, err
+
+		case assetSpentField:
+			spent, n, err := consumeVarint(typ, b)
+			graphAsset.Spent = protowire.DecodeBool(spent)
+			return n, err
+
+		case assetGroupKeyField:
+			keyBytes, n, err := consumeBytes(typ, b)
+			if err != nil {
+				return 0, err
+			}
+
+			rawKey, err := unmarshalKeyDescriptor(keyBytes)
+			groupKey = &rawKey
+			return n, err
+
+		default:
+			return consumeUnknownField(num, typ, b)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// With the asset decoded, we can now add the raw keys of its script
+	// and group key.
+	graphAsset.ScriptKey.TweakedScriptKey = scriptKey
+	if groupKey != nil {
+		if graphAsset.GroupKey == nil {
+			return nil, fmt.Errorf("raw group key of asset " +
+				"without group key")
+		}
+		graphAsset.GroupKey.RawKey = *groupKey
+	}
+
+	return &graphAsset, nil
+}
+
+// marshalKeyDescriptor serializes a key descriptor of the asset graph.
+func marshalKeyDescriptor(key keychain.KeyDescriptor) []byte {
+	var b []byte
+	if key.PubKey != nil {
+		b = protowire.AppendTag(b, keyRawKeyField, protowire.BytesType)
+		b = protowire.AppendBytes(b, key.PubKey.SerializeCompressed())
+	}
+	b = protowire.AppendTag(b, keyFamilyField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(key.Family))
+	b = protowire.AppendTag(b, keyIndexField, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(key.Index))
+
+	return b
+}
+
+// unmarshalKeyDescriptor deserializes a key descriptor of the asset graph.
+func unmarshalKeyDescriptor(b []byte) (keychain.KeyDescriptor, error) {
+	var key keychain.KeyDescriptor
+	err := unmarshalFields(b, func(num protowire.Number,
+		typ protowire.Type, b []byte) (int, error) {
+
+		switch num {
+		case keyRawKeyField:
+			rawKey, n, err := consumeBytes(typ, b)
+			if err != nil {
+				return 0, err
+			}
+
+			key.PubKey, err = btcec.ParsePubKey(rawKey)
+			return n, err
+
+		case keyFamilyField:
+			family, n, err := consumeVarint(typ, b)
+			key.Family = keychain.KeyFamily(family)
+			return n, err
+
+		case keyIndexField:
+			index, n, err := consumeVarint(typ, b)
+			key.Index = uint32(index)
+			return n, err
+
+		default:
+			return consumeUnknownField(num, typ, b)
+		}
+	})
+
+	return key, err
+}
+
+// unmarshalFields calls the given function for each field of the given
+// protobuf message. The function is passed the remaining bytes of the message
+// starting at the value of the field, and returns the length of the value.
+func unmarshalFields(b []byte, unmarshalField func(protowire.Number,
+	protowire.Type, []byte) (int, error)) error {
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		n, err := unmarshalField(num, typ, b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+
+	return nil
+}
+
+// consumeBytes parses a length-prefixed bytes field value, returning a copy of
+// the value along with the length of the encoded field value.
+func consumeBytes(typ protowire.Type, b []byte) ([]byte, int, error) {
+	if typ != protowire.BytesType {
+		return nil, 0, errInvalidWireType
+	}
+
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return nil, 0, protowire.ParseError(n)
+	}
+
+	return append([]byte(nil), v...), n, nil
+}
+
+// consumeVarint parses a varint field value, returning the value along with
+// the length of the encoded field value.
+func consumeVarint(typ protowire.Type, b []byte) (uint64, int, error) {
+	if typ != protowire.VarintType {
+		return 0, 0, errInvalidWireType
+	}
+
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, 0, protowire.ParseError(n)
+	}
+
+	return v, n, nil
+}
+
+// consumeUnknownField skips over the value of a field that is unknown, so
+// newer versions of the asset graph can still be parsed.
+func consumeUnknownField(num protowire.Number, typ protowire.Type,
+	b []byte) (int, error) {
+
+	n := protowire.ConsumeFieldValue(num, typ, b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,271 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAssetGraphRoundTrip tests that an asset graph exported from one
+// database and imported into another one results in the very same graph.
+func TestAssetGraphRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	importTestAssetGraph(t, assetStore, db)
+
+	graphBytes, digest := exportGraphDigest(t, assetStore)
+
+	// We'll now import the serialized graph into a new database. Exporting
+	// the graph from there should result in the same digest.
+	var graph AssetGraphProto
+	require.NoError(t, graph.Unmarshal(graphBytes))
+
+	_, importStore, _ := newAssetStore(t)
+	require.NoError(t, importStore.ImportAssetGraph(ctx, &graph))
+
+	_, newDigest := exportGraphDigest(t, importStore)
+	require.Equal(t, digest, newDigest)
+
+	// The spent flag and the distinction between an empty and a missing
+	// script key tweak should survive the round trip.
+	importedGraph, err := importStore.ExportAssetGraph(ctx)
+	require.NoError(t, err)
+
+	var numSpent, numUnanchored int
+	tweaks := make(map[asset.SerializedKey][]byte)
+	for _, graphAsset := range importedGraph.Assets {
+		if graphAsset.Spent {
+			numSpent++
+		}
+		if graphAsset.AnchorIndex == nil {
+			numUnanchored++
+
+			tweak := graphAsset.ScriptKey.Tweak
+			require.NotNil(t, tweak)
+			require.Empty(t, tweak)
+		}
+
+		scriptKey := asset.ToSerialized(graphAsset.ScriptKey.PubKey)
+		tweaks[scriptKey] = graphAsset.ScriptKey.Tweak
+	}
+	require.Equal(t, 1, numSpent)
+	require.Equal(t, 1, numUnanchored)
+
+	for _, graphAsset := range graph.Assets {
+		scriptKey := asset.ToSerialized(graphAsset.ScriptKey.PubKey)
+		tweak := graphAsset.ScriptKey.Tweak
+		require.Equal(t, tweak == nil, tweaks[scriptKey] == nil)
+		require.Equal(t, tweak, tweaks[scriptKey])
+	}
+}
+
+// TestAssetGraphImportIdempotent tests that importing the same asset graph
+// more than once doesn't duplicate any assets, while assets that were spent
+// since the last import are marked as spent.
+func TestAssetGraphImportIdempotent(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	importTestAssetGraph(t, assetStore, db)
+	_, digest := exportGraphDigest(t, assetStore)
+
+	graph, err := assetStore.ExportAssetGraph(ctx)
+	require.NoError(t, err)
+
+	// Importing the graph into the database it was exported from
+	// shouldn't change anything.
+	require.NoError(t, assetStore.ImportAssetGraph(ctx, graph))
+
+	_, newDigest := exportGraphDigest(t, assetStore)
+	require.Equal(t, digest, newDigest)
+
+	// We'll now import the graph into a new database twice, spending an
+	// asset in between. The second import should only mark the asset as
+	// spent.
+	_, importStore, _ := newAssetStore(t)
+	unspentGraph := &AssetGraphProto{
+		Anchors: graph.Anchors,
+	}
+	for _, graphAsset := range graph.Assets {
+		unspentAsset := *graphAsset
+		unspentAsset.Spent = false
+		unspentGraph.Assets = append(
+			unspentGraph.Assets, &unspentAsset,
+		)
+	}
+	require.NoError(t, importStore.ImportAssetGraph(ctx, unspentGraph))
+	require.NoError(t, importStore.ImportAssetGraph(ctx, graph))
+
+	_, newDigest = exportGraphDigest(t, importStore)
+	require.Equal(t, digest, newDigest)
+}
+
+// stubAssetSerializer is an AssetSerializer that keeps the encoded asset graph
+// in memory, and only writes a marker to the writer.
+type stubAssetSerializer struct {
+	graph *AssetGraphProto
+}
+
+// stubGraphMarker is written by the stubAssetSerializer in place of the graph.
+var stubGraphMarker = []byte("stub graph")
+
+// Encode serializes the given asset graph into the passed writer.
+func (s *stubAssetSerializer) Encode(w io.Writer,
+	graph *AssetGraphProto) error {
+
+	s.graph = graph
+	_, err := w.Write(stubGraphMarker)
+	return err
+}
+
+// Decode deserializes an asset graph from the passed reader.
+func (s *stubAssetSerializer) Decode(r io.Reader) (*AssetGraphProto, error) {
+	marker, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(marker, stubGraphMarker) || s.graph == nil {
+		return nil, fmt.Errorf("unknown graph")
+	}
+
+	return s.graph, nil
+}
+
+// TestAssetSerializerRoundTrip tests that an asset graph exported from one
+// database with a serializer can be imported into another one with the same
+// serializer, resulting in the very same graph.
+func TestAssetSerializerRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		serializer AssetSerializer
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:       "json",
+			serializer: &JSONAssetSerializer{},
+		},
+		{
+			name:       "proto",
+			serializer: &ProtoAssetSerializer{},
+		},
+		{
+			name:       "stub",
+			serializer: &stubAssetSerializer{},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, assetStore, db := newAssetStore(t)
+			ctx := context.Background()
+
+			importTestAssetGraph(t, assetStore, db)
+			_, digest := exportGraphDigest(t, assetStore)
+
+			var b bytes.Buffer
+			err := assetStore.ExportAssetGraphTo(
+				ctx, &b, testCase.serializer,
+			)
+			require.NoError(t, err)
+
+			_, importStore, _ := newAssetStore(t)
+			err = importStore.ImportAssetGraphFrom(
+				ctx, &b, testCase.serializer,
+			)
+			require.NoError(t, err)
+
+			_, newDigest := exportGraphDigest(t, importStore)
+			require.Equal(t, digest, newDigest)
+		})
+	}
+}
+
+// importTestAssetGraph imports a grouped and an ungrouped asset of the same
+// genesis point, which are anchored in the same output, and an asset with a
+// script key that commits to a tapscript root, which is spent. It also inserts
+// an asset with an empty script key tweak that isn't anchored yet.
+func importTestAssetGraph(t *testing.T, assetStore *AssetStore,
+	db BatchedQuerier) {
+
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	sharedAnchor := randAnchorUTXO(t)
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, []*asset.Asset{
+			randAsset(
+				t, withAssetGenPoint(genesisPoint),
+				withAssetGenKeyGroup(test.RandPrivKey(t)),
+			),
+			randAsset(
+				t, withAssetGenPoint(genesisPoint),
+				withNoGroupKey(),
+			),
+		}, []AnchorUTXO{sharedAnchor, sharedAnchor},
+	)
+	require.NoError(t, err)
+
+	tapscriptAsset := randAsset(
+		t, withScriptKey(tapscriptScriptKey(t, test.RandBytes(32))),
+	)
+	err = assetStore.ImportAssetsWithAnchors(
+		ctx, tapscriptAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{tapscriptAsset}, []AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	tapscriptKey := tapscriptAsset.ScriptKey.PubKey.SerializeCompressed()
+	dbAssets, err := db.QueryAssets(ctx, QueryAssetFilters{
+		ScriptKeyFilter: tapscriptKey,
+	})
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 1)
+
+	_, err = db.SetAssetSpent(ctx, dbAssets[0].AssetPrimaryKey)
+	require.NoError(t, err)
+
+	scriptKey := asset.NewScriptKeyBIP0086(keychain.KeyDescriptor{
+		PubKey: test.RandPubKey(t),
+	})
+	scriptKey.Tweak = []byte{}
+	unanchoredAsset := randAsset(t, withScriptKey(scriptKey))
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(),
+		unanchoredAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{unanchoredAsset}, nil,
+	)
+	require.NoError(t, err)
+}
+
+// exportGraphDigest exports the asset graph of the given store, and returns
+// its protobuf encoding along with the digest of the encoding.
+func exportGraphDigest(t *testing.T, store *AssetStore) ([]byte, [32]byte) {
+	graph, err := store.ExportAssetGraph(context.Background())
+	require.NoError(t, err)
+	require.Len(t, graph.Anchors, 2)
+	require.Len(t, graph.Assets, 4)
+
+	graphBytes, err := graph.Marshal()
+	require.NoError(t, err)
+
+	return graphBytes, sha256.Sum256(graphBytes)
+}
@@ -3,9 +3,11 @@ package tarodb
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
@@ -65,6 +67,10 @@ type (
 	// will create them on-chain.
 	AssetAnchor = sqlc.AnchorPendingAssetsParams
 
+	// AssetOutputAnchor is used to bind the assets of a genesis point that
+	// share the same genesis output index with a managed UTXO.
+	AssetOutputAnchor = sqlc.AnchorAssetsByOutputIndexParams
+
 	// GenesisPointAnchor is used to update the genesis point with the
 	// final information w.r.t where it's confirmed on chain.
 	GenesisPointAnchor = sqlc.AnchorGenesisPointParams
@@ -79,10 +85,30 @@ type (
 	// the DB.
 	GenesisAsset = sqlc.UpsertGenesisAssetParams
 
+	// NewGenesisAssets wraps the params needed to insert a set of genesis
+	// assets on disk in a single statement.
+	NewGenesisAssets = sqlc.UpsertGenesisAssetsParams
+
+	// UpsertedGenesisAsset is a genesis asset returned by a batch upsert
+	// along with its primary key.
+	UpsertedGenesisAsset = sqlc.UpsertGenesisAssetsRow
+
+	// StoredGenesisAsset is the base information of an asset as it's
+	// stored on disk.
+	StoredGenesisAsset = sqlc.GenesisAsset
+
 	// AssetGroupSig is used to insert the group key signature for a given
 	// asset on disk.
 	AssetGroupSig = sqlc.UpsertAssetGroupSigParams
 
+	// GenesisReissuance is used to mark a genesis asset as a reissuance of
+	// its asset group.
+	GenesisReissuance = sqlc.SetGenesisReissuanceParams
+
+	// GenesisMetaReveal is used to store the full metadata of genesis
+	// assets along with the hash of the metadata.
+	GenesisMetaReveal = sqlc.UpsertGenesisMetaRevealParams
+
 	// AssetSprout is used to fetch the set of assets from disk.
 	AssetSprout = sqlc.FetchAssetsForBatchRow
 
@@ -95,6 +121,26 @@ type (
 	// NewScriptKey wraps the params needed to insert a new script key on
 	// disk.
 	NewScriptKey = sqlc.UpsertScriptKeyParams
+
+	// NewGenesisPoint wraps the params needed to insert a new genesis
+	// point on disk.
+	NewGenesisPoint = sqlc.UpsertGenesisPointParams
+
+	// NewGenesisPoints wraps the params needed to insert a set of new
+	// genesis points on disk in a single statement.
+	NewGenesisPoints = sqlc.UpsertGenesisPointsParams
+
+	// UpsertedGenesisPoint is a genesis point returned by a batch upsert
+	// along with its primary key.
+	UpsertedGenesisPoint = sqlc.UpsertGenesisPointsRow
+
+	// UpsertedInternalKey is an internal key returned by a batch upsert
+	// along with its primary key.
+	UpsertedInternalKey = sqlc.UpsertInternalKeysRow
+
+	// GenesisPointTimeRange is used to query for the genesis points that
+	// were created within a given time range.
+	GenesisPointTimeRange = sqlc.FetchGenesisPointsCreatedBetweenParams
 )
 
 // PendingAssetStore is a sub-set of the main sqlc.Querier interface that
@@ -146,17 +192,21 @@ type PendingAssetStore interface {
 		error)
 
 	// AnchorPendingAssets associated an asset on disk with the transaction
-	// that once confirmed will mint the asset.
-	AnchorPendingAssets(ctx context.Context, arg AssetAnchor) error
+	// that once confirmed will mint the asset, and returns the primary
+	// keys of the anchored assets.
+	AnchorPendingAssets(ctx context.Context, arg AssetAnchor) ([]int32,
+		error)
+
+	// AnchorAssetsByOutputIndex associates all assets of a genesis point
+	// with the given genesis output index with a managed UTXO, and returns
+	// the primary keys of the anchored assets.
+	AnchorAssetsByOutputIndex(ctx context.Context,
+		arg AssetOutputAnchor) ([]int32, error)
 
 	// AnchorGenesisPoint associates a genesis point with the transaction
 	// that mints the associated assets on disk.
 	AnchorGenesisPoint(ctx context.Context, arg GenesisPointAnchor) error
 
-	// UpsertChainTx inserts a new or updates an existing chain tx into the
-	// DB.
-	UpsertChainTx(ctx context.Context, arg ChainTx) (int32, error)
-
 	// ConfirmChainTx confirms an existing chain tx.
 	ConfirmChainTx(ctx context.Context, arg ChainTxConf) error
 
@@ -171,6 +221,11 @@ type PendingAssetStore interface {
 	// TODO(roasbeef): move somewhere else??
 	UpsertAssetProof(ctx context.Context,
 		arg sqlc.UpsertAssetProofParams) error
+
+	// FetchGenesisPointsCreatedBetween fetches all genesis points that
+	// were created within the given time range.
+	FetchGenesisPointsCreatedBetween(ctx context.Context,
+		arg GenesisPointTimeRange) ([]sqlc.GenesisPoint, error)
 }
 
 // AssetStoreTxOptions defines the set of db txn options the PendingAssetStore
@@ -203,19 +258,62 @@ type BatchedPendingAssetStore interface {
 	BatchedTx[PendingAssetStore]
 }
 
+// serializationRetryPendingAssetStore wraps a BatchedPendingAssetStore and
+// retries all of its transactions that fail because they conflicted with a
+// concurrent one.
+type serializationRetryPendingAssetStore struct {
+	BatchedPendingAssetStore
+
+	cfg *SerializationRetryConfig
+}
+
+// NewSerializationRetryPendingAssetStore returns a new
+// BatchedPendingAssetStore that transparently retries transactions failing
+// with a serialization failure or a deadlock, such as minting batches racing
+// with concurrent imports under Postgres. Any other error is passed through
+// unchanged.
+func NewSerializationRetryPendingAssetStore(db BatchedPendingAssetStore,
+	cfg *SerializationRetryConfig) BatchedPendingAssetStore {
+
+	return &serializationRetryPendingAssetStore{
+		BatchedPendingAssetStore: db,
+		cfg:                      cfg,
+	}
+}
+
+// ExecTx executes the passed txBody in a single transaction, and retries the
+// transaction from the start if it failed because of a serialization failure.
+//
+// NOTE: This implements the BatchedTx interface.
+func (s *serializationRetryPendingAssetStore) ExecTx(ctx context.Context,
+	txOptions TxOptions, txBody func(PendingAssetStore) error) error {
+
+	return ExecTxWithSerializationRetry[PendingAssetStore](
+		ctx, s.BatchedPendingAssetStore, s.cfg, txOptions, txBody,
+	)
+}
+
 // AssetMintingStore is an implementation of the tarogarden.PlantingLog
 // interface backed by a persistent database. The abstracted
 // BatchedPendingAssetStore permits re-use of the main storage related business
 // logic for any backend that can implement the specified interface.
 type AssetMintingStore struct {
 	db BatchedPendingAssetStore
+
+	// upsertOpts are the policies applied when storing the assets of a
+	// minting batch.
+	upsertOpts *upsertOptions
 }
 
 // NewAssetMintingStore creates a new AssetMintingStore from the specified
-// BatchedPendingAssetStore interface.
-func NewAssetMintingStore(db BatchedPendingAssetStore) *AssetMintingStore {
+// BatchedPendingAssetStore interface. The passed options modify the policies
+// applied when storing the assets of a minting batch.
+func NewAssetMintingStore(db BatchedPendingAssetStore,
+	opts ...UpsertOption) *AssetMintingStore {
+
 	return &AssetMintingStore{
-		db: db,
+		db:         db,
+		upsertOpts: newUpsertOptions(opts...),
 	}
 }
 
@@ -434,11 +532,17 @@ func fetchAssetSprouts(ctx context.Context, q PendingAssetStore,
 			return nil, fmt.Errorf("unable to read "+
 				"outpoint: %w", err)
 		}
+		outputIndex, err := parseOutputIndex(
+			sprout.GenesisOutputIndex,
+		)
+		if err != nil {
+			return nil, err
+		}
 		assetGenesis := asset.Genesis{
 			FirstPrevOut: genesisPrevOut,
 			Tag:          sprout.AssetTag,
 			Metadata:     sprout.MetaData,
-			OutputIndex:  uint32(sprout.GenesisOutputIndex),
+			OutputIndex:  outputIndex,
 			Type:         asset.Type(sprout.AssetType),
 		}
 
@@ -622,7 +726,7 @@ func (a *AssetMintingStore) AddSproutsToBatch(ctx context.Context,
 	var writeTxOpts AssetStoreTxOptions
 	return a.db.ExecTx(ctx, &writeTxOpts, func(q PendingAssetStore) error {
 		genesisPointID, _, err := upsertAssetsWithGenesis(
-			ctx, q, genesisOutpoint, assets, nil,
+			ctx, q, a.upsertOpts, genesisOutpoint, assets, nil,
 		)
 		if err != nil {
 			return fmt.Errorf("error inserting assets with "+
@@ -747,13 +851,17 @@ func (a *AssetMintingStore) CommitSignedGenesisTx(ctx context.Context,
 		// With the managed UTXO inserted, we also need to update all
 		// the assets created in a prior step to also reference this
 		// managed UTXO.
-		err = q.AnchorPendingAssets(ctx, AssetAnchor{
+		assetIDs, err := q.AnchorPendingAssets(ctx, AssetAnchor{
 			PrevOut:      genesisOutpoint,
 			AnchorUtxoID: sqlInt32(utxoID),
 		})
 		if err != nil {
 			return fmt.Errorf("unable to anchor pending assets: %v", err)
 		}
+		err = a.upsertOpts.auditAssetUpdates(ctx, q, assetIDs)
+		if err != nil {
+			return err
+		}
 
 		// Next, we'll anchor the genesis point to point to the chain
 		// transaction we inserted above.
@@ -772,6 +880,40 @@ func (a *AssetMintingStore) CommitSignedGenesisTx(ctx context.Context,
 	})
 }
 
+// LinkBatchAnchors anchors the assets created from the given genesis point to
+// the managed UTXOs of the confirmed minting transaction. Each asset is linked
+// to the managed UTXO that's mapped to its genesis output index. All updates
+// are applied within a single transaction.
+func (a *AssetMintingStore) LinkBatchAnchors(ctx context.Context,
+	genesisPointID int32,
+	anchorUtxoIDByOutputIndex map[uint32]int32) error {
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q PendingAssetStore) error {
+		for outputIndex, utxoID := range anchorUtxoIDByOutputIndex {
+			anchor := AssetOutputAnchor{
+				AnchorUtxoID:   sqlInt32(utxoID),
+				GenesisPointID: genesisPointID,
+				OutputIndex:    int32(outputIndex),
+			}
+			assetIDs, err := q.AnchorAssetsByOutputIndex(
+				ctx, anchor,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to anchor assets of "+
+					"output index %v: %w", outputIndex, err)
+			}
+
+			err = a.upsertOpts.auditAssetUpdates(ctx, q, assetIDs)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 // MarkBatchConfirmed stores final confirmation information for a batch on
 // disk.
 func (a *AssetMintingStore) MarkBatchConfirmed(ctx context.Context,
@@ -820,6 +962,113 @@ func (a *AssetMintingStore) MarkBatchConfirmed(ctx context.Context,
 	})
 }
 
+// GenesisPointInfo holds a genesis point along with the time it was first
+// stored.
+type GenesisPointInfo struct {
+	// OutPoint is the genesis point itself.
+	OutPoint wire.OutPoint
+
+	// CreatedAt is the time the genesis point was first stored.
+	CreatedAt time.Time
+}
+
+// FetchGenesisPointsCreatedBetween returns all genesis points that were first
+// stored within the given (inclusive) time range, ordered by creation time.
+// Genesis points stored before their creation time was tracked are never
+// returned.
+func (a *AssetMintingStore) FetchGenesisPointsCreatedBetween(
+	ctx context.Context, start, end time.Time) ([]GenesisPointInfo, error) {
+
+	var genesisPoints []GenesisPointInfo
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q PendingAssetStore) error {
+		dbPoints, err := q.FetchGenesisPointsCreatedBetween(
+			ctx, GenesisPointTimeRange{
+				CreatedAfter: sql.NullTime{
+					Time:  start.UTC(),
+					Valid: true,
+				},
+				CreatedBefore: sql.NullTime{
+					Time:  end.UTC(),
+					Valid: true,
+				},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to fetch genesis points: %w",
+				err)
+		}
+
+		genesisPoints = make([]GenesisPointInfo, len(dbPoints))
+		for i, dbPoint := range dbPoints {
+			err := readOutPoint(
+				bytes.NewReader(dbPoint.PrevOut), 0, 0,
+				&genesisPoints[i].OutPoint,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to read genesis "+
+					"point: %w", err)
+			}
+
+			genesisPoints[i].CreatedAt = dbPoint.CreatedAt.Time
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return genesisPoints, nil
+}
+
+// DayCount is the number of mints that happened on a single day.
+type DayCount struct {
+	// Day is the start of the day (in UTC).
+	Day time.Time
+
+	// NumMints is the number of genesis points that were created on this
+	// day.
+	NumMints int
+}
+
+// FetchMintsPerDay returns the number of mints (genesis points) that were
+// created on each day within the given (inclusive) time range, ordered by day.
+// Days without any mints are omitted.
+//
+// NOTE: Days are bucketed in Go rather than in SQL, as the date functions
+// differ between our database backends.
+func (a *AssetMintingStore) FetchMintsPerDay(ctx context.Context, start,
+	end time.Time) ([]DayCount, error) {
+
+	genesisPoints, err := a.FetchGenesisPointsCreatedBetween(
+		ctx, start, end,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// As the genesis points are ordered by creation time, all points of a
+	// single day are next to each other.
+	var dayCounts []DayCount
+	for _, genesisPoint := range genesisPoints {
+		day := genesisPoint.CreatedAt.UTC().Truncate(24 * time.Hour)
+
+		numDays := len(dayCounts)
+		if numDays == 0 || !dayCounts[numDays-1].Day.Equal(day) {
+			dayCounts = append(dayCounts, DayCount{
+				Day: day,
+			})
+			numDays++
+		}
+
+		dayCounts[numDays-1].NumMints++
+	}
+
+	return dayCounts, nil
+}
+
 // A compile-time assertion to ensure that AssetMintingStore meets the
 // tarogarden.MintingStore interface.
 var _ tarogarden.MintingStore = (*AssetMintingStore)(nil)
@@ -16,6 +16,7 @@ import (
 	"github.com/lightninglabs/taro/asset"
 	"github.com/lightninglabs/taro/chanutils"
 	"github.com/lightninglabs/taro/commitment"
+	"github.com/lightninglabs/taro/internal/test"
 	"github.com/lightninglabs/taro/proof"
 	"github.com/lightninglabs/taro/tarodb/sqlc"
 	"github.com/lightninglabs/taro/tarogarden"
@@ -25,9 +26,9 @@ import (
 )
 
 // newAssetStore makes a new instance of the AssetMintingStore backed by sqlite
-// by default.
-func newAssetStore(t *testing.T) (*AssetMintingStore, *AssetStore,
-	sqlc.Querier) {
+// by default. The passed options are applied to both stores.
+func newAssetStore(t testing.TB, opts ...UpsertOption) (*AssetMintingStore,
+	*AssetStore, BatchedQuerier) {
 
 	// First, Make a new test database.
 	db := NewTestDB(t)
@@ -47,8 +48,8 @@ func newAssetStore(t *testing.T) (*AssetMintingStore, *AssetStore,
 	assetsDB := NewTransactionExecutor[ActiveAssetsStore](
 		db, activeTxCreator,
 	)
-	return NewAssetMintingStore(assetMintingDB), NewAssetStore(assetsDB),
-		db
+	return NewAssetMintingStore(assetMintingDB, opts...),
+		NewAssetStore(assetsDB, opts...), db
 }
 
 func assertBatchState(t *testing.T, batch *tarogarden.MintingBatch,
@@ -566,7 +567,9 @@ func TestDuplicateGroupKey(t *testing.T) {
 	// Before we can insert the group key, we also need to insert a valid
 	// genesis point as well. We'll just use the key again as uniqueness is
 	// what matters.
-	genesisPointID, err := db.UpsertGenesisPoint(ctx, rawKey)
+	genesisPointID, err := db.UpsertGenesisPoint(ctx, NewGenesisPoint{
+		PrevOut: rawKey,
+	})
 	require.NoError(t, err)
 
 	// We'll just use the same group key here as it doesn't really matter
@@ -589,3 +592,212 @@ func TestDuplicateGroupKey(t *testing.T) {
 func init() {
 	rand.Seed(time.Now().Unix())
 }
+
+// TestFetchGenesisPointsCreatedBetween tests that we're able to fetch the
+// genesis points that were created within a given time range.
+func TestFetchGenesisPointsCreatedBetween(t *testing.T) {
+	t.Parallel()
+
+	assetStore, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll insert a series of genesis points, each created an hour after
+	// the previous one.
+	const numPoints = 5
+	baseTime := time.Unix(1_600_000_000, 0).UTC()
+	genesisPoints := make([]wire.OutPoint, numPoints)
+	for i := 0; i < numPoints; i++ {
+		genesisPoints[i] = test.RandOp(t)
+		prevOut, err := encodeOutpoint(genesisPoints[i])
+		require.NoError(t, err)
+
+		_, err = db.UpsertGenesisPoint(ctx, NewGenesisPoint{
+			PrevOut: prevOut,
+			CreatedAt: sql.NullTime{
+				Time:  baseTime.Add(time.Duration(i) * time.Hour),
+				Valid: true,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	// A genesis point without a creation time should never be returned.
+	prevOut, err := encodeOutpoint(test.RandOp(t))
+	require.NoError(t, err)
+	_, err = db.UpsertGenesisPoint(ctx, NewGenesisPoint{
+		PrevOut: prevOut,
+	})
+	require.NoError(t, err)
+
+	// Querying for the range spanning the second to the fourth point
+	// should return exactly those, in order.
+	points, err := assetStore.FetchGenesisPointsCreatedBetween(
+		ctx, baseTime.Add(time.Hour), baseTime.Add(3*time.Hour),
+	)
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	for i, point := range points {
+		require.Equal(t, genesisPoints[i+1], point.OutPoint)
+		require.True(t, baseTime.Add(time.Duration(i+1)*time.Hour).Equal(
+			point.CreatedAt,
+		))
+	}
+
+	// A range before all points should return nothing.
+	points, err = assetStore.FetchGenesisPointsCreatedBetween(
+		ctx, baseTime.Add(-2*time.Hour), baseTime.Add(-time.Hour),
+	)
+	require.NoError(t, err)
+	require.Empty(t, points)
+
+	// Upserting an existing genesis point shouldn't change its creation
+	// time.
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoints[0])
+	require.NoError(t, err)
+	require.NotZero(t, genesisPointID)
+
+	points, err = assetStore.FetchGenesisPointsCreatedBetween(
+		ctx, baseTime, baseTime,
+	)
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	require.Equal(t, genesisPoints[0], points[0].OutPoint)
+}
+
+// TestFetchMintsPerDay tests that we're able to count the number of mints per
+// day within a given time range.
+func TestFetchMintsPerDay(t *testing.T) {
+	t.Parallel()
+
+	assetStore, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll mint twice on the first day, skip a day, then mint three
+	// times on the third day and once on the fourth day.
+	dayStart := time.Unix(1_600_000_000, 0).UTC().Truncate(24 * time.Hour)
+	day := func(n int) time.Time {
+		return dayStart.Add(time.Duration(n) * 24 * time.Hour)
+	}
+	mintTimes := []time.Time{
+		day(0).Add(time.Hour), day(0).Add(23 * time.Hour),
+		day(2), day(2).Add(time.Minute), day(2).Add(12 * time.Hour),
+		day(3).Add(6 * time.Hour),
+	}
+	for _, mintTime := range mintTimes {
+		prevOut, err := encodeOutpoint(test.RandOp(t))
+		require.NoError(t, err)
+
+		_, err = db.UpsertGenesisPoint(ctx, NewGenesisPoint{
+			PrevOut: prevOut,
+			CreatedAt: sql.NullTime{
+				Time:  mintTime,
+				Valid: true,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	// Querying for all days should return the counts of each day with at
+	// least one mint.
+	dayCounts, err := assetStore.FetchMintsPerDay(ctx, day(0), day(4))
+	require.NoError(t, err)
+	require.Len(t, dayCounts, 3)
+
+	expectedCounts := []DayCount{
+		{Day: day(0), NumMints: 2},
+		{Day: day(2), NumMints: 3},
+		{Day: day(3), NumMints: 1},
+	}
+	for i, dayCount := range dayCounts {
+		require.True(t, expectedCounts[i].Day.Equal(dayCount.Day))
+		require.Equal(t, expectedCounts[i].NumMints, dayCount.NumMints)
+	}
+
+	// The range is applied to the exact creation time, so a range
+	// starting mid-day only counts the mints after that.
+	dayCounts, err = assetStore.FetchMintsPerDay(
+		ctx, day(2).Add(time.Hour), day(3),
+	)
+	require.NoError(t, err)
+	require.Len(t, dayCounts, 1)
+	require.True(t, day(2).Equal(dayCounts[0].Day))
+	require.Equal(t, 1, dayCounts[0].NumMints)
+
+	// A range without any mints should return nothing.
+	dayCounts, err = assetStore.FetchMintsPerDay(ctx, day(5), day(6))
+	require.NoError(t, err)
+	require.Empty(t, dayCounts)
+}
+
+// TestLinkBatchAnchors tests that we're able to link the assets of a genesis
+// point to a set of managed UTXOs based on their genesis output index.
+func TestLinkBatchAnchors(t *testing.T) {
+	t.Parallel()
+
+	assetStore, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create two assets that were minted into the first output, one
+	// that was minted into the second output, and one that was minted into
+	// an output we won't link.
+	genesisPoint := test.RandOp(t)
+	newAsset := func(outputIndex uint32) *asset.Asset {
+		gen := asset.RandGenesis(t, asset.Normal)
+		gen.OutputIndex = outputIndex
+
+		return randAsset(
+			t, withAssetGen(gen), withAssetGenPoint(genesisPoint),
+		)
+	}
+	assets := []*asset.Asset{
+		newAsset(0), newAsset(0), newAsset(1), newAsset(2),
+	}
+	genesisPointID, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), genesisPoint, assets, nil,
+	)
+	require.NoError(t, err)
+
+	// Next, we'll insert the managed UTXOs the assets should be anchored
+	// to.
+	newAnchorUTXO := func() int32 {
+		utxoID, err := upsertAnchorUTXO(
+			ctx, db, newUpsertOptions(), randAnchorUTXO(t),
+		)
+		require.NoError(t, err)
+
+		return utxoID
+	}
+	firstUtxoID, secondUtxoID := newAnchorUTXO(), newAnchorUTXO()
+
+	err = assetStore.LinkBatchAnchors(ctx, genesisPointID, map[uint32]int32{
+		0: firstUtxoID,
+		1: secondUtxoID,
+	})
+	require.NoError(t, err)
+
+	// Each UTXO should now anchor the assets minted into its output.
+	firstAssets, err := db.FetchAssetsByAnchorTx(ctx, sqlInt32(firstUtxoID))
+	require.NoError(t, err)
+	require.Len(t, firstAssets, 2)
+
+	secondAssets, err := db.FetchAssetsByAnchorTx(
+		ctx, sqlInt32(secondUtxoID),
+	)
+	require.NoError(t, err)
+	require.Len(t, secondAssets, 1)
+
+	// The asset of the output we didn't link should still be unanchored.
+	prevOut, err := encodeOutpoint(genesisPoint)
+	require.NoError(t, err)
+	dbAssets, err := db.AssetsByGenesisPoint(ctx, prevOut)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, len(assets))
+
+	var numUnanchored int
+	for _, dbAsset := range dbAssets {
+		if !dbAsset.AnchorUtxoID.Valid {
+			numUnanchored++
+		}
+	}
+	require.Equal(t, 1, numUnanchored)
+}
@@ -0,0 +1,352 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightningnetwork/lnd/keychain"
+)
+
+// AssetSerializer is used to encode an asset graph into a serialized format
+// and decode it again, so the format of exported assets can be chosen by the
+// caller.
+type AssetSerializer interface {
+	// Encode serializes the given asset graph into the passed writer.
+	Encode(w io.Writer, graph *AssetGraphProto) error
+
+	// Decode deserializes an asset graph from the passed reader.
+	Decode(r io.Reader) (*AssetGraphProto, error)
+}
+
+// ExportAssetGraphTo exports all the assets on disk as an asset graph, and
+// writes it to the passed writer using the given serializer. If no serializer
+// is given, the graph is encoded as JSON.
+func (a *AssetStore) ExportAssetGraphTo(ctx context.Context, w io.Writer,
+	serializer AssetSerializer) error {
+
+	if serializer == nil {
+		serializer = &JSONAssetSerializer{}
+	}
+
+	graph, err := a.ExportAssetGraph(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := serializer.Encode(w, graph); err != nil {
+		return fmt.Errorf("unable to encode asset graph: %w", err)
+	}
+
+	return nil
+}
+
+// ImportAssetGraphFrom reads an asset graph from the passed reader using the
+// given serializer, and imports all of its assets. If no serializer is given,
+// the graph is expected to be encoded as JSON.
+func (a *AssetStore) ImportAssetGraphFrom(ctx context.Context, r io.Reader,
+	serializer AssetSerializer) error {
+
+	if serializer == nil {
+		serializer = &JSONAssetSerializer{}
+	}
+
+	graph, err := serializer.Decode(r)
+	if err != nil {
+		return fmt.Errorf("unable to decode asset graph: %w", err)
+	}
+
+	return a.ImportAssetGraph(ctx, graph)
+}
+
+// ProtoAssetSerializer is an AssetSerializer that encodes the asset graph as
+// a protobuf message.
+type ProtoAssetSerializer struct{}
+
+// Encode serializes the given asset graph into the passed writer.
+//
+// NOTE: This implements the AssetSerializer interface.
+func (p *ProtoAssetSerializer) Encode(w io.Writer,
+	graph *AssetGraphProto) error {
+
+	graphBytes, err := graph.Marshal()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(graphBytes)
+	return err
+}
+
+// Decode deserializes an asset graph from the passed reader.
+//
+// NOTE: This implements the AssetSerializer interface.
+func (p *ProtoAssetSerializer) Decode(r io.Reader) (*AssetGraphProto, error) {
+	graphBytes, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var graph AssetGraphProto
+	if err := graph.Unmarshal(graphBytes); err != nil {
+		return nil, err
+	}
+
+	return &graph, nil
+}
+
+// JSONAssetSerializer is an AssetSerializer that encodes the asset graph as a
+// JSON object. The fields of the object mirror the fields of the protobuf
+// message the graph is otherwise encoded as, with binary values encoded as
+// base64 strings.
+type JSONAssetSerializer struct{}
+
+// jsonAssetGraph is the JSON encoding of an asset graph.
+type jsonAssetGraph struct {
+	Anchors []*jsonGraphAnchor `json:"anchors"`
+	Assets  []*jsonGraphAsset  `json:"assets"`
+}
+
+// jsonGraphAnchor is the JSON encoding of an anchor of the asset graph.
+type jsonGraphAnchor struct {
+	AnchorTx         []byte            `json:"anchor_tx"`
+	OutputIndex      uint32            `json:"output_index"`
+	OutputValue      int64             `json:"output_value"`
+	InternalKey      jsonKeyDescriptor `json:"internal_key"`
+	TaroRoot         []byte            `json:"taro_root"`
+	TapscriptSibling []byte            `json:"tapscript_sibling,omitempty"`
+}
+
+// jsonGraphAsset is the JSON encoding of an asset of the asset graph.
+type jsonGraphAsset struct {
+	// Asset is the TLV encoding of the asset.
+	Asset       []byte             `json:"asset"`
+	AnchorIndex *uint32            `json:"anchor_index,omitempty"`
+	ScriptKey   *jsonKeyDescriptor `json:"script_key,omitempty"`
+
+	// ScriptKeyTweak is encoded as null if there is no tweak, and as an
+	// empty string if the tweak is empty.
+	ScriptKeyTweak []byte             `json:"script_key_tweak"`
+	GroupKey       *jsonKeyDescriptor `json:"group_key,omitempty"`
+	Spent          bool               `json:"spent,omitempty"`
+}
+
+// jsonKeyDescriptor is the JSON encoding of a key descriptor of the asset
+// graph.
+type jsonKeyDescriptor struct {
+	RawKey    []byte `json:"raw_key,omitempty"`
+	KeyFamily uint32 `json:"key_family"`
+	KeyIndex  uint32 `json:"key_index"`
+}
+
+// Encode serializes the given asset graph into the passed writer.
+//
+// NOTE: This implements the AssetSerializer interface.
+func (j *JSONAssetSerializer) Encode(w io.Writer,
+	graph *AssetGraphProto) error {
+
+	jsonGraph := jsonAssetGraph{
+		Anchors: make([]*jsonGraphAnchor, len(graph.Anchors)),
+		Assets:  make([]*jsonGraphAsset, len(graph.Assets)),
+	}
+	for i, anchor := range graph.Anchors {
+		if anchor.AnchorTx == nil {
+			return fmt.Errorf("anchor tx for %v missing",
+				anchor.OutPoint)
+		}
+
+		var anchorTx bytes.Buffer
+		if err := anchor.AnchorTx.Serialize(&anchorTx); err != nil {
+			return err
+		}
+
+		jsonGraph.Anchors[i] = &jsonGraphAnchor{
+			AnchorTx:    anchorTx.Bytes(),
+			OutputIndex: anchor.OutPoint.Index,
+			OutputValue: int64(anchor.OutputValue),
+			InternalKey: newJSONKeyDescriptor(
+				anchor.InternalKey,
+			),
+			TaroRoot:         anchor.TaroRoot,
+			TapscriptSibling: anchor.TapscriptSibling,
+		}
+	}
+
+	for i, graphAsset := range graph.Assets {
+		var assetTLV bytes.Buffer
+		if err := graphAsset.Encode(&assetTLV); err != nil {
+			return err
+		}
+
+		jsonAsset := &jsonGraphAsset{
+			Asset:       assetTLV.Bytes(),
+			AnchorIndex: graphAsset.AnchorIndex,
+			Spent:       graphAsset.Spent,
+		}
+
+		// The TLV encoding of the asset only contains the tweaked
+		// script and group keys, so we'll also add the raw keys they
+		// were derived from.
+		scriptKey := graphAsset.ScriptKey.TweakedScriptKey
+		if scriptKey != nil {
+			rawKey := newJSONKeyDescriptor(scriptKey.RawKey)
+			jsonAsset.ScriptKey = &rawKey
+			jsonAsset.ScriptKeyTweak = scriptKey.Tweak
+		}
+		if graphAsset.GroupKey != nil {
+			rawKey := newJSONKeyDescriptor(
+				graphAsset.GroupKey.RawKey,
+			)
+			jsonAsset.GroupKey = &rawKey
+		}
+
+		jsonGraph.Assets[i] = jsonAsset
+	}
+
+	return json.NewEncoder(w).Encode(&jsonGraph)
+}
+
+// Decode deserializes an asset graph from the passed reader.
+//
+// NOTE: This implements the AssetSerializer interface.
+func (j *JSONAssetSerializer) Decode(r io.Reader) (*AssetGraphProto, error) {
+	var jsonGraph jsonAssetGraph
+	if err := json.NewDecoder(r).Decode(&jsonGraph); err != nil {
+		return nil, err
+	}
+
+	graph := &AssetGraphProto{
+		Anchors: make([]*AnchorUTXO, len(jsonGraph.Anchors)),
+		Assets:  make([]*GraphAsset, len(jsonGraph.Assets)),
+	}
+	for i, jsonAnchor := range jsonGraph.Anchors {
+		anchorTx := wire.NewMsgTx(2)
+		err := anchorTx.Deserialize(bytes.NewReader(
+			jsonAnchor.AnchorTx,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode anchor tx: "+
+				"%w", err)
+		}
+
+		internalKey, err := jsonAnchor.InternalKey.keyDescriptor()
+		if err != nil {
+			return nil, err
+		}
+
+		graph.Anchors[i] = &AnchorUTXO{
+			ManagedUTXO: ManagedUTXO{
+				OutPoint: wire.OutPoint{
+					Hash:  anchorTx.TxHash(),
+					Index: jsonAnchor.OutputIndex,
+				},
+				OutputValue: btcutil.Amount(
+					jsonAnchor.OutputValue,
+				),
+				InternalKey:      internalKey,
+				TaroRoot:         jsonAnchor.TaroRoot,
+				TapscriptSibling: jsonAnchor.TapscriptSibling,
+			},
+			AnchorTx: anchorTx,
+		}
+	}
+
+	for i, jsonAsset := range jsonGraph.Assets {
+		graphAsset, err := jsonAsset.graphAsset()
+		if err != nil {
+			return nil, err
+		}
+
+		graph.Assets[i] = graphAsset
+	}
+
+	return graph, nil
+}
+
+// graphAsset converts the JSON encoding of an asset back into an asset of the
+// asset graph.
+func (j *jsonGraphAsset) graphAsset() (*GraphAsset, error) {
+	graphAsset := &GraphAsset{
+		Asset:       &asset.Asset{},
+		AnchorIndex: j.AnchorIndex,
+		Spent:       j.Spent,
+	}
+	if err := graphAsset.Decode(bytes.NewReader(j.Asset)); err != nil {
+		return nil, fmt.Errorf("unable to decode asset: %w", err)
+	}
+
+	// With the asset decoded, we can now add the raw keys of its script
+	// and group key.
+	if j.ScriptKey != nil {
+		rawKey, err := j.ScriptKey.keyDescriptor()
+		if err != nil {
+			return nil, err
+		}
+
+		graphAsset.ScriptKey.TweakedScriptKey = &asset.TweakedScriptKey{
+			RawKey: rawKey,
+			Tweak:  j.ScriptKeyTweak,
+		}
+	}
+
+	if j.GroupKey != nil {
+		if graphAsset.GroupKey == nil {
+			return nil, fmt.Errorf("raw group key of asset " +
+				"without group key")
+		}
+
+		rawKey, err := j.GroupKey.keyDescriptor()
+		if err != nil {
+			return nil, err
+		}
+		graphAsset.GroupKey.RawKey = rawKey
+	}
+
+	return graphAsset, nil
+}
+
+// newJSONKeyDescriptor returns the JSON encoding of the given key descriptor.
+func newJSONKeyDescriptor(key keychain.KeyDescriptor) jsonKeyDescriptor {
+	jsonKey := jsonKeyDescriptor{
+		KeyFamily: uint32(key.Family),
+		KeyIndex:  key.Index,
+	}
+	if key.PubKey != nil {
+		jsonKey.RawKey = key.PubKey.SerializeCompressed()
+	}
+
+	return jsonKey
+}
+
+// keyDescriptor converts the JSON encoding of a key descriptor back into a
+// key descriptor.
+func (j *jsonKeyDescriptor) keyDescriptor() (keychain.KeyDescriptor, error) {
+	key := keychain.KeyDescriptor{
+		KeyLocator: keychain.KeyLocator{
+			Family: keychain.KeyFamily(j.KeyFamily),
+			Index:  j.KeyIndex,
+		},
+	}
+	if len(j.RawKey) == 0 {
+		return key, nil
+	}
+
+	var err error
+	key.PubKey, err = btcec.ParsePubKey(j.RawKey)
+	if err != nil {
+		return key, fmt.Errorf("unable to parse raw key: %w", err)
+	}
+
+	return key, nil
+}
+
+// A compile-time assertion to ensure that ProtoAssetSerializer and
+// JSONAssetSerializer meet the AssetSerializer interface.
+var _ AssetSerializer = (*ProtoAssetSerializer)(nil)
+var _ AssetSerializer = (*JSONAssetSerializer)(nil)
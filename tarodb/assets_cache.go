@@ -0,0 +1,406 @@
+package tarodb
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/proof"
+	"github.com/lightninglabs/taro/tarofreighter"
+)
+
+const (
+	// DefaultAssetCacheSize is the default number of fully reconstructed
+	// assets the CachedAssetStore keeps in memory.
+	DefaultAssetCacheSize = 1000
+)
+
+// cachedAsset is a single entry within the asset cache.
+type cachedAsset struct {
+	key   assetSortKey
+	asset *ChainAsset
+}
+
+// CachedAssetStore wraps an AssetStore with a read-through LRU cache for
+// fully reconstructed assets, as reconstructing a single asset requires us to
+// stitch together the information of many tables. Only an explicit set of the
+// methods of the AssetStore is exposed, and each of the writes among them
+// invalidates the cached versions of the assets it may affect.
+//
+// NOTE: Mutations made through the wrapped AssetStore directly bypass the
+// cache, so all writes must go through the CachedAssetStore.
+type CachedAssetStore struct {
+	store *AssetStore
+
+	cacheSize int
+
+	mu      sync.Mutex
+	entries map[assetSortKey]*list.Element
+	lru     *list.List
+
+	// generation is incremented on each invalidation, which allows us to
+	// detect that an asset was mutated while we were reading it from disk.
+	generation uint64
+}
+
+// NewCachedAssetStore creates a new CachedAssetStore that keeps up to
+// cacheSize assets in memory.
+func NewCachedAssetStore(store *AssetStore,
+	cacheSize int) *CachedAssetStore {
+
+	return &CachedAssetStore{
+		store:     store,
+		cacheSize: cacheSize,
+		entries:   make(map[assetSortKey]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// FetchAsset fetches the asset with the given asset ID and script key, along
+// with the information of where it's anchored on chain. The asset is served
+// from the cache if possible.
+//
+// NOTE: The returned asset is shared with the cache, so it must not be
+// modified by the caller.
+func (c *CachedAssetStore) FetchAsset(ctx context.Context, id asset.ID,
+	scriptKey *btcec.PublicKey) (*ChainAsset, error) {
+
+	key := newAssetSortKey(id, scriptKey)
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.mu.Unlock()
+
+		return elem.Value.(*cachedAsset).asset, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	chainAsset, err := c.store.FetchAsset(ctx, id, scriptKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// If the cache was invalidated while we were reading from disk, then
+	// the asset we read may already be stale, so we won't cache it.
+	if generation != c.generation {
+		return chainAsset, nil
+	}
+
+	// Another caller may have populated the cache in the meantime, in
+	// which case we'll just refresh the entry.
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cachedAsset).asset = chainAsset
+		c.lru.MoveToFront(elem)
+
+		return chainAsset, nil
+	}
+
+	c.entries[key] = c.lru.PushFront(&cachedAsset{
+		key:   key,
+		asset: chainAsset,
+	})
+	for c.lru.Len() > c.cacheSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedAsset).key)
+	}
+
+	return chainAsset, nil
+}
+
+// invalidate removes the assets identified by the passed keys from the cache.
+func (c *CachedAssetStore) invalidate(keys ...assetSortKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.lru.Remove(elem)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// purge removes all assets from the cache.
+func (c *CachedAssetStore) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[assetSortKey]*list.Element)
+	c.lru.Init()
+}
+
+// ImportProofs attempts to store fully populated proofs on disk, and
+// invalidates the cached versions of the assets the proofs are for.
+//
+// NOTE: This implements the proof.ArchiveBackend interface.
+func (c *CachedAssetStore) ImportProofs(ctx context.Context,
+	proofs ...*proof.AnnotatedProof) error {
+
+	// We invalidate the assets even if the import fails, as we don't
+	// know which of them were written.
+	keys := fMap(proofs, func(p *proof.AnnotatedProof) assetSortKey {
+		return newAssetSortKey(p.Asset.ID(), p.Asset.ScriptKey.PubKey)
+	})
+	defer c.invalidate(keys...)
+
+	return c.store.ImportProofs(ctx, proofs...)
+}
+
+// ImportAssetsWithAnchors imports the passed assets along with the UTXOs that
+// anchor them, and invalidates the cached versions of the assets.
+func (c *CachedAssetStore) ImportAssetsWithAnchors(ctx context.Context,
+	genesisOutpoint wire.OutPoint, assets []*asset.Asset,
+	anchors []AnchorUTXO) error {
+
+	keys := fMap(assets, func(a *asset.Asset) assetSortKey {
+		return newAssetSortKey(a.ID(), a.ScriptKey.PubKey)
+	})
+	defer c.invalidate(keys...)
+
+	return c.store.ImportAssetsWithAnchors(
+		ctx, genesisOutpoint, assets, anchors,
+	)
+}
+
+// ReleaseQuarantinedAsset removes an asset from the quarantine and imports its
+// fixed version, invalidating the cached version of the fixed asset.
+func (c *CachedAssetStore) ReleaseQuarantinedAsset(ctx context.Context,
+	quarantineID int32, fixedAsset *asset.Asset, anchor AnchorUTXO) error {
+
+	defer c.invalidate(newAssetSortKey(
+		fixedAsset.ID(), fixedAsset.ScriptKey.PubKey,
+	))
+
+	return c.store.ReleaseQuarantinedAsset(
+		ctx, quarantineID, fixedAsset, anchor,
+	)
+}
+
+// TransferAsset archives the old state of an asset and inserts its new state.
+//
+// As the old asset is only identified by its primary key, we can't tell which
+// of the cached assets it is, so the entire cache is purged.
+func (c *CachedAssetStore) TransferAsset(ctx context.Context,
+	oldAssetID int32, newAsset *asset.Asset, newAnchorUtxoID int32) error {
+
+	defer c.purge()
+
+	return c.store.TransferAsset(
+		ctx, oldAssetID, newAsset, newAnchorUtxoID,
+	)
+}
+
+// DeleteAssetByScriptKey deletes all assets with the given tweaked script key.
+//
+// As the assets are only identified by their script key, we can't tell which
+// of the cached assets they are, so the entire cache is purged.
+func (c *CachedAssetStore) DeleteAssetByScriptKey(ctx context.Context,
+	tweakedScriptKey []byte) error {
+
+	defer c.purge()
+
+	return c.store.DeleteAssetByScriptKey(ctx, tweakedScriptKey)
+}
+
+// ArchiveAssetsByIDs archives the assets with the given primary keys by
+// marking them as spent.
+//
+// As the cache isn't keyed by the primary keys of the assets, we can't tell
+// which of the cached assets are affected, so the entire cache is purged.
+func (c *CachedAssetStore) ArchiveAssetsByIDs(ctx context.Context,
+	ids []int32) (int64, error) {
+
+	defer c.purge()
+
+	return c.store.ArchiveAssetsByIDs(ctx, ids)
+}
+
+// ConfirmParcelDelivery marks a spend event on disk as confirmed. This updates
+// the on-chain reference information on disk to point to this new spend.
+//
+// As confirming a parcel re-anchors all assets that shared the spent anchor
+// point, we can't tell which of the cached assets are affected, so the entire
+// cache is purged.
+//
+// NOTE: This implements the tarofreighter.ExportLog interface.
+func (c *CachedAssetStore) ConfirmParcelDelivery(ctx context.Context,
+	conf *tarofreighter.AssetConfirmEvent) error {
+
+	defer c.purge()
+
+	return c.store.ConfirmParcelDelivery(ctx, conf)
+}
+
+// ResumeImport imports the passed assets in chunks, skipping the ones a
+// previous import of the same batch already committed, and invalidates the
+// cached versions of the assets.
+func (c *CachedAssetStore) ResumeImport(ctx context.Context, batchID string,
+	genesisOutpoint wire.OutPoint, assets []*asset.Asset,
+	anchors []AnchorUTXO, chunkSize int) error {
+
+	keys := fMap(assets, func(a *asset.Asset) assetSortKey {
+		return newAssetSortKey(a.ID(), a.ScriptKey.PubKey)
+	})
+	defer c.invalidate(keys...)
+
+	return c.store.ResumeImport(
+		ctx, batchID, genesisOutpoint, assets, anchors, chunkSize,
+	)
+}
+
+// ImportAssetGraph imports all the assets of the given asset graph, and
+// invalidates the cached versions of the assets.
+//
+// As the graph only holds the encoded assets, we'd need to decode all of them
+// to tell which of the cached assets are affected, so the entire cache is
+// purged instead.
+func (c *CachedAssetStore) ImportAssetGraph(ctx context.Context,
+	graph *AssetGraphProto) error {
+
+	defer c.purge()
+
+	return c.store.ImportAssetGraph(ctx, graph)
+}
+
+// ImportAssetGraphFrom reads an asset graph from the passed reader using the
+// given serializer, and imports all of its assets. The entire cache is purged,
+// as the imported assets are only known once the graph was read.
+func (c *CachedAssetStore) ImportAssetGraphFrom(ctx context.Context,
+	r io.Reader, serializer AssetSerializer) error {
+
+	defer c.purge()
+
+	return c.store.ImportAssetGraphFrom(ctx, r, serializer)
+}
+
+// BindAssetAnchor binds the asset with the given primary key to the managed
+// UTXO that anchors it.
+//
+// As the cache isn't keyed by the primary keys of the assets, we can't tell
+// which of the cached assets is affected, so the entire cache is purged.
+func (c *CachedAssetStore) BindAssetAnchor(ctx context.Context, assetID int32,
+	anchorUtxoID int32) error {
+
+	defer c.purge()
+
+	return c.store.BindAssetAnchor(ctx, assetID, anchorUtxoID)
+}
+
+// TouchAssets bumps the update time of the assets with the given primary keys.
+//
+// As the cache isn't keyed by the primary keys of the assets, we can't tell
+// which of the cached assets are affected, so the entire cache is purged.
+func (c *CachedAssetStore) TouchAssets(ctx context.Context, ids []int32) error {
+	defer c.purge()
+
+	return c.store.TouchAssets(ctx, ids)
+}
+
+// WithAssetStore executes the passed closure within a single database
+// transaction. As the closure may write anything, the entire cache is purged.
+func (c *CachedAssetStore) WithAssetStore(ctx context.Context,
+	f func(UpsertAssetStore) error) error {
+
+	defer c.purge()
+
+	return c.store.WithAssetStore(ctx, f)
+}
+
+// LogPendingParcel marks an outbound parcel as pending on disk. The entire
+// cache is purged, as the parcel spends assets we can only identify by their
+// primary keys.
+//
+// NOTE: This implements the tarofreighter.ExportLog interface.
+func (c *CachedAssetStore) LogPendingParcel(ctx context.Context,
+	spend *tarofreighter.OutboundParcelDelta) error {
+
+	defer c.purge()
+
+	return c.store.LogPendingParcel(ctx, spend)
+}
+
+// SetAssetMetaType sets the type of the metadata of the asset with the given
+// ID. The entire cache is purged, as all assets of the genesis are affected.
+func (c *CachedAssetStore) SetAssetMetaType(ctx context.Context, id asset.ID,
+	metaType MetaType) error {
+
+	defer c.purge()
+
+	return c.store.SetAssetMetaType(ctx, id, metaType)
+}
+
+// UpsertGenesisAssets inserts new or resolves existing genesis assets with the
+// given metadata policy. The entire cache is purged, as updating the metadata
+// type of a genesis affects all of its assets.
+func (c *CachedAssetStore) UpsertGenesisAssets(ctx context.Context,
+	genesisAssets []GenesisAsset,
+	policy MetadataPolicy) ([]int32, error) {
+
+	defer c.purge()
+
+	return c.store.UpsertGenesisAssets(ctx, genesisAssets, policy)
+}
+
+// UpsertInternalKeys inserts new or updates existing internal keys. The
+// entire cache is purged, as updating a key affects all assets that use it.
+func (c *CachedAssetStore) UpsertInternalKeys(ctx context.Context,
+	keys []InternalKey) ([]int32, error) {
+
+	defer c.purge()
+
+	return c.store.UpsertInternalKeys(ctx, keys)
+}
+
+// FetchProof fetches a proof for an asset uniquely identified by the passed
+// locator.
+//
+// NOTE: This implements the proof.ArchiveBackend interface.
+func (c *CachedAssetStore) FetchProof(ctx context.Context,
+	locator proof.Locator) (proof.Blob, error) {
+
+	return c.store.FetchProof(ctx, locator)
+}
+
+// PendingParcels returns the set of parcels that haven't yet been finalized.
+//
+// NOTE: This implements the tarofreighter.ExportLog interface.
+func (c *CachedAssetStore) PendingParcels(
+	ctx context.Context) ([]*tarofreighter.OutboundParcelDelta, error) {
+
+	return c.store.PendingParcels(ctx)
+}
+
+// FetchAllAssets fetches the set of confirmed assets stored on disk. The
+// assets are always read from disk, as the query may match any of them.
+func (c *CachedAssetStore) FetchAllAssets(ctx context.Context,
+	query *AssetQueryFilters) ([]*ChainAsset, error) {
+
+	return c.store.FetchAllAssets(ctx, query)
+}
+
+// FetchManagedUTXOs fetches all UTXOs we manage.
+func (c *CachedAssetStore) FetchManagedUTXOs(ctx context.Context) (
+	[]*ManagedUTXO, error) {
+
+	return c.store.FetchManagedUTXOs(ctx)
+}
+
+// A compile-time assertion to ensure that CachedAssetStore meets the
+// proof.Archiver interface.
+var _ proof.Archiver = (*CachedAssetStore)(nil)
+
+// A compile-time assertion to ensure that CachedAssetStore meets the
+// tarofreighter.ExportLog interface.
+var _ tarofreighter.ExportLog = (*CachedAssetStore)(nil)
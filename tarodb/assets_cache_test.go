@@ -0,0 +1,198 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/lightninglabs/taro/mssmt"
+	"github.com/lightninglabs/taro/tarofreighter"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCachedAssetStore tests that the CachedAssetStore serves assets from its
+// cache, and that it doesn't return stale assets after they were mutated.
+func TestCachedAssetStore(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	cachedStore := NewCachedAssetStore(assetStore, DefaultAssetCacheSize)
+	ctx := context.Background()
+
+	// We'll import two assets that share the same anchor. We'll later
+	// spend the first one, which re-anchors the second one as well.
+	genesisPoint := test.RandOp(t)
+	newAsset := func() *asset.Asset {
+		scriptKey := asset.NewScriptKeyBIP0086(keychain.KeyDescriptor{
+			PubKey: test.RandPubKey(t),
+		})
+
+		return randAsset(
+			t, withAssetGenPoint(genesisPoint), withNoGroupKey(),
+			withScriptKey(scriptKey),
+		)
+	}
+	spentAsset, passiveAsset := newAsset(), newAsset()
+
+	anchor := randAnchorUTXO(t)
+	err := cachedStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, []*asset.Asset{spentAsset, passiveAsset},
+		[]AnchorUTXO{anchor, anchor},
+	)
+	require.NoError(t, err)
+
+	// Fetching the passive asset twice should return the very same
+	// instance the second time around, as it's served from the cache.
+	fetchPassive := func() *ChainAsset {
+		chainAsset, err := cachedStore.FetchAsset(
+			ctx, passiveAsset.ID(), passiveAsset.ScriptKey.PubKey,
+		)
+		require.NoError(t, err)
+
+		return chainAsset
+	}
+	cachedAsset := fetchPassive()
+	require.Equal(t, anchor.OutPoint, cachedAsset.AnchorOutpoint)
+	require.Same(t, cachedAsset, fetchPassive())
+
+	// An asset we don't know of should result in an error.
+	_, err = cachedStore.FetchAsset(
+		ctx, passiveAsset.ID(), test.RandPubKey(t),
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+
+	// We'll now spend the first asset, which moves both assets to a new
+	// anchor once the transfer confirms.
+	newAnchorTx := wire.NewMsgTx(2)
+	newAnchorTx.AddTxIn(&wire.TxIn{})
+	newAnchorTx.AddTxOut(&wire.TxOut{
+		PkScript: bytes.Repeat([]byte{0x01}, 34),
+		Value:    1000,
+	})
+	newAnchorPoint := wire.OutPoint{
+		Hash:  newAnchorTx.TxHash(),
+		Index: 0,
+	}
+	spendDelta := &tarofreighter.OutboundParcelDelta{
+		OldAnchorPoint: anchor.OutPoint,
+		NewAnchorPoint: newAnchorPoint,
+		NewInternalKey: keychain.KeyDescriptor{
+			PubKey: test.RandPubKey(t),
+		},
+		TaroRoot: test.RandBytes(32),
+		AnchorTx: newAnchorTx,
+		AssetSpendDeltas: []tarofreighter.AssetSpendDelta{{
+			OldScriptKey: *spentAsset.ScriptKey.PubKey,
+			NewAmt:       spentAsset.Amount / 2,
+			NewScriptKey: asset.NewScriptKeyBIP0086(
+				keychain.KeyDescriptor{
+					PubKey: test.RandPubKey(t),
+				},
+			),
+			SplitCommitmentRoot: mssmt.NewComputedNode(
+				sha256.Sum256([]byte("root")),
+				spentAsset.Amount,
+			),
+			WitnessData: []asset.Witness{{
+				PrevID:    &asset.PrevID{},
+				TxWitness: [][]byte{{0x01}},
+			}},
+			SenderAssetProof:   test.RandBytes(100),
+			ReceiverAssetProof: test.RandBytes(100),
+		}},
+	}
+	require.NoError(t, cachedStore.LogPendingParcel(ctx, spendDelta))
+
+	err = cachedStore.ConfirmParcelDelivery(
+		ctx, &tarofreighter.AssetConfirmEvent{
+			AnchorPoint:      newAnchorPoint,
+			BlockHeight:      100,
+			BlockHash:        chainhash.Hash{0x01},
+			FinalSenderProof: test.RandBytes(100),
+		},
+	)
+	require.NoError(t, err)
+
+	// The cached version of the passive asset should now have been
+	// invalidated, so we should get back a fresh, non-stale asset that
+	// reflects its new anchor.
+	freshAsset := fetchPassive()
+	require.NotSame(t, cachedAsset, freshAsset)
+	require.Equal(t, newAnchorPoint, freshAsset.AnchorOutpoint)
+	assertAssetEqual(t, passiveAsset, freshAsset.Asset)
+}
+
+// TestCachedAssetStoreWritesInvalidate tests that writes made through the
+// CachedAssetStore that can't tell which assets they affect purge the cache.
+func TestCachedAssetStoreWritesInvalidate(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	cachedStore := NewCachedAssetStore(assetStore, DefaultAssetCacheSize)
+	ctx := context.Background()
+
+	newAsset := randAsset(t)
+	anchor := randAnchorUTXO(t)
+	err := cachedStore.ImportAssetsWithAnchors(
+		ctx, newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+		[]AnchorUTXO{anchor},
+	)
+	require.NoError(t, err)
+
+	fetchAsset := func() *ChainAsset {
+		chainAsset, err := cachedStore.FetchAsset(
+			ctx, newAsset.ID(), newAsset.ScriptKey.PubKey,
+		)
+		require.NoError(t, err)
+
+		return chainAsset
+	}
+
+	anchorKey := anchor.InternalKey.PubKey.SerializeCompressed()
+
+	testCases := []struct {
+		name  string
+		write func() error
+	}{
+		{
+			name: "touch assets",
+			write: func() error {
+				return cachedStore.TouchAssets(ctx, nil)
+			},
+		},
+		{
+			name: "with asset store",
+			write: func() error {
+				return cachedStore.WithAssetStore(
+					ctx, func(UpsertAssetStore) error {
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name: "upsert internal keys",
+			write: func() error {
+				_, err := cachedStore.UpsertInternalKeys(
+					ctx, []InternalKey{{
+						RawKey: anchorKey,
+					}},
+				)
+				return err
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		cachedAsset := fetchAsset()
+		require.Same(t, cachedAsset, fetchAsset())
+
+		require.NoError(t, testCase.write(), testCase.name)
+		require.NotSame(t, cachedAsset, fetchAsset(), testCase.name)
+	}
+}
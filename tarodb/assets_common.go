@@ -3,10 +3,14 @@ package tarodb
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btclog"
 	"github.com/lightninglabs/taro/asset"
 	"github.com/lightninglabs/taro/tarodb/sqlc"
 )
@@ -16,7 +20,14 @@ import (
 type UpsertAssetStore interface {
 	// UpsertGenesisPoint inserts a new or updates an existing genesis point
 	// on disk, and returns the primary key.
-	UpsertGenesisPoint(ctx context.Context, prevOut []byte) (int32, error)
+	UpsertGenesisPoint(ctx context.Context, arg NewGenesisPoint) (int32,
+		error)
+
+	// UpsertGenesisPoints inserts new or updates existing genesis points
+	// on disk with a single statement, and returns their primary keys
+	// along with the genesis points in no particular order.
+	UpsertGenesisPoints(ctx context.Context,
+		arg NewGenesisPoints) ([]UpsertedGenesisPoint, error)
 
 	// UpsertGenesisAsset inserts a new or updates an existing genesis asset
 	// (the base asset info) in the DB, and returns the primary key.
@@ -27,6 +38,18 @@ type UpsertAssetStore interface {
 	//  * or use a sort of mix-in type?
 	UpsertGenesisAsset(ctx context.Context, arg GenesisAsset) (int32, error)
 
+	// UpsertGenesisAssets inserts new or resolves existing genesis assets
+	// with a single statement, and returns their primary keys along with
+	// their tags in no particular order. No row is returned for a genesis
+	// asset whose upsert the metadata policy rejected.
+	UpsertGenesisAssets(ctx context.Context,
+		arg NewGenesisAssets) ([]UpsertedGenesisAsset, error)
+
+	// FetchGenesisAssetByTag fetches the genesis asset with the given
+	// tag.
+	FetchGenesisAssetByTag(ctx context.Context,
+		assetTag string) (StoredGenesisAsset, error)
+
 	// FetchScriptKeyIDByTweakedKey determines the database ID of a script
 	// key by querying it by the tweaked key.
 	FetchScriptKeyIDByTweakedKey(ctx context.Context,
@@ -36,6 +59,12 @@ type UpsertAssetStore interface {
 	// into the database.
 	UpsertInternalKey(ctx context.Context, arg InternalKey) (int32, error)
 
+	// UpsertInternalKeys inserts new or updates existing internal keys
+	// with a single statement, and returns their primary keys along with
+	// the raw keys in no particular order.
+	UpsertInternalKeys(ctx context.Context,
+		keys []InternalKey) ([]UpsertedInternalKey, error)
+
 	// UpsertScriptKey inserts a new script key on disk into the DB.
 	UpsertScriptKey(context.Context, NewScriptKey) (int32, error)
 
@@ -47,165 +76,922 @@ type UpsertAssetStore interface {
 	UpsertAssetGroupKey(ctx context.Context, arg AssetGroupKey) (int32,
 		error)
 
+	// FetchGroupSigIDByGenesisID fetches the primary key of the group sig
+	// of the genesis asset with the given primary key.
+	FetchGroupSigIDByGenesisID(ctx context.Context,
+		genAssetID int32) (int32, error)
+
+	// SetGenesisReissuance marks a genesis asset as a reissuance if another
+	// genesis asset was issued under its group key before it.
+	SetGenesisReissuance(ctx context.Context, arg GenesisReissuance) error
+
+	// UpsertGenesisMetaReveal stores the full metadata of genesis assets
+	// with the given metadata hash, unless it's already known.
+	UpsertGenesisMetaReveal(ctx context.Context,
+		arg GenesisMetaReveal) error
+
+	// AuditLogStore houses the methods related to appending to the audit
+	// log, which records the writes made while importing assets.
+	AuditLogStore
+
 	// InsertNewAsset inserts a new asset on disk.
 	InsertNewAsset(ctx context.Context,
 		arg sqlc.InsertNewAssetParams) (int32, error)
+
+	// FetchGenesisPointIDByGenAssetID returns the primary key of the
+	// genesis point the given genesis asset was created from.
+	FetchGenesisPointIDByGenAssetID(ctx context.Context,
+		genAssetID int32) (int32, error)
+
+	// CountAssetsByGenesisPoint returns the number of assets that were
+	// created from the given genesis point.
+	CountAssetsByGenesisPoint(ctx context.Context,
+		genesisPointID int32) (int64, error)
+
+	// SetAssetBigAmount stores the exact amount of an asset that doesn't
+	// fit into the amount column.
+	SetAssetBigAmount(ctx context.Context,
+		arg sqlc.SetAssetBigAmountParams) error
+
+	// UpsertChainTx inserts a new or updates an existing chain tx into the
+	// DB.
+	UpsertChainTx(ctx context.Context, arg ChainTx) (int32, error)
 }
 
-// upsertGenesis imports a new genesis point into the database or returns the
-// existing ID if that point already exists.
+// maxGenesisPointsPerUpsert is the maximum number of genesis points that are
+// upserted with a single statement, which keeps the number of query
+// parameters well below the limits of the database backends.
+const maxGenesisPointsPerUpsert = 1000
+
+// maxGenesisAssetsPerUpsert is the maximum number of genesis assets that are
+// upserted with a single statement. Each genesis asset takes up seven query
+// parameters.
+const maxGenesisAssetsPerUpsert = 1000
+
+// maxInternalKeysPerUpsert is the maximum number of internal keys that are
+// upserted with a single statement. Each key takes up three query parameters.
+const maxInternalKeysPerUpsert = 1000
+
+// upsertGenesisPoint imports a new genesis point into the database or returns
+// the existing ID if that point already exists.
 func upsertGenesisPoint(ctx context.Context, q UpsertAssetStore,
 	genesisOutpoint wire.OutPoint) (int32, error) {
 
-	genesisPoint, err := encodeOutpoint(genesisOutpoint)
+	genesisPointIDs, err := upsertGenesisPoints(
+		ctx, q, []wire.OutPoint{genesisOutpoint},
+	)
 	if err != nil {
-		return 0, fmt.Errorf("unable to encode genesis point: %w", err)
+		return 0, err
 	}
 
-	// First, we'll insert the component that ties together all the assets
-	// in a batch: the genesis point.
-	genesisPointID, err := q.UpsertGenesisPoint(ctx, genesisPoint)
-	if err != nil {
-		return 0, fmt.Errorf("unable to insert genesis point: %w", err)
+	return genesisPointIDs[0], nil
+}
+
+// upsertGenesisPoints imports new genesis points into the database in bulk,
+// and returns the IDs of the new or already existing points in the same order
+// as the given outpoints. Outpoints that are passed more than once resolve to
+// the same ID.
+func upsertGenesisPoints(ctx context.Context, q UpsertAssetStore,
+	genesisOutpoints []wire.OutPoint) ([]int32, error) {
+
+	// As a single statement can't upsert the same row twice, we'll only
+	// encode each distinct outpoint once.
+	var prevOuts [][]byte
+	uniquePoints := make(map[wire.OutPoint]struct{}, len(genesisOutpoints))
+	for _, genesisOutpoint := range genesisOutpoints {
+		if _, ok := uniquePoints[genesisOutpoint]; ok {
+			continue
+		}
+		uniquePoints[genesisOutpoint] = struct{}{}
+
+		genesisPoint, err := encodeOutpoint(genesisOutpoint)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode genesis "+
+				"point: %w", err)
+		}
+		prevOuts = append(prevOuts, genesisPoint)
+	}
+
+	// The points are returned in no particular order, so we'll map them
+	// back to their IDs by their encoding.
+	createdAt := sql.NullTime{
+		Time:  time.Now().UTC(),
+		Valid: true,
+	}
+	pointIDs := make(map[string]int32, len(prevOuts))
+	for start := 0; start < len(prevOuts); {
+		end := start + maxGenesisPointsPerUpsert
+		if end > len(prevOuts) {
+			end = len(prevOuts)
+		}
+
+		dbPoints, err := q.UpsertGenesisPoints(ctx, NewGenesisPoints{
+			PrevOuts:  prevOuts[start:end],
+			CreatedAt: createdAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to insert genesis "+
+				"points: %w", normalizeDBError(err))
+		}
+		for _, dbPoint := range dbPoints {
+			pointIDs[string(dbPoint.PrevOut)] = dbPoint.GenesisID
+		}
+
+		start = end
 	}
 
-	return genesisPointID, nil
+	genesisPointIDs := make([]int32, len(genesisOutpoints))
+	for i, genesisOutpoint := range genesisOutpoints {
+		genesisPoint, err := encodeOutpoint(genesisOutpoint)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode genesis "+
+				"point: %w", err)
+		}
+
+		pointID, ok := pointIDs[string(genesisPoint)]
+		if !ok {
+			return nil, fmt.Errorf("genesis point %v not upserted",
+				genesisOutpoint)
+		}
+		genesisPointIDs[i] = pointID
+	}
+
+	return genesisPointIDs, nil
+}
+
+// MetadataPolicy decides what happens when a genesis asset that already exists
+// is imported again with different metadata. As the asset ID commits to the
+// metadata and stored assets reference the existing genesis asset, its asset
+// ID and metadata are never changed. Instead, the policy decides whether the
+// differing metadata is ignored or the import is rejected.
+type MetadataPolicy int16
+
+const (
+	// MetadataKeepExisting keeps the existing metadata, and ignores the
+	// metadata of the genesis being imported. This is the default.
+	MetadataKeepExisting MetadataPolicy = 0
+
+	// MetadataReplace requests the existing metadata to be replaced with
+	// the metadata of the genesis being imported. As that would change the
+	// asset ID of the existing genesis asset, the import is rejected with
+	// ErrGenesisImmutable if the metadata differs.
+	MetadataReplace MetadataPolicy = 1
+
+	// MetadataPreferLonger requests the existing metadata to be replaced
+	// only if the metadata of the genesis being imported is longer. Just
+	// like MetadataReplace, the import is rejected with
+	// ErrGenesisImmutable in that case, while shorter metadata is ignored.
+	MetadataPreferLonger MetadataPolicy = 2
+)
+
+// String returns a human readable version of the metadata policy.
+func (p MetadataPolicy) String() string {
+	switch p {
+	case MetadataKeepExisting:
+		return "keep_existing"
+
+	case MetadataReplace:
+		return "replace"
+
+	case MetadataPreferLonger:
+		return "prefer_longer"
+
+	default:
+		return fmt.Sprintf("unknown<%d>", p)
+	}
+}
+
+// MetaType describes how the metadata of a genesis asset should be
+// interpreted.
+type MetaType int16
+
+const (
+	// MetaOpaque is used for metadata that's an opaque blob of bytes. This
+	// is the type of all metadata that wasn't explicitly typed.
+	MetaOpaque MetaType = 0
+
+	// MetaJSON is used for metadata that's a JSON document.
+	MetaJSON MetaType = 1
+
+	// MetaImage is used for metadata that's an image.
+	MetaImage MetaType = 2
+)
+
+// String returns a human readable version of the metadata type.
+func (t MetaType) String() string {
+	switch t {
+	case MetaOpaque:
+		return "opaque"
+
+	case MetaJSON:
+		return "json"
+
+	case MetaImage:
+		return "image"
+
+	default:
+		return fmt.Sprintf("unknown<%d>", t)
+	}
+}
+
+// ScriptKeyType describes how a script key was derived from its raw key, which
+// tells the wallet how to derive the key again to spend an asset.
+type ScriptKeyType int16
+
+const (
+	// ScriptKeyUnknown is used for script keys whose raw key isn't known,
+	// such as the keys of assets imported to mirror the state of another
+	// node. We can't spend assets sent to these keys.
+	ScriptKeyUnknown ScriptKeyType = 0
+
+	// ScriptKeyBip86 is used for script keys that are derived from their
+	// raw key as specified by BIP-86, without any additional tweak.
+	ScriptKeyBip86 ScriptKeyType = 1
+
+	// ScriptKeyTweaked is used for script keys whose raw key is tweaked
+	// with a custom tweak, such as the root of a tapscript tree.
+	ScriptKeyTweaked ScriptKeyType = 2
+)
+
+// String returns a human readable version of the script key type.
+func (t ScriptKeyType) String() string {
+	switch t {
+	case ScriptKeyUnknown:
+		return "unknown"
+
+	case ScriptKeyBip86:
+		return "bip86"
+
+	case ScriptKeyTweaked:
+		return "tweaked"
+
+	default:
+		return fmt.Sprintf("unknown<%d>", t)
+	}
+}
+
+// knownScriptKeyType returns the type of a script key whose raw key is known,
+// based on the tweak it was derived with.
+func knownScriptKeyType(tweak []byte) ScriptKeyType {
+	if len(tweak) == 0 {
+		return ScriptKeyBip86
+	}
+
+	return ScriptKeyTweaked
 }
 
 // upsertGenesis imports a new genesis record into the database or returns the
-// existing ID of the genesis if it already exists.
+// existing ID of the genesis if it already exists. The passed policy decides
+// what happens if the existing genesis has different metadata, see
+// MetadataPolicy.
 func upsertGenesis(ctx context.Context, q UpsertAssetStore,
-	genesisPointID int32, genesis asset.Genesis) (int32, error) {
+	opts *upsertOptions, genesisPointID int32, genesis asset.Genesis,
+	policy MetadataPolicy) (int32, error) {
+
+	// We'll refuse to store excessively large metadata, as it would bloat
+	// the table and slow down any scan of it.
+	if err := checkMetadataSize(opts, genesis.Metadata); err != nil {
+		return 0, err
+	}
+
+	// The metadata itself is stored as a reveal keyed by its hash, which
+	// is all the genesis asset references.
+	if err := upsertMetaReveals(ctx, q, genesis); err != nil {
+		return 0, err
+	}
 
 	// Then we'll insert the genesis_assets row which tracks all the
 	// information that uniquely derives a given asset ID.
+	genAssetID, err := q.UpsertGenesisAsset(
+		ctx, newGenesisAsset(genesisPointID, genesis, policy),
+	)
+	if err != nil {
+		return 0, genesisUpsertError(genesis.Tag, err)
+	}
+
+	return genAssetID, nil
+}
+
+// genesisUpsertError wraps the error of upserting the genesis asset with the
+// given tag. If no row was returned, the upsert was rejected because it would
+// have changed the metadata of the existing genesis asset.
+func genesisUpsertError(tag string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: metadata of %v differs from the stored "+
+			"metadata", ErrGenesisImmutable, tag)
+	}
+
+	return fmt.Errorf("unable to insert genesis asset: %w",
+		normalizeDBError(err))
+}
+
+// newGenesisAsset returns the genesis_assets row of the given genesis, which
+// tracks all the information that uniquely derives a given asset ID. Only the
+// hash of the metadata is stored with the genesis asset, the metadata itself is
+// stored separately with upsertMetaReveals.
+func newGenesisAsset(genesisPointID int32, genesis asset.Genesis,
+	policy MetadataPolicy) GenesisAsset {
+
 	assetID := genesis.ID()
-	genAssetID, err := q.UpsertGenesisAsset(ctx, GenesisAsset{
+	metaHash := genesis.MetadataHash()
+	return GenesisAsset{
 		AssetID:        assetID[:],
 		AssetTag:       genesis.Tag,
-		MetaData:       genesis.Metadata,
 		OutputIndex:    int32(genesis.OutputIndex),
 		AssetType:      int16(genesis.Type),
 		GenesisPointID: genesisPointID,
-	})
+		// The genesis itself doesn't tell us how its metadata should be
+		// interpreted, so we store it as opaque bytes unless the caller
+		// sets a more specific type.
+		MetaType:       int16(MetaOpaque),
+		MetaHash:       metaHash[:],
+		MetadataPolicy: int16(policy),
+	}
+}
+
+// upsertMetaReveals stores the full metadata of the given geneses, keyed by the
+// hash of the metadata the genesis assets reference. Geneses without metadata
+// don't need a reveal, and each distinct metadata is only upserted once.
+func upsertMetaReveals(ctx context.Context, q UpsertAssetStore,
+	geneses ...asset.Genesis) error {
+
+	revealed := make(map[[sha256.Size]byte]struct{}, len(geneses))
+	for _, genesis := range geneses {
+		if len(genesis.Metadata) == 0 {
+			continue
+		}
+
+		metaHash := genesis.MetadataHash()
+		if _, ok := revealed[metaHash]; ok {
+			continue
+		}
+
+		err := q.UpsertGenesisMetaReveal(ctx, GenesisMetaReveal{
+			MetaHash: metaHash[:],
+			MetaData: genesis.Metadata,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to upsert metadata reveal: "+
+				"%w", err)
+		}
+
+		revealed[metaHash] = struct{}{}
+	}
+
+	return nil
+}
+
+// upsertGenesisAssets inserts new or updates existing genesis assets in bulk,
+// and returns their primary keys in the same order as the given genesis
+// assets. As the asset ID commits to all the information of a genesis asset,
+// each distinct asset ID is only upserted once. The genesis assets only
+// reference their metadata by its hash, so the metadata must be stored with
+// upsertMetaReveals beforehand.
+func upsertGenesisAssets(ctx context.Context, q UpsertAssetStore,
+	genesisAssets []GenesisAsset) ([]int32, error) {
+
+	var uniqueAssets []GenesisAsset
+	seenAssets := make(map[string]struct{}, len(genesisAssets))
+	for _, genAsset := range genesisAssets {
+		if _, ok := seenAssets[string(genAsset.AssetID)]; ok {
+			continue
+		}
+		seenAssets[string(genAsset.AssetID)] = struct{}{}
+
+		uniqueAssets = append(uniqueAssets, genAsset)
+	}
+
+	// A single statement can't upsert the same row twice, and all of its
+	// rows share the same metadata policy. So we'll start a new statement
+	// whenever a tag repeats or the policy changes, which keeps the
+	// genesis assets upserted in the given order.
+	uniqueGenAssetIDs := make(map[string]int32, len(uniqueAssets))
+	for start := 0; start < len(uniqueAssets); {
+		policy := uniqueAssets[start].MetadataPolicy
+		tags := make(map[string]struct{})
+
+		end := start
+		for end < len(uniqueAssets) &&
+			end-start < maxGenesisAssetsPerUpsert {
+
+			genAsset := uniqueAssets[end]
+			if _, ok := tags[genAsset.AssetTag]; ok ||
+				genAsset.MetadataPolicy != policy {
+
+				break
+			}
+			tags[genAsset.AssetTag] = struct{}{}
+
+			end++
+		}
+		chunk := uniqueAssets[start:end]
+		start = end
+
+		dbAssets, err := q.UpsertGenesisAssets(ctx, NewGenesisAssets{
+			Assets:         chunk,
+			MetadataPolicy: policy,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to insert genesis "+
+				"assets: %w", normalizeDBError(err))
+		}
+
+		// The genesis assets are returned in no particular order, so
+		// we'll map them back by their tag. No row is returned for a
+		// genesis asset whose upsert the metadata policy rejected.
+		tagIDs := make(map[string]int32, len(dbAssets))
+		for _, dbAsset := range dbAssets {
+			tagIDs[dbAsset.AssetTag] = dbAsset.GenAssetID
+		}
+		for _, genAsset := range chunk {
+			genAssetID, ok := tagIDs[genAsset.AssetTag]
+			if !ok {
+				return nil, genesisUpsertError(
+					genAsset.AssetTag, sql.ErrNoRows,
+				)
+			}
+
+			uniqueGenAssetIDs[string(genAsset.AssetID)] = genAssetID
+		}
+	}
+
+	genAssetIDs := make([]int32, len(genesisAssets))
+	for i, genAsset := range genesisAssets {
+		genAssetIDs[i] = uniqueGenAssetIDs[string(genAsset.AssetID)]
+	}
+
+	return genAssetIDs, nil
+}
+
+// upsertInternalKeys inserts new or updates existing internal keys in bulk,
+// and returns their primary keys in the same order as the given keys. Each
+// distinct key is only upserted once, so importing many assets that share the
+// same keys doesn't result in redundant writes.
+func upsertInternalKeys(ctx context.Context, q UpsertAssetStore,
+	keys []InternalKey) ([]int32, error) {
+
+	// As a single statement can't upsert the same row twice, we'll only
+	// pass each distinct raw key once. Just like a single upsert, the key
+	// family and index of an existing key are never updated.
+	var uniqueKeys []InternalKey
+	seenKeys := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := seenKeys[string(key.RawKey)]; ok {
+			continue
+		}
+		seenKeys[string(key.RawKey)] = struct{}{}
+
+		uniqueKeys = append(uniqueKeys, key)
+	}
+
+	// The keys are returned in no particular order, so we'll map them back
+	// to their IDs by their raw key.
+	rawKeyIDs := make(map[string]int32, len(uniqueKeys))
+	for start := 0; start < len(uniqueKeys); {
+		end := start + maxInternalKeysPerUpsert
+		if end > len(uniqueKeys) {
+			end = len(uniqueKeys)
+		}
+
+		dbKeys, err := q.UpsertInternalKeys(ctx, uniqueKeys[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("unable to insert internal "+
+				"keys: %w", normalizeDBError(err))
+		}
+		for _, dbKey := range dbKeys {
+			rawKeyIDs[string(dbKey.RawKey)] = dbKey.KeyID
+		}
+
+		start = end
+	}
+
+	keyIDs := make([]int32, len(keys))
+	for i, key := range keys {
+		keyID, ok := rawKeyIDs[string(key.RawKey)]
+		if !ok {
+			return nil, fmt.Errorf("internal key %x not upserted",
+				key.RawKey)
+		}
+		keyIDs[i] = keyID
+	}
+
+	return keyIDs, nil
+}
+
+// internalKeyCacheKey identifies an internal key within an internalKeyCache.
+type internalKeyCacheKey struct {
+	rawKey    string
+	keyFamily int32
+	keyIndex  int32
+}
+
+// internalKeyCache maps the internal keys that were already upserted to their
+// primary keys. A cache is only meant to be used for the duration of a single
+// database transaction, so internal keys shared by many assets of the same
+// batch are only written once.
+type internalKeyCache map[internalKeyCacheKey]int32
+
+// newInternalKeyCacheKey returns the cache key of the given internal key.
+func newInternalKeyCacheKey(key InternalKey) internalKeyCacheKey {
+	return internalKeyCacheKey{
+		rawKey:    string(key.RawKey),
+		keyFamily: key.KeyFamily,
+		keyIndex:  key.KeyIndex,
+	}
+}
+
+// upsertInternalKeyOnce returns the primary key of the given internal key. If
+// the key is already part of the given cache, then it isn't upserted again.
+// Otherwise, the key is upserted and added to the cache. A nil cache disables
+// caching.
+func upsertInternalKeyOnce(ctx context.Context, q UpsertAssetStore,
+	key InternalKey, keyCache internalKeyCache) (int32, error) {
+
+	cacheKey := newInternalKeyCacheKey(key)
+	if keyID, ok := keyCache[cacheKey]; ok {
+		return keyID, nil
+	}
+
+	keyID, err := q.UpsertInternalKey(ctx, key)
 	if err != nil {
-		return 0, fmt.Errorf("unable to insert genesis asset: %w", err)
+		return 0, normalizeDBError(err)
 	}
 
-	return genAssetID, nil
+	if keyCache != nil {
+		keyCache[cacheKey] = keyID
+	}
+
+	return keyID, nil
+}
+
+// groupInternalKey returns the internal key that's referenced by the given
+// group key.
+func groupInternalKey(groupKey *asset.GroupKey) InternalKey {
+	// When we insert a proof, we don't know the raw key. So we just insert
+	// the tweaked key as the internal key.
+	//
+	// TODO(roasbeef):
+	//   * don't have the key desc information here necessarily
+	//   * inserting the group key rn, which is ok as its external w/ no key
+	//     desc info
+	rawKeyBytes := groupKey.GroupPubKey.SerializeCompressed()
+	if groupKey.RawKey.PubKey != nil {
+		rawKeyBytes = groupKey.RawKey.PubKey.SerializeCompressed()
+	}
+
+	return InternalKey{
+		RawKey:    rawKeyBytes,
+		KeyFamily: int32(groupKey.RawKey.Family),
+		KeyIndex:  int32(groupKey.RawKey.Index),
+	}
+}
+
+// scriptInternalKey returns the internal key of the given script key, if the
+// raw script key is known.
+func scriptInternalKey(scriptKey asset.ScriptKey) (InternalKey, bool) {
+	if scriptKey.TweakedScriptKey == nil {
+		return InternalKey{}, false
+	}
+
+	return InternalKey{
+		RawKey:    scriptKey.RawKey.PubKey.SerializeCompressed(),
+		KeyFamily: int32(scriptKey.RawKey.Family),
+		KeyIndex:  int32(scriptKey.RawKey.Index),
+	}, true
 }
 
 // upsertAssetsWithGenesis imports new assets and their genesis information into
 // the database.
 func upsertAssetsWithGenesis(ctx context.Context, q UpsertAssetStore,
-	genesisOutpoint wire.OutPoint, assets []*asset.Asset,
+	opts *upsertOptions, genesisOutpoint wire.OutPoint,
+	assets []*asset.Asset,
 	anchorUtxoIDs []sql.NullInt32) (int32, []int32, error) {
 
+	upserted, err := upsertAssetsWithGenesisIDs(
+		ctx, q, opts, genesisOutpoint, assets, anchorUtxoIDs,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return upserted.genesisPointID, upserted.assetIDs, nil
+}
+
+// upsertedAssets holds the primary keys of all the rows that were inserted or
+// updated when upserting a batch of assets along with their genesis. Apart from
+// the genesis point, each of them is aligned with the upserted assets.
+type upsertedAssets struct {
+	// genesisPointID is the primary key of the genesis point shared by
+	// all the assets.
+	genesisPointID int32
+
+	// genAssetIDs are the primary keys of the genesis assets.
+	genAssetIDs []int32
+
+	// groupKeys are the primary keys of the rows backing the group keys.
+	// They're zero for assets without a group key.
+	groupKeys []upsertedGroupKey
+
+	// scriptKeyIDs are the primary keys of the script keys.
+	scriptKeyIDs []int32
+
+	// assetIDs are the primary keys of the assets themselves.
+	assetIDs []int32
+}
+
+// upsertAssetsWithGenesisIDs imports new assets and their genesis information
+// into the database just like upsertAssetsWithGenesis, but returns the primary
+// keys of all the rows the assets depend on as well.
+func upsertAssetsWithGenesisIDs(ctx context.Context, q UpsertAssetStore,
+	opts *upsertOptions, genesisOutpoint wire.OutPoint,
+	assets []*asset.Asset,
+	anchorUtxoIDs []sql.NullInt32) (*upsertedAssets, error) {
+
+	// We'll refuse the whole batch if any of the assets carries
+	// excessively large metadata or an amount we can't represent, so we
+	// don't end up with a partially inserted genesis.
+	logger := opts.upsertLogger()
+	numAssets := len(assets)
+	for idx, a := range assets {
+		err := checkMetadataSize(opts, a.Genesis.Metadata)
+		if err == nil {
+			err = checkAssetAmount(opts, a.Amount)
+		}
+		if err != nil {
+			logAssetUpsertFailure(logger, idx, numAssets, a, err)
+			return nil, err
+		}
+	}
+
+	// All the writes below are recorded in the audit log, if the store
+	// keeps one.
+	q = opts.auditStore(q)
+
 	// First, we'll insert the component that ties together all the assets
 	// in a batch: the genesis point.
 	genesisPointID, err := upsertGenesisPoint(ctx, q, genesisOutpoint)
 	if err != nil {
-		return 0, nil, fmt.Errorf("unable to upsert genesis point: %w",
+		logger.Debugf("Unable to upsert genesis point %v for %d "+
+			"assets: %v", genesisOutpoint, numAssets, err)
+
+		return nil, fmt.Errorf("unable to upsert genesis point: %w",
 			err)
 	}
+	logger.Tracef("Upserted genesis point %v (id=%d) for %d assets",
+		genesisOutpoint, genesisPointID, numAssets)
+
+	// If the store limits the number of assets per genesis point, we'll
+	// make sure the whole batch fits before inserting any of the assets.
+	err = checkAssetQuota(ctx, q, opts, genesisPointID, numAssets)
+	if err != nil {
+		return nil, err
+	}
+
+	// Before inserting the assets one by one, we'll insert all the
+	// internal keys they reference in bulk, as many of them are usually
+	// shared between the assets of a batch. The resulting IDs are cached
+	// for the rest of this batch only, so any key that's referenced again
+	// further below resolves to its ID without hitting the database.
+	keyCache := make(internalKeyCache)
+	for _, a := range assets {
+		if a.GroupKey != nil {
+			_, err := upsertInternalKeyOnce(
+				ctx, q, groupInternalKey(a.GroupKey), keyCache,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("unable to insert "+
+					"internal key: %w", err)
+			}
+		}
+		if key, ok := scriptInternalKey(a.ScriptKey); ok {
+			_, err := upsertInternalKeyOnce(ctx, q, key, keyCache)
+			if err != nil {
+				return nil, fmt.Errorf("unable to insert "+
+					"internal key: %w", err)
+			}
+		}
+	}
+
+	// If the store asks for it, we'll insert the genesis assets and the
+	// assets themselves in a specific order. The returned IDs are still
+	// aligned with the passed assets.
+	assetIndexes := sortedAssetIndexes(assets, opts.insertOrder)
+
+	// We'll also make sure the genesis asset information of all the
+	// assets exists in the database, along with their metadata.
+	geneses := fMap(assets, func(a *asset.Asset) asset.Genesis {
+		return a.Genesis
+	})
+	if err := upsertMetaReveals(ctx, q, geneses...); err != nil {
+		return nil, err
+	}
+	genesisAssets := fMap(assetIndexes, func(idx int) GenesisAsset {
+		return newGenesisAsset(
+			genesisPointID, assets[idx].Genesis,
+			MetadataKeepExisting,
+		)
+	})
+	sortedGenAssetIDs, err := upsertGenesisAssets(ctx, q, genesisAssets)
+	if err != nil {
+		return nil, fmt.Errorf("unable to upsert genesis: %w", err)
+	}
+	upserted := &upsertedAssets{
+		genesisPointID: genesisPointID,
+		genAssetIDs:    make([]int32, len(assets)),
+		groupKeys:      make([]upsertedGroupKey, len(assets)),
+		scriptKeyIDs:   make([]int32, len(assets)),
+		assetIDs:       make([]int32, len(assets)),
+	}
+	for i, idx := range assetIndexes {
+		upserted.genAssetIDs[idx] = sortedGenAssetIDs[i]
+	}
 
 	// We'll now insert each asset into the database. Some assets have a key
 	// group, so we'll need to insert them before we can insert the asset
 	// itself.
-	assetIDs := make([]int32, len(assets))
-	for idx, a := range assets {
-		// First, we make sure the genesis asset information exists in
-		// the database.
-		genAssetID, err := upsertGenesis(
-			ctx, q, genesisPointID, a.Genesis,
-		)
-		if err != nil {
-			return 0, nil, fmt.Errorf("unable to upsert genesis: "+
-				"%w", err)
+	for _, idx := range assetIndexes {
+		a := assets[idx]
+		genAssetID := upserted.genAssetIDs[idx]
+
+		// Computing the asset ID requires hashing the genesis, so
+		// we'll only do so if we're going to log it.
+		traceAsset := func(format string, params ...interface{}) {
+			if logger.Level() > btclog.LevelTrace {
+				return
+			}
+
+			assetParams := []interface{}{idx, numAssets, a.ID()}
+			logger.Tracef("Asset %d of %d (asset_id=%v): "+format,
+				append(assetParams, params...)...)
 		}
 
 		// This asset has as key group, so we'll insert it into the
 		// database. If it doesn't exist, the UPSERT query will still
 		// return the group_id we'll need.
-		groupSigID, err := upsertGroupKey(
+		groupIDs, err := upsertGroupKey(
 			ctx, a.GroupKey, q, genesisPointID, genAssetID,
+			keyCache, false,
 		)
 		if err != nil {
-			return 0, nil, fmt.Errorf("unable to upsert group "+
+			logAssetUpsertFailure(logger, idx, numAssets, a, err)
+			return nil, fmt.Errorf("unable to upsert group "+
 				"key: %w", err)
 		}
+		if a.GroupKey != nil {
+			traceAsset("upserted group key %x (group_id=%d)",
+				a.GroupKey.GroupPubKey.SerializeCompressed(),
+				groupIDs.groupID)
+		}
 
-		scriptKeyID, err := upsertScriptKey(ctx, a.ScriptKey, q)
+		scriptKeyID, err := upsertScriptKey(
+			ctx, a.ScriptKey, q, keyCache,
+		)
 		if err != nil {
-			return 0, nil, fmt.Errorf("unable to upsert script "+
+			logAssetUpsertFailure(logger, idx, numAssets, a, err)
+			return nil, fmt.Errorf("unable to upsert script "+
 				"key: %w", err)
 		}
 
+		upserted.groupKeys[idx] = groupIDs
+		upserted.scriptKeyIDs[idx] = scriptKeyID
+
 		// Is the asset anchored already?
 		var anchorUtxoID sql.NullInt32
 		if len(anchorUtxoIDs) > 0 {
 			anchorUtxoID = anchorUtxoIDs[idx]
 		}
 
+		// If this is the root asset of a split, we'll also store the
+		// root of the split commitment it commits to.
+		var (
+			splitRootHash  []byte
+			splitRootValue sql.NullInt64
+		)
+		if a.SplitCommitmentRoot != nil {
+			rootHash := a.SplitCommitmentRoot.NodeHash()
+			splitRootHash = rootHash[:]
+			splitRootValue, err = splitRootValueColumn(
+				a.SplitCommitmentRoot.NodeSum(),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("invalid split "+
+					"commitment root: %w", err)
+			}
+		}
+
 		// With all the dependent data inserted, we can now insert the
 		// base asset information itself.
-		assetIDs[idx], err = q.InsertNewAsset(
+		amount, amountBig := assetAmountColumns(a.Amount)
+		upserted.assetIDs[idx], err = q.InsertNewAsset(
 			ctx, sqlc.InsertNewAssetParams{
-				GenesisID:        genAssetID,
-				Version:          int32(a.Version),
-				ScriptKeyID:      scriptKeyID,
-				AssetGroupSigID:  groupSigID,
-				ScriptVersion:    int32(a.ScriptVersion),
-				Amount:           int64(a.Amount),
-				LockTime:         sqlInt32(a.LockTime),
-				RelativeLockTime: sqlInt32(a.RelativeLockTime),
-				AnchorUtxoID:     anchorUtxoID,
+				GenesisID:                genAssetID,
+				Version:                  int32(a.Version),
+				ScriptKeyID:              scriptKeyID,
+				AssetGroupSigID:          groupIDs.groupSigID,
+				ScriptVersion:            int32(a.ScriptVersion),
+				Amount:                   amount,
+				LockTime:                 sqlOptInt32(a.LockTime),
+				RelativeLockTime:         sqlInt32(a.RelativeLockTime),
+				AnchorUtxoID:             anchorUtxoID,
+				SplitCommitmentRootHash:  splitRootHash,
+				SplitCommitmentRootValue: splitRootValue,
 			},
 		)
 		if err != nil {
-			return 0, nil, fmt.Errorf("unable to insert asset: %w",
+			err = normalizeDBError(err)
+			logAssetUpsertFailure(logger, idx, numAssets, a, err)
+
+			return nil, fmt.Errorf("unable to insert asset: %w",
 				err)
 		}
+		traceAsset("inserted asset (id=%d, script_key_id=%d)",
+			upserted.assetIDs[idx], scriptKeyID)
+
+		// The amount column is signed, so we'll also store the exact
+		// amount of any asset exceeding it.
+		if amountBig.Valid {
+			err := q.SetAssetBigAmount(
+				ctx, sqlc.SetAssetBigAmountParams{
+					Amount:    amount,
+					AmountBig: amountBig,
+					AssetID:   upserted.assetIDs[idx],
+				},
+			)
+			if err != nil {
+				logAssetUpsertFailure(
+					logger, idx, numAssets, a, err,
+				)
+				return nil, fmt.Errorf("unable to store "+
+					"big amount: %w", err)
+			}
+		}
 	}
 
-	return genesisPointID, assetIDs, nil
+	return upserted, nil
+}
+
+// ErrGroupGenesisPointMismatch is returned when a genesis asset is grouped
+// under a genesis point it wasn't created from.
+var ErrGroupGenesisPointMismatch = errors.New("genesis asset doesn't belong " +
+	"to genesis point of group key")
+
+// upsertedGroupKey holds the primary keys of the rows that back a group key
+// once it has been upserted.
+type upsertedGroupKey struct {
+	// groupID is the primary key of the asset group.
+	groupID int32
+
+	// internalKeyID is the primary key of the internal key the group key
+	// was derived from.
+	internalKeyID int32
+
+	// groupSigID is the primary key of the group sig of the genesis asset
+	// that was grouped. It's only valid if the asset has a group key.
+	groupSigID sql.NullInt32
 }
 
 // upsertGroupKey inserts or updates a group key and its associated internal
-// key.
+// key, and returns the primary keys of the rows backing the group key. The
+// internal key is only upserted if it isn't part of the given cache of
+// internal keys that were already upserted. If skipSig is true, the group sig
+// of the genesis asset isn't inserted, so only the group key itself is stored.
+// The sig can then be added later on with upsertGroupSig once it's known.
 func upsertGroupKey(ctx context.Context, groupKey *asset.GroupKey,
-	q UpsertAssetStore, genesisPointID, genAssetID int32) (sql.NullInt32,
-	error) {
+	q UpsertAssetStore, genesisPointID, genAssetID int32,
+	keyCache internalKeyCache, skipSig bool) (upsertedGroupKey, error) {
 
 	// No group key, this asset is not re-issuable.
-	var nullID sql.NullInt32
+	var noGroup upsertedGroupKey
 	if groupKey == nil {
-		return nullID, nil
+		return noGroup, nil
+	}
+
+	// The group key references the genesis point of the asset being
+	// grouped, so we'll make sure the genesis asset was actually created
+	// from that genesis point to avoid linking them incorrectly.
+	genAssetPointID, err := q.FetchGenesisPointIDByGenAssetID(
+		ctx, genAssetID,
+	)
+	if err != nil {
+		return noGroup, fmt.Errorf("unable to fetch genesis point "+
+			"of genesis asset: %w", err)
+	}
+	if genAssetPointID != genesisPointID {
+		return noGroup, fmt.Errorf("%w: genesis asset %d was "+
+			"created from genesis point %d, not %d",
+			ErrGroupGenesisPointMismatch, genAssetID,
+			genAssetPointID, genesisPointID)
 	}
 
 	// Before we can insert a new asset key group, we'll also need to
 	// insert an internal key which will be referenced by the key group.
-	// When we insert a proof, we don't know the raw key. So we just insert
-	// the tweaked key as the internal key.
-	//
-	// TODO(roasbeef):
-	//   * don't have the key desc information here necessarily
-	//   * inserting the group key rn, which is ok as its external w/ no key
-	//     desc info
 	tweakedKeyBytes := groupKey.GroupPubKey.SerializeCompressed()
-	rawKeyBytes := groupKey.GroupPubKey.SerializeCompressed()
-	if groupKey.RawKey.PubKey != nil {
-		rawKeyBytes = groupKey.RawKey.PubKey.SerializeCompressed()
-	}
-
-	keyID, err := q.UpsertInternalKey(ctx, InternalKey{
-		RawKey:    rawKeyBytes,
-		KeyFamily: int32(groupKey.RawKey.Family),
-		KeyIndex:  int32(groupKey.RawKey.Index),
-	})
+	keyID, err := upsertInternalKeyOnce(
+		ctx, q, groupInternalKey(groupKey), keyCache,
+	)
 	if err != nil {
-		return nullID, fmt.Errorf("unable to insert internal key: %w",
-			err)
+		return noGroup, fmt.Errorf("unable to insert internal key: "+
+			"%w", err)
 	}
 	groupID, err := q.UpsertAssetGroupKey(ctx, AssetGroupKey{
 		TweakedGroupKey: tweakedKeyBytes,
@@ -213,39 +999,103 @@ func upsertGroupKey(ctx context.Context, groupKey *asset.GroupKey,
 		GenesisPointID:  genesisPointID,
 	})
 	if err != nil {
-		return nullID, fmt.Errorf("unable to insert group key: %w",
-			err)
+		return noGroup, fmt.Errorf("unable to insert group key: %w",
+			normalizeDBError(err))
+	}
+
+	groupIDs := upsertedGroupKey{
+		groupID:       groupID,
+		internalKeyID: keyID,
+	}
+
+	// If we don't have the sig of the genesis asset yet, we're done here,
+	// unless the sig was already stored before. In that case we'll make
+	// sure the reissuance flag of the genesis asset reflects it.
+	if skipSig {
+		groupSigID, err := q.FetchGroupSigIDByGenesisID(ctx, genAssetID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return groupIDs, nil
+
+		case err != nil:
+			return noGroup, fmt.Errorf("unable to fetch group "+
+				"sig: %w", err)
+		}
+
+		err = q.SetGenesisReissuance(ctx, GenesisReissuance{
+			GroupKeyID: groupID,
+			SigID:      groupSigID,
+			GenAssetID: genAssetID,
+		})
+		if err != nil {
+			return noGroup, fmt.Errorf("unable to set genesis "+
+				"reissuance: %w", normalizeDBError(err))
+		}
+
+		groupIDs.groupSigID = sqlInt32(groupSigID)
+
+		return groupIDs, nil
+	}
+
+	groupSigID, err := upsertGroupSig(
+		ctx, q, groupKey.Sig.Serialize(), groupID, genAssetID,
+	)
+	if err != nil {
+		return noGroup, err
 	}
 
-	// With the statement above complete, we'll now insert the
-	// asset_group_sig entry for this, which has a one-to-many relationship
-	// with group keys (there can be many sigs for a group key which link
-	// together otherwise disparate asset IDs).
+	groupIDs.groupSigID = sqlInt32(groupSigID)
+
+	return groupIDs, nil
+}
+
+// upsertGroupSig inserts the group sig of a genesis asset under the group key
+// with the given primary key, and returns the primary key of the sig. This is
+// also used to backfill the sig of group keys that were stored without it.
+func upsertGroupSig(ctx context.Context, q UpsertAssetStore, sig []byte,
+	groupID, genAssetID int32) (int32, error) {
+
+	// The asset_group_sig entry has a one-to-many relationship with group
+	// keys (there can be many sigs for a group key which link together
+	// otherwise disparate asset IDs).
 	//
 	// TODO(roasbeef): sig here doesn't actually matter?
 	groupSigID, err := q.UpsertAssetGroupSig(ctx, AssetGroupSig{
-		GenesisSig: groupKey.Sig.Serialize(),
+		GenesisSig: sig,
 		GenAssetID: genAssetID,
 		GroupKeyID: groupID,
 	})
 	if err != nil {
-		return nullID, fmt.Errorf("unable to insert group sig: %w", err)
+		return 0, fmt.Errorf("unable to insert group sig: %w",
+			normalizeDBError(err))
+	}
+
+	// Now that the genesis asset is linked to its group, we can tell
+	// whether it's the genesis asset that first emitted the group, or a
+	// reissuance of the group.
+	err = q.SetGenesisReissuance(ctx, GenesisReissuance{
+		GroupKeyID: groupID,
+		SigID:      groupSigID,
+		GenAssetID: genAssetID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to set genesis reissuance: %w",
+			normalizeDBError(err))
 	}
 
-	return sqlInt32(groupSigID), nil
+	return groupSigID, nil
 }
 
 // upsertScriptKey inserts or updates a script key and its associated internal
-// key.
+// key. The internal key is only upserted if it isn't part of the given cache
+// of internal keys that were already upserted.
 func upsertScriptKey(ctx context.Context, scriptKey asset.ScriptKey,
-	q UpsertAssetStore) (int32, error) {
+	q UpsertAssetStore, keyCache internalKeyCache) (int32, error) {
 
-	if scriptKey.TweakedScriptKey != nil {
-		rawScriptKeyID, err := q.UpsertInternalKey(ctx, InternalKey{
-			RawKey:    scriptKey.RawKey.PubKey.SerializeCompressed(),
-			KeyFamily: int32(scriptKey.RawKey.Family),
-			KeyIndex:  int32(scriptKey.RawKey.Index),
-		})
+	if rawKey, ok := scriptInternalKey(scriptKey); ok {
+		rawScriptKeyID, err := upsertInternalKeyOnce(
+			ctx, q, rawKey, keyCache,
+		)
 		if err != nil {
 			return 0, fmt.Errorf("unable to insert internal key: "+
 				"%w", err)
@@ -254,10 +1104,14 @@ func upsertScriptKey(ctx context.Context, scriptKey asset.ScriptKey,
 			InternalKeyID:    rawScriptKeyID,
 			TweakedScriptKey: scriptKey.PubKey.SerializeCompressed(),
 			Tweak:            scriptKey.Tweak,
+			IsKnownRaw:       true,
+			KeyType: int16(
+				knownScriptKeyType(scriptKey.Tweak),
+			),
 		})
 		if err != nil {
 			return 0, fmt.Errorf("unable to insert script key: "+
-				"%w", err)
+				"%w", normalizeDBError(err))
 		}
 
 		return scriptKeyID, nil
@@ -276,9 +1130,11 @@ func upsertScriptKey(ctx context.Context, scriptKey asset.ScriptKey,
 		// can't actually use this asset, but the import will complete.
 		//
 		// TODO(roasbeef): remove after itest work
-		rawScriptKeyID, err := q.UpsertInternalKey(ctx, InternalKey{
-			RawKey: scriptKey.PubKey.SerializeCompressed(),
-		})
+		rawScriptKeyID, err := upsertInternalKeyOnce(
+			ctx, q, InternalKey{
+				RawKey: scriptKey.PubKey.SerializeCompressed(),
+			}, keyCache,
+		)
 		if err != nil {
 			return 0, fmt.Errorf("unable to insert internal key: "+
 				"%w", err)
@@ -289,48 +1145,282 @@ func upsertScriptKey(ctx context.Context, scriptKey asset.ScriptKey,
 		})
 		if err != nil {
 			return 0, fmt.Errorf("unable to insert script key: "+
-				"%w", err)
+				"%w", normalizeDBError(err))
 		}
 	}
 
 	return scriptKeyID, nil
 }
 
+// ErrGenesisNotFound is returned when a genesis asset can't be found in the
+// database.
+var ErrGenesisNotFound = errors.New("genesis not found")
+
+// genesisNotFoundError is returned when a genesis asset can't be found in the
+// database. It matches ErrGenesisNotFound, so callers don't need to know about
+// the error the database backend signals a missing row with, while still
+// wrapping that error as its cause.
+type genesisNotFoundError struct {
+	// query describes the genesis asset that was looked up.
+	query string
+
+	// err is the error returned by the database backend.
+	err error
+}
+
+// Error returns the error message of the genesisNotFoundError.
+func (e *genesisNotFoundError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrGenesisNotFound, e.query)
+}
+
+// Is returns true if the target is ErrGenesisNotFound.
+func (e *genesisNotFoundError) Is(target error) bool {
+	return target == ErrGenesisNotFound
+}
+
+// Unwrap returns the error returned by the database backend.
+func (e *genesisNotFoundError) Unwrap() error {
+	return e.err
+}
+
 // FetchGenesisStore houses the methods related to fetching genesis assets.
 type FetchGenesisStore interface {
 	// FetchGenesisByID returns a single genesis asset by its primary key
 	// ID.
 	FetchGenesisByID(ctx context.Context, assetID int32) (Genesis, error)
+
+	// FetchGenesisByOutpoint returns a single genesis asset by its
+	// genesis point and output index.
+	FetchGenesisByOutpoint(ctx context.Context,
+		arg GenesisOutpointQuery) (GenesisByOutpoint, error)
+
+	// FetchGenesesByIDs returns all genesis assets with one of the given
+	// primary keys, in no particular order.
+	FetchGenesesByIDs(ctx context.Context,
+		genAssetIDs []int32) ([]GenesisWithID, error)
 }
 
 // fetchGenesis returns a fully populated genesis record from the database,
-// identified by its primary key ID.
+// identified by its primary key ID, along with the type of its metadata. If
+// there's no such genesis asset, ErrGenesisNotFound is returned. If the store
+// implements StrictGenesisStore, the asset ID of the genesis is verified too.
 func fetchGenesis(ctx context.Context, q FetchGenesisStore,
-	assetID int32) (asset.Genesis, error) {
+	assetID int32) (asset.Genesis, MetaType, error) {
 
 	// Now we fetch the genesis information that so far we
 	// only have the ID for in the address record.
 	gen, err := q.FetchGenesisByID(ctx, assetID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return asset.Genesis{}, 0, &genesisNotFoundError{
+			query: fmt.Sprintf("gen_asset_id=%d", assetID),
+			err:   err,
+		}
+
+	case err != nil:
+		return asset.Genesis{}, 0, fmt.Errorf("unable to fetch "+
+			"genesis: %w", err)
+	}
+
+	genesis, err := parseGenesis(gen)
+	if err != nil {
+		return asset.Genesis{}, 0, err
+	}
+	if err := checkMetaReveal(genesis, gen.AssetID); err != nil {
+		return asset.Genesis{}, 0, err
+	}
+
+	// In strict mode, we'll make sure the genesis wasn't corrupted by
+	// deriving its asset ID again.
+	if strictStore, ok := q.(StrictGenesisStore); ok {
+		err := strictStore.VerifyGenesisID(genesis, gen.AssetID)
+		if err != nil {
+			return asset.Genesis{}, 0, err
+		}
+	}
+
+	return genesis, MetaType(gen.MetaType), nil
+}
+
+// maxGenesesPerFetch is the maximum number of genesis assets that are fetched
+// by their primary keys with a single query.
+const maxGenesesPerFetch = 1000
+
+// fetchGenesesByIDs returns the fully populated genesis records of the genesis
+// assets with the given primary keys, keyed by their primary key. IDs that
+// don't belong to any genesis asset are omitted from the returned map.
+//
+// The genesis assets are fetched with an IN query per chunk of at most
+// maxGenesesPerFetch distinct IDs, so sparse IDs don't result in a scan of all
+// the genesis assets in between.
+func fetchGenesesByIDs(ctx context.Context, q FetchGenesisStore,
+	ids []int32) (map[int32]asset.Genesis, error) {
+
+	geneses := make(map[int32]asset.Genesis, len(ids))
+
+	var uniqueIDs []int32
+	seenIDs := make(map[int32]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seenIDs[id]; ok {
+			continue
+		}
+		seenIDs[id] = struct{}{}
+
+		uniqueIDs = append(uniqueIDs, id)
+	}
+
+	var dbGeneses []GenesisWithID
+	for start := 0; start < len(uniqueIDs); {
+		end := start + maxGenesesPerFetch
+		if end > len(uniqueIDs) {
+			end = len(uniqueIDs)
+		}
+
+		chunk, err := q.FetchGenesesByIDs(ctx, uniqueIDs[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch geneses: %w",
+				err)
+		}
+		dbGeneses = append(dbGeneses, chunk...)
+
+		start = end
+	}
+
+	for _, dbGen := range dbGeneses {
+		genesis, err := parseGenesis(Genesis{
+			AssetID:     dbGen.AssetID,
+			AssetTag:    dbGen.AssetTag,
+			MetaData:    dbGen.MetaData,
+			OutputIndex: dbGen.OutputIndex,
+			AssetType:   dbGen.AssetType,
+			MetaType:    dbGen.MetaType,
+			PrevOut:     dbGen.PrevOut,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMetaReveal(genesis, dbGen.AssetID); err != nil {
+			return nil, err
+		}
+
+		if strictStore, ok := q.(StrictGenesisStore); ok {
+			err := strictStore.VerifyGenesisID(
+				genesis, dbGen.AssetID,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		geneses[dbGen.GenAssetID] = genesis
+	}
+
+	return geneses, nil
+}
+
+// ErrMetaRevealMissing is returned when a genesis asset is read whose metadata
+// reveal isn't stored.
+var ErrMetaRevealMissing = errors.New("metadata reveal missing")
+
+// checkMetaReveal makes sure the metadata of the given genesis, as read from
+// the database, was revealed. Genesis assets only reference their metadata by
+// its hash, so a genesis whose reveal is missing is read without metadata,
+// which derives a different asset ID than the stored one.
+func checkMetaReveal(genesis asset.Genesis, storedAssetID []byte) error {
+	if len(genesis.Metadata) != 0 {
+		return nil
+	}
+
+	assetID := genesis.ID()
+	if bytes.Equal(assetID[:], storedAssetID) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: asset_id=%x", ErrMetaRevealMissing,
+		storedAssetID)
+}
+
+// fetchGenesisByOutpoint returns a fully populated genesis record from the
+// database, identified by its genesis point and output index. If there's no
+// such genesis asset, ErrGenesisNotFound is returned.
+func fetchGenesisByOutpoint(ctx context.Context, q FetchGenesisStore,
+	genesisPoint wire.OutPoint, outputIndex uint32) (asset.Genesis, error) {
+
+	prevOut, err := encodeOutpoint(genesisPoint)
 	if err != nil {
+		return asset.Genesis{}, fmt.Errorf("unable to encode genesis "+
+			"point: %w", err)
+	}
+
+	gen, err := q.FetchGenesisByOutpoint(ctx, GenesisOutpointQuery{
+		PrevOut:     prevOut,
+		OutputIndex: int32(outputIndex),
+	})
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return asset.Genesis{}, &genesisNotFoundError{
+			query: fmt.Sprintf("genesis_point=%v, output_index=%d",
+				genesisPoint, outputIndex),
+			err: err,
+		}
+
+	case err != nil:
 		return asset.Genesis{}, fmt.Errorf("unable to fetch genesis: "+
 			"%w", err)
 	}
 
-	// Next, we'll populate the asset genesis information which
-	// includes the genesis prev out, and the other information
-	// needed to derive an asset ID.
+	genesis, err := parseGenesis(Genesis(gen))
+	if err != nil {
+		return asset.Genesis{}, err
+	}
+	if err := checkMetaReveal(genesis, gen.AssetID); err != nil {
+		return asset.Genesis{}, err
+	}
+
+	return genesis, nil
+}
+
+// parseGenesis converts a genesis record read from the database into an
+// asset.Genesis.
+func parseGenesis(gen Genesis) (asset.Genesis, error) {
+	// We'll populate the asset genesis information which includes the
+	// genesis prev out, and the other information needed to derive an
+	// asset ID.
 	var genesisPrevOut wire.OutPoint
-	err = readOutPoint(bytes.NewReader(gen.PrevOut), 0, 0, &genesisPrevOut)
+	err := readOutPoint(bytes.NewReader(gen.PrevOut), 0, 0, &genesisPrevOut)
 	if err != nil {
 		return asset.Genesis{}, fmt.Errorf("unable to read outpoint: "+
 			"%w", err)
 	}
 
+	outputIndex, err := parseOutputIndex(gen.OutputIndex)
+	if err != nil {
+		return asset.Genesis{}, err
+	}
+
 	return asset.Genesis{
 		FirstPrevOut: genesisPrevOut,
 		Tag:          gen.AssetTag,
 		Metadata:     gen.MetaData,
-		OutputIndex:  uint32(gen.OutputIndex),
+		OutputIndex:  outputIndex,
 		Type:         asset.Type(gen.AssetType),
 	}, nil
 }
+
+// ErrInvalidOutputIndex is returned when an output index read from the
+// database is out of the range of valid output indexes.
+var ErrInvalidOutputIndex = errors.New("invalid output index")
+
+// parseOutputIndex converts an output index read from the database into the
+// unsigned output index it was stored from. As output indexes are stored in
+// signed columns, a negative value can only stem from a corrupted record.
+// Rather than silently wrapping it around to a huge index, which would for
+// example result in a genesis with the wrong asset ID, we refuse it.
+func parseOutputIndex(outputIndex int32) (uint32, error) {
+	if outputIndex < 0 {
+		return 0, fmt.Errorf("%w: %d is negative",
+			ErrInvalidOutputIndex, outputIndex)
+	}
+
+	return uint32(outputIndex), nil
+}
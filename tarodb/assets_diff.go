@@ -0,0 +1,315 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/lightninglabs/taro/asset"
+)
+
+// diffAssetsPageSize is the number of assets DiffAssets fetches from each
+// store at once.
+const diffAssetsPageSize = 500
+
+// ReadOnlyAssetStore is a read-only view of a set of assets that can be
+// compared against the assets of an AssetStore.
+type ReadOnlyAssetStore interface {
+	// FetchSortedAssets fetches up to limit unspent, anchored assets in
+	// canonical order (asset ID, then script key, then primary key). If
+	// after is non-nil, only the assets sorted after it are returned, so
+	// the assets can be paged through by passing the last asset of the
+	// previous page.
+	FetchSortedAssets(ctx context.Context, after *ChainAsset,
+		limit int32) ([]*ChainAsset, error)
+}
+
+// A compile-time assertion to ensure that AssetStore meets the
+// ReadOnlyAssetStore interface.
+var _ ReadOnlyAssetStore = (*AssetStore)(nil)
+
+// AssetDiffType denotes the way an asset differs between two stores.
+type AssetDiffType uint8
+
+const (
+	// AssetDiffMissingRemote denotes an asset that's only present in the
+	// local store.
+	AssetDiffMissingRemote AssetDiffType = iota
+
+	// AssetDiffMissingLocal denotes an asset that's only present in the
+	// remote store.
+	AssetDiffMissingLocal
+
+	// AssetDiffMismatch denotes an asset that's present in both stores,
+	// but differs between them.
+	AssetDiffMismatch
+)
+
+// String returns a human readable version of the diff type.
+func (t AssetDiffType) String() string {
+	switch t {
+	case AssetDiffMissingRemote:
+		return "missing_remote"
+
+	case AssetDiffMissingLocal:
+		return "missing_local"
+
+	case AssetDiffMismatch:
+		return "mismatch"
+
+	default:
+		return fmt.Sprintf("unknown<%d>", t)
+	}
+}
+
+// AssetDiff describes a single asset that differs between two stores.
+type AssetDiff struct {
+	// Type is the way the asset differs between the two stores.
+	Type AssetDiffType
+
+	// ID is the asset ID of the differing asset.
+	ID asset.ID
+
+	// ScriptKey is the script key of the differing asset.
+	ScriptKey asset.SerializedKey
+
+	// Local is the asset as found in the local store. This is nil if the
+	// asset is missing in the local store.
+	Local *ChainAsset
+
+	// Remote is the asset as found in the remote store. This is nil if
+	// the asset is missing in the remote store.
+	Remote *ChainAsset
+}
+
+// assetSortKey is the key we use to order assets canonically, made up of the
+// asset ID and the script key. It doesn't uniquely identify an asset, as the
+// same asset can be sent to the same script key more than once.
+type assetSortKey [len(asset.ID{}) + len(asset.SerializedKey{})]byte
+
+// newAssetSortKey returns the sort key of the asset with the given ID and
+// script key.
+func newAssetSortKey(id asset.ID, scriptKey *btcec.PublicKey) assetSortKey {
+	var key assetSortKey
+	copy(key[:], id[:])
+
+	serializedKey := asset.ToSerialized(scriptKey)
+	copy(key[len(id):], serializedKey[:])
+
+	return key
+}
+
+// chainAssetSortKey is the key we use to order the assets of a single store
+// canonically. It extends the assetSortKey of an asset with its primary key,
+// which breaks ties between assets with the same asset ID and script key.
+type chainAssetSortKey [len(assetSortKey{}) + 4]byte
+
+// assetKey returns the asset sort key the chain asset sort key extends.
+func (k chainAssetSortKey) assetKey() assetSortKey {
+	var key assetSortKey
+	copy(key[:], k[:])
+
+	return key
+}
+
+// sortKey returns the canonical sort key of the passed asset.
+func sortKey(a *ChainAsset) chainAssetSortKey {
+	var key chainAssetSortKey
+	assetKey := newAssetSortKey(a.ID(), a.ScriptKey.PubKey)
+	copy(key[:], assetKey[:])
+	binary.BigEndian.PutUint32(key[len(assetKey):], uint32(a.primaryKey))
+
+	return key
+}
+
+// sortedAssetIterator walks the assets of a ReadOnlyAssetStore in canonical
+// order, fetching a single page of assets at a time.
+type sortedAssetIterator struct {
+	store ReadOnlyAssetStore
+
+	// page is the current page of assets, and idx the index of the next
+	// asset within it.
+	page []*ChainAsset
+	idx  int
+
+	// done is true once the last page was fetched.
+	done bool
+}
+
+// peek returns the next asset of the iterator without advancing it, or nil if
+// all assets were walked.
+func (s *sortedAssetIterator) peek(ctx context.Context) (*ChainAsset, error) {
+	if s.idx < len(s.page) {
+		return s.page[s.idx], nil
+	}
+	if s.done {
+		return nil, nil
+	}
+
+	// We've walked the current page, so we'll fetch the next one, starting
+	// after the last asset of the current page.
+	var after *ChainAsset
+	if len(s.page) > 0 {
+		after = s.page[len(s.page)-1]
+	}
+	page, err := s.store.FetchSortedAssets(ctx, after, diffAssetsPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	s.page, s.idx = page, 0
+	s.done = len(page) < diffAssetsPageSize
+	if len(page) == 0 {
+		return nil, nil
+	}
+
+	return page[0], nil
+}
+
+// next advances the iterator past the asset last returned by peek.
+func (s *sortedAssetIterator) next() {
+	s.idx++
+}
+
+// chainAssetsEqual returns true if the two chain assets, including their
+// anchor information, are equal.
+func chainAssetsEqual(a, b *ChainAsset) bool {
+	switch {
+	case !a.Asset.DeepEqual(b.Asset):
+		return false
+
+	case a.AnchorTxid != b.AnchorTxid:
+		return false
+
+	case a.AnchorOutpoint != b.AnchorOutpoint:
+		return false
+
+	case a.AnchorBlockHash != b.AnchorBlockHash:
+		return false
+	}
+
+	return true
+}
+
+// DiffAssets compares the set of assets in this store with the set of assets
+// in the other store. Both sets are walked in canonical order (asset ID, then
+// script key) a page at a time, so neither set needs to be loaded into memory
+// at once, and an entry is returned for each asset that's only present in
+// one of the stores, or that differs between them.
+func (a *AssetStore) DiffAssets(ctx context.Context,
+	other ReadOnlyAssetStore) ([]AssetDiff, error) {
+
+	newDiff := func(diffType AssetDiffType, local,
+		remote *ChainAsset) AssetDiff {
+
+		chainAsset := local
+		if chainAsset == nil {
+			chainAsset = remote
+		}
+
+		return AssetDiff{
+			Type:      diffType,
+			ID:        chainAsset.ID(),
+			ScriptKey: asset.ToSerialized(chainAsset.ScriptKey.PubKey),
+			Local:     local,
+			Remote:    remote,
+		}
+	}
+
+	// With both sets sorted, we can walk them in lock step, always
+	// advancing the side with the smaller key.
+	var (
+		diffs        []AssetDiff
+		localAssets  = &sortedAssetIterator{store: a}
+		remoteAssets = &sortedAssetIterator{store: other}
+	)
+	for {
+		local, err := localAssets.peek(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch local assets: "+
+				"%w", err)
+		}
+		remote, err := remoteAssets.peek(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch remote "+
+				"assets: %w", err)
+		}
+
+		switch {
+		case local == nil && remote == nil:
+			return diffs, nil
+
+		case remote == nil:
+			diffs = append(diffs, newDiff(
+				AssetDiffMissingRemote, local, nil,
+			))
+			localAssets.next()
+
+		case local == nil:
+			diffs = append(diffs, newDiff(
+				AssetDiffMissingLocal, nil, remote,
+			))
+			remoteAssets.next()
+
+		default:
+			// The primary keys of the assets are specific to each
+			// store, so we only compare their asset sort keys.
+			localKey := sortKey(local).assetKey()
+			remoteKey := sortKey(remote).assetKey()
+
+			switch cmp := bytes.Compare(localKey[:], remoteKey[:]); {
+			case cmp < 0:
+				diffs = append(diffs, newDiff(
+					AssetDiffMissingRemote, local, nil,
+				))
+				localAssets.next()
+
+			case cmp > 0:
+				diffs = append(diffs, newDiff(
+					AssetDiffMissingLocal, nil, remote,
+				))
+				remoteAssets.next()
+
+			default:
+				if !chainAssetsEqual(local, remote) {
+					diffs = append(diffs, newDiff(
+						AssetDiffMismatch, local,
+						remote,
+					))
+				}
+				localAssets.next()
+				remoteAssets.next()
+			}
+		}
+	}
+}
+
+// FetchSortedAssets fetches up to limit unspent, anchored assets in canonical
+// order (asset ID, then script key, then primary key). If after is non-nil,
+// only the assets sorted after it are returned, so the assets can be paged
+// through by passing the last asset of the previous page.
+//
+// NOTE: This implements the ReadOnlyAssetStore interface.
+func (a *AssetStore) FetchSortedAssets(ctx context.Context, after *ChainAsset,
+	limit int32) ([]*ChainAsset, error) {
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	assetFilter := QueryAssetFilters{
+		CanonicalOrder: sqlBool(true),
+		NumLimit:       sqlInt32(limit),
+	}
+	if after != nil {
+		afterID := after.ID()
+		assetFilter.AfterSortAssetID = afterID[:]
+		assetFilter.AfterSortScriptKey =
+			after.ScriptKey.PubKey.SerializeCompressed()
+		assetFilter.AfterSortPrimaryKey = sqlInt32(after.primaryKey)
+	}
+
+	return a.fetchChainAssets(ctx, assetFilter)
+}
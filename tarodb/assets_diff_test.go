@@ -0,0 +1,188 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDiffAssets tests that we're able to detect assets that are missing in
+// either of two stores, or that differ between them.
+func TestDiffAssets(t *testing.T) {
+	t.Parallel()
+
+	_, localStore, _ := newAssetStore(t)
+	_, remoteStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	assetGen := asset.RandGenesis(t, asset.Normal)
+	newAsset := func() *asset.Asset {
+		return randAsset(
+			t, withAssetGen(assetGen),
+			withAssetGenPoint(genesisPoint), withNoGroupKey(),
+		)
+	}
+	importAsset := func(store *AssetStore, a *asset.Asset,
+		anchor AnchorUTXO) {
+
+		err := store.ImportAssetsWithAnchors(
+			ctx, genesisPoint, []*asset.Asset{a},
+			[]AnchorUTXO{anchor},
+		)
+		require.NoError(t, err)
+	}
+
+	// Two stores without any assets don't differ.
+	diffs, err := localStore.DiffAssets(ctx, remoteStore)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	// We'll now import an asset that's identical in both stores, which
+	// shouldn't show up in the diff.
+	sharedAsset, sharedAnchor := newAsset(), randAnchorUTXO(t)
+	importAsset(localStore, sharedAsset, sharedAnchor)
+	importAsset(remoteStore, sharedAsset.Copy(), sharedAnchor)
+
+	diffs, err = localStore.DiffAssets(ctx, remoteStore)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	// Next, we'll add an asset that's only known locally, one that's only
+	// known remotely, and one that has a different amount in each store.
+	localOnly, remoteOnly := newAsset(), newAsset()
+	importAsset(localStore, localOnly, randAnchorUTXO(t))
+	importAsset(remoteStore, remoteOnly, randAnchorUTXO(t))
+
+	mismatched, mismatchedAnchor := newAsset(), randAnchorUTXO(t)
+	remoteMismatched := mismatched.Copy()
+	remoteMismatched.Amount += 2
+	importAsset(localStore, mismatched, mismatchedAnchor)
+	importAsset(remoteStore, remoteMismatched, mismatchedAnchor)
+
+	diffs, err = localStore.DiffAssets(ctx, remoteStore)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+
+	diffTypes := make(map[asset.SerializedKey]AssetDiffType, len(diffs))
+	for _, diff := range diffs {
+		diffTypes[diff.ScriptKey] = diff.Type
+
+		switch diff.Type {
+		case AssetDiffMissingRemote:
+			require.NotNil(t, diff.Local)
+			require.Nil(t, diff.Remote)
+
+		case AssetDiffMissingLocal:
+			require.Nil(t, diff.Local)
+			require.NotNil(t, diff.Remote)
+
+		case AssetDiffMismatch:
+			require.NotNil(t, diff.Local)
+			require.NotNil(t, diff.Remote)
+		}
+	}
+
+	toKey := func(a *asset.Asset) asset.SerializedKey {
+		return asset.ToSerialized(a.ScriptKey.PubKey)
+	}
+	require.Equal(t, map[asset.SerializedKey]AssetDiffType{
+		toKey(localOnly):  AssetDiffMissingRemote,
+		toKey(remoteOnly): AssetDiffMissingLocal,
+		toKey(mismatched): AssetDiffMismatch,
+	}, diffTypes)
+
+	// Diffing in the other direction should flip the missing sides.
+	diffs, err = remoteStore.DiffAssets(ctx, localStore)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	for _, diff := range diffs {
+		switch diff.ScriptKey {
+		case toKey(localOnly):
+			require.Equal(t, AssetDiffMissingLocal, diff.Type)
+
+		case toKey(remoteOnly):
+			require.Equal(t, AssetDiffMissingRemote, diff.Type)
+
+		default:
+			require.Equal(t, AssetDiffMismatch, diff.Type)
+		}
+	}
+}
+
+// TestFetchSortedAssets tests that paging through the assets of a store in
+// canonical order returns all of its assets exactly once, in that order.
+func TestFetchSortedAssets(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import a few assets of two different genesis assets, so the
+	// assets need to be ordered by both their asset ID and script key. All
+	// assets of the first genesis asset are sent to the same script key,
+	// so they're only told apart by their primary key, even across pages.
+	const numAssets = 7
+	genesisPoint := test.RandOp(t)
+	assetGens := []asset.Genesis{
+		asset.RandGenesis(t, asset.Normal),
+		asset.RandGenesis(t, asset.Normal),
+	}
+	sharedScriptKey := asset.NewScriptKeyBIP0086(keychain.KeyDescriptor{
+		PubKey: test.RandPubKey(t),
+	})
+	for i := 0; i < numAssets; i++ {
+		opts := []assetGenOpt{
+			withAssetGen(assetGens[i%len(assetGens)]),
+			withAssetGenPoint(genesisPoint), withNoGroupKey(),
+		}
+		if i%len(assetGens) == 0 {
+			opts = append(opts, withScriptKey(sharedScriptKey))
+		}
+
+		a := randAsset(t, opts...)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, genesisPoint, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	expectedAssets, err := assetStore.FetchAllAssets(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, expectedAssets, numAssets)
+	sort.Slice(expectedAssets, func(i, j int) bool {
+		keyI, keyJ := sortKey(expectedAssets[i]),
+			sortKey(expectedAssets[j])
+		return bytes.Compare(keyI[:], keyJ[:]) < 0
+	})
+
+	// Paging through the assets should result in the very same order,
+	// with only the last page being partially filled.
+	const pageSize = 3
+	var (
+		sortedAssets []*ChainAsset
+		after        *ChainAsset
+	)
+	for {
+		page, err := assetStore.FetchSortedAssets(ctx, after, pageSize)
+		require.NoError(t, err)
+
+		sortedAssets = append(sortedAssets, page...)
+		if len(page) < pageSize {
+			break
+		}
+		after = page[len(page)-1]
+	}
+	require.Equal(t, expectedAssets, sortedAssets)
+
+	// A limit that isn't positive is rejected.
+	_, err = assetStore.FetchSortedAssets(ctx, nil, 0)
+	require.Error(t, err)
+}
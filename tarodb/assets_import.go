@@ -0,0 +1,156 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taro/asset"
+)
+
+// ErrImportBatchMismatch is returned when resuming the import of a batch with
+// assets or anchors that differ from the ones its checkpoint was recorded for.
+var ErrImportBatchMismatch = errors.New("import batch doesn't match " +
+	"checkpoint")
+
+// importBatchHash returns the hash that commits to the genesis outpoint, the
+// assets and the anchors of an import batch, in the order they're imported.
+func importBatchHash(genesisOutpoint wire.OutPoint, assets []*asset.Asset,
+	anchors []AnchorUTXO) ([]byte, error) {
+
+	h := sha256.New()
+
+	genesisPoint, err := encodeOutpoint(genesisOutpoint)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = h.Write(genesisPoint)
+
+	for i := range assets {
+		var assetBuf bytes.Buffer
+		if err := assets[i].Encode(&assetBuf); err != nil {
+			return nil, fmt.Errorf("unable to encode asset: %w",
+				err)
+		}
+		_, _ = h.Write(assetBuf.Bytes())
+
+		anchorPoint, err := encodeOutpoint(anchors[i].OutPoint)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = h.Write(anchorPoint)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// ResumeImport imports the passed assets along with the UTXOs that anchor
+// them in chunks of the given size, each committed in its own database
+// transaction. Along with each chunk, the index of its last asset is recorded
+// as the checkpoint of the given batch, together with a hash of the whole
+// batch. If a previous import of the same batch was interrupted, the assets up
+// to and including its checkpoint are skipped, so the import picks up right
+// after the last chunk that made it to disk. The checkpoint is deleted along
+// with the last chunk, so a batch ID can be reused once its import completed.
+// ErrImportBatchMismatch is returned if a checkpoint exists for the batch ID,
+// but was recorded for different assets or anchors.
+//
+// NOTE: The caller must pass the same assets in the same order each time the
+// import of a batch is resumed.
+func (a *AssetStore) ResumeImport(ctx context.Context, batchID string,
+	genesisOutpoint wire.OutPoint, assets []*asset.Asset,
+	anchors []AnchorUTXO, chunkSize int) error {
+
+	if len(assets) != len(anchors) {
+		return fmt.Errorf("number of assets (%v) doesn't match number "+
+			"of anchors (%v)", len(assets), len(anchors))
+	}
+	if chunkSize <= 0 {
+		return fmt.Errorf("invalid chunk size: %v", chunkSize)
+	}
+
+	batchHash, err := importBatchHash(genesisOutpoint, assets, anchors)
+	if err != nil {
+		return err
+	}
+
+	// First, we'll check how far a previous import of this batch got, if
+	// there was one at all.
+	start := 0
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		checkpoint, err := q.FetchImportCheckpoint(ctx, batchID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		// Skipping the committed assets is only safe if they're the
+		// ones the checkpoint was recorded for.
+		if !bytes.Equal(checkpoint.BatchHash, batchHash) {
+			return fmt.Errorf("%w: batch %v",
+				ErrImportBatchMismatch, batchID)
+		}
+
+		start = int(checkpoint.LastCommittedIndex) + 1
+		return nil
+	})
+	if dbErr != nil {
+		return fmt.Errorf("unable to fetch import checkpoint: %w",
+			dbErr)
+	}
+
+	// With the starting point known, we'll import the remaining assets
+	// chunk by chunk, moving the checkpoint forward with each of them.
+	for chunkStart := start; chunkStart < len(assets); {
+		chunkEnd := chunkStart + chunkSize
+		if chunkEnd > len(assets) {
+			chunkEnd = len(assets)
+		}
+
+		importChunk := func(q ActiveAssetsStore) error {
+			err := a.importAssetsWithAnchors(
+				ctx, q, genesisOutpoint,
+				assets[chunkStart:chunkEnd],
+				anchors[chunkStart:chunkEnd],
+			)
+			if err != nil {
+				return err
+			}
+
+			// Once the last chunk is committed, the import is
+			// complete, so there's nothing left to resume.
+			if chunkEnd == len(assets) {
+				return q.DeleteImportCheckpoint(ctx, batchID)
+			}
+
+			return q.UpsertImportCheckpoint(ctx, ImportCheckpoint{
+				BatchID:            batchID,
+				LastCommittedIndex: int32(chunkEnd - 1),
+				UpdatedAt:          time.Now().UTC(),
+				BatchHash:          batchHash,
+			})
+		}
+
+		var writeTxOpts AssetStoreTxOptions
+		err := a.db.ExecTx(ctx, &writeTxOpts, importChunk)
+		if err != nil {
+			return fmt.Errorf("unable to import assets %d to %d "+
+				"of batch %v: %w", chunkStart, chunkEnd-1,
+				batchID, err)
+		}
+
+		chunkStart = chunkEnd
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResumeImport tests that an import that's interrupted half way can be
+// resumed from its last checkpoint without inserting any of the already
+// committed assets a second time.
+func TestResumeImport(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	const (
+		batchID   = "test-batch"
+		chunkSize = 2
+	)
+
+	genesisPoint := test.RandOp(t)
+	assets := make([]*asset.Asset, 6)
+	anchors := make([]AnchorUTXO, len(assets))
+	for i := range assets {
+		assets[i] = randAsset(t, withAssetGenPoint(genesisPoint))
+		anchors[i] = randAnchorUTXO(t)
+	}
+
+	// We'll simulate a crash during the second chunk by removing the
+	// anchor transaction of one of its assets.
+	anchorTx := anchors[3].AnchorTx
+	anchors[3].AnchorTx = nil
+
+	err := assetStore.ResumeImport(
+		ctx, batchID, genesisPoint, assets, anchors, chunkSize,
+	)
+	require.ErrorContains(t, err, "anchor tx")
+
+	// Only the first chunk should've made it to disk, and the checkpoint
+	// should point to its last asset.
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, chunkSize)
+
+	checkpoint, err := db.FetchImportCheckpoint(ctx, batchID)
+	require.NoError(t, err)
+	require.EqualValues(t, chunkSize-1, checkpoint.LastCommittedIndex)
+
+	// Resuming the batch with different assets under the same ID should
+	// be rejected, as we'd otherwise skip assets that were never
+	// imported.
+	otherAssets := append([]*asset.Asset{}, assets...)
+	otherAssets[0] = randAsset(t, withAssetGenPoint(genesisPoint))
+	anchors[3].AnchorTx = anchorTx
+	err = assetStore.ResumeImport(
+		ctx, batchID, genesisPoint, otherAssets, anchors, chunkSize,
+	)
+	require.ErrorIs(t, err, ErrImportBatchMismatch)
+
+	dbAssets, err = db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, chunkSize)
+
+	// Once the anchor is fixed, resuming the import should insert the
+	// remaining assets only.
+	err = assetStore.ResumeImport(
+		ctx, batchID, genesisPoint, assets, anchors, chunkSize,
+	)
+	require.NoError(t, err)
+
+	dbAssets, err = db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, len(assets))
+
+	// With the import complete, its checkpoint should be gone.
+	_, err = db.FetchImportCheckpoint(ctx, batchID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Each of the assets should've been inserted exactly once.
+	for _, a := range assets {
+		scriptKeyID, err := db.FetchScriptKeyIDByTweakedKey(
+			ctx, a.ScriptKey.PubKey.SerializeCompressed(),
+		)
+		require.NoError(t, err)
+
+		var numAssets int
+		for _, dbAsset := range dbAssets {
+			if dbAsset.ScriptKeyID == scriptKeyID {
+				numAssets++
+			}
+		}
+		require.Equal(t, 1, numAssets)
+	}
+}
@@ -0,0 +1,114 @@
+package tarodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lightninglabs/taro/asset"
+)
+
+var (
+	// ErrQuarantinedAssetNotFound is returned when a quarantined asset
+	// can't be found in the database.
+	ErrQuarantinedAssetNotFound = errors.New("quarantined asset not found")
+)
+
+// QuarantinedAsset is an asset that failed validation. Rather than rejecting
+// it outright, it's kept around so it can be inspected and possibly recovered
+// later on.
+type QuarantinedAsset struct {
+	// ID is the primary key of the quarantined asset.
+	ID int32
+
+	// RawAsset is the raw encoding of the asset as it was received.
+	RawAsset []byte
+
+	// Reason describes why the asset failed validation.
+	Reason string
+
+	// QuarantinedAt is the time the asset was quarantined.
+	QuarantinedAt time.Time
+}
+
+// QuarantineAsset stores the raw encoding of an asset that failed validation
+// along with the reason it failed, and returns the ID of the quarantined
+// asset.
+func (a *AssetStore) QuarantineAsset(ctx context.Context, rawAsset []byte,
+	reason string) (int32, error) {
+
+	var quarantineID int32
+
+	var writeTxOpts AssetStoreTxOptions
+	dbErr := a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		var err error
+		quarantineID, err = q.InsertQuarantinedAsset(
+			ctx, NewQuarantinedAsset{
+				RawAsset:      rawAsset,
+				FailureReason: reason,
+				QuarantinedAt: time.Now().UTC(),
+			},
+		)
+		return err
+	})
+	if dbErr != nil {
+		return 0, fmt.Errorf("unable to quarantine asset: %w", dbErr)
+	}
+
+	return quarantineID, nil
+}
+
+// FetchQuarantinedAssets fetches all quarantined assets, in the order they
+// were quarantined.
+func (a *AssetStore) FetchQuarantinedAssets(
+	ctx context.Context) ([]*QuarantinedAsset, error) {
+
+	var dbAssets []RawQuarantinedAsset
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbAssets, err = q.FetchQuarantinedAssets(ctx)
+		return err
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch quarantined assets: %w",
+			dbErr)
+	}
+
+	return fMap(dbAssets, func(a RawQuarantinedAsset) *QuarantinedAsset {
+		return &QuarantinedAsset{
+			ID:            a.QuarantineID,
+			RawAsset:      a.RawAsset,
+			Reason:        a.FailureReason,
+			QuarantinedAt: a.QuarantinedAt.UTC(),
+		}
+	}), nil
+}
+
+// ReleaseQuarantinedAsset removes an asset from the quarantine, and imports
+// its fixed version along with the UTXO that anchors it instead. Both happen
+// in a single database transaction, so the asset stays quarantined if the
+// fixed version can't be imported.
+func (a *AssetStore) ReleaseQuarantinedAsset(ctx context.Context,
+	quarantineID int32, fixedAsset *asset.Asset, anchor AnchorUTXO) error {
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		numDeleted, err := q.DeleteQuarantinedAsset(ctx, quarantineID)
+		if err != nil {
+			return fmt.Errorf("unable to delete quarantined "+
+				"asset: %w", err)
+		}
+		if numDeleted == 0 {
+			return fmt.Errorf("%w: id=%d",
+				ErrQuarantinedAssetNotFound, quarantineID)
+		}
+
+		return a.importAssetsWithAnchors(
+			ctx, q, fixedAsset.Genesis.FirstPrevOut,
+			[]*asset.Asset{fixedAsset}, []AnchorUTXO{anchor},
+		)
+	})
+}
@@ -0,0 +1,95 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQuarantineAsset tests that we're able to quarantine an asset that
+// failed validation, and to release it again once it was fixed.
+func TestQuarantineAsset(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create an asset whose encoding was truncated, which means it
+	// fails to decode.
+	goodAsset := randAsset(t, withNoGroupKey())
+	var assetBuf bytes.Buffer
+	require.NoError(t, goodAsset.Encode(&assetBuf))
+	rawAsset := assetBuf.Bytes()[:assetBuf.Len()/2]
+
+	var badAsset asset.Asset
+	decodeErr := badAsset.Decode(bytes.NewReader(rawAsset))
+	require.Error(t, decodeErr)
+
+	// Rather than rejecting it, we'll quarantine it.
+	quarantineID, err := assetStore.QuarantineAsset(
+		ctx, rawAsset, decodeErr.Error(),
+	)
+	require.NoError(t, err)
+
+	quarantined, err := assetStore.FetchQuarantinedAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	require.Equal(t, quarantineID, quarantined[0].ID)
+	require.Equal(t, rawAsset, quarantined[0].RawAsset)
+	require.Equal(t, decodeErr.Error(), quarantined[0].Reason)
+	require.False(t, quarantined[0].QuarantinedAt.IsZero())
+
+	// Releasing an unknown asset should fail.
+	anchor := randAnchorUTXO(t)
+	err = assetStore.ReleaseQuarantinedAsset(
+		ctx, quarantineID+1, goodAsset, anchor,
+	)
+	require.ErrorIs(t, err, ErrQuarantinedAssetNotFound)
+
+	// Once fixed, we'll release the asset, which should remove it from the
+	// quarantine and import it.
+	err = assetStore.ReleaseQuarantinedAsset(
+		ctx, quarantineID, goodAsset, anchor,
+	)
+	require.NoError(t, err)
+
+	quarantined, err = assetStore.FetchQuarantinedAssets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, quarantined)
+
+	chainAsset, err := assetStore.FetchAsset(
+		ctx, goodAsset.ID(), goodAsset.ScriptKey.PubKey,
+	)
+	require.NoError(t, err)
+	require.Equal(t, anchor.OutPoint, chainAsset.AnchorOutpoint)
+	assertAssetEqual(t, goodAsset, chainAsset.Asset)
+
+	// The asset can only be released once.
+	err = assetStore.ReleaseQuarantinedAsset(
+		ctx, quarantineID, goodAsset, randAnchorUTXO(t),
+	)
+	require.ErrorIs(t, err, ErrQuarantinedAssetNotFound)
+
+	// Finally, if the fixed asset can't be imported, then the asset should
+	// stay quarantined.
+	quarantineID, err = assetStore.QuarantineAsset(
+		ctx, test.RandBytes(32), "invalid asset",
+	)
+	require.NoError(t, err)
+
+	noAnchorTx := randAnchorUTXO(t)
+	noAnchorTx.AnchorTx = nil
+	err = assetStore.ReleaseQuarantinedAsset(
+		ctx, quarantineID, randAsset(t), noAnchorTx,
+	)
+	require.Error(t, err)
+
+	quarantined, err = assetStore.FetchQuarantinedAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	require.Equal(t, quarantineID, quarantined[0].ID)
+}
@@ -3,9 +3,20 @@ package tarodb
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
@@ -23,9 +34,72 @@ import (
 )
 
 type (
-	// ConfirmedAsset is an asset that has been fully confirmed on chain.
+	// ConfirmedAsset is an asset along with the information of where
+	// it's anchored on chain, if it's anchored yet.
 	ConfirmedAsset = sqlc.QueryAssetsRow
 
+	// AssetAnchorBinding is used to bind an asset to the UTXO that
+	// anchors it.
+	AssetAnchorBinding = sqlc.BindAssetAnchorParams
+
+	// GroupAssetAmount is the amount of an unspent asset of an asset
+	// group.
+	GroupAssetAmount = sqlc.FetchGroupAssetAmountsRow
+
+	// StoredAssetAmount is the amount of an asset as stored in the amount
+	// and amount_big columns.
+	StoredAssetAmount = sqlc.FetchAssetAmountsRow
+
+	// StoredGroupKey is a group key along with its raw key and the sig of
+	// the first genesis asset created with it.
+	StoredGroupKey = sqlc.FetchAllGroupKeysRow
+
+	// StoredGroupRawKey is the internal key a group key was derived from.
+	StoredGroupRawKey = sqlc.FetchGroupRawKeyRow
+
+	// GroupSize is the number of unspent assets of an asset group.
+	GroupSize = sqlc.FetchGroupSizesRow
+
+	// AssetTypeCount is the number of assets of a particular asset type.
+	AssetTypeCount = sqlc.CountAssetsByTypeRow
+
+	// SharedMetaHashGenesis is a genesis asset whose metadata hash is
+	// shared with at least one other genesis asset.
+	SharedMetaHashGenesis = sqlc.FetchGenesesWithSharedMetaHashRow
+
+	// GroupReissuance is a genesis asset that reissued an asset group.
+	GroupReissuance = sqlc.FetchGroupReissuancesRow
+
+	// AnchorInternalKey is the internal key of the output an asset is
+	// anchored in.
+	AnchorInternalKey = sqlc.FetchAssetAnchorInternalKeyRow
+
+	// StoredInternalKey is an internal key as stored in the database,
+	// along with the key locator it was derived from.
+	StoredInternalKey = sqlc.FetchInternalKeyByIDRow
+
+	// RawInternalKey is an internal key as stored in the database, looked
+	// up by its raw key.
+	RawInternalKey = sqlc.FetchInternalKeyByRawKeyRow
+
+	// StoredScriptKey is a script key as stored in the database, along
+	// with the raw key it was derived from and its type.
+	StoredScriptKey = sqlc.FetchScriptKeyByTweakedKeyRow
+
+	// GenesisMetaType is used to set the metadata type of a genesis asset.
+	GenesisMetaType = sqlc.SetGenesisAssetMetaTypeParams
+
+	// MetadataJSONFieldQuery is used to query genesis assets by a field of
+	// their JSON metadata.
+	MetadataJSONFieldQuery = sqlc.FetchAssetIDsByMetadataJSONFieldParams
+
+	// AssetTouch is used to set the update time of a set of assets.
+	AssetTouch = sqlc.TouchAssetsParams
+
+	// AssetPrevID is the information of an anchored asset that's needed to
+	// reference it as the previous input of a new asset.
+	AssetPrevID = sqlc.FetchAssetPrevIDRow
+
 	// RawAssetBalance holds a balance query result for a particular asset
 	// or all assets tracked by this daemon.
 	RawAssetBalance = sqlc.QueryAssetBalancesByAssetRow
@@ -102,6 +176,84 @@ type (
 	// NewSpendProof is used to insert new spend proofs for the
 	// sender+receiver.
 	NewSpendProof = sqlc.InsertSpendProofsParams
+
+	// ScriptKeyKinds tallies the assets of a group by whether their script
+	// key carries a tweak or not.
+	ScriptKeyKinds = sqlc.FetchGroupAssetsScriptKeyKindsRow
+
+	// AnchorStatusCounts tallies the unspent assets by whether they're
+	// anchored in a managed UTXO or not.
+	AnchorStatusCounts = sqlc.FetchAnchorStatusCountsRow
+
+	// FreedKeyIndex is the locator of a locally derived internal key that
+	// was deleted, so its index can be handed out again.
+	FreedKeyIndex = sqlc.InsertFreedKeyIndexParams
+
+	// OrphanScriptKey is a script key that was deleted as nothing
+	// referenced it anymore.
+	OrphanScriptKey = sqlc.DeleteOrphanScriptKeyRow
+
+	// OrphanInternalKey is the locator of an internal key that was deleted
+	// as nothing referenced it anymore.
+	OrphanInternalKey = sqlc.DeleteOrphanInternalKeyRow
+
+	// GenesisWithoutMetadata is a genesis asset that has no metadata.
+	GenesisWithoutMetadata = sqlc.FetchGenesisAssetsWithoutMetadataRow
+
+	// DuplicateOutputGenesis is a genesis asset that shares its output
+	// index with another genesis asset of the same genesis point.
+	DuplicateOutputGenesis = sqlc.FetchDuplicateOutputIndicesRow
+
+	// UnanchoredAsset is an asset that isn't anchored in an on-chain
+	// output, along with its genesis and the time its genesis point was
+	// first stored.
+	UnanchoredAsset = sqlc.FetchStaleUnanchoredAssetsRow
+
+	// NullScriptKeyAsset is an asset that references a script key that
+	// doesn't exist, along with its genesis.
+	NullScriptKeyAsset = sqlc.FetchAssetsWithNullScriptKeyRow
+
+	// AnchorUtxoAssetCount tallies the number of assets anchored by a
+	// managed UTXO.
+	AnchorUtxoAssetCount = sqlc.FetchAnchorUtxoAssetCountsRow
+
+	// NewQuarantinedAsset wraps the params needed to quarantine an asset
+	// that failed validation.
+	NewQuarantinedAsset = sqlc.InsertQuarantinedAssetParams
+
+	// RawQuarantinedAsset is an asset that failed validation as stored in
+	// the quarantine table.
+	RawQuarantinedAsset = sqlc.QuarantinedAsset
+
+	// RawSharedInternalKey is an internal key that's referenced by the
+	// script keys of multiple assets.
+	RawSharedInternalKey = sqlc.FetchSharedScriptInternalKeysRow
+
+	// GenAssetGroupSig is the group sig of a genesis asset fetched as part
+	// of a set of genesis assets.
+	GenAssetGroupSig = sqlc.FetchGroupSigsByGenAssetIDsRow
+
+	// AssetAmountQuery is used to query the amount of an asset by its
+	// asset ID and script key.
+	AssetAmountQuery = sqlc.FetchAssetAmountsByScriptKeyParams
+
+	// ImportCheckpoint records the index of the last asset of an import
+	// batch that was committed to disk.
+	ImportCheckpoint = sqlc.UpsertImportCheckpointParams
+
+	// StoredImportCheckpoint is the checkpoint of an import batch, along
+	// with the hash of the batch it was recorded for.
+	StoredImportCheckpoint = sqlc.FetchImportCheckpointRow
+
+	// NewAuditLogEntry is used to append a new entry to the audit log.
+	NewAuditLogEntry = sqlc.InsertAuditLogEntryParams
+
+	// AuditLogEntry is a single entry of the audit log.
+	AuditLogEntry = sqlc.AuditLog
+
+	// AuditedAsset holds the columns of an asset that are checked against
+	// the audit log.
+	AuditedAsset = sqlc.FetchAuditedAssetsRow
 )
 
 // ActiveAssetsStore is a sub-set of the main sqlc.Querier interface that
@@ -111,10 +263,196 @@ type ActiveAssetsStore interface {
 	// assets.
 	UpsertAssetStore
 
-	// QueryAssets fetches the set of fully confirmed assets.
+	// FetchGenesisStore houses the methods related to fetching genesis
+	// assets.
+	FetchGenesisStore
+
+	// QueryAssets fetches the set of assets matching the given filters.
+	// Unless the filters ask for them, spent assets and assets that aren't
+	// anchored yet are left out.
 	QueryAssets(context.Context, QueryAssetFilters) ([]ConfirmedAsset,
 		error)
 
+	// FetchGroupAssetAmounts fetches the amount of each unspent asset
+	// of all asset groups, ordered by their tweaked group key.
+	FetchGroupAssetAmounts(ctx context.Context) ([]GroupAssetAmount,
+		error)
+
+	// FetchAllGroupKeys fetches all group keys along with their raw key
+	// and the sig of the first genesis asset created with them.
+	FetchAllGroupKeys(ctx context.Context) ([]StoredGroupKey, error)
+
+	// FetchGroupRawKey fetches the internal key the group key with the
+	// given tweaked key was derived from.
+	FetchGroupRawKey(ctx context.Context,
+		tweakedGroupKey []byte) (StoredGroupRawKey, error)
+
+	// FetchGroupSizes fetches the number of unspent assets of each asset
+	// group.
+	FetchGroupSizes(ctx context.Context) ([]GroupSize, error)
+
+	// FetchGenesesWithSharedMetaHash fetches all genesis assets whose
+	// metadata hash is shared with at least one other genesis asset,
+	// ordered by their metadata hash.
+	FetchGenesesWithSharedMetaHash(
+		ctx context.Context) ([]SharedMetaHashGenesis, error)
+
+	// CountAssetsByType counts the assets of each asset type. Spent
+	// assets are only counted if includeSpent is true.
+	CountAssetsByType(ctx context.Context,
+		includeSpent interface{}) ([]AssetTypeCount, error)
+
+	// FetchGroupReissuances fetches the genesis assets that reissued the
+	// asset group with the given tweaked group key, in the order they were
+	// issued in.
+	FetchGroupReissuances(ctx context.Context,
+		tweakedGroupKey []byte) ([]GroupReissuance, error)
+
+	// FetchAssetAnchorInternalKey fetches the internal key of the output
+	// the asset with the given primary key is anchored in.
+	FetchAssetAnchorInternalKey(ctx context.Context,
+		assetID int32) (AnchorInternalKey, error)
+
+	// FetchGenesisPointByAssetID fetches the genesis point the asset with
+	// the given asset ID was minted from.
+	FetchGenesisPointByAssetID(ctx context.Context,
+		assetID []byte) ([]byte, error)
+
+	// FetchAssetPrevID fetches the information needed to reference the
+	// anchored asset with the given primary key as a previous input.
+	FetchAssetPrevID(ctx context.Context, assetID int32) (AssetPrevID,
+		error)
+
+	// FetchAssetAnchorUTXOID fetches the primary key of the managed UTXO
+	// that anchors the asset with the given primary key, if any.
+	FetchAssetAnchorUTXOID(ctx context.Context,
+		assetID int32) (sql.NullInt32, error)
+
+	// BindAssetAnchor binds an asset to the given anchor UTXO, unless it's
+	// already anchored in a different UTXO. The number of bound assets is
+	// returned.
+	BindAssetAnchor(ctx context.Context,
+		arg AssetAnchorBinding) (int64, error)
+
+	// SetAssetSpent marks the asset with the given primary key as spent,
+	// returning the number of assets that weren't spent before.
+	SetAssetSpent(ctx context.Context, assetID int32) (int64, error)
+
+	// SetAssetsSpent marks all assets with the given primary keys as
+	// spent with a single statement, returning the number of assets that
+	// weren't spent before.
+	SetAssetsSpent(ctx context.Context, assetIDs []int32) (int64, error)
+
+	// TouchAssets sets the update time of all assets with the given
+	// primary keys with a single statement, returning the number of
+	// assets that were updated.
+	TouchAssets(ctx context.Context, arg AssetTouch) (int64, error)
+
+	// SetGenesisAssetMetaType sets the metadata type of a genesis asset,
+	// returning the number of genesis assets that were updated.
+	SetGenesisAssetMetaType(ctx context.Context,
+		arg GenesisMetaType) (int64, error)
+
+	// FetchGenesisMetadataByAssetID fetches the metadata of the genesis
+	// asset with the given asset ID, which is nil if it wasn't revealed.
+	FetchGenesisMetadataByAssetID(ctx context.Context,
+		assetID []byte) ([]byte, error)
+
+	// FetchAssetIDsByMetadataJSONField fetches the asset IDs of all
+	// genesis assets with metadata of the given type that has a JSON
+	// field at the given path with the given value. This is only
+	// supported by Postgres.
+	FetchAssetIDsByMetadataJSONField(ctx context.Context,
+		arg MetadataJSONFieldQuery) ([][]byte, error)
+
+	// Backend returns the type of database backend the queries are
+	// executed against.
+	Backend() sqlc.BackendType
+
+	// InsertQuarantinedAsset stores an asset that failed validation in
+	// the quarantine table.
+	InsertQuarantinedAsset(ctx context.Context,
+		arg NewQuarantinedAsset) (int32, error)
+
+	// FetchQuarantinedAssets fetches all quarantined assets.
+	FetchQuarantinedAssets(
+		ctx context.Context) ([]RawQuarantinedAsset, error)
+
+	// DeleteQuarantinedAsset removes an asset from the quarantine table,
+	// returning the number of rows deleted.
+	DeleteQuarantinedAsset(ctx context.Context,
+		quarantineID int32) (int64, error)
+
+	// UpsertImportCheckpoint records the index of the last committed
+	// asset of an import batch.
+	UpsertImportCheckpoint(ctx context.Context,
+		arg ImportCheckpoint) error
+
+	// FetchImportCheckpoint fetches the index of the last committed asset
+	// of the import batch with the given ID.
+	FetchImportCheckpoint(ctx context.Context,
+		batchID string) (StoredImportCheckpoint, error)
+
+	// DeleteImportCheckpoint deletes the checkpoint of the import batch
+	// with the given ID.
+	DeleteImportCheckpoint(ctx context.Context, batchID string) error
+
+	// FetchAuditLog fetches all entries of the audit log, in the order
+	// they were appended.
+	FetchAuditLog(ctx context.Context) ([]AuditLogEntry, error)
+
+	// FetchDistinctAssetIDs fetches the unique asset IDs of all assets.
+	FetchDistinctAssetIDs(ctx context.Context) ([][]byte, error)
+
+	// FetchAssetAmountsByScriptKey fetches the amounts of all assets
+	// with the given asset ID and script key.
+	FetchAssetAmountsByScriptKey(ctx context.Context,
+		arg AssetAmountQuery) ([]sqlc.FetchAssetAmountsByScriptKeyRow,
+		error)
+
+	// FetchGroupSigsByGenAssetIDs fetches the group sigs of all genesis
+	// assets with one of the given primary keys.
+	FetchGroupSigsByGenAssetIDs(ctx context.Context,
+		genAssetIDs []int32) ([]GenAssetGroupSig, error)
+
+	// FetchSharedScriptInternalKeys returns the internal keys that are
+	// referenced by the script keys of at least the given number of
+	// assets.
+	FetchSharedScriptInternalKeys(ctx context.Context,
+		minNumAssets int64) ([]RawSharedInternalKey, error)
+
+	// FetchAssetAmounts returns the amount of each asset on disk.
+	FetchAssetAmounts(ctx context.Context) ([]StoredAssetAmount, error)
+
+	// FetchAnchorUtxoAssetCounts returns the number of assets anchored by
+	// each managed UTXO that anchors at least one asset.
+	FetchAnchorUtxoAssetCounts(
+		ctx context.Context) ([]AnchorUtxoAssetCount, error)
+
+	// FetchGenesisAssetsWithoutMetadata fetches the set of genesis assets
+	// that have either no or empty metadata.
+	FetchGenesisAssetsWithoutMetadata(
+		ctx context.Context) ([]GenesisWithoutMetadata, error)
+
+	// GenesisPoints fetches all genesis points.
+	GenesisPoints(ctx context.Context) ([]sqlc.GenesisPoint, error)
+
+	// FetchDuplicateOutputIndices fetches the genesis assets of the given
+	// genesis point that share their output index with another one.
+	FetchDuplicateOutputIndices(ctx context.Context,
+		genesisPointID int32) ([]DuplicateOutputGenesis, error)
+
+	// FetchStaleUnanchoredAssets fetches the assets that aren't anchored
+	// yet, even though their genesis point was stored before the given
+	// time.
+	FetchStaleUnanchoredAssets(ctx context.Context,
+		olderThan sql.NullTime) ([]UnanchoredAsset, error)
+
+	// FetchAssetsWithNullScriptKey fetches the assets that reference a
+	// script key that doesn't exist.
+	FetchAssetsWithNullScriptKey(
+		ctx context.Context) ([]NullScriptKeyAsset, error)
+
 	// QueryAssetBalancesByAsset queries the balances for assets or
 	// alternatively for a selected one that matches the passed asset ID
 	// filter.
@@ -127,6 +465,17 @@ type ActiveAssetsStore interface {
 	QueryAssetBalancesByGroup(context.Context,
 		[]byte) ([]RawAssetGroupBalance, error)
 
+	// FetchGroupAssetsScriptKeyKinds counts the assets of the group
+	// identified by the tweaked group key, split by whether their script
+	// key has a tweak or not.
+	FetchGroupAssetsScriptKeyKinds(ctx context.Context,
+		tweakedGroupKey []byte) (ScriptKeyKinds, error)
+
+	// FetchAnchorStatusCounts counts the unspent assets, split by whether
+	// they're anchored in a managed UTXO or not.
+	FetchAnchorStatusCounts(ctx context.Context) (AnchorStatusCounts,
+		error)
+
 	// FetchAssetProofs fetches all the asset proofs we have stored on
 	// disk.
 	FetchAssetProofs(ctx context.Context) ([]AssetProof, error)
@@ -136,15 +485,49 @@ type ActiveAssetsStore interface {
 	FetchAssetProof(ctx context.Context,
 		scriptKey []byte) (AssetProofI, error)
 
-	// UpsertChainTx inserts a new or updates an existing chain tx into the
-	// DB.
-	UpsertChainTx(ctx context.Context, arg ChainTx) (int32, error)
-
 	// UpsertManagedUTXO inserts a new or updates an existing managed UTXO
 	// to disk and returns the primary key.
 	UpsertManagedUTXO(ctx context.Context, arg RawManagedUTXO) (int32,
 		error)
 
+	// FetchChainTx fetches a chain tx from the DB by its txid.
+	FetchChainTx(ctx context.Context, txid []byte) (sqlc.ChainTxn, error)
+
+	// FetchGenesisPointIDByPrevOut fetches the primary key of the genesis
+	// point with the given serialized outpoint.
+	FetchGenesisPointIDByPrevOut(ctx context.Context,
+		prevOut []byte) (int32, error)
+
+	// FetchGenesisAssetIDByTag fetches the primary key of the genesis
+	// asset with the given tag.
+	FetchGenesisAssetIDByTag(ctx context.Context,
+		assetTag string) (int32, error)
+
+	// FetchInternalKeyIDByRawKey fetches the primary key of the internal
+	// key with the given raw key.
+	FetchInternalKeyIDByRawKey(ctx context.Context,
+		rawKey []byte) (int32, error)
+
+	// FetchInternalKeyByID fetches the internal key with the given
+	// primary key.
+	FetchInternalKeyByID(ctx context.Context,
+		keyID int32) (StoredInternalKey, error)
+
+	// FetchInternalKeyByRawKey fetches the internal key with the given
+	// raw key.
+	FetchInternalKeyByRawKey(ctx context.Context,
+		rawKey []byte) (RawInternalKey, error)
+
+	// FetchScriptKeyByTweakedKey fetches the script key with the given
+	// tweaked key, along with the raw key it was derived from.
+	FetchScriptKeyByTweakedKey(ctx context.Context,
+		tweakedScriptKey []byte) (StoredScriptKey, error)
+
+	// FetchGroupKeyIDByTweakedKey fetches the primary key of the asset
+	// group with the given tweaked group key.
+	FetchGroupKeyIDByTweakedKey(ctx context.Context,
+		tweakedGroupKey []byte) (int32, error)
+
 	// UpsertAssetProof inserts a new or updates an existing asset proof on
 	// disk.
 	UpsertAssetProof(ctx context.Context,
@@ -167,8 +550,10 @@ type ActiveAssetsStore interface {
 	FetchManagedUTXOs(context.Context) ([]ManagedUTXORow, error)
 
 	// ReanchorAssets takes an old anchor point, then updates all assets
-	// that point to that old anchor point-to-point to the new one.
-	ReanchorAssets(ctx context.Context, arg AssetAnchorUpdate) error
+	// that point to that old anchor point-to-point to the new one. The
+	// primary keys of the updated assets are returned.
+	ReanchorAssets(ctx context.Context, arg AssetAnchorUpdate) ([]int32,
+		error)
 
 	// ApplySpendDelta applies a sped delta (new amount and script key)
 	// based on the existing script key of an asset.
@@ -178,6 +563,15 @@ type ActiveAssetsStore interface {
 	// serialized outpoint.
 	DeleteManagedUTXO(ctx context.Context, outpoint []byte) error
 
+	// InsertFreedKeyIndex records the index of a deleted, locally derived
+	// internal key as freed, so it can be reserved again.
+	InsertFreedKeyIndex(ctx context.Context, arg FreedKeyIndex) error
+
+	// ReserveFreedKeyIndex reserves the lowest freed index in the given
+	// key family that isn't reserved yet, and returns it.
+	ReserveFreedKeyIndex(ctx context.Context,
+		keyFamily int32) (int32, error)
+
 	// ConfirmChainAnchorTx marks a new anchor transaction that was
 	// previously unconfirmed as confirmed.
 	ConfirmChainAnchorTx(ctx context.Context, arg AnchorTxConf) error
@@ -207,6 +601,29 @@ type ActiveAssetsStore interface {
 	// given asset ID.
 	DeleteAssetWitnesses(ctx context.Context, assetID int32) error
 
+	// DeleteAssetProofsByScriptKeyID deletes the proofs of all assets
+	// with the given script key.
+	DeleteAssetProofsByScriptKeyID(ctx context.Context,
+		scriptKeyID int32) error
+
+	// DeleteAssetsByScriptKeyID deletes all assets with the given script
+	// key along with their witnesses, and returns the primary keys of the
+	// deleted assets.
+	DeleteAssetsByScriptKeyID(ctx context.Context,
+		scriptKeyID int32) ([]int32, error)
+
+	// DeleteOrphanScriptKey deletes the script key with the given primary
+	// key if nothing references it anymore, and returns the primary key
+	// of its internal key along with whether that raw key is known.
+	DeleteOrphanScriptKey(ctx context.Context,
+		scriptKeyID int32) (OrphanScriptKey, error)
+
+	// DeleteOrphanInternalKey deletes the internal key with the given
+	// primary key if nothing references it anymore, and returns its key
+	// locator.
+	DeleteOrphanInternalKey(ctx context.Context,
+		keyID int32) (OrphanInternalKey, error)
+
 	// InsertSpendProofs is used to insert the new spend proofs after a
 	// transfer into DB.
 	InsertSpendProofs(ctx context.Context, arg NewSpendProof) (int32, error)
@@ -250,16 +667,54 @@ type BatchedAssetStore interface {
 	BatchedTx[ActiveAssetsStore]
 }
 
+// serializationRetryAssetStore wraps a BatchedAssetStore and retries all of
+// its transactions that fail because they conflicted with a concurrent one.
+type serializationRetryAssetStore struct {
+	BatchedAssetStore
+
+	cfg *SerializationRetryConfig
+}
+
+// NewSerializationRetryAssetStore returns a new BatchedAssetStore that
+// transparently retries transactions failing with a serialization failure or
+// a deadlock, such as concurrent imports racing on the same genesis point
+// under Postgres. Any other error is passed through unchanged.
+func NewSerializationRetryAssetStore(db BatchedAssetStore,
+	cfg *SerializationRetryConfig) BatchedAssetStore {
+
+	return &serializationRetryAssetStore{
+		BatchedAssetStore: db,
+		cfg:               cfg,
+	}
+}
+
+// ExecTx executes the passed txBody in a single transaction, and retries the
+// transaction from the start if it failed because of a serialization failure.
+//
+// NOTE: This implements the BatchedTx interface.
+func (s *serializationRetryAssetStore) ExecTx(ctx context.Context,
+	txOptions TxOptions, txBody func(ActiveAssetsStore) error) error {
+
+	return ExecTxWithSerializationRetry[ActiveAssetsStore](
+		ctx, s.BatchedAssetStore, s.cfg, txOptions, txBody,
+	)
+}
+
 // AssetStore is used to query for the set of pending and confirmed assets.
 type AssetStore struct {
 	db BatchedAssetStore
+
+	// upsertOpts are the policies applied when importing assets.
+	upsertOpts *upsertOptions
 }
 
 // NewAssetStore creates a new AssetStore from the specified BatchedAssetStore
-// interface.
-func NewAssetStore(db BatchedAssetStore) *AssetStore {
+// interface. The passed options modify the policies applied when importing
+// assets.
+func NewAssetStore(db BatchedAssetStore, opts ...UpsertOption) *AssetStore {
 	return &AssetStore{
-		db: db,
+		db:         db,
+		upsertOpts: newUpsertOptions(opts...),
 	}
 }
 
@@ -283,6 +738,10 @@ type ChainAsset struct {
 	// AnchorInternalKey is the raw internal key that was used to create the
 	// anchor Taproot output key.
 	AnchorInternalKey *btcec.PublicKey
+
+	// primaryKey is the primary key of the asset in the store it was
+	// fetched from, which breaks ties in the canonical order of assets.
+	primaryKey int32
 }
 
 // ManagedUTXO holds information about a given UTXO we manage.
@@ -470,11 +929,17 @@ func dbAssetsToChainAssets(dbAssets []ConfirmedAsset,
 			return nil, fmt.Errorf("unable to read "+
 				"outpoint: %w", err)
 		}
+		outputIndex, err := parseOutputIndex(
+			sprout.GenesisOutputIndex,
+		)
+		if err != nil {
+			return nil, err
+		}
 		assetGenesis := asset.Genesis{
 			FirstPrevOut: genesisPrevOut,
 			Tag:          sprout.AssetTag,
 			Metadata:     sprout.MetaData,
-			OutputIndex:  uint32(sprout.GenesisOutputIndex),
+			OutputIndex:  outputIndex,
 			Type:         asset.Type(sprout.AssetType),
 		}
 
@@ -487,7 +952,12 @@ func dbAssetsToChainAssets(dbAssets []ConfirmedAsset,
 		var amount uint64
 		switch asset.Type(sprout.AssetType) {
 		case asset.Normal:
-			amount = uint64(sprout.Amount)
+			amount, err = parseAssetAmount(
+				sprout.Amount, sprout.AmountBig,
+			)
+			if err != nil {
+				return nil, err
+			}
 		case asset.Collectible:
 			amount = 1
 		}
@@ -496,11 +966,21 @@ func dbAssetsToChainAssets(dbAssets []ConfirmedAsset,
 		if err != nil {
 			return nil, err
 		}
+
+		// An empty tweak that isn't NULL is read back as a nil slice,
+		// so we restore it here to preserve the distinction between a
+		// BIP 86 key (no tweak) and a key with an explicit empty
+		// tweak.
+		scriptKeyTweak := sprout.ScriptKeyTweak
+		if scriptKeyTweak == nil && !sprout.ScriptKeyTweakIsNull {
+			scriptKeyTweak = []byte{}
+		}
+
 		scriptKey := asset.ScriptKey{
 			PubKey: scriptKeyPub,
 			TweakedScriptKey: &asset.TweakedScriptKey{
 				RawKey: rawScriptKeyDesc,
-				Tweak:  sprout.ScriptKeyTweak,
+				Tweak:  scriptKeyTweak,
 			},
 		}
 
@@ -513,6 +993,10 @@ func dbAssetsToChainAssets(dbAssets []ConfirmedAsset,
 				"%v", err)
 		}
 
+		assetSprout.ScriptVersion = asset.ScriptVersion(
+			sprout.ScriptVersion,
+		)
+
 		if len(sprout.SplitCommitmentRootHash) != 0 {
 			var nodeHash mssmt.NodeHash
 			copy(nodeHash[:], sprout.SplitCommitmentRootHash)
@@ -544,6 +1028,17 @@ func dbAssetsToChainAssets(dbAssets []ConfirmedAsset,
 			}
 		}
 
+		chainAssets[i] = &ChainAsset{
+			Asset:      assetSprout,
+			primaryKey: sprout.AssetPrimaryKey,
+		}
+
+		// An asset that isn't anchored yet doesn't have any of the
+		// anchor information below.
+		if sprout.AnchorTx == nil {
+			continue
+		}
+
 		anchorTx := wire.NewMsgTx(2)
 		err = anchorTx.Deserialize(bytes.NewBuffer(sprout.AnchorTx))
 		if err != nil {
@@ -582,14 +1077,11 @@ func dbAssetsToChainAssets(dbAssets []ConfirmedAsset,
 				"internal key: %w", err)
 		}
 
-		chainAssets[i] = &ChainAsset{
-			Asset:             assetSprout,
-			AnchorTx:          anchorTx,
-			AnchorTxid:        anchorTx.TxHash(),
-			AnchorBlockHash:   anchorBlockHash,
-			AnchorOutpoint:    anchorOutpoint,
-			AnchorInternalKey: anchorInternalKey,
-		}
+		chainAssets[i].AnchorTx = anchorTx
+		chainAssets[i].AnchorTxid = anchorTx.TxHash()
+		chainAssets[i].AnchorBlockHash = anchorBlockHash
+		chainAssets[i].AnchorOutpoint = anchorOutpoint
+		chainAssets[i].AnchorInternalKey = anchorInternalKey
 	}
 
 	return chainAssets, nil
@@ -679,12 +1171,19 @@ func (a *AssetStore) QueryBalancesByAsset(ctx context.Context,
 			var assetID asset.ID
 			copy(assetID[:], assetBalance.AssetID[:])
 
+			outputIndex, err := parseOutputIndex(
+				assetBalance.OutputIndex,
+			)
+			if err != nil {
+				return err
+			}
+
 			assetIDBalance := AssetBalance{
 				Version:     assetBalance.Version,
 				Balance:     uint64(assetBalance.Balance),
 				Tag:         assetBalance.AssetTag,
 				Type:        asset.Type(assetBalance.AssetType),
-				OutputIndex: uint32(assetBalance.OutputIndex),
+				OutputIndex: outputIndex,
 			}
 
 			err = readOutPoint(
@@ -762,363 +1261,3280 @@ func (a *AssetStore) QueryAssetBalancesByGroup(ctx context.Context,
 	return balances, nil
 }
 
-// FetchAllAssets fetches the set of confirmed assets stored on disk.
-func (a *AssetStore) FetchAllAssets(ctx context.Context,
-	query *AssetQueryFilters) ([]*ChainAsset, error) {
-
-	var (
-		dbAssets       []ConfirmedAsset
-		assetWitnesses map[int32][]AssetWitness
-		err            error
-	)
+// FetchGroupSizes returns the number of unspent assets of each asset group,
+// keyed by the hex encoded tweaked group key. This can be used to tell large
+// collections apart from groups with just a single asset.
+func (a *AssetStore) FetchGroupSizes(ctx context.Context) (map[string]int,
+	error) {
 
-	// We'll now map the application level filtering to the type of
-	// filtering our database query understands.
-	assetFilter := constraintsToDbFilter(query)
+	var groupSizes map[string]int
 
-	// With the query constructed, we can now fetch the assets along w/
-	// their witness information.
 	readOpts := NewAssetStoreReadTx()
 	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
-		dbAssets, assetWitnesses, err = fetchAssetsWithWitness(
-			ctx, q, assetFilter,
-		)
+		dbSizes, err := q.FetchGroupSizes(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to fetch group sizes: %w",
+				err)
+		}
 
-		return err
+		groupSizes = make(map[string]int, len(dbSizes))
+		for _, dbSize := range dbSizes {
+			groupKey := hex.EncodeToString(dbSize.TweakedGroupKey)
+			groupSizes[groupKey] = int(dbSize.NumAssets)
+		}
+
+		return nil
 	})
 	if dbErr != nil {
 		return nil, dbErr
 	}
 
-	return dbAssetsToChainAssets(dbAssets, assetWitnesses)
+	return groupSizes, nil
 }
 
-// FetchManagedUTXOs fetches all UTXOs we manage.
-func (a *AssetStore) FetchManagedUTXOs(ctx context.Context) (
-	[]*ManagedUTXO, error) {
+// CountAssetsByType returns the number of unspent assets of each asset type,
+// without loading the assets themselves. Asset types without any unspent
+// assets are omitted.
+func (a *AssetStore) CountAssetsByType(
+	ctx context.Context) (map[asset.Type]int64, error) {
 
-	var (
-		utxos []ManagedUTXORow
-		err   error
-	)
+	return a.countAssetsByType(ctx, false)
+}
+
+// CountAllAssetsByType returns the number of assets of each asset type,
+// including the historical assets that were spent already.
+func (a *AssetStore) CountAllAssetsByType(
+	ctx context.Context) (map[asset.Type]int64, error) {
+
+	return a.countAssetsByType(ctx, true)
+}
+
+// countAssetsByType returns the number of assets of each asset type, only
+// counting spent assets if includeSpent is set.
+func (a *AssetStore) countAssetsByType(ctx context.Context,
+	includeSpent bool) (map[asset.Type]int64, error) {
+
+	var typeCounts map[asset.Type]int64
 
 	readOpts := NewAssetStoreReadTx()
 	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
-		utxos, err = q.FetchManagedUTXOs(ctx)
-		return err
-	})
-	if dbErr != nil {
-		return nil, dbErr
-	}
-
-	managedUtxos := make([]*ManagedUTXO, len(utxos))
-	for i, u := range utxos {
-		var anchorPoint wire.OutPoint
-		err := readOutPoint(
-			bytes.NewReader(u.Outpoint), 0, 0, &anchorPoint,
-		)
+		dbCounts, err := q.CountAssetsByType(ctx, includeSpent)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("unable to count assets: %w", err)
 		}
 
-		internalKey, err := btcec.ParsePubKey(u.RawKey)
-		if err != nil {
-			return nil, err
+		typeCounts = make(map[asset.Type]int64, len(dbCounts))
+		for _, dbCount := range dbCounts {
+			assetType := asset.Type(dbCount.AssetType)
+			typeCounts[assetType] = dbCount.NumAssets
 		}
 
-		managedUtxos[i] = &ManagedUTXO{
-			OutPoint:    anchorPoint,
-			OutputValue: btcutil.Amount(u.AmtSats),
-			InternalKey: keychain.KeyDescriptor{
-				PubKey: internalKey,
-				KeyLocator: keychain.KeyLocator{
-					Index: uint32(u.KeyIndex),
-					Family: keychain.KeyFamily(
-						u.KeyFamily,
-					),
-				},
-			},
-			TaroRoot:         u.TaroRoot,
-			TapscriptSibling: u.TapscriptSibling,
-		}
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
 	}
 
-	return managedUtxos, nil
+	return typeCounts, nil
 }
 
-// FetchAssetProofs returns the latest proof file for either the set of target
-// assets, or all assets if no script keys for an asset are passed in.
-//
-// TODO(roasbeef): potentially have a version that writes thru a reader
-// instead?
-func (a *AssetStore) FetchAssetProofs(ctx context.Context,
-	targetAssets ...*btcec.PublicKey) (proof.AssetBlobs, error) {
+// FetchReissuances returns the geneses of all assets that were issued under
+// the given tweaked group key after the group was first emitted, in the order
+// they were issued in. The genesis of the initial emission isn't included.
+func (a *AssetStore) FetchReissuances(ctx context.Context,
+	tweakedGroupKey []byte) ([]asset.Genesis, error) {
 
-	proofs := make(proof.AssetBlobs)
+	var reissuances []asset.Genesis
 
 	readOpts := NewAssetStoreReadTx()
 	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
-		// No target asset so we can just read them all from disk.
-		if len(targetAssets) == 0 {
-			assetProofs, err := q.FetchAssetProofs(ctx)
-			if err != nil {
-				return fmt.Errorf("unable to fetch asset "+
-					"proofs: %w", err)
-			}
-
-			for _, p := range assetProofs {
-				scriptKey, err := btcec.ParsePubKey(p.ScriptKey)
-				if err != nil {
-					return err
-				}
-
-				serializedKey := asset.ToSerialized(scriptKey)
-				proofs[serializedKey] = p.ProofFile
-			}
-
-			return nil
+		dbGens, err := q.FetchGroupReissuances(ctx, tweakedGroupKey)
+		if err != nil {
+			return fmt.Errorf("unable to fetch reissuances: %w",
+				err)
 		}
 
-		// Otherwise, we'll need to issue a series of queries to fetch
-		// each of the relevant proof files.
-		//
-		// TODO(roasbeef): can modify the query to use IN somewhere
-		// instead? then would take input params and insert into
-		// virtual rows to use
-		for _, scriptKey := range targetAssets {
-			scriptKey := scriptKey
-			serializedKey := asset.ToSerialized(scriptKey)
-
-			assetProof, err := q.FetchAssetProof(
-				ctx, serializedKey[:],
-			)
+		reissuances = make([]asset.Genesis, 0, len(dbGens))
+		for _, dbGen := range dbGens {
+			gen, err := parseGenesis(Genesis(dbGen))
 			if err != nil {
-				return fmt.Errorf("unable to fetch asset "+
-					"proof: %w", err)
+				return err
 			}
 
-			proofs[serializedKey] = assetProof.ProofFile
+			reissuances = append(reissuances, gen)
 		}
+
 		return nil
 	})
 	if dbErr != nil {
 		return nil, dbErr
 	}
 
-	return proofs, nil
+	return reissuances, nil
 }
 
-// FetchProof fetches a proof for an asset uniquely identified by the passed
-// ProofIdentifier.
-//
-// NOTE: This implements the proof.ArchiveBackend interface.
-func (a *AssetStore) FetchProof(ctx context.Context,
-	locator proof.Locator) (proof.Blob, error) {
-
-	// We don't need anything else but the script key since we have an
-	// on-disk index for all proofs we store.
-	scriptKey := locator.ScriptKey
+// FetchGroupsBySupplyRange returns the asset groups whose total supply, summed
+// over all their unspent assets, lies within the given (inclusive) range. The
+// groups are ordered by their tweaked group key.
+func (a *AssetStore) FetchGroupsBySupplyRange(ctx context.Context,
+	minSupply, maxSupply uint64) ([]AssetGroupBalance, error) {
 
-	var diskProof proof.Blob
+	var dbAmounts []GroupAssetAmount
 
 	readOpts := NewAssetStoreReadTx()
 	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
-		assetProof, err := q.FetchAssetProof(
-			ctx, scriptKey.SerializeCompressed(),
+		var err error
+		dbAmounts, err = q.FetchGroupAssetAmounts(ctx)
+		return err
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch groups by supply: %w",
+			dbErr)
+	}
+
+	// The supply of a group can exceed the range of a uint64, so we sum
+	// up the exact amounts of the assets of each group as big integers.
+	// The group keys are kept in the order they're returned in, which is
+	// ordered by the group key.
+	var (
+		supplies  = make(map[string]*big.Int)
+		groupKeys [][]byte
+	)
+	for _, dbAmount := range dbAmounts {
+		amount, err := parseAssetAmount(
+			dbAmount.Amount, dbAmount.AmountBig,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		supply, ok := supplies[string(dbAmount.TweakedGroupKey)]
+		if !ok {
+			supply = new(big.Int)
+			supplies[string(dbAmount.TweakedGroupKey)] = supply
+			groupKeys = append(groupKeys, dbAmount.TweakedGroupKey)
+		}
+		supply.Add(supply, new(big.Int).SetUint64(amount))
+	}
+
+	var (
+		groups   []AssetGroupBalance
+		minTotal = new(big.Int).SetUint64(minSupply)
+		maxTotal = new(big.Int).SetUint64(maxSupply)
+	)
+	for _, groupKey := range groupKeys {
+		supply := supplies[string(groupKey)]
+		if supply.Cmp(minTotal) < 0 || supply.Cmp(maxTotal) > 0 {
+			continue
+		}
+
+		groupPubKey, err := btcec.ParsePubKey(groupKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse group key: %w",
+				err)
+		}
+
+		groups = append(groups, AssetGroupBalance{
+			GroupKey: groupPubKey,
+			Balance:  supply.Uint64(),
+		})
+	}
+
+	return groups, nil
+}
+
+// FetchAllGroupKeysReconstructed fetches the fully populated group key of
+// every asset group, including the raw key the group key was derived from,
+// and the sig of the first genesis asset created with the group. The group
+// keys are returned in the order they were created.
+func (a *AssetStore) FetchAllGroupKeysReconstructed(
+	ctx context.Context) ([]*asset.GroupKey, error) {
+
+	var groupKeys []*asset.GroupKey
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbGroupKeys, err := q.FetchAllGroupKeys(ctx)
+		if err != nil {
+			return err
+		}
+
+		groupKeys = make([]*asset.GroupKey, len(dbGroupKeys))
+		for i, dbGroupKey := range dbGroupKeys {
+			groupKeys[i], err = parseGroupKey(dbGroupKey)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch group keys: %w", dbErr)
+	}
+
+	return groupKeys, nil
+}
+
+// parseGroupKey converts a group key read from the database into an
+// asset.GroupKey.
+func parseGroupKey(dbGroupKey StoredGroupKey) (*asset.GroupKey, error) {
+	tweakedGroupKey, err := btcec.ParsePubKey(dbGroupKey.TweakedGroupKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse group key: %w", err)
+	}
+	rawGroupKey, err := parseGroupRawKey(
+		dbGroupKey.RawKey, dbGroupKey.KeyFamily, dbGroupKey.KeyIndex,
+	)
+	if err != nil {
+		return nil, err
+	}
+	groupSig, err := schnorr.ParseSignature(dbGroupKey.GenesisSig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse group sig: %w", err)
+	}
+
+	return &asset.GroupKey{
+		RawKey:      rawGroupKey.KeyDesc,
+		GroupPubKey: *tweakedGroupKey,
+		Sig:         *groupSig,
+	}, nil
+}
+
+// GroupRawKey is the raw key a group key was derived from.
+type GroupRawKey struct {
+	// KeyDesc is the key descriptor of the raw key. The key locator is
+	// only set if the key is owned by the local node.
+	KeyDesc keychain.KeyDescriptor
+
+	// External is true if the raw key isn't owned by the local node, as
+	// the group key was imported from a remote proof. In that case, the
+	// raw key is just the tweaked group key itself, and the node can't
+	// produce issuance signatures for the group.
+	External bool
+}
+
+// parseGroupRawKey converts the internal key backing a group key into the
+// raw key of the group. Group keys imported from remote proofs are stored
+// without a key locator, which is how we tell them apart from our own keys.
+func parseGroupRawKey(rawKey []byte, keyFamily,
+	keyIndex int32) (GroupRawKey, error) {
+
+	pubKey, err := btcec.ParsePubKey(rawKey)
+	if err != nil {
+		return GroupRawKey{}, fmt.Errorf("unable to parse raw group "+
+			"key: %w", err)
+	}
+
+	if keyFamily == 0 && keyIndex == 0 {
+		return GroupRawKey{
+			KeyDesc: keychain.KeyDescriptor{
+				PubKey: pubKey,
+			},
+			External: true,
+		}, nil
+	}
+
+	return GroupRawKey{
+		KeyDesc: keychain.KeyDescriptor{
+			PubKey: pubKey,
+			KeyLocator: keychain.KeyLocator{
+				Family: keychain.KeyFamily(keyFamily),
+				Index:  uint32(keyIndex),
+			},
+		},
+	}, nil
+}
+
+// ErrGroupKeyNotFound is returned when a group key can't be found in the
+// database.
+var ErrGroupKeyNotFound = errors.New("group key not found")
+
+// FetchGroupRawKey returns the raw key the group key with the given tweaked
+// key was derived from, so the signer knows up front whether it's able to
+// produce an issuance signature for the group. ErrGroupKeyNotFound is returned
+// if the group key doesn't exist.
+func (a *AssetStore) FetchGroupRawKey(ctx context.Context,
+	tweakedGroupKey []byte) (*GroupRawKey, error) {
+
+	var dbKey StoredGroupRawKey
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbKey, err = q.FetchGroupRawKey(ctx, tweakedGroupKey)
+		return err
+	})
+	switch {
+	case errors.Is(dbErr, sql.ErrNoRows):
+		return nil, ErrGroupKeyNotFound
+
+	case dbErr != nil:
+		return nil, fmt.Errorf("unable to fetch group raw key: %w",
+			dbErr)
+	}
+
+	rawKey, err := parseGroupRawKey(
+		dbKey.RawKey, dbKey.KeyFamily, dbKey.KeyIndex,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rawKey, nil
+}
+
+// FetchGroupAssetsScriptKeyKinds returns the number of unspent assets within
+// the group identified by the passed tweaked group key that have a script key
+// with a tweak (script path spendable), and the number of assets that don't.
+func (a *AssetStore) FetchGroupAssetsScriptKeyKinds(ctx context.Context,
+	tweakedGroupKey []byte) (int, int, error) {
+
+	var (
+		withTweak, withoutTweak int
+		readOpts                = NewAssetStoreReadTx()
+	)
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		kinds, err := q.FetchGroupAssetsScriptKeyKinds(
+			ctx, tweakedGroupKey,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to query script key kinds: "+
+				"%w", err)
+		}
+
+		withTweak = int(kinds.WithTweak)
+		withoutTweak = int(kinds.WithoutTweak)
+
+		return nil
+	})
+	if dbErr != nil {
+		return 0, 0, dbErr
+	}
+
+	return withTweak, withoutTweak, nil
+}
+
+// FetchGroupAssetsByType fetches all assets within the asset group identified
+// by the given tweaked group key that are of the given asset type.
+func (a *AssetStore) FetchGroupAssetsByType(ctx context.Context,
+	tweakedGroupKey []byte, t asset.Type) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		KeyGroupFilter:  tweakedGroupKey,
+		AssetTypeFilter: sqlInt16(t),
+	})
+}
+
+// SplitAmountMismatch describes a set of split assets that all commit to the
+// same split commitment root, but whose amounts don't add up to the value
+// committed to by that root.
+type SplitAmountMismatch struct {
+	// RootHash is the node hash of the split commitment root.
+	RootHash mssmt.NodeHash
+
+	// RootValue is the sum committed to by the split commitment root,
+	// which is the amount of the asset input that was split.
+	RootValue uint64
+
+	// TotalAmount is the sum of the amounts of all split assets below,
+	// capped at the maximum uint64 value.
+	TotalAmount uint64
+
+	// Assets is the set of split assets (including the root asset) that
+	// commit to the split commitment root.
+	Assets []*ChainAsset
+}
+
+// splitOutputs tracks the outputs of a split that are known locally, along
+// with the inclusion proofs of the split assets among them.
+type splitOutputs struct {
+	SplitAmountMismatch
+
+	// keys are the keys of the leaves of the known outputs within the
+	// split commitment tree.
+	keys [][32]byte
+
+	// proofs are the inclusion proofs of the known split assets, keyed
+	// by the index of their leaf key within keys.
+	proofs map[int]*mssmt.Proof
+
+	// unknownKey is set if the leaf key of any known output can't be
+	// derived, in which case the set of known outputs can't be checked.
+	unknownKey bool
+
+	// overflow is set if the total amount doesn't fit into a uint64.
+	overflow bool
+}
+
+// splitLeafKey returns the key of the leaf of the given split asset within its
+// split commitment tree, which commits to the index of the output the asset
+// was anchored in. False is returned if the asset isn't anchored.
+func splitLeafKey(chainAsset *ChainAsset) ([32]byte, bool) {
+	if chainAsset.AnchorOutpoint == (wire.OutPoint{}) {
+		return [32]byte{}, false
+	}
+
+	locator := commitment.SplitLocator{
+		OutputIndex: chainAsset.AnchorOutpoint.Index,
+		AssetID:     chainAsset.ID(),
+		ScriptKey:   asset.ToSerialized(chainAsset.ScriptKey.PubKey),
+	}
+
+	return locator.Hash(), true
+}
+
+// splitKeyBit returns the bit of the given leaf key that decides in which
+// subtree of the node at the given height (counted from the root) the leaf is
+// located.
+func splitKeyBit(key [32]byte, height int) byte {
+	return (key[height/8] >> (height % 8)) & 1
+}
+
+// complete returns whether all outputs of the split are known. As the tree is
+// sparse, this is the case if each non-empty sibling along the inclusion
+// proofs of the known split assets is the root of a subtree that contains the
+// leaf of another known output.
+func (s *splitOutputs) complete() bool {
+	if s.unknownKey || len(s.proofs) == 0 {
+		return false
+	}
+
+	// sharesSubtree returns whether the given key is located in the
+	// subtree of the sibling at the given height of the passed leaf key,
+	// which shares all bits above that height but the last one.
+	sharesSubtree := func(key, leafKey [32]byte, height int) bool {
+		for i := 0; i < height; i++ {
+			if splitKeyBit(key, i) != splitKeyBit(leafKey, i) {
+				return false
+			}
+		}
+
+		return splitKeyBit(key, height) != splitKeyBit(leafKey, height)
+	}
+
+	for keyIdx, proof := range s.proofs {
+		if len(proof.Nodes) != mssmt.MaxTreeLevels {
+			return false
+		}
+
+		// The proof nodes start at the leaf, while the empty tree
+		// starts at the root.
+		leafKey := s.keys[keyIdx]
+		for i, node := range proof.Nodes {
+			emptyNode := mssmt.EmptyTree[mssmt.MaxTreeLevels-i]
+			if node.NodeHash() == emptyNode.NodeHash() {
+				continue
+			}
+
+			height := mssmt.MaxTreeLevels - 1 - i
+			covered := false
+			for _, key := range s.keys {
+				if sharesSubtree(key, leafKey, height) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// FetchSplitAmountMismatches returns all sets of split assets whose amounts
+// don't add up to the amount of the asset input that was split, as committed
+// to by their split commitment root. Spent split assets are taken into account
+// as well, while a split is only reported once all of its outputs are known
+// locally, as the amounts of the others can't be known.
+func (a *AssetStore) FetchSplitAmountMismatches(
+	ctx context.Context) ([]SplitAmountMismatch, error) {
+
+	chainAssets, err := a.fetchChainAssets(ctx, QueryAssetFilters{
+		IncludeSpent: sqlBool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// We'll group all split assets by the split commitment root they
+	// commit to. The root asset carries the root itself, while all other
+	// splits carry it in the root asset of their split commitment witness,
+	// along with their inclusion proof.
+	splits := make(map[mssmt.NodeHash]*splitOutputs)
+	addSplit := func(root mssmt.Node, chainAsset *ChainAsset,
+		proof *mssmt.Proof) {
+
+		rootHash := root.NodeHash()
+		split, ok := splits[rootHash]
+		if !ok {
+			split = &splitOutputs{
+				SplitAmountMismatch: SplitAmountMismatch{
+					RootHash:  rootHash,
+					RootValue: root.NodeSum(),
+				},
+				proofs: make(map[int]*mssmt.Proof),
+			}
+			splits[rootHash] = split
+		}
+
+		total := split.TotalAmount + chainAsset.Amount
+		if total < split.TotalAmount {
+			split.overflow = true
+			total = math.MaxUint64
+		}
+		split.TotalAmount = total
+		split.Assets = append(split.Assets, chainAsset)
+
+		key, ok := splitLeafKey(chainAsset)
+		if !ok {
+			split.unknownKey = true
+			return
+		}
+		if proof != nil {
+			split.proofs[len(split.keys)] = proof
+		}
+		split.keys = append(split.keys, key)
+	}
+	for _, chainAsset := range chainAssets {
+		if chainAsset.SplitCommitmentRoot != nil {
+			addSplit(
+				chainAsset.SplitCommitmentRoot, chainAsset,
+				nil,
+			)
+			continue
+		}
+
+		for _, witness := range chainAsset.PrevWitnesses {
+			splitCommitment := witness.SplitCommitment
+			if splitCommitment == nil ||
+				splitCommitment.RootAsset.SplitCommitmentRoot == nil {
+
+				continue
+			}
+
+			addSplit(
+				splitCommitment.RootAsset.SplitCommitmentRoot,
+				chainAsset, &splitCommitment.Proof,
+			)
+			break
+		}
+	}
+
+	var mismatches []SplitAmountMismatch
+	for _, split := range splits {
+		if !split.overflow && split.TotalAmount == split.RootValue {
+			continue
+		}
+		if !split.complete() {
+			continue
+		}
+
+		mismatches = append(mismatches, split.SplitAmountMismatch)
+	}
+
+	// Return the mismatches in a stable order.
+	sort.Slice(mismatches, func(i, j int) bool {
+		return bytes.Compare(
+			mismatches[i].RootHash[:], mismatches[j].RootHash[:],
+		) < 0
+	})
+
+	return mismatches, nil
+}
+
+// MetaHashCollision describes a set of genesis assets that share the same
+// metadata hash, but the metadata revealed for the hash doesn't derive the
+// asset ID of all of them. As the hash is derived from the metadata, this
+// should never happen unless the stored hash of one of them was corrupted.
+type MetaHashCollision struct {
+	// MetaHash is the metadata hash shared by the genesis assets.
+	MetaHash [sha256.Size]byte
+
+	// GenAssetIDs are the primary keys of all genesis assets with the
+	// metadata hash.
+	GenAssetIDs []int32
+}
+
+// FetchMetaHashCollisions returns all sets of genesis assets that share the
+// same metadata hash while the metadata revealed for it doesn't derive the
+// stored asset ID of each of them. Genesis assets that were stored before
+// their metadata hash was tracked aren't taken into account.
+func (a *AssetStore) FetchMetaHashCollisions(
+	ctx context.Context) ([]MetaHashCollision, error) {
+
+	var dbGeneses []SharedMetaHashGenesis
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbGeneses, err = q.FetchGenesesWithSharedMetaHash(ctx)
+		return err
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch geneses: %w", dbErr)
+	}
+
+	// The genesis assets are ordered by their metadata hash, so we can
+	// check each group of genesis assets sharing a hash in a single pass.
+	var collisions []MetaHashCollision
+	for start := 0; start < len(dbGeneses); {
+		metaHash := dbGeneses[start].MetaHash
+
+		end := start + 1
+		for end < len(dbGeneses) &&
+			bytes.Equal(dbGeneses[end].MetaHash, metaHash) {
+
+			end++
+		}
+		group := dbGeneses[start:end]
+		start = end
+
+		// All genesis assets of the group share the same reveal, so
+		// it's enough for any of them to not derive its stored asset ID
+		// from it.
+		isCollision := false
+		for _, dbGenesis := range group {
+			genesis, err := parseGenesis(Genesis{
+				AssetTag:    dbGenesis.AssetTag,
+				MetaData:    dbGenesis.MetaData,
+				OutputIndex: dbGenesis.OutputIndex,
+				AssetType:   dbGenesis.AssetType,
+				PrevOut:     dbGenesis.PrevOut,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			assetID := genesis.ID()
+			if !bytes.Equal(assetID[:], dbGenesis.AssetID) {
+				isCollision = true
+				break
+			}
+		}
+		if !isCollision {
+			continue
+		}
+
+		genAssetID := func(g SharedMetaHashGenesis) int32 {
+			return g.GenAssetID
+		}
+		collision := MetaHashCollision{
+			GenAssetIDs: fMap(group, genAssetID),
+		}
+		copy(collision.MetaHash[:], metaHash)
+
+		collisions = append(collisions, collision)
+	}
+
+	return collisions, nil
+}
+
+// fetchChainAssets fetches all assets that match the given database filter,
+// along with their witnesses and anchor information.
+func (a *AssetStore) fetchChainAssets(ctx context.Context,
+	assetFilter QueryAssetFilters) ([]*ChainAsset, error) {
+
+	var (
+		dbAssets       []ConfirmedAsset
+		assetWitnesses map[int32][]AssetWitness
+		err            error
+	)
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbAssets, assetWitnesses, err = fetchAssetsWithWitness(
+			ctx, q, assetFilter,
+		)
+
+		return err
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return dbAssetsToChainAssets(dbAssets, assetWitnesses)
+}
+
+// FetchAllAssets fetches the set of confirmed assets stored on disk.
+func (a *AssetStore) FetchAllAssets(ctx context.Context,
+	query *AssetQueryFilters) ([]*ChainAsset, error) {
+
+	// We'll now map the application level filtering to the type of
+	// filtering our database query understands.
+	assetFilter := constraintsToDbFilter(query)
+
+	// With the query constructed, we can now fetch the assets along w/
+	// their witness information.
+	return a.fetchChainAssets(ctx, assetFilter)
+}
+
+// ErrAssetNotFound is returned when an asset can't be found in the database.
+var ErrAssetNotFound = errors.New("asset not found")
+
+// ErrInvalidJSONMetadata is returned when the metadata of an asset is set to
+// be of the JSON type, but isn't a valid UTF-8 encoded JSON document.
+var ErrInvalidJSONMetadata = errors.New("asset metadata isn't valid JSON")
+
+// ErrAssetSpent is returned when trying to transfer an asset that was already
+// spent.
+var ErrAssetSpent = errors.New("asset already spent")
+
+// ErrAssetAlreadyAnchored is returned when trying to bind an asset to an
+// anchor UTXO while it's already anchored in a different UTXO.
+type ErrAssetAlreadyAnchored struct {
+	// AssetID is the primary key of the asset.
+	AssetID int32
+
+	// AnchorUtxoID is the primary key of the UTXO the asset is already
+	// anchored in.
+	AnchorUtxoID int32
+}
+
+func (e ErrAssetAlreadyAnchored) Error() string {
+	return fmt.Sprintf("asset %d is already anchored in utxo %d",
+		e.AssetID, e.AnchorUtxoID)
+}
+
+// FetchAsset fetches the asset with the given asset ID and script key, along
+// with the information of where it's anchored on chain.
+func (a *AssetStore) FetchAsset(ctx context.Context, id asset.ID,
+	scriptKey *btcec.PublicKey) (*ChainAsset, error) {
+
+	var (
+		dbAssets       []ConfirmedAsset
+		assetWitnesses map[int32][]AssetWitness
+		err            error
+	)
+
+	assetFilter := QueryAssetFilters{
+		AssetIDFilter: id[:],
+	}
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbAssets, assetWitnesses, err = fetchAssetsWithWitness(
+			ctx, q, assetFilter,
+		)
+
+		return err
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	// There may be several assets with the same asset ID, so we'll only
+	// convert the one with the script key we're looking for.
+	scriptKeyBytes := scriptKey.SerializeCompressed()
+	for _, dbAsset := range dbAssets {
+		if !bytes.Equal(dbAsset.TweakedScriptKey, scriptKeyBytes) {
+			continue
+		}
+
+		chainAssets, err := dbAssetsToChainAssets(
+			[]ConfirmedAsset{dbAsset}, assetWitnesses,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return chainAssets[0], nil
+	}
+
+	return nil, ErrAssetNotFound
+}
+
+// FetchAssetBigAmount returns the exact amount of the unspent asset with the
+// given asset ID and script key. If the exact amount was stored separately
+// because it exceeds the range of the amount column, then that amount is
+// returned.
+func (a *AssetStore) FetchAssetBigAmount(ctx context.Context, id asset.ID,
+	scriptKey *btcec.PublicKey) (*big.Int, error) {
+
+	var (
+		dbAmounts []sqlc.FetchAssetAmountsByScriptKeyRow
+		amtQuery  = AssetAmountQuery{
+			AssetID:          id[:],
+			TweakedScriptKey: scriptKey.SerializeCompressed(),
+		}
+	)
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbAmounts, err = q.FetchAssetAmountsByScriptKey(ctx, amtQuery)
+		return err
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch asset amount: %w",
+			dbErr)
+	}
+	if len(dbAmounts) == 0 {
+		return nil, ErrAssetNotFound
+	}
+
+	amount, err := parseAssetAmount(
+		dbAmounts[0].Amount, dbAmounts[0].AmountBig,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetUint64(amount), nil
+}
+
+// FetchAssetsByScriptKeyKnown fetches the set of assets whose raw script key
+// is known to us, or alternatively not known. Assets with an unknown raw
+// script key were imported with a foreign script key, so we're unable to
+// spend them.
+func (a *AssetStore) FetchAssetsByScriptKeyKnown(ctx context.Context,
+	known bool) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		ScriptKeyKnown: sql.NullBool{
+			Bool:  known,
+			Valid: true,
+		},
+	})
+}
+
+// FetchAssetsWithLockTime fetches the set of assets that have an absolute lock
+// time set.
+func (a *AssetStore) FetchAssetsWithLockTime(
+	ctx context.Context) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		HasLockTime: sql.NullBool{
+			Bool:  true,
+			Valid: true,
+		},
+	})
+}
+
+// FetchGenesisAssetsWithoutMetadata fetches the genesis information of all
+// assets that were created with either no or empty metadata.
+func (a *AssetStore) FetchGenesisAssetsWithoutMetadata(
+	ctx context.Context) ([]asset.Genesis, error) {
+
+	var genesisAssets []asset.Genesis
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbGenesisAssets, err := q.FetchGenesisAssetsWithoutMetadata(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to fetch genesis assets: %w",
+				err)
+		}
+
+		genesisAssets = make([]asset.Genesis, len(dbGenesisAssets))
+		for i, dbGenesis := range dbGenesisAssets {
+			genesisAssets[i], err = parseGenesis(Genesis(dbGenesis))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return genesisAssets, nil
+}
+
+// FetchGenesisByOutpoint fetches the genesis information of the asset that was
+// created from the given genesis point at the given output index. If there's
+// no such asset, ErrGenesisNotFound is returned.
+func (a *AssetStore) FetchGenesisByOutpoint(ctx context.Context,
+	genesisPoint wire.OutPoint, outputIndex uint32) (asset.Genesis, error) {
+
+	var gen asset.Genesis
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		gen, err = fetchGenesisByOutpoint(
+			ctx, q, genesisPoint, outputIndex,
+		)
+		return err
+	})
+	if dbErr != nil {
+		return asset.Genesis{}, dbErr
+	}
+
+	return gen, nil
+}
+
+// NonCanonicalOutpoint is a genesis point that's stored with an encoding that
+// differs from the canonical encoding of its outpoint.
+type NonCanonicalOutpoint struct {
+	// GenesisPointID is the primary key of the genesis point.
+	GenesisPointID int32
+
+	// Stored is the encoding of the genesis point as it's stored.
+	Stored []byte
+
+	// Canonical is the canonical encoding of the outpoint the stored
+	// encoding decodes to. It's nil if the stored encoding can't be
+	// decoded at all.
+	Canonical []byte
+}
+
+// FetchAssetsWithNonCanonicalOutpoints audits all stored genesis points by
+// re-encoding them, and returns the ones with an encoding that differs from
+// the canonical one. Such rows may have been written by an older, buggy
+// encoder, and can't be found by looking up their outpoint.
+func (a *AssetStore) FetchAssetsWithNonCanonicalOutpoints(
+	ctx context.Context) ([]NonCanonicalOutpoint, error) {
+
+	var dbPoints []sqlc.GenesisPoint
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbPoints, err = q.GenesisPoints(ctx)
+		return err
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch genesis points: %w",
+			dbErr)
+	}
+
+	var nonCanonical []NonCanonicalOutpoint
+	for _, dbPoint := range dbPoints {
+		var (
+			genesisPoint wire.OutPoint
+			canonical    []byte
+		)
+		err := readOutPoint(
+			bytes.NewReader(dbPoint.PrevOut), 0, 0, &genesisPoint,
+		)
+		if err == nil {
+			canonical, err = encodeOutpoint(genesisPoint)
+			if err != nil {
+				return nil, fmt.Errorf("unable to encode "+
+					"genesis point: %w", err)
+			}
+		}
+
+		if bytes.Equal(dbPoint.PrevOut, canonical) {
+			continue
+		}
+
+		nonCanonical = append(nonCanonical, NonCanonicalOutpoint{
+			GenesisPointID: dbPoint.GenesisID,
+			Stored:         dbPoint.PrevOut,
+			Canonical:      canonical,
+		})
+	}
+
+	sort.Slice(nonCanonical, func(i, j int) bool {
+		return nonCanonical[i].GenesisPointID <
+			nonCanonical[j].GenesisPointID
+	})
+
+	return nonCanonical, nil
+}
+
+// FetchDuplicateOutputIndices fetches the genesis information of all assets
+// of the given genesis point that share their output index with another asset
+// of the same genesis point. As each asset of a genesis point is expected to
+// be minted into its own output, any such asset likely points to a bug. The
+// assets are returned ordered by their output index.
+func (a *AssetStore) FetchDuplicateOutputIndices(ctx context.Context,
+	genesisPointID int32) ([]asset.Genesis, error) {
+
+	var genesisAssets []asset.Genesis
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbGenesisAssets, err := q.FetchDuplicateOutputIndices(
+			ctx, genesisPointID,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to fetch genesis assets: %w",
+				err)
+		}
+
+		genesisAssets = make([]asset.Genesis, len(dbGenesisAssets))
+		for i, dbGenesis := range dbGenesisAssets {
+			genesisAssets[i], err = parseGenesis(Genesis(dbGenesis))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return genesisAssets, nil
+}
+
+// StaleUnanchoredAsset is an asset that was never anchored in an on-chain
+// output, even though its genesis point was stored a while ago. This is
+// usually the result of a mint that never confirmed.
+type StaleUnanchoredAsset struct {
+	// AssetPrimaryKey is the primary key of the asset.
+	AssetPrimaryKey int32
+
+	// Genesis is the genesis of the asset.
+	Genesis asset.Genesis
+
+	// Amount is the amount of the asset.
+	Amount uint64
+
+	// CreatedAt is the time the genesis point of the asset was first
+	// stored.
+	CreatedAt time.Time
+}
+
+// FetchStaleUnanchoredAssets returns all assets that aren't anchored in an
+// on-chain output, even though their genesis point was first stored before
+// the given time. This can be used to clean up mints that never confirmed.
+// The assets are returned ordered by the time their genesis point was stored.
+func (a *AssetStore) FetchStaleUnanchoredAssets(ctx context.Context,
+	olderThan time.Time) ([]StaleUnanchoredAsset, error) {
+
+	var staleAssets []StaleUnanchoredAsset
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbAssets, err := q.FetchStaleUnanchoredAssets(
+			ctx, sql.NullTime{
+				Time:  olderThan.UTC(),
+				Valid: true,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to fetch stale assets: %w",
+				err)
+		}
+
+		staleAssets = make([]StaleUnanchoredAsset, len(dbAssets))
+		for i, dbAsset := range dbAssets {
+			genesis, err := parseGenesis(Genesis{
+				AssetID:     dbAsset.AssetID,
+				AssetTag:    dbAsset.AssetTag,
+				MetaData:    dbAsset.MetaData,
+				OutputIndex: dbAsset.OutputIndex,
+				AssetType:   dbAsset.AssetType,
+				PrevOut:     dbAsset.PrevOut,
+			})
+			if err != nil {
+				return err
+			}
+
+			staleAssets[i] = StaleUnanchoredAsset{
+				AssetPrimaryKey: dbAsset.AssetPrimaryKey,
+				Genesis:         genesis,
+				Amount:          uint64(dbAsset.Amount),
+				CreatedAt:       dbAsset.CreatedAt.Time,
+			}
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return staleAssets, nil
+}
+
+// MissingScriptKeyAsset is an asset that references a script key that doesn't
+// exist. This can only happen if the database was corrupted on a backend that
+// doesn't enforce foreign keys.
+type MissingScriptKeyAsset struct {
+	// AssetPrimaryKey is the primary key of the asset.
+	AssetPrimaryKey int32
+
+	// ScriptKeyID is the primary key of the missing script key the asset
+	// references.
+	ScriptKeyID int32
+
+	// Genesis is the genesis of the asset.
+	Genesis asset.Genesis
+
+	// Amount is the amount of the asset.
+	Amount uint64
+}
+
+// FetchAssetsWithNullScriptKey returns all assets that reference a script key
+// that doesn't exist. As every asset must reference a script key, any asset
+// returned is a sign of database corruption. The assets can't be fully
+// reconstructed without their script key, so only their genesis and amount
+// are returned.
+func (a *AssetStore) FetchAssetsWithNullScriptKey(
+	ctx context.Context) ([]MissingScriptKeyAsset, error) {
+
+	var corruptAssets []MissingScriptKeyAsset
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbAssets, err := q.FetchAssetsWithNullScriptKey(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to fetch assets with null "+
+				"script key: %w", err)
+		}
+
+		corruptAssets = make([]MissingScriptKeyAsset, len(dbAssets))
+		for i, dbAsset := range dbAssets {
+			genesis, err := parseGenesis(Genesis{
+				AssetID:     dbAsset.AssetID,
+				AssetTag:    dbAsset.AssetTag,
+				MetaData:    dbAsset.MetaData,
+				OutputIndex: dbAsset.OutputIndex,
+				AssetType:   dbAsset.AssetType,
+				PrevOut:     dbAsset.PrevOut,
+			})
+			if err != nil {
+				return err
+			}
+
+			corruptAssets[i] = MissingScriptKeyAsset{
+				AssetPrimaryKey: dbAsset.AssetPrimaryKey,
+				ScriptKeyID:     dbAsset.ScriptKeyID,
+				Genesis:         genesis,
+				Amount:          uint64(dbAsset.Amount),
+			}
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return corruptAssets, nil
+}
+
+// FetchAssetsByConfirmationStatus fetches the set of unspent assets whose
+// anchor transaction is confirmed, or alternatively not confirmed yet. Assets
+// that aren't anchored at all are considered to be unconfirmed.
+func (a *AssetStore) FetchAssetsByConfirmationStatus(ctx context.Context,
+	confirmed bool) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		IncludeUnanchored: sqlBool(true),
+		Confirmed:         sqlBool(confirmed),
+	})
+}
+
+// FetchAssetsByScriptVersion fetches a page of the unspent assets that use
+// the given script version, regardless of whether they're anchored yet. The
+// assets are returned in a stable order, so all assets of a version can be
+// paged through by advancing the offset by the limit.
+func (a *AssetStore) FetchAssetsByScriptVersion(ctx context.Context,
+	v int32, limit, offset int32) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		IncludeUnanchored:   sqlBool(true),
+		ScriptVersionFilter: sqlInt32(v),
+		NumLimit:            sqlInt32(limit),
+		NumOffset:           sqlInt32(offset),
+	})
+}
+
+// FetchAssetsByScriptVersionAndType fetches all unspent assets, anchored or
+// not, that use the given script version and are of the given asset type.
+func (a *AssetStore) FetchAssetsByScriptVersionAndType(ctx context.Context,
+	v int32, t asset.Type) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		AssetTypeFilter:     sqlInt16(t),
+		IncludeUnanchored:   sqlBool(true),
+		ScriptVersionFilter: sqlInt32(v),
+	})
+}
+
+// FetchAssetsMissingGroupSig fetches all assets of the group with the given
+// tweaked group key that were stored without the group sig of their genesis
+// asset, such as assets imported from proofs that lack the sig. This allows
+// the sigs to be backfilled once they're known. As these assets aren't linked
+// to their group yet, they're found through the genesis point the group key
+// was stored with, and are returned without a group key. Spent assets are
+// returned as well, as the history of the group also needs to be linked to
+// it once the sig is backfilled.
+func (a *AssetStore) FetchAssetsMissingGroupSig(ctx context.Context,
+	tweakedGroupKey []byte) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		IncludeSpent:       sqlBool(true),
+		IncludeUnanchored:  sqlBool(true),
+		MissingSigGroupKey: tweakedGroupKey,
+	})
+}
+
+// FetchAssetsByMetadataLength fetches all unspent assets, anchored or not,
+// whose genesis metadata is between minLen and maxLen bytes long, inclusive.
+// The assets with the largest metadata are returned first, which makes this
+// useful to find the assets taking up the most space on disk.
+func (a *AssetStore) FetchAssetsByMetadataLength(ctx context.Context,
+	minLen, maxLen int) ([]*ChainAsset, error) {
+
+	chainAssets, err := a.fetchChainAssets(ctx, QueryAssetFilters{
+		IncludeUnanchored: sqlBool(true),
+		MinMetaLength:     sqlInt64(minLen),
+		MaxMetaLength:     sqlInt64(maxLen),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The assets are returned in the order they were stored, so a stable
+	// sort keeps that order for assets with metadata of the same length.
+	sort.SliceStable(chainAssets, func(i, j int) bool {
+		return len(chainAssets[i].Genesis.Metadata) >
+			len(chainAssets[j].Genesis.Metadata)
+	})
+
+	return chainAssets, nil
+}
+
+// ListAssetsQuery is used to fetch a single page of all assets.
+type ListAssetsQuery struct {
+	// Limit is the maximum number of assets to return.
+	Limit int32
+
+	// Cursor is the opaque cursor returned by the previous page. If empty,
+	// the first page is returned.
+	Cursor []byte
+}
+
+// ListAssetsResult is a single page of all assets.
+type ListAssetsResult struct {
+	// Assets are the assets of the page.
+	Assets []*ChainAsset
+
+	// NextCursor is the cursor to fetch the next page with. If empty,
+	// this is the last page.
+	NextCursor []byte
+}
+
+// encodeAssetCursor encodes the primary key of the last asset of a page as an
+// opaque cursor.
+func encodeAssetCursor(assetPrimaryKey int32) []byte {
+	var cursor [4]byte
+	binary.BigEndian.PutUint32(cursor[:], uint32(assetPrimaryKey))
+
+	return cursor[:]
+}
+
+// decodeAssetCursor decodes the primary key of the last asset of a page from
+// an opaque cursor. An empty cursor decodes to a key before all assets.
+func decodeAssetCursor(cursor []byte) (int32, error) {
+	switch len(cursor) {
+	case 0:
+		return 0, nil
+
+	case 4:
+		return int32(binary.BigEndian.Uint32(cursor)), nil
+
+	default:
+		return 0, fmt.Errorf("invalid cursor length: %d", len(cursor))
+	}
+}
+
+// ListAssets fetches a single page of all assets, regardless of whether
+// they're anchored or spent. The assets are ordered by their primary key
+// rather than by any of their fields such as the amount, so the order is
+// deterministic and the cursor remains stable under concurrent inserts.
+func (a *AssetStore) ListAssets(ctx context.Context,
+	query ListAssetsQuery) (ListAssetsResult, error) {
+
+	var result ListAssetsResult
+
+	if query.Limit <= 0 {
+		return result, fmt.Errorf("invalid limit: %d", query.Limit)
+	}
+
+	// We'll fetch one more asset than requested, so we know whether
+	// there's another page after this one. The limit is clamped so that
+	// doesn't overflow, which would result in a negative LIMIT.
+	limit := query.Limit
+	if limit > math.MaxInt32-1 {
+		limit = math.MaxInt32 - 1
+	}
+
+	afterAssetID, err := decodeAssetCursor(query.Cursor)
+	if err != nil {
+		return result, err
+	}
+
+	assetFilter := QueryAssetFilters{
+		IncludeSpent:      sqlBool(true),
+		IncludeUnanchored: sqlBool(true),
+		AfterAssetID:      sqlInt32(afterAssetID),
+		NumLimit:          sqlInt32(limit + 1),
+	}
+
+	var (
+		dbAssets       []ConfirmedAsset
+		assetWitnesses map[int32][]AssetWitness
+	)
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbAssets, assetWitnesses, err = fetchAssetsWithWitness(
+			ctx, q, assetFilter,
+		)
+
+		return err
+	})
+	if dbErr != nil {
+		return result, dbErr
+	}
+
+	hasNextPage := len(dbAssets) > int(limit)
+	if hasNextPage {
+		dbAssets = dbAssets[:limit]
+	}
+
+	result.Assets, err = dbAssetsToChainAssets(dbAssets, assetWitnesses)
+	if err != nil {
+		return result, err
+	}
+
+	if hasNextPage {
+		lastAsset := dbAssets[len(dbAssets)-1]
+		result.NextCursor = encodeAssetCursor(lastAsset.AssetPrimaryKey)
+	}
+
+	return result, nil
+}
+
+// FetchAssetsOrderedByAmount fetches up to limit unspent anchored assets
+// ordered by their amount, either starting with the largest or the smallest
+// amount. Assets with the same amount are returned in the order they were
+// stored.
+func (a *AssetStore) FetchAssetsOrderedByAmount(ctx context.Context,
+	descending bool, limit int32) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		AmountDescending: sqlBool(descending),
+		NumLimit:         sqlInt32(limit),
+	})
+}
+
+// FetchAssetsByTag returns all unspent anchored assets with the given tag. If
+// caseInsensitive is true, then the tags are compared case-insensitively.
+//
+// NOTE: Only ASCII characters are compared case-insensitively on sqlite.
+func (a *AssetStore) FetchAssetsByTag(ctx context.Context, tag string,
+	caseInsensitive bool) ([]*ChainAsset, error) {
+
+	var assetFilter QueryAssetFilters
+
+	tagFilter := sql.NullString{
+		String: tag,
+		Valid:  true,
+	}
+	if caseInsensitive {
+		assetFilter.AssetTagLowerFilter = tagFilter
+	} else {
+		assetFilter.AssetTagFilter = tagFilter
+	}
+
+	return a.fetchChainAssets(ctx, assetFilter)
+}
+
+// SetAssetMetaType sets the type of the metadata of the asset with the given
+// ID. ErrAssetNotFound is returned if there's no genesis for the asset ID.
+func (a *AssetStore) SetAssetMetaType(ctx context.Context, id asset.ID,
+	metaType MetaType) error {
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		// JSON metadata is decoded by the database when assets are
+		// queried by one of its fields, so it needs to be valid.
+		if metaType == MetaJSON {
+			metadata, err := q.FetchGenesisMetadataByAssetID(
+				ctx, id[:],
+			)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrAssetNotFound
+
+			case err != nil:
+				return fmt.Errorf("unable to fetch "+
+					"metadata: %w", err)
+			}
+
+			if metadata != nil && (!utf8.Valid(metadata) ||
+				!json.Valid(metadata)) {
+
+				return ErrInvalidJSONMetadata
+			}
+		}
+
+		genesisMetaType := GenesisMetaType{
+			MetaType: int16(metaType),
+			AssetID:  id[:],
+		}
+		numUpdated, err := q.SetGenesisAssetMetaType(
+			ctx, genesisMetaType,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to set meta type: %w", err)
+		}
+		if numUpdated == 0 {
+			return ErrAssetNotFound
+		}
+
+		return a.upsertOpts.auditEntry(
+			ctx, q, UpsertOpGenesisMetaType, genesisMetaType,
+			sql.NullInt32{},
+		)
+	})
+}
+
+// ErrMetaRevealMismatch is returned when a metadata reveal doesn't match the
+// metadata hash it's stored for.
+var ErrMetaRevealMismatch = errors.New("metadata reveal doesn't match hash")
+
+// UpsertGenesisMetaReveal stores the full metadata of the genesis assets with
+// the given metadata hash in a separate table, from where it's joined in
+// whenever the genesis assets are read, as they only reference their metadata
+// by its hash. ErrMetaRevealMismatch is returned if the reveal doesn't hash to
+// the given metadata hash.
+func (a *AssetStore) UpsertGenesisMetaReveal(ctx context.Context,
+	metaHash [32]byte, reveal []byte) error {
+
+	if sha256.Sum256(reveal) != metaHash {
+		return fmt.Errorf("%w: %x", ErrMetaRevealMismatch, metaHash[:])
+	}
+	if err := checkMetadataSize(a.upsertOpts, reveal); err != nil {
+		return err
+	}
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		uq := a.upsertOpts.auditStore(q)
+		err := uq.UpsertGenesisMetaReveal(ctx, GenesisMetaReveal{
+			MetaHash: metaHash[:],
+			MetaData: reveal,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to upsert metadata reveal: "+
+				"%w", err)
+		}
+
+		return nil
+	})
+}
+
+// moveGenesisMetadata moves the metadata of all genesis assets that still
+// carry it inline over to the reveals, keyed by the hash of the metadata,
+// which is all the genesis assets reference afterwards. Genesis assets that
+// were stored before the hash was tracked get their hash stored along the way.
+// The metadata of a genesis asset whose stored hash doesn't match its metadata
+// is left in place, as it can't be revealed under that hash.
+func moveGenesisMetadata(ctx context.Context, q sqlc.Querier) error {
+	genesisAssets, err := q.FetchGenesisAssetsWithInlineMetadata(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to fetch genesis assets: %w", err)
+	}
+
+	var numMoved int
+	for _, genesisAsset := range genesisAssets {
+		metaHash := sha256.Sum256(genesisAsset.MetaData)
+		if genesisAsset.MetaHash != nil &&
+			!bytes.Equal(genesisAsset.MetaHash, metaHash[:]) {
+
+			log.Warnf("Metadata of genesis asset %d doesn't match "+
+				"its hash %x, not moving it",
+				genesisAsset.GenAssetID, genesisAsset.MetaHash)
+			continue
+		}
+
+		if len(genesisAsset.MetaData) > 0 {
+			err := q.UpsertGenesisMetaReveal(ctx, GenesisMetaReveal{
+				MetaHash: metaHash[:],
+				MetaData: genesisAsset.MetaData,
+			})
+			if err != nil {
+				return fmt.Errorf("unable to upsert metadata "+
+					"reveal: %w", err)
+			}
+		}
+
+		moveParams := sqlc.MoveGenesisMetadataParams{
+			MetaHash:   metaHash[:],
+			GenAssetID: genesisAsset.GenAssetID,
+		}
+		if err := q.MoveGenesisMetadata(ctx, moveParams); err != nil {
+			return fmt.Errorf("unable to move metadata: %w", err)
+		}
+
+		numMoved++
+	}
+
+	if numMoved > 0 {
+		log.Infof("Moved the metadata of %d genesis assets to the "+
+			"reveals", numMoved)
+	}
+
+	return nil
+}
+
+// FetchAssetsByMetadataType returns all unspent anchored assets whose
+// metadata is of the given type.
+func (a *AssetStore) FetchAssetsByMetadataType(ctx context.Context,
+	metaType MetaType) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		MetaTypeFilter: sqlInt16(metaType),
+	})
+}
+
+// FetchAssetsByMetadataJSONField returns all unspent anchored assets with JSON
+// metadata that has a field at the given path with the given value. The path
+// is a dot separated list of object keys and array indices, e.g. "a.b.0".
+// Values that aren't strings are matched against their JSON encoding, so the
+// number 42 is matched by the value "42".
+//
+// NOTE: On Postgres, the metadata is matched by the database. SQLite lacks
+// the JSON functions to do so, so the metadata is decoded and matched in Go
+// there instead.
+func (a *AssetStore) FetchAssetsByMetadataJSONField(ctx context.Context,
+	path, value string) ([]*ChainAsset, error) {
+
+	if path == "" {
+		return nil, fmt.Errorf("empty JSON path")
+	}
+
+	var (
+		jsonAssets []*ChainAsset
+		matchInGo  bool
+	)
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		jsonAssets = nil
+
+		if q.Backend() != sqlc.BackendTypePostgres {
+			matchInGo = true
+
+			var err error
+			jsonAssets, err = queryChainAssets(
+				ctx, q, QueryAssetFilters{
+					MetaTypeFilter: sqlInt16(MetaJSON),
+				},
+			)
+			return err
+		}
+
+		assetIDs, err := q.FetchAssetIDsByMetadataJSONField(
+			ctx, MetadataJSONFieldQuery{
+				MetaType:  int16(MetaJSON),
+				JsonPath:  path,
+				JsonValue: value,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to fetch asset IDs: %w", err)
+		}
+
+		for _, assetID := range assetIDs {
+			chainAssets, err := queryChainAssets(
+				ctx, q, QueryAssetFilters{
+					AssetIDFilter: assetID,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			jsonAssets = append(jsonAssets, chainAssets...)
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	if !matchInGo {
+		return jsonAssets, nil
+	}
+
+	pathKeys := strings.Split(path, ".")
+
+	var matches []*ChainAsset
+	for _, jsonAsset := range jsonAssets {
+		fieldValue, ok := jsonFieldValue(
+			jsonAsset.Genesis.Metadata, pathKeys,
+		)
+		if ok && fieldValue == value {
+			matches = append(matches, jsonAsset)
+		}
+	}
+
+	return matches, nil
+}
+
+// jsonFieldValue decodes the given JSON document and returns the value of the
+// field at the given path. String values are returned as is, all other values
+// are returned in their JSON encoding. False is returned if the document
+// isn't valid JSON or doesn't have a field at the path.
+func jsonFieldValue(doc []byte, pathKeys []string) (string, bool) {
+	var field interface{}
+	if err := json.Unmarshal(doc, &field); err != nil {
+		return "", false
+	}
+
+	for _, key := range pathKeys {
+		switch node := field.(type) {
+		case map[string]interface{}:
+			var ok bool
+			field, ok = node[key]
+			if !ok {
+				return "", false
+			}
+
+		case []interface{}:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return "", false
+			}
+			field = node[idx]
+
+		default:
+			return "", false
+		}
+	}
+
+	if str, ok := field.(string); ok {
+		return str, true
+	}
+
+	fieldBytes, err := json.Marshal(field)
+	if err != nil {
+		return "", false
+	}
+
+	return string(fieldBytes), true
+}
+
+// FetchAssetsByTapscriptRoot fetches all unspent assets with a script key
+// that commits to the given tapscript root. As the script key of such a
+// script-path asset is tweaked with the tapscript root, this is the tweak
+// stored along with the script key.
+func (a *AssetStore) FetchAssetsByTapscriptRoot(ctx context.Context,
+	root []byte) ([]*ChainAsset, error) {
+
+	if len(root) != chainhash.HashSize {
+		return nil, fmt.Errorf("invalid tapscript root length: %d",
+			len(root))
+	}
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		ScriptKeyTweakFilter: root,
+	})
+}
+
+// FetchGroupAssetsByAmountRange fetches all unspent assets of the asset group
+// with the given tweaked group key, with an amount within the given
+// (inclusive) range. The assets are returned ordered by their amount.
+func (a *AssetStore) FetchGroupAssetsByAmountRange(ctx context.Context,
+	tweakedGroupKey []byte, minAmt,
+	maxAmt uint64) ([]*ChainAsset, error) {
+
+	assetFilter := QueryAssetFilters{
+		KeyGroupFilter:   tweakedGroupKey,
+		AmountDescending: sqlBool(false),
+	}
+
+	// Amounts exceeding the range of the amount column are stored as the
+	// largest amount we can store, so that's the bound we query for if
+	// the range exceeds it. The exact amounts are then filtered below.
+	assetFilter.MinAmt = sqlInt64(minAmt)
+	if minAmt > MaxAssetAmount {
+		assetFilter.MinAmt = sqlInt64(MaxAssetAmount)
+	}
+	if maxAmt <= MaxAssetAmount {
+		assetFilter.MaxAmt = sqlInt64(maxAmt)
+	}
+
+	chainAssets, err := a.fetchChainAssets(ctx, assetFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	inRange := chainAssets[:0]
+	for _, chainAsset := range chainAssets {
+		if chainAsset.Amount < minAmt || chainAsset.Amount > maxAmt {
+			continue
+		}
+
+		inRange = append(inRange, chainAsset)
+	}
+
+	return inRange, nil
+}
+
+// FetchGroupAssetsPaginated fetches a page of the unspent assets of the asset
+// group with the given tweaked group key, regardless of whether they're
+// anchored. The assets are returned in a stable order, so all assets of a
+// large group can be paged through by advancing the offset by the limit.
+func (a *AssetStore) FetchGroupAssetsPaginated(ctx context.Context,
+	tweakedGroupKey []byte, limit, offset int32) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		KeyGroupFilter:    tweakedGroupKey,
+		IncludeUnanchored: sqlBool(true),
+		NumLimit:          sqlInt32(limit),
+		NumOffset:         sqlInt32(offset),
+	})
+}
+
+// FetchAssetsByGroupKey fetches all assets of the asset group with the given
+// tweaked group key, across all the asset IDs of the group, regardless of
+// whether they're anchored yet. Assets that were already spent are only
+// returned if includeSpent is set.
+func (a *AssetStore) FetchAssetsByGroupKey(ctx context.Context,
+	groupPubKey []byte, includeSpent bool) ([]*asset.Asset, error) {
+
+	chainAssets, err := a.fetchChainAssets(ctx, QueryAssetFilters{
+		KeyGroupFilter:    groupPubKey,
+		IncludeSpent:      sqlBool(includeSpent),
+		IncludeUnanchored: sqlBool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fMap(chainAssets, func(a *ChainAsset) *asset.Asset {
+		return a.Asset
+	}), nil
+}
+
+// ErrAmbiguousAsset is returned when several assets match a query that is
+// expected to resolve a single asset.
+var ErrAmbiguousAsset = errors.New("asset query is ambiguous")
+
+// FetchAssetByScriptKeyAndGroup fetches the unspent asset with the given
+// tweaked script key of the asset group with the given tweaked group key,
+// along with the information of where it's anchored on chain. If there's no
+// such asset, ErrAssetNotFound is returned. If the script key and group key
+// don't identify a single asset UTXO, ErrAmbiguousAsset is returned.
+func (a *AssetStore) FetchAssetByScriptKeyAndGroup(ctx context.Context,
+	tweakedScriptKey, tweakedGroupKey []byte) (*ChainAsset, error) {
+
+	chainAssets, err := a.fetchChainAssets(ctx, QueryAssetFilters{
+		KeyGroupFilter:    tweakedGroupKey,
+		IncludeUnanchored: sqlBool(true),
+		ScriptKeyFilter:   tweakedScriptKey,
+	})
+	switch {
+	case err != nil:
+		return nil, err
+
+	case len(chainAssets) == 0:
+		return nil, ErrAssetNotFound
+
+	case len(chainAssets) > 1:
+		return nil, fmt.Errorf("%w: %d assets with script key %x in "+
+			"group %x", ErrAmbiguousAsset, len(chainAssets),
+			tweakedScriptKey, tweakedGroupKey)
+	}
+
+	return chainAssets[0], nil
+}
+
+// ErrInsufficientGroupBalance is returned when the unspent assets of an asset
+// group don't add up to the requested amount.
+var ErrInsufficientGroupBalance = errors.New("insufficient balance of asset " +
+	"group")
+
+// FetchSpendableAssetsByGroup selects unspent, anchored assets of the asset
+// group with the given tweaked group key that add up to at least minTotal.
+// The assets are selected greedily by decreasing amount, so as few assets as
+// possible are needed. If the unspent assets of the group don't add up to
+// minTotal, all of them are returned along with ErrInsufficientGroupBalance.
+func (a *AssetStore) FetchSpendableAssetsByGroup(ctx context.Context,
+	groupKey []byte, minTotal uint64) ([]*asset.Asset, error) {
+
+	// We'll fetch all unspent assets of the group, which are returned in
+	// increasing order of their amount.
+	chainAssets, err := a.FetchGroupAssetsByAmountRange(
+		ctx, groupKey, 0, math.MaxUint64,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []*asset.Asset
+	remaining := minTotal
+	for i := len(chainAssets) - 1; i >= 0 && remaining > 0; i-- {
+		chainAsset := chainAssets[i]
+		selected = append(selected, chainAsset.Asset)
+
+		if chainAsset.Amount >= remaining {
+			remaining = 0
+		} else {
+			remaining -= chainAsset.Amount
+		}
+	}
+
+	if remaining > 0 {
+		return selected, fmt.Errorf("%w: need %d, missing %d",
+			ErrInsufficientGroupBalance, minTotal, remaining)
+	}
+
+	return selected, nil
+}
+
+// FetchAssetsByAnchorKeyFamily fetches all unspent assets that are anchored
+// in an output with an internal key of the given key family. This can be used
+// to find the assets that belong to a particular account.
+func (a *AssetStore) FetchAssetsByAnchorKeyFamily(ctx context.Context,
+	family int32) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		AnchorKeyFamily: sqlInt32(family),
+	})
+}
+
+// FetchGroupedAssetsWithoutSig fetches all assets that are part of an asset
+// group, but don't reference the group sig of their genesis. Such assets
+// should never exist, so this can be used to detect corrupted assets.
+func (a *AssetStore) FetchGroupedAssetsWithoutSig(
+	ctx context.Context) ([]*ChainAsset, error) {
+
+	return a.fetchChainAssets(ctx, QueryAssetFilters{
+		IncludeSpent:      sqlBool(true),
+		GroupedWithoutSig: sqlBool(true),
+	})
+}
+
+// FetchAmountHistogram counts the unspent assets on disk by their amount. The
+// passed buckets are the strictly ascending lower bounds of each bucket, so
+// bucket i counts the assets with an amount within [buckets[i],
+// buckets[i+1]), with the last bucket being unbounded. Assets with an amount
+// below the first bucket aren't counted.
+func (a *AssetStore) FetchAmountHistogram(ctx context.Context,
+	buckets []uint64) ([]int, error) {
+
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return nil, fmt.Errorf("buckets must be strictly " +
+				"ascending")
+		}
+	}
+
+	var dbAmounts []StoredAssetAmount
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbAmounts, err = q.FetchAssetAmounts(ctx)
+		return err
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch asset amounts: %w",
+			dbErr)
+	}
+
+	histogram := make([]int, len(buckets))
+	for _, dbAmount := range dbAmounts {
+		amount, err := parseAssetAmount(
+			dbAmount.Amount, dbAmount.AmountBig,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// We look for the first bucket that starts above the amount,
+		// the asset then belongs to the bucket right before it.
+		idx := sort.Search(len(buckets), func(i int) bool {
+			return buckets[i] > amount
+		})
+		if idx == 0 {
+			continue
+		}
+
+		histogram[idx-1]++
+	}
+
+	return histogram, nil
+}
+
+// FetchGroupSigsByGenAssetIDs fetches the group sigs of the genesis assets
+// with the given primary keys, keyed by the genesis asset ID. Genesis assets
+// that aren't part of a group aren't included.
+//
+// The group sigs are fetched with an IN query per chunk of at most
+// maxGenesesPerFetch distinct IDs, so sparse IDs don't result in a scan of the
+// group sigs of all the genesis assets in between.
+func (a *AssetStore) FetchGroupSigsByGenAssetIDs(ctx context.Context,
+	ids []int32) (map[int32]AssetGroupSig, error) {
+
+	groupSigs := make(map[int32]AssetGroupSig, len(ids))
+
+	var uniqueIDs []int32
+	seenIDs := make(map[int32]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seenIDs[id]; ok {
+			continue
+		}
+		seenIDs[id] = struct{}{}
+
+		uniqueIDs = append(uniqueIDs, id)
+	}
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		for start := 0; start < len(uniqueIDs); {
+			end := start + maxGenesesPerFetch
+			if end > len(uniqueIDs) {
+				end = len(uniqueIDs)
+			}
+
+			dbSigs, err := q.FetchGroupSigsByGenAssetIDs(
+				ctx, uniqueIDs[start:end],
+			)
+			if err != nil {
+				return err
+			}
+			for _, dbSig := range dbSigs {
+				genAssetID := dbSig.GenAssetID
+				groupSigs[genAssetID] = AssetGroupSig(dbSig)
+			}
+
+			start = end
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch group sigs: %w", dbErr)
+	}
+
+	return groupSigs, nil
+}
+
+// FetchGenesisByIDs fetches the genesis information of the genesis assets
+// with the given primary keys, keyed by their primary key. IDs that don't
+// belong to any genesis asset are omitted from the returned map.
+func (a *AssetStore) FetchGenesisByIDs(ctx context.Context,
+	ids []int32) (map[int32]asset.Genesis, error) {
+
+	var geneses map[int32]asset.Genesis
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		geneses, err = fetchGenesesByIDs(ctx, q, ids)
+		return err
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return geneses, nil
+}
+
+// SharedScriptInternalKey is an internal key that's used as the raw key of the
+// script keys of multiple assets.
+type SharedScriptInternalKey struct {
+	// RawKey is the shared internal key.
+	RawKey *btcec.PublicKey
+
+	// NumAssets is the number of assets whose script key is derived from
+	// the internal key.
+	NumAssets int
+}
+
+// FetchAssetsSharingScriptInternalKey returns all internal keys that are
+// referenced by the script keys of more than one asset. In most flows each
+// asset gets a fresh script key, so this can be used to detect unexpected key
+// re-use.
+func (a *AssetStore) FetchAssetsSharingScriptInternalKey(
+	ctx context.Context) ([]SharedScriptInternalKey, error) {
+
+	return a.FetchHeavilyReusedInternalKeys(ctx, 2)
+}
+
+// FetchHeavilyReusedInternalKeys returns all internal keys that are referenced
+// by the script keys of at least threshold assets, including assets that were
+// already spent. This can be used to find keys that are re-used unusually
+// often.
+func (a *AssetStore) FetchHeavilyReusedInternalKeys(ctx context.Context,
+	threshold int) ([]SharedScriptInternalKey, error) {
+
+	var dbKeys []RawSharedInternalKey
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbKeys, err = q.FetchSharedScriptInternalKeys(
+			ctx, int64(threshold),
+		)
+		return err
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch shared internal "+
+			"keys: %w", dbErr)
+	}
+
+	sharedKeys := make([]SharedScriptInternalKey, len(dbKeys))
+	for i, dbKey := range dbKeys {
+		rawKey, err := btcec.ParsePubKey(dbKey.RawKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse internal key: "+
+				"%w", err)
+		}
+
+		sharedKeys[i] = SharedScriptInternalKey{
+			RawKey:    rawKey,
+			NumAssets: int(dbKey.NumAssets),
+		}
+	}
+
+	return sharedKeys, nil
+}
+
+// FetchAssetAnchorInternalKey returns the internal key of the Taproot output
+// the asset with the given primary key is anchored in, which is needed to
+// re-derive the anchor output. ErrAssetNotFound is returned if the asset
+// doesn't exist or isn't anchored yet.
+func (a *AssetStore) FetchAssetAnchorInternalKey(ctx context.Context,
+	assetPrimaryKey int32) (*keychain.KeyDescriptor, error) {
+
+	var dbKey AnchorInternalKey
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbKey, err = q.FetchAssetAnchorInternalKey(ctx, assetPrimaryKey)
+		return err
+	})
+	switch {
+	case errors.Is(dbErr, sql.ErrNoRows):
+		return nil, ErrAssetNotFound
+
+	case dbErr != nil:
+		return nil, fmt.Errorf("unable to fetch anchor internal key: "+
+			"%w", dbErr)
+	}
+
+	internalKey, err := btcec.ParsePubKey(dbKey.RawKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse internal key: %w", err)
+	}
+
+	return &keychain.KeyDescriptor{
+		PubKey: internalKey,
+		KeyLocator: keychain.KeyLocator{
+			Family: keychain.KeyFamily(dbKey.KeyFamily),
+			Index:  uint32(dbKey.KeyIndex),
+		},
+	}, nil
+}
+
+// FetchAssetCommitmentLeaf reconstructs the asset with the given primary key,
+// and returns the value of the leaf it's committed to in the MS-SMT of its
+// asset commitment, which is the canonical TLV encoding of the asset.
+// ErrAssetNotFound is returned if the asset doesn't exist or isn't anchored
+// yet.
+func (a *AssetStore) FetchAssetCommitmentLeaf(ctx context.Context,
+	assetPrimaryKey int32) ([]byte, error) {
+
+	chainAssets, err := a.fetchChainAssets(ctx, QueryAssetFilters{
+		IncludeSpent:    sqlBool(true),
+		AssetPrimaryKey: sqlInt32(assetPrimaryKey),
+	})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("unable to fetch asset: %w", err)
+
+	case len(chainAssets) == 0:
+		return nil, ErrAssetNotFound
+	}
+
+	leaf, err := chainAssets[0].Leaf()
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode asset leaf: %w", err)
+	}
+
+	return leaf.Value, nil
+}
+
+// ErrInternalKeyNotFound is returned when an internal key can't be found in
+// the database.
+var ErrInternalKeyNotFound = errors.New("internal key not found")
+
+// FetchInternalKeyDesc returns the key descriptor of the internal key with the
+// given primary key, such as the one backing a group key, so the key can be
+// re-derived by the wallet to sign with it again. ErrInternalKeyNotFound is
+// returned if the internal key doesn't exist.
+func (a *AssetStore) FetchInternalKeyDesc(ctx context.Context,
+	keyID int32) (*keychain.KeyDescriptor, error) {
+
+	var dbKey StoredInternalKey
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbKey, err = q.FetchInternalKeyByID(ctx, keyID)
+		return err
+	})
+	switch {
+	case errors.Is(dbErr, sql.ErrNoRows):
+		return nil, ErrInternalKeyNotFound
+
+	case dbErr != nil:
+		return nil, fmt.Errorf("unable to fetch internal key: %w",
+			dbErr)
+	}
+
+	rawKey, err := btcec.ParsePubKey(dbKey.RawKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse internal key: %w", err)
+	}
+
+	return &keychain.KeyDescriptor{
+		PubKey: rawKey,
+		KeyLocator: keychain.KeyLocator{
+			Family: keychain.KeyFamily(dbKey.KeyFamily),
+			Index:  uint32(dbKey.KeyIndex),
+		},
+	}, nil
+}
+
+// FetchInternalKeyByRawKey returns the internal key with the given raw key,
+// including the key family and index it was derived with. This allows a
+// signer to map a key referenced by an incoming PSBT back to its derivation
+// path. ErrInternalKeyNotFound is returned if the internal key doesn't exist.
+func (a *AssetStore) FetchInternalKeyByRawKey(ctx context.Context,
+	rawKey []byte) (InternalKey, error) {
+
+	var dbKey RawInternalKey
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbKey, err = q.FetchInternalKeyByRawKey(ctx, rawKey)
+		return err
+	})
+	switch {
+	case errors.Is(dbErr, sql.ErrNoRows):
+		return InternalKey{}, ErrInternalKeyNotFound
+
+	case dbErr != nil:
+		return InternalKey{}, fmt.Errorf("unable to fetch internal "+
+			"key: %w", dbErr)
+	}
+
+	return InternalKey(dbKey), nil
+}
+
+// ErrScriptKeyNotFound is returned when a script key can't be found in the
+// database.
+var ErrScriptKeyNotFound = errors.New("script key not found")
+
+// FetchScriptKey returns the script key with the given tweaked key along with
+// its type, so the wallet knows how to derive the key when spending an asset
+// sent to it. The TweakedScriptKey of the returned script key is nil if the
+// raw key isn't known, in which case the type is ScriptKeyUnknown.
+// ErrScriptKeyNotFound is returned if the script key doesn't exist.
+func (a *AssetStore) FetchScriptKey(ctx context.Context,
+	tweakedScriptKey *btcec.PublicKey) (*asset.ScriptKey, ScriptKeyType,
+	error) {
+
+	var dbKey StoredScriptKey
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbKey, err = q.FetchScriptKeyByTweakedKey(
+			ctx, tweakedScriptKey.SerializeCompressed(),
+		)
+		return err
+	})
+	switch {
+	case errors.Is(dbErr, sql.ErrNoRows):
+		return nil, ScriptKeyUnknown, ErrScriptKeyNotFound
+
+	case dbErr != nil:
+		return nil, ScriptKeyUnknown, fmt.Errorf("unable to fetch "+
+			"script key: %w", dbErr)
+	}
+
+	scriptKey := &asset.ScriptKey{
+		PubKey: tweakedScriptKey,
+	}
+
+	keyType := ScriptKeyType(dbKey.KeyType)
+	if keyType == ScriptKeyUnknown {
+		return scriptKey, keyType, nil
+	}
+
+	rawKey, err := btcec.ParsePubKey(dbKey.RawKey)
+	if err != nil {
+		return nil, ScriptKeyUnknown, fmt.Errorf("unable to parse "+
+			"raw script key: %w", err)
+	}
+	scriptKey.TweakedScriptKey = &asset.TweakedScriptKey{
+		RawKey: keychain.KeyDescriptor{
+			PubKey: rawKey,
+			KeyLocator: keychain.KeyLocator{
+				Family: keychain.KeyFamily(dbKey.KeyFamily),
+				Index:  uint32(dbKey.KeyIndex),
+			},
+		},
+		Tweak: dbKey.Tweak,
+	}
+
+	return scriptKey, keyType, nil
+}
+
+// FetchGenesisPointByAssetID returns the genesis point outpoint that minted
+// the asset with the given asset ID. ErrAssetNotFound is returned if the
+// asset isn't known.
+func (a *AssetStore) FetchGenesisPointByAssetID(ctx context.Context,
+	assetID [32]byte) (wire.OutPoint, error) {
+
+	var prevOut []byte
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		prevOut, err = q.FetchGenesisPointByAssetID(ctx, assetID[:])
+		return err
+	})
+	switch {
+	case errors.Is(dbErr, sql.ErrNoRows):
+		return wire.OutPoint{}, ErrAssetNotFound
+
+	case dbErr != nil:
+		return wire.OutPoint{}, fmt.Errorf("unable to fetch genesis "+
+			"point: %w", dbErr)
+	}
+
+	var genesisPoint wire.OutPoint
+	err := readOutPoint(bytes.NewReader(prevOut), 0, 0, &genesisPoint)
+	if err != nil {
+		return wire.OutPoint{}, fmt.Errorf("unable to decode genesis "+
+			"point: %w", err)
+	}
+
+	return genesisPoint, nil
+}
+
+// FetchDistinctAssetIDs returns the unique IDs of all unspent assets we know
+// of, regardless of how many UTXOs they're spread across.
+func (a *AssetStore) FetchDistinctAssetIDs(
+	ctx context.Context) ([][32]byte, error) {
+
+	var dbAssetIDs [][]byte
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		dbAssetIDs, err = q.FetchDistinctAssetIDs(ctx)
+		return err
+	})
+	if dbErr != nil {
+		return nil, fmt.Errorf("unable to fetch asset IDs: %w", dbErr)
+	}
+
+	assetIDs := make([][32]byte, len(dbAssetIDs))
+	for i, dbAssetID := range dbAssetIDs {
+		if len(dbAssetID) != len(assetIDs[i]) {
+			return nil, fmt.Errorf("invalid asset ID length: %v",
+				len(dbAssetID))
+		}
+
+		copy(assetIDs[i][:], dbAssetID)
+	}
+
+	return assetIDs, nil
+}
+
+// FetchAnchorUtxoAssetCounts returns the number of unspent assets each managed
+// UTXO anchors, keyed by the primary key of the managed UTXO. Managed UTXOs
+// that don't anchor any unspent assets aren't included.
+func (a *AssetStore) FetchAnchorUtxoAssetCounts(
+	ctx context.Context) (map[int32]int, error) {
+
+	assetCounts := make(map[int32]int)
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		dbCounts, err := q.FetchAnchorUtxoAssetCounts(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to fetch asset counts: %w",
+				err)
+		}
+
+		for _, dbCount := range dbCounts {
+			utxoID := dbCount.AnchorUtxoID.Int32
+			assetCounts[utxoID] = int(dbCount.NumAssets)
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return assetCounts, nil
+}
+
+// FetchAnchorStatusCounts returns the number of unspent assets that are
+// anchored in a managed UTXO, and the number of unspent assets that aren't
+// anchored yet.
+func (a *AssetStore) FetchAnchorStatusCounts(ctx context.Context) (int, int,
+	error) {
+
+	var (
+		anchored, unanchored int
+		readOpts             = NewAssetStoreReadTx()
+	)
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		counts, err := q.FetchAnchorStatusCounts(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to query anchor status "+
+				"counts: %w", err)
+		}
+
+		anchored = int(counts.Anchored)
+		unanchored = int(counts.Unanchored)
+
+		return nil
+	})
+	if dbErr != nil {
+		return 0, 0, dbErr
+	}
+
+	return anchored, unanchored, nil
+}
+
+// FetchManagedUTXOs fetches all UTXOs we manage.
+func (a *AssetStore) FetchManagedUTXOs(ctx context.Context) (
+	[]*ManagedUTXO, error) {
+
+	var (
+		utxos []ManagedUTXORow
+		err   error
+	)
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		utxos, err = q.FetchManagedUTXOs(ctx)
+		return err
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return parseManagedUTXOs(utxos)
+}
+
+// parseManagedUTXOs parses the given managed UTXO rows.
+func parseManagedUTXOs(utxos []ManagedUTXORow) ([]*ManagedUTXO, error) {
+	managedUtxos := make([]*ManagedUTXO, len(utxos))
+	for i, u := range utxos {
+		var anchorPoint wire.OutPoint
+		err := readOutPoint(
+			bytes.NewReader(u.Outpoint), 0, 0, &anchorPoint,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		internalKey, err := btcec.ParsePubKey(u.RawKey)
+		if err != nil {
+			return nil, err
+		}
+
+		managedUtxos[i] = &ManagedUTXO{
+			OutPoint:    anchorPoint,
+			OutputValue: btcutil.Amount(u.AmtSats),
+			InternalKey: keychain.KeyDescriptor{
+				PubKey: internalKey,
+				KeyLocator: keychain.KeyLocator{
+					Index: uint32(u.KeyIndex),
+					Family: keychain.KeyFamily(
+						u.KeyFamily,
+					),
+				},
+			},
+			TaroRoot:         u.TaroRoot,
+			TapscriptSibling: u.TapscriptSibling,
+		}
+	}
+
+	return managedUtxos, nil
+}
+
+// ReserveFreedKeyIndex attempts to reserve the index of a locally derived
+// internal key within the given key family that has since been freed, as the
+// asset it was derived for was deleted. The lowest freed index is returned and
+// flagged as reserved, so it won't be handed out again unless it's freed once
+// more. If no index has been freed, then false is returned and the caller
+// should derive a fresh index instead.
+func (a *AssetStore) ReserveFreedKeyIndex(ctx context.Context,
+	family int32) (int32, bool, error) {
+
+	var (
+		keyIndex int32
+		found    bool
+	)
+
+	var writeTxOpts AssetStoreTxOptions
+	dbErr := a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		var err error
+		keyIndex, err = q.ReserveFreedKeyIndex(ctx, family)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil
+
+		case err != nil:
+			return fmt.Errorf("unable to reserve freed key index: "+
+				"%w", err)
+		}
+
+		found = true
+
+		return a.upsertOpts.auditEntry(
+			ctx, q, UpsertOpFreedKeyReserve, FreedKeyIndex{
+				KeyFamily: family,
+				KeyIndex:  keyIndex,
+			}, sql.NullInt32{},
+		)
+	})
+	if dbErr != nil {
+		return 0, false, dbErr
+	}
+
+	return keyIndex, found, nil
+}
+
+// FetchAssetProofs returns the latest proof file for either the set of target
+// assets, or all assets if no script keys for an asset are passed in.
+//
+// TODO(roasbeef): potentially have a version that writes thru a reader
+// instead?
+func (a *AssetStore) FetchAssetProofs(ctx context.Context,
+	targetAssets ...*btcec.PublicKey) (proof.AssetBlobs, error) {
+
+	proofs := make(proof.AssetBlobs)
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		// No target asset so we can just read them all from disk.
+		if len(targetAssets) == 0 {
+			assetProofs, err := q.FetchAssetProofs(ctx)
+			if err != nil {
+				return fmt.Errorf("unable to fetch asset "+
+					"proofs: %w", err)
+			}
+
+			for _, p := range assetProofs {
+				scriptKey, err := btcec.ParsePubKey(p.ScriptKey)
+				if err != nil {
+					return err
+				}
+
+				serializedKey := asset.ToSerialized(scriptKey)
+				proofs[serializedKey] = p.ProofFile
+			}
+
+			return nil
+		}
+
+		// Otherwise, we'll need to issue a series of queries to fetch
+		// each of the relevant proof files.
+		//
+		// TODO(roasbeef): can modify the query to use IN somewhere
+		// instead? then would take input params and insert into
+		// virtual rows to use
+		for _, scriptKey := range targetAssets {
+			scriptKey := scriptKey
+			serializedKey := asset.ToSerialized(scriptKey)
+
+			assetProof, err := q.FetchAssetProof(
+				ctx, serializedKey[:],
+			)
+			if err != nil {
+				return fmt.Errorf("unable to fetch asset "+
+					"proof: %w", err)
+			}
+
+			proofs[serializedKey] = assetProof.ProofFile
+		}
+		return nil
+	})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	return proofs, nil
+}
+
+// FetchProof fetches a proof for an asset uniquely identified by the passed
+// ProofIdentifier.
+//
+// NOTE: This implements the proof.ArchiveBackend interface.
+func (a *AssetStore) FetchProof(ctx context.Context,
+	locator proof.Locator) (proof.Blob, error) {
+
+	// We don't need anything else but the script key since we have an
+	// on-disk index for all proofs we store.
+	scriptKey := locator.ScriptKey
+
+	var diskProof proof.Blob
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		assetProof, err := q.FetchAssetProof(
+			ctx, scriptKey.SerializeCompressed(),
 		)
 		if err != nil {
 			return fmt.Errorf("unable to fetch asset "+
 				"proof: %w", err)
 		}
 
-		diskProof = assetProof.ProofFile
+		diskProof = assetProof.ProofFile
+
+		return nil
+	})
+	switch {
+	case errors.Is(dbErr, sql.ErrNoRows):
+		return nil, proof.ErrProofNotFound
+	case dbErr != nil:
+		return nil, dbErr
+	}
+
+	return diskProof, nil
+}
+
+// insertAssetWitnesses attempts to insert the set of asset witnesses in to the
+// database, referencing the passed asset primary key.
+func (a *AssetStore) insertAssetWitnesses(ctx context.Context,
+	db ActiveAssetsStore, assetID int32, inputs []asset.Witness) error {
+
+	var buf [8]byte
+	for _, input := range inputs {
+		prevID := input.PrevID
+
+		prevOutpoint, err := encodeOutpoint(prevID.OutPoint)
+		if err != nil {
+			return fmt.Errorf("unable to write outpoint: %w", err)
+		}
+
+		var witnessStack []byte
+		if len(input.TxWitness) != 0 {
+			var b bytes.Buffer
+			err = asset.TxWitnessEncoder(&b, &input.TxWitness, &buf)
+			if err != nil {
+				return fmt.Errorf("unable to encode "+
+					"witness: %w", err)
+			}
+
+			witnessStack = make([]byte, b.Len())
+			copy(witnessStack, b.Bytes())
+		}
+
+		var splitCommitmentProof []byte
+
+		if input.SplitCommitment != nil {
+			var b bytes.Buffer
+			err := asset.SplitCommitmentEncoder(
+				&b, &input.SplitCommitment, &buf,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to encode split "+
+					"commitment: %w", err)
+			}
+
+			splitCommitmentProof = make([]byte, b.Len())
+			copy(splitCommitmentProof, b.Bytes())
+		}
+
+		err = db.InsertAssetWitness(ctx, PrevInput{
+			AssetID:              assetID,
+			PrevOutPoint:         prevOutpoint,
+			PrevAssetID:          prevID.ID[:],
+			PrevScriptKey:        prevID.ScriptKey.CopyBytes(),
+			WitnessStack:         witnessStack,
+			SplitCommitmentProof: splitCommitmentProof,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to insert witness: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// importAssetFromProof imports a new asset into the database based on the
+// information associated with the annotated proofs. This will result in a new
+// asset inserted on disk, with all dependencies such as the asset witnesses
+// inserted along the way.
+func (a *AssetStore) importAssetFromProof(ctx context.Context,
+	db ActiveAssetsStore, proof *proof.AnnotatedProof) error {
+
+	// TODO(roasbeef): below needs to be updated to support asset splits
+
+	// We already know where this lives on-chain, so we can go ahead and
+	// insert the chain information now.
+	//
+	// From the final asset snapshot, we'll obtain the final "resting
+	// place" of the asset and insert that into the DB.
+	var anchorTxBuf bytes.Buffer
+	if err := proof.AnchorTx.Serialize(&anchorTxBuf); err != nil {
+		return err
+	}
+	anchorTXID := proof.AnchorTx.TxHash()
+	udb := a.upsertOpts.auditStore(db)
+	chainTXID, err := udb.UpsertChainTx(ctx, ChainTx{
+		Txid:        anchorTXID[:],
+		RawTx:       anchorTxBuf.Bytes(),
+		BlockHeight: sqlInt32(proof.AnchorBlockHeight),
+		BlockHash:   proof.AnchorBlockHash[:],
+		TxIndex:     sqlInt32(proof.AnchorTxIndex),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to insert chain tx: %w", err)
+	}
+
+	anchorOutput := proof.AnchorTx.TxOut[proof.OutputIndex]
+	anchorPoint, err := encodeOutpoint(wire.OutPoint{
+		Hash:  anchorTXID,
+		Index: proof.OutputIndex,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode outpoint: %w", err)
+	}
+
+	// Before we import the managed UTXO below, we'll make sure to insert
+	// the internal key, though it might already exist here.
+	_, err = udb.UpsertInternalKey(ctx, InternalKey{
+		RawKey: proof.InternalKey.SerializeCompressed(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to insert internal key: %w", err)
+	}
+
+	// Next, we'll insert the managed UTXO that points to the output in our
+	// control for the specified asset.
+	//
+	// TODO(roasbeef): also need to store sibling hash here?
+	tapscriptRoot := proof.ScriptRoot.TapscriptRoot(nil)
+	managedUtxo := RawManagedUTXO{
+		RawKey:   proof.InternalKey.SerializeCompressed(),
+		Outpoint: anchorPoint,
+		AmtSats:  anchorOutput.Value,
+		TaroRoot: tapscriptRoot[:],
+		TxnID:    chainTXID,
+	}
+	utxoID, err := db.UpsertManagedUTXO(ctx, managedUtxo)
+	if err != nil {
+		return fmt.Errorf("unable to insert managed utxo: %w", err)
+	}
+	err = a.upsertOpts.auditEntry(
+		ctx, db, UpsertOpManagedUTXO, managedUtxo, sqlInt32(utxoID),
+	)
+	if err != nil {
+		return err
+	}
+
+	newAsset := proof.Asset
+
+	// Insert/update the asset information in the database now.
+	_, assetIDs, err := upsertAssetsWithGenesis(
+		ctx, db, a.upsertOpts, newAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{newAsset}, []sql.NullInt32{sqlInt32(utxoID)},
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting asset with genesis: %w", err)
+	}
+
+	// Now that we have the asset inserted, we'll also insert all the
+	// witness data associated with the asset in a new row.
+	err = a.insertAssetWitnesses(
+		ctx, db, assetIDs[0], newAsset.PrevWitnesses,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to insert asset witness: %w", err)
+	}
+
+	// As a final step, we'll insert the proof file we used to generate all
+	// the above information.
+	scriptKeyBytes := newAsset.ScriptKey.PubKey.SerializeCompressed()
+	proofUpdate := ProofUpdate{
+		TweakedScriptKey: scriptKeyBytes,
+		ProofFile:        proof.Blob,
+	}
+	err = db.UpsertAssetProof(ctx, proofUpdate)
+	if err != nil {
+		return err
+	}
+
+	return a.upsertOpts.auditEntry(
+		ctx, db, UpsertOpAssetProof, proofUpdate, sql.NullInt32{},
+	)
+}
+
+// ImportProofs attempts to store fully populated proofs on disk. The previous
+// outpoint of the first state transition will be used as the Genesis point.
+// The final resting place of the asset will be used as the script key itself.
+//
+// NOTE: This implements the proof.ArchiveBackend interface.
+func (a *AssetStore) ImportProofs(ctx context.Context,
+	proofs ...*proof.AnnotatedProof) error {
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		for _, proof := range proofs {
+			err := a.importAssetFromProof(ctx, q, proof)
+			if err != nil {
+				return fmt.Errorf("unable to import asset: %w",
+					err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// AnchorUTXO describes an on-chain output that anchors a set of assets, along
+// with the transaction that created the output.
+type AnchorUTXO struct {
+	ManagedUTXO
+
+	// AnchorTx is the transaction that creates the anchor output.
+	AnchorTx *wire.MsgTx
+}
+
+// upsertAnchorUTXO inserts the chain transaction, the internal key and the
+// managed UTXO of the passed anchor, returning the primary key of the managed
+// UTXO. The writes are recorded in the audit log, if the options enable it.
+func upsertAnchorUTXO(ctx context.Context, q ActiveAssetsStore,
+	opts *upsertOptions, anchor AnchorUTXO) (int32, error) {
+
+	if anchor.AnchorTx == nil {
+		return 0, fmt.Errorf("anchor tx for %v missing",
+			anchor.OutPoint)
+	}
+
+	var anchorTxBuf bytes.Buffer
+	if err := anchor.AnchorTx.Serialize(&anchorTxBuf); err != nil {
+		return 0, err
+	}
+	anchorTXID := anchor.AnchorTx.TxHash()
+	if anchorTXID != anchor.OutPoint.Hash {
+		return 0, fmt.Errorf("anchor tx %v doesn't create outpoint %v",
+			anchorTXID, anchor.OutPoint)
+	}
+
+	uq := opts.auditStore(q)
+	chainTXID, err := uq.UpsertChainTx(ctx, ChainTx{
+		Txid:  anchorTXID[:],
+		RawTx: anchorTxBuf.Bytes(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert chain tx: %w",
+			normalizeDBError(err))
+	}
+
+	anchorPoint, err := encodeOutpoint(anchor.OutPoint)
+	if err != nil {
+		return 0, fmt.Errorf("unable to encode outpoint: %w", err)
+	}
+
+	internalKey := anchor.InternalKey.PubKey.SerializeCompressed()
+	_, err = uq.UpsertInternalKey(ctx, InternalKey{
+		RawKey:    internalKey,
+		KeyFamily: int32(anchor.InternalKey.Family),
+		KeyIndex:  int32(anchor.InternalKey.Index),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert internal key: %w",
+			normalizeDBError(err))
+	}
+
+	managedUtxo := RawManagedUTXO{
+		RawKey:           internalKey,
+		Outpoint:         anchorPoint,
+		AmtSats:          int64(anchor.OutputValue),
+		TapscriptSibling: anchor.TapscriptSibling,
+		TaroRoot:         anchor.TaroRoot,
+		TxnID:            chainTXID,
+	}
+	utxoID, err := q.UpsertManagedUTXO(ctx, managedUtxo)
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert managed utxo: %w",
+			normalizeDBError(err))
+	}
+	err = opts.auditEntry(
+		ctx, q, UpsertOpManagedUTXO, managedUtxo, sqlInt32(utxoID),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return utxoID, nil
+}
+
+// TransferAsset archives the old state of an asset by marking it as spent, and
+// inserts its new state anchored in the given managed UTXO. The new asset is
+// linked to the old one through a witness that references the old asset as
+// its previous input, which is added if the new asset doesn't already carry
+// one. Either both or none of the changes are applied.
+func (a *AssetStore) TransferAsset(ctx context.Context, oldAssetID int32,
+	newAsset *asset.Asset, newAnchorUtxoID int32) error {
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		dbPrevID, err := q.FetchAssetPrevID(ctx, oldAssetID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrAssetNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch old asset: %w", err)
+		}
+
+		numSpent, err := q.SetAssetSpent(ctx, oldAssetID)
+		if err != nil {
+			return fmt.Errorf("unable to archive old asset: %w",
+				err)
+		}
+		if numSpent == 0 {
+			return ErrAssetSpent
+		}
+		err = a.upsertOpts.auditAssetUpdates(
+			ctx, q, []int32{oldAssetID},
+		)
+		if err != nil {
+			return err
+		}
+
+		// With the old asset archived, we'll insert the new asset along
+		// with its witnesses.
+		_, assetIDs, err := upsertAssetsWithGenesis(
+			ctx, q, a.upsertOpts, newAsset.Genesis.FirstPrevOut,
+			[]*asset.Asset{newAsset},
+			[]sql.NullInt32{sqlInt32(newAnchorUtxoID)},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert new asset: %w", err)
+		}
+
+		var prevID asset.PrevID
+		err = readOutPoint(
+			bytes.NewReader(dbPrevID.AnchorOutpoint), 0, 0,
+			&prevID.OutPoint,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to read anchor point: %w",
+				err)
+		}
+		copy(prevID.ID[:], dbPrevID.AssetID)
+		copy(prevID.ScriptKey[:], dbPrevID.TweakedScriptKey)
+
+		// We copy the witnesses, so we don't modify the passed asset
+		// when adding the witness that links it to the old asset.
+		witnesses := make(
+			[]asset.Witness, 0, len(newAsset.PrevWitnesses)+1,
+		)
+		isLinked := false
+		for _, witness := range newAsset.PrevWitnesses {
+			if witness.PrevID != nil && *witness.PrevID == prevID {
+				isLinked = true
+			}
+
+			witnesses = append(witnesses, witness)
+		}
+		if !isLinked {
+			witnesses = append(witnesses, asset.Witness{
+				PrevID: &prevID,
+			})
+		}
+
+		return a.insertAssetWitnesses(ctx, q, assetIDs[0], witnesses)
+	})
+}
+
+// maxAssetIDsPerStatement is the maximum number of assets that are archived or
+// touched with a single statement, which keeps the number of query parameters
+// well below the limits of the database backends.
+const maxAssetIDsPerStatement = 1000
+
+// TouchAssets bumps the update time of the assets with the given primary keys
+// without changing them otherwise, which signals downstream consumers to
+// resync them. All assets are touched within a single transaction. Unknown
+// primary keys are ignored.
+func (a *AssetStore) TouchAssets(ctx context.Context, ids []int32) error {
+	updatedAt := sql.NullTime{
+		Time:  time.Now().UTC(),
+		Valid: true,
+	}
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		for start := 0; start < len(ids); {
+			end := start + maxAssetIDsPerStatement
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			_, err := q.TouchAssets(ctx, AssetTouch{
+				AssetIDs:  ids[start:end],
+				UpdatedAt: updatedAt,
+			})
+			if err != nil {
+				return fmt.Errorf("unable to touch assets: %w",
+					err)
+			}
+
+			start = end
+		}
+
+		return a.upsertOpts.auditAssetUpdates(ctx, q, ids)
+	})
+}
+
+// ArchiveAssetsByIDs archives the assets with the given primary keys by
+// marking them as spent, just like the old state of an asset is archived on
+// transfer. All assets are archived within a single transaction, and the
+// number of assets that weren't archived before is returned. Unknown primary
+// keys are ignored.
+func (a *AssetStore) ArchiveAssetsByIDs(ctx context.Context,
+	ids []int32) (int64, error) {
+
+	var numArchived int64
+
+	var writeTxOpts AssetStoreTxOptions
+	dbErr := a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		numArchived = 0
+		for start := 0; start < len(ids); {
+			end := start + maxAssetIDsPerStatement
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			numSpent, err := q.SetAssetsSpent(ctx, ids[start:end])
+			if err != nil {
+				return fmt.Errorf("unable to archive "+
+					"assets: %w", err)
+			}
+			numArchived += numSpent
+
+			start = end
+		}
 
-		return nil
+		return a.upsertOpts.auditAssetUpdates(ctx, q, ids)
 	})
-	switch {
-	case errors.Is(dbErr, sql.ErrNoRows):
-		return nil, proof.ErrProofNotFound
-	case dbErr != nil:
-		return nil, dbErr
+	if dbErr != nil {
+		return 0, dbErr
 	}
 
-	return diskProof, nil
+	return numArchived, nil
 }
 
-// insertAssetWitnesses attempts to insert the set of asset witnesses in to the
-// database, referencing the passed asset primary key.
-func (a *AssetStore) insertAssetWitnesses(ctx context.Context,
-	db ActiveAssetsStore, assetID int32, inputs []asset.Witness) error {
+// DeleteAssetByScriptKey deletes all assets with the given tweaked script key,
+// along with their witnesses and proofs. The script key and the internal key
+// it was derived from are deleted as well, unless anything else still
+// references them. As an internal key may be shared, for example with a group
+// key, it's only deleted once it isn't referenced anymore. Either all or none
+// of the rows are deleted, so an asset that's still referenced elsewhere (for
+// example by an address event) can't be deleted. ErrAssetNotFound is returned
+// if there's no asset with the given script key.
+func (a *AssetStore) DeleteAssetByScriptKey(ctx context.Context,
+	tweakedScriptKey []byte) error {
 
-	var buf [8]byte
-	for _, input := range inputs {
-		prevID := input.PrevID
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		scriptKeyID, err := q.FetchScriptKeyIDByTweakedKey(
+			ctx, tweakedScriptKey,
+		)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrAssetNotFound
 
-		prevOutpoint, err := encodeOutpoint(prevID.OutPoint)
+		case err != nil:
+			return fmt.Errorf("unable to fetch script key: %w", err)
+		}
+
+		// The proofs don't cascade, so we'll need to delete them
+		// before the assets themselves.
+		err = q.DeleteAssetProofsByScriptKeyID(ctx, scriptKeyID)
 		if err != nil {
-			return fmt.Errorf("unable to write outpoint: %w", err)
+			return fmt.Errorf("unable to delete asset proofs: %w",
+				err)
+		}
+		assetIDs, err := q.DeleteAssetsByScriptKeyID(ctx, scriptKeyID)
+		if err != nil {
+			return fmt.Errorf("unable to delete assets: %w", err)
+		}
+		if len(assetIDs) == 0 {
+			return ErrAssetNotFound
 		}
 
-		var witnessStack []byte
-		if len(input.TxWitness) != 0 {
-			var b bytes.Buffer
-			err = asset.TxWitnessEncoder(&b, &input.TxWitness, &buf)
-			if err != nil {
-				return fmt.Errorf("unable to encode "+
-					"witness: %w", err)
-			}
+		// The deleted assets are recorded in the audit log, so they
+		// aren't mistaken for removed rows when verifying it.
+		err = a.upsertOpts.auditAssetDelete(ctx, q, assetIDs)
+		if err != nil {
+			return err
+		}
 
-			witnessStack = make([]byte, b.Len())
-			copy(witnessStack, b.Bytes())
+		// With the assets gone, we'll clean up the script key if it
+		// isn't used anywhere else, and then its internal key.
+		scriptKey, err := q.DeleteOrphanScriptKey(ctx, scriptKeyID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil
+
+		case err != nil:
+			return fmt.Errorf("unable to delete script key: %w",
+				err)
 		}
 
-		var splitCommitmentProof []byte
+		internalKey, err := q.DeleteOrphanInternalKey(
+			ctx, scriptKey.InternalKeyID,
+		)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil
 
-		if input.SplitCommitment != nil {
-			var b bytes.Buffer
-			err := asset.SplitCommitmentEncoder(
-				&b, &input.SplitCommitment, &buf,
-			)
-			if err != nil {
-				return fmt.Errorf("unable to encode split "+
-					"commitment: %w", err)
-			}
+		case err != nil:
+			return fmt.Errorf("unable to delete internal key: %w",
+				err)
+		}
 
-			splitCommitmentProof = make([]byte, b.Len())
-			copy(splitCommitmentProof, b.Bytes())
+		// Only the index of a key we derived ourselves can be handed
+		// out again. The raw key of a foreign script key is just its
+		// tweaked key, and imported keys without a locator would
+		// otherwise all free index zero of the default family.
+		keyLocator := keychain.KeyLocator{
+			Family: keychain.KeyFamily(internalKey.KeyFamily),
+			Index:  uint32(internalKey.KeyIndex),
+		}
+		if !scriptKey.IsKnownRaw || keyLocator.IsEmpty() {
+			return nil
 		}
 
-		err = db.InsertAssetWitness(ctx, PrevInput{
-			AssetID:              assetID,
-			PrevOutPoint:         prevOutpoint,
-			PrevAssetID:          prevID.ID[:],
-			PrevScriptKey:        prevID.ScriptKey.CopyBytes(),
-			WitnessStack:         witnessStack,
-			SplitCommitmentProof: splitCommitmentProof,
-		})
+		err = q.InsertFreedKeyIndex(ctx, FreedKeyIndex(internalKey))
 		if err != nil {
-			return fmt.Errorf("unable to insert witness: %v", err)
+			return fmt.Errorf("unable to insert freed key index: "+
+				"%w", err)
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
-// importAssetFromProof imports a new asset into the database based on the
-// information associated with the annotated proofs. This will result in a new
-// asset inserted on disk, with all dependencies such as the asset witnesses
-// inserted along the way.
-func (a *AssetStore) importAssetFromProof(ctx context.Context,
-	db ActiveAssetsStore, proof *proof.AnnotatedProof) error {
+// UpsertGenesisPoints inserts new or updates existing genesis points in a
+// single database transaction, and returns their primary keys in the same
+// order as the given outpoints. Outpoints that are passed more than once
+// resolve to the same ID.
+func (a *AssetStore) UpsertGenesisPoints(ctx context.Context,
+	genesisOutpoints []wire.OutPoint) ([]int32, error) {
 
-	// TODO(roasbeef): below needs to be updated to support asset splits
+	var genesisPointIDs []int32
 
-	// We already know where this lives on-chain, so we can go ahead and
-	// insert the chain information now.
-	//
-	// From the final asset snapshot, we'll obtain the final "resting
-	// place" of the asset and insert that into the DB.
-	var anchorTxBuf bytes.Buffer
-	if err := proof.AnchorTx.Serialize(&anchorTxBuf); err != nil {
+	var writeTxOpts AssetStoreTxOptions
+	err := a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		var err error
+		genesisPointIDs, err = upsertGenesisPoints(
+			ctx, q, genesisOutpoints,
+		)
 		return err
-	}
-	anchorTXID := proof.AnchorTx.TxHash()
-	chainTXID, err := db.UpsertChainTx(ctx, ChainTx{
-		Txid:        anchorTXID[:],
-		RawTx:       anchorTxBuf.Bytes(),
-		BlockHeight: sqlInt32(proof.AnchorBlockHeight),
-		BlockHash:   proof.AnchorBlockHash[:],
-		TxIndex:     sqlInt32(proof.AnchorTxIndex),
 	})
 	if err != nil {
-		return fmt.Errorf("unable to insert chain tx: %w", err)
+		return nil, err
 	}
 
-	anchorOutput := proof.AnchorTx.TxOut[proof.OutputIndex]
-	anchorPoint, err := encodeOutpoint(wire.OutPoint{
-		Hash:  anchorTXID,
-		Index: proof.OutputIndex,
-	})
-	if err != nil {
-		return fmt.Errorf("unable to encode outpoint: %w", err)
+	return genesisPointIDs, nil
+}
+
+// UpsertGenesisAssets inserts new or resolves existing genesis assets in a
+// single database transaction, and returns their primary keys in the same
+// order as the given genesis assets. The passed policy decides what happens
+// if an existing genesis asset has different metadata, and overrides any
+// policy set on the given genesis assets, see MetadataPolicy. The genesis
+// assets only reference their metadata by its hash, so any metadata should be
+// stored with UpsertGenesisMetaReveal beforehand, or the genesis assets can't
+// be read back.
+func (a *AssetStore) UpsertGenesisAssets(ctx context.Context,
+	genesisAssets []GenesisAsset,
+	policy MetadataPolicy) ([]int32, error) {
+
+	genesisAssets = append([]GenesisAsset(nil), genesisAssets...)
+	for i := range genesisAssets {
+		genesisAssets[i].MetadataPolicy = int16(policy)
 	}
 
-	// Before we import the managed UTXO below, we'll make sure to insert
-	// the internal key, though it might already exist here.
-	_, err = db.UpsertInternalKey(ctx, InternalKey{
-		RawKey: proof.InternalKey.SerializeCompressed(),
+	var genAssetIDs []int32
+
+	var writeTxOpts AssetStoreTxOptions
+	err := a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		var err error
+		genAssetIDs, err = upsertGenesisAssets(
+			ctx, a.upsertOpts.auditStore(q), genesisAssets,
+		)
+		return err
 	})
 	if err != nil {
-		return fmt.Errorf("unable to insert internal key: %w", err)
+		return nil, err
 	}
 
-	// Next, we'll insert the managed UTXO that points to the output in our
-	// control for the specified asset.
-	//
-	// TODO(roasbeef): also need to store sibling hash here?
-	tapscriptRoot := proof.ScriptRoot.TapscriptRoot(nil)
-	utxoID, err := db.UpsertManagedUTXO(ctx, RawManagedUTXO{
-		RawKey:   proof.InternalKey.SerializeCompressed(),
-		Outpoint: anchorPoint,
-		AmtSats:  anchorOutput.Value,
-		TaroRoot: tapscriptRoot[:],
-		TxnID:    chainTXID,
+	return genAssetIDs, nil
+}
+
+// UpsertInternalKeys inserts new or updates existing internal keys in a single
+// database transaction, and returns their primary keys in the same order as
+// the given keys.
+func (a *AssetStore) UpsertInternalKeys(ctx context.Context,
+	keys []InternalKey) ([]int32, error) {
+
+	var keyIDs []int32
+
+	var writeTxOpts AssetStoreTxOptions
+	err := a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		var err error
+		keyIDs, err = upsertInternalKeys(
+			ctx, a.upsertOpts.auditStore(q), keys,
+		)
+		return err
 	})
 	if err != nil {
-		return fmt.Errorf("unable to insert managed utxo: %w", err)
+		return nil, err
 	}
 
-	newAsset := proof.Asset
+	return keyIDs, nil
+}
 
-	// Insert/update the asset information in the database now.
-	_, assetIDs, err := upsertAssetsWithGenesis(
-		ctx, db, newAsset.Genesis.FirstPrevOut,
-		[]*asset.Asset{newAsset}, []sql.NullInt32{sqlInt32(utxoID)},
-	)
-	if err != nil {
-		return fmt.Errorf("error inserting asset with genesis: %w", err)
+// ImportAssetsWithAnchors imports a set of assets that share the same genesis
+// outpoint, along with the on-chain outputs that anchor them. The anchor at a
+// given index anchors the asset at the same index. All anchors and assets are
+// inserted in a single database transaction.
+func (a *AssetStore) ImportAssetsWithAnchors(ctx context.Context,
+	genesisOutpoint wire.OutPoint, assets []*asset.Asset,
+	anchors []AnchorUTXO) error {
+
+	if len(assets) != len(anchors) {
+		return fmt.Errorf("number of assets (%v) doesn't match number "+
+			"of anchors (%v)", len(assets), len(anchors))
 	}
 
-	// Now that we have the asset inserted, we'll also insert all the
-	// witness data associated with the asset in a new row.
-	err = a.insertAssetWitnesses(
-		ctx, db, assetIDs[0], newAsset.PrevWitnesses,
-	)
-	if err != nil {
-		return fmt.Errorf("unable to insert asset witness: %w", err)
-	}
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		return a.importAssetsWithAnchors(
+			ctx, q, genesisOutpoint, assets, anchors,
+		)
+	})
+}
 
-	// As a final step, we'll insert the proof file we used to generate all
-	// the above information.
-	scriptKeyBytes := newAsset.ScriptKey.PubKey.SerializeCompressed()
-	return db.UpsertAssetProof(ctx, ProofUpdate{
-		TweakedScriptKey: scriptKeyBytes,
-		ProofFile:        proof.Blob,
+// WithAssetStore executes the passed closure within a single database
+// transaction, so callers can combine inserting assets with other writes, such
+// as a record of the transaction anchoring them, in one atomic unit. The
+// transaction is committed if the closure returns nil, and rolled back
+// otherwise.
+func (a *AssetStore) WithAssetStore(ctx context.Context,
+	f func(UpsertAssetStore) error) error {
+
+	var writeTxOpts AssetStoreTxOptions
+	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		return f(q)
 	})
 }
 
-// ImportProofs attempts to store fully populated proofs on disk. The previous
-// outpoint of the first state transition will be used as the Genesis point.
-// The final resting place of the asset will be used as the script key itself.
-//
-// NOTE: This implements the proof.ArchiveBackend interface.
-func (a *AssetStore) ImportProofs(ctx context.Context,
-	proofs ...*proof.AnnotatedProof) error {
+// BindAssetAnchor binds the asset with the given primary key to the managed
+// UTXO that anchors it. This allows an asset to be imported before its anchor
+// transaction is known, and to be linked once the anchor UTXO was inserted.
+// Binding an asset to the UTXO it's already anchored in is a no-op, while
+// ErrAssetAlreadyAnchored is returned if it's anchored in a different one.
+func (a *AssetStore) BindAssetAnchor(ctx context.Context, assetID int32,
+	anchorUtxoID int32) error {
 
 	var writeTxOpts AssetStoreTxOptions
 	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
-		for _, proof := range proofs {
-			err := a.importAssetFromProof(ctx, q, proof)
-			if err != nil {
-				return fmt.Errorf("unable to import asset: %w",
-					err)
-			}
+		numBound, err := q.BindAssetAnchor(ctx, AssetAnchorBinding{
+			AnchorUtxoID: sqlInt32(anchorUtxoID),
+			AssetID:      assetID,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to bind asset anchor: %w",
+				normalizeDBError(err))
+		}
+		if numBound != 0 {
+			return a.upsertOpts.auditAssetUpdates(
+				ctx, q, []int32{assetID},
+			)
 		}
 
-		return nil
+		// The asset either doesn't exist, or is anchored in a
+		// different UTXO already.
+		currentAnchor, err := q.FetchAssetAnchorUTXOID(ctx, assetID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrAssetNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch asset anchor: %w",
+				err)
+		}
+
+		return &ErrAssetAlreadyAnchored{
+			AssetID:      assetID,
+			AnchorUtxoID: currentAnchor.Int32,
+		}
 	})
 }
 
+// importAssetsWithAnchors inserts the passed assets along with the UTXOs that
+// anchor them within the passed database transaction.
+func (a *AssetStore) importAssetsWithAnchors(ctx context.Context,
+	q ActiveAssetsStore, genesisOutpoint wire.OutPoint,
+	assets []*asset.Asset, anchors []AnchorUTXO) error {
+
+	// First, we'll insert all the anchor UTXOs, so we can link the assets
+	// to them below.
+	anchorUtxoIDs := make([]sql.NullInt32, len(anchors))
+	for i, anchor := range anchors {
+		utxoID, err := upsertAnchorUTXO(ctx, q, a.upsertOpts, anchor)
+		if err != nil {
+			return err
+		}
+
+		anchorUtxoIDs[i] = sqlInt32(utxoID)
+	}
+
+	_, assetIDs, err := upsertAssetsWithGenesis(
+		ctx, q, a.upsertOpts, genesisOutpoint, assets, anchorUtxoIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting assets with genesis: %w",
+			err)
+	}
+
+	// With the assets inserted, we'll also insert the witness data of each
+	// of them.
+	for i, newAsset := range assets {
+		err := a.insertAssetWitnesses(
+			ctx, q, assetIDs[i], newAsset.PrevWitnesses,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert asset witness: %w",
+				err)
+		}
+	}
+
+	return nil
+}
+
 // queryChainAssets queries the database for assets matching the passed filter.
 // The returned assets have all anchor and witness information populated.
 func queryChainAssets(ctx context.Context, q ActiveAssetsStore,
@@ -1337,9 +4753,12 @@ func (a *AssetStore) LogPendingParcel(ctx context.Context,
 
 	var writeTxOpts AssetStoreTxOptions
 	return a.db.ExecTx(ctx, &writeTxOpts, func(q ActiveAssetsStore) error {
+		// All writes are recorded in the audit log, if it's enabled.
+		uq := a.upsertOpts.auditStore(q)
+
 		// First, we'll insert the new internal on disk, so we can
 		// reference it later when we go to apply the new transfer.
-		internalKeyID, err := q.UpsertInternalKey(ctx, InternalKey{
+		internalKeyID, err := uq.UpsertInternalKey(ctx, InternalKey{
 			RawKey:    internalKeyBytes,
 			KeyFamily: int32(spend.NewInternalKey.Family),
 			KeyIndex:  int32(spend.NewInternalKey.Index),
@@ -1351,7 +4770,7 @@ func (a *AssetStore) LogPendingParcel(ctx context.Context,
 
 		// Next, we'll insert the new transaction that anchors the new
 		// anchor point (commits to the set of new outputs).
-		txnID, err := q.UpsertChainTx(ctx, ChainTx{
+		txnID, err := uq.UpsertChainTx(ctx, ChainTx{
 			Txid:      newAnchorTXID[:],
 			RawTx:     anchorTxBytes,
 			ChainFees: spend.ChainFees,
@@ -1364,47 +4783,71 @@ func (a *AssetStore) LogPendingParcel(ctx context.Context,
 		// Now that the chain transaction been inserted, we can now
 		// insert a _new_ managed UTXO which houses the information
 		// related to the new anchor point of the transaction.
-		newUtxoID, err := q.UpsertManagedUTXO(ctx, RawManagedUTXO{
+		newUtxo := RawManagedUTXO{
 			RawKey:           internalKeyBytes,
 			Outpoint:         newAnchorPointBytes,
 			AmtSats:          anchorValue,
 			TaroRoot:         spend.TaroRoot,
 			TapscriptSibling: spend.TapscriptSibling,
 			TxnID:            txnID,
-		})
+		}
+		newUtxoID, err := q.UpsertManagedUTXO(ctx, newUtxo)
 		if err != nil {
 			return fmt.Errorf("unable to insert new managed "+
 				"utxo: %w", err)
 		}
+		err = a.upsertOpts.auditEntry(
+			ctx, q, UpsertOpManagedUTXO, newUtxo,
+			sqlInt32(newUtxoID),
+		)
+		if err != nil {
+			return err
+		}
 
 		// With the internal key inserted, we can now insert the asset
 		// transfer body itself.
-		transferID, err := q.InsertAssetTransfer(ctx, NewAssetTransfer{
+		newTransfer := NewAssetTransfer{
 			OldAnchorPoint:   oldAnchorPointBytes,
 			NewInternalKey:   internalKeyID,
 			NewAnchorUtxo:    newUtxoID,
 			HeightHint:       int32(spend.AnchorTxHeightHint),
 			TransferTimeUnix: spend.TransferTime,
-		})
+		}
+		transferID, err := q.InsertAssetTransfer(ctx, newTransfer)
 		if err != nil {
 			return fmt.Errorf("unable to insert asset "+
 				"transfer: %w", err)
 		}
+		err = a.upsertOpts.auditEntry(
+			ctx, q, UpsertOpAssetTransfer, newTransfer,
+			sqlInt32(transferID),
+		)
+		if err != nil {
+			return err
+		}
 
 		// Now that the transfer itself has been inserted, we can
 		// insert the deltas associated w/ each transfer.
 		for _, assetDelta := range spend.AssetSpendDeltas {
 			// With the main transfer inserted, we'll also insert
 			// the proof for the sender and receiver.
-			proofID, err := q.InsertSpendProofs(ctx, NewSpendProof{
+			spendProof := NewSpendProof{
 				TransferID:    transferID,
 				SenderProof:   assetDelta.SenderAssetProof,
 				ReceiverProof: assetDelta.ReceiverAssetProof,
-			})
+			}
+			proofID, err := q.InsertSpendProofs(ctx, spendProof)
 			if err != nil {
 				return fmt.Errorf("unable to insert spend "+
 					"proof: %w", err)
 			}
+			err = a.upsertOpts.auditEntry(
+				ctx, q, UpsertOpSpendProof, spendProof,
+				sqlInt32(proofID),
+			)
+			if err != nil {
+				return err
+			}
 
 			var (
 				witnessBuf bytes.Buffer
@@ -1420,12 +4863,18 @@ func (a *AssetStore) LogPendingParcel(ctx context.Context,
 
 			newCommitRoot := assetDelta.SplitCommitmentRoot
 			splitRootHash := newCommitRoot.NodeHash()
-			splitRootSum := newCommitRoot.NodeSum()
+			splitRootValue, err := splitRootValueColumn(
+				newCommitRoot.NodeSum(),
+			)
+			if err != nil {
+				return fmt.Errorf("invalid split commitment "+
+					"root: %w", err)
+			}
 
 			// Before we can insert the asset delta, we need to
 			// insert the new script key on disk.
 			rawScriptKey := assetDelta.NewScriptKey.RawKey
-			rawScriptKeyID, err := q.UpsertInternalKey(
+			rawScriptKeyID, err := uq.UpsertInternalKey(
 				ctx, InternalKey{
 					RawKey:    rawScriptKey.PubKey.SerializeCompressed(),
 					KeyFamily: int32(rawScriptKey.Family),
@@ -1436,32 +4885,41 @@ func (a *AssetStore) LogPendingParcel(ctx context.Context,
 				return fmt.Errorf("unable to insert internal "+
 					"key: %w", err)
 			}
-			scriptKeyID, err := q.UpsertScriptKey(ctx, NewScriptKey{
+			scriptKeyID, err := uq.UpsertScriptKey(ctx, NewScriptKey{
 				InternalKeyID:    rawScriptKeyID,
 				TweakedScriptKey: assetDelta.NewScriptKey.PubKey.SerializeCompressed(),
 				Tweak:            assetDelta.NewScriptKey.Tweak,
+				IsKnownRaw:       true,
+				KeyType: int16(knownScriptKeyType(
+					assetDelta.NewScriptKey.Tweak,
+				)),
 			})
 			if err != nil {
 				return fmt.Errorf("unable to insert script "+
 					"key: %w", err)
 			}
-			err = q.InsertAssetDelta(ctx, NewAssetDelta{
-				OldScriptKey:            assetDelta.OldScriptKey.SerializeCompressed(),
-				NewAmt:                  int64(assetDelta.NewAmt),
-				NewScriptKey:            scriptKeyID,
-				SerializedWitnesses:     witnessBuf.Bytes(),
-				TransferID:              transferID,
-				ProofID:                 proofID,
-				SplitCommitmentRootHash: splitRootHash[:],
-				SplitCommitmentRootValue: sql.NullInt64{
-					Int64: int64(splitRootSum),
-					Valid: true,
-				},
-			})
+			newDelta := NewAssetDelta{
+				OldScriptKey:             assetDelta.OldScriptKey.SerializeCompressed(),
+				NewAmt:                   int64(assetDelta.NewAmt),
+				NewScriptKey:             scriptKeyID,
+				SerializedWitnesses:      witnessBuf.Bytes(),
+				TransferID:               transferID,
+				ProofID:                  proofID,
+				SplitCommitmentRootHash:  splitRootHash[:],
+				SplitCommitmentRootValue: splitRootValue,
+			}
+			err = q.InsertAssetDelta(ctx, newDelta)
 			if err != nil {
 				return fmt.Errorf("unable to insert asset "+
 					"delta: %w", err)
 			}
+			err = a.upsertOpts.auditEntry(
+				ctx, q, UpsertOpAssetDelta, newDelta,
+				sql.NullInt32{},
+			)
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -1493,7 +4951,7 @@ func (a *AssetStore) ConfirmParcelDelivery(ctx context.Context,
 		// Now that we have the new managed UTXO inserted, we'll update
 		// the managed UTXO pointer for _all_ assets that were anchored
 		// by the old managed UTXO.
-		err = q.ReanchorAssets(ctx, AssetAnchorUpdate{
+		reanchoredIDs, err := q.ReanchorAssets(ctx, AssetAnchorUpdate{
 			OldOutpoint: assetTransfer.OldAnchorPoint,
 			NewOutpointUtxoID: sqlInt32(
 				assetTransfer.NewAnchorUtxoID,
@@ -1502,6 +4960,10 @@ func (a *AssetStore) ConfirmParcelDelivery(ctx context.Context,
 		if err != nil {
 			return err
 		}
+		err = a.upsertOpts.auditAssetUpdates(ctx, q, reanchoredIDs)
+		if err != nil {
+			return err
+		}
 
 		// Now that we've re-anchored all the other assets, we also
 		// need to fetch the set of deltas so we can apply to each
@@ -1526,6 +4988,12 @@ func (a *AssetStore) ConfirmParcelDelivery(ctx context.Context,
 				return fmt.Errorf("unable to update "+
 					"spend delta: %w", err)
 			}
+			err = a.upsertOpts.auditAssetUpdates(
+				ctx, q, []int32{assetIDKey},
+			)
+			if err != nil {
+				return err
+			}
 
 			// With the delta applied, we'll delete the _old_ set
 			// of witnesses, and re-insert new ones.
@@ -1557,10 +5025,18 @@ func (a *AssetStore) ConfirmParcelDelivery(ctx context.Context,
 
 			// Now we can update the asset proof for the sender for
 			// this given delta.
-			err = q.UpsertAssetProof(ctx, ProofUpdate{
+			proofUpdate := ProofUpdate{
 				TweakedScriptKey: assetDelta.NewScriptKeyBytes,
 				ProofFile:        conf.FinalSenderProof,
-			})
+			}
+			err = q.UpsertAssetProof(ctx, proofUpdate)
+			if err != nil {
+				return err
+			}
+			err = a.upsertOpts.auditEntry(
+				ctx, q, UpsertOpAssetProof, proofUpdate,
+				sql.NullInt32{},
+			)
 			if err != nil {
 				return err
 			}
@@ -1569,12 +5045,20 @@ func (a *AssetStore) ConfirmParcelDelivery(ctx context.Context,
 		// To confirm a delivery (successful send) all we need to do is
 		// update the chain information for the transaction that
 		// anchors the new anchor point.
-		err = q.ConfirmChainAnchorTx(ctx, AnchorTxConf{
+		anchorTxConf := AnchorTxConf{
 			Outpoint:    anchorPointBytes,
 			BlockHash:   conf.BlockHash[:],
 			BlockHeight: sqlInt32(conf.BlockHeight),
 			TxIndex:     sqlInt32(conf.TxIndex),
-		})
+		}
+		err = q.ConfirmChainAnchorTx(ctx, anchorTxConf)
+		if err != nil {
+			return err
+		}
+		err = a.upsertOpts.auditEntry(
+			ctx, q, UpsertOpAnchorTxConf, anchorTxConf,
+			sql.NullInt32{},
+		)
 		if err != nil {
 			return err
 		}
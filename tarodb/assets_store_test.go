@@ -4,8 +4,15 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"math"
 	"math/rand"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -16,6 +23,7 @@ import (
 	"github.com/lightninglabs/taro/internal/test"
 	"github.com/lightninglabs/taro/mssmt"
 	"github.com/lightninglabs/taro/proof"
+	"github.com/lightninglabs/taro/tarodb/sqlc"
 	"github.com/lightninglabs/taro/tarofreighter"
 	"github.com/lightningnetwork/lnd/keychain"
 	"github.com/stretchr/testify/require"
@@ -37,7 +45,7 @@ type assetGenOptions struct {
 	scriptKey asset.ScriptKey
 }
 
-func defaultAssetGenOpts(t *testing.T) *assetGenOptions {
+func defaultAssetGenOpts(t testing.TB) *assetGenOptions {
 	gen := asset.RandGenesis(t, asset.Normal)
 
 	return &assetGenOptions{
@@ -94,7 +102,7 @@ func withNoGroupKey() assetGenOpt {
 	}
 }
 
-func randAsset(t *testing.T, genOpts ...assetGenOpt) *asset.Asset {
+func randAsset(t testing.TB, genOpts ...assetGenOpt) *asset.Asset {
 	opts := defaultAssetGenOpts(t)
 	for _, optFunc := range genOpts {
 		optFunc(opts)
@@ -139,7 +147,12 @@ func randAsset(t *testing.T, genOpts ...assetGenOpt) *asset.Asset {
 	}
 
 	// Go with an even amount to make the splits always work nicely.
-	if newAsset.Amount%2 != 0 {
+	// Collectibles can't be split, so they always have an amount of one.
+	switch {
+	case genesis.Type == asset.Collectible:
+		newAsset.Amount = 1
+
+	case newAsset.Amount%2 != 0:
 		newAsset.Amount++
 	}
 
@@ -159,6 +172,16 @@ func randAsset(t *testing.T, genOpts ...assetGenOpt) *asset.Asset {
 		}
 		witnesses = make([]asset.Witness, numWitness)
 		for i := 0; i < numWitness; i++ {
+			var splitCommitment *asset.SplitCommitment
+			if genesis.Type == asset.Normal {
+				// For simplicity, we just use the base asset
+				// itself as the "anchor" asset in the split
+				// commitment.
+				splitCommitment = commitment.RandSplitCommit(
+					t, *newAsset,
+				)
+			}
+
 			scriptKey := asset.NewScriptKeyBIP0086(
 				keychain.KeyDescriptor{
 					PubKey: test.RandPubKey(t),
@@ -172,13 +195,8 @@ func randAsset(t *testing.T, genOpts ...assetGenOpt) *asset.Asset {
 						scriptKey.PubKey,
 					),
 				},
-				TxWitness: test.RandTxWitnesses(t),
-				// For simplicity, we just use the base asset
-				// itself as the "anchor" asset in the split
-				// commitment.
-				SplitCommitment: commitment.RandSplitCommit(
-					t, *newAsset,
-				),
+				TxWitness:       test.RandTxWitnesses(t),
+				SplitCommitment: splitCommitment,
 			}
 		}
 	}
@@ -930,7 +948,8 @@ func TestAssetGroupSigUpsert(t *testing.T) {
 	require.NoError(t, err)
 
 	genAssetID, err := upsertGenesis(
-		ctx, db, genesisPointID, asset.RandGenesis(t, asset.Normal),
+		ctx, db, newUpsertOptions(), genesisPointID,
+		asset.RandGenesis(t, asset.Normal), MetadataKeepExisting,
 	)
 	require.NoError(t, err)
 
@@ -961,3 +980,5836 @@ func TestAssetGroupSigUpsert(t *testing.T) {
 
 	require.Equal(t, groupSigID, groupSigID2)
 }
+
+// TestFetchGroupAssetsScriptKeyKinds tests that we're able to count the assets
+// of a group by whether their script key has a tweak or not.
+func TestFetchGroupAssetsScriptKeyKinds(t *testing.T) {
+	t.Parallel()
+
+	_, assetsStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	const (
+		numWithTweak    = 3
+		numWithoutTweak = 2
+	)
+
+	genesisPoint := test.RandOp(t)
+	assetGen := asset.RandGenesis(t, asset.Normal)
+	groupPriv := test.RandPrivKey(t)
+
+	// We'll make a set of assets that all belong to the same group, some
+	// with a tweaked script key, and some with a plain BIP 86 script key.
+	var assets []*asset.Asset
+	for i := 0; i < numWithTweak+numWithoutTweak; i++ {
+		scriptKey := asset.NewScriptKeyBIP0086(keychain.KeyDescriptor{
+			PubKey: test.RandPubKey(t),
+		})
+		if i < numWithTweak {
+			scriptKey.Tweak = test.RandBytes(32)
+		}
+
+		assets = append(assets, randAsset(
+			t, withAssetGen(assetGen),
+			withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+			withScriptKey(scriptKey),
+		))
+	}
+
+	// We'll also add an asset that isn't part of the group at all, which
+	// shouldn't be counted.
+	assets = append(assets, randAsset(
+		t, withAssetGenPoint(genesisPoint), withNoGroupKey(),
+	))
+
+	anchorUtxoIDs := make([]sql.NullInt32, len(assets))
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), genesisPoint, assets,
+		anchorUtxoIDs,
+	)
+	require.NoError(t, err)
+
+	groupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+	kinds := assetsStore.FetchGroupAssetsScriptKeyKinds
+	withTweak, withoutTweak, err := kinds(ctx, groupKey)
+	require.NoError(t, err)
+	require.Equal(t, numWithTweak, withTweak)
+	require.Equal(t, numWithoutTweak, withoutTweak)
+
+	// An unknown group should have no assets of either kind.
+	withTweak, withoutTweak, err = kinds(
+		ctx, test.RandPubKey(t).SerializeCompressed(),
+	)
+	require.NoError(t, err)
+	require.Zero(t, withTweak)
+	require.Zero(t, withoutTweak)
+}
+
+// TestImportAssetsWithAnchors tests that we're able to import a set of assets
+// along with the UTXOs that anchor them, then retrieve them again.
+func TestImportAssetsWithAnchors(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	const numAssets = 2
+
+	genesisPoint := test.RandOp(t)
+	assetGen := asset.RandGenesis(t, asset.Normal)
+	groupPriv := test.RandPrivKey(t)
+
+	// We'll make a few assets that share the same genesis, each anchored
+	// in a distinct transaction.
+	var (
+		assets  []*asset.Asset
+		anchors []AnchorUTXO
+	)
+	for i := 0; i < numAssets; i++ {
+		newAsset := randAsset(
+			t, withAssetGen(assetGen),
+			withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		)
+		assets = append(assets, newAsset)
+
+		anchorTx := wire.NewMsgTx(2)
+		anchorTx.AddTxIn(&wire.TxIn{})
+		anchorTx.AddTxOut(&wire.TxOut{
+			PkScript: bytes.Repeat([]byte{byte(i)}, 34),
+			Value:    1000,
+		})
+
+		anchors = append(anchors, AnchorUTXO{
+			ManagedUTXO: ManagedUTXO{
+				OutPoint: wire.OutPoint{
+					Hash:  anchorTx.TxHash(),
+					Index: 0,
+				},
+				OutputValue: 1000,
+				InternalKey: keychain.KeyDescriptor{
+					PubKey: test.RandPubKey(t),
+					KeyLocator: keychain.KeyLocator{
+						Family: keychain.KeyFamily(i),
+						Index:  uint32(i),
+					},
+				},
+				TaroRoot: test.RandBytes(32),
+			},
+			AnchorTx: anchorTx,
+		})
+	}
+
+	// Importing a mismatched number of assets and anchors should fail.
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors[:1],
+	)
+	require.Error(t, err)
+
+	err = assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors,
+	)
+	require.NoError(t, err)
+
+	// We should now be able to read back all the assets, each linked to
+	// the anchor it was imported with.
+	dbAssets, err := assetStore.FetchAllAssets(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, numAssets)
+
+	anchorIndex := make(map[wire.OutPoint]int, numAssets)
+	for i, anchor := range anchors {
+		anchorIndex[anchor.OutPoint] = i
+	}
+	for _, dbAsset := range dbAssets {
+		i, ok := anchorIndex[dbAsset.AnchorOutpoint]
+		require.True(t, ok)
+
+		assertAssetEqual(t, assets[i], dbAsset.Asset)
+		require.Equal(
+			t, anchors[i].AnchorTx.TxHash(), dbAsset.AnchorTx.TxHash(),
+		)
+		require.True(t, anchors[i].InternalKey.PubKey.IsEqual(
+			dbAsset.AnchorInternalKey,
+		))
+	}
+
+	// The managed UTXOs should also carry the anchor details.
+	utxos, err := assetStore.FetchManagedUTXOs(ctx)
+	require.NoError(t, err)
+	require.Len(t, utxos, numAssets)
+	for _, utxo := range utxos {
+		i, ok := anchorIndex[utxo.OutPoint]
+		require.True(t, ok)
+
+		require.Equal(t, anchors[i].OutputValue, utxo.OutputValue)
+		require.Equal(t, anchors[i].TaroRoot, utxo.TaroRoot)
+		require.Equal(
+			t, anchors[i].InternalKey.KeyLocator,
+			utxo.InternalKey.KeyLocator,
+		)
+	}
+}
+
+// randAnchorUTXO creates a random anchor UTXO, along with the transaction that
+// creates it.
+func randAnchorUTXO(t *testing.T) AnchorUTXO {
+	anchorTx := wire.NewMsgTx(2)
+	anchorTx.AddTxIn(&wire.TxIn{})
+	anchorTx.AddTxOut(&wire.TxOut{
+		PkScript: test.RandBytes(34),
+		Value:    1000,
+	})
+
+	return AnchorUTXO{
+		ManagedUTXO: ManagedUTXO{
+			OutPoint: wire.OutPoint{
+				Hash:  anchorTx.TxHash(),
+				Index: 0,
+			},
+			OutputValue: 1000,
+			InternalKey: keychain.KeyDescriptor{
+				PubKey: test.RandPubKey(t),
+			},
+			TaroRoot: test.RandBytes(32),
+		},
+		AnchorTx: anchorTx,
+	}
+}
+
+// TestFetchGroupAssetsByType tests that we're able to fetch the assets of a
+// group that contains assets of different types, filtered by their type.
+func TestFetchGroupAssetsByType(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	const (
+		numNormal      = 3
+		numCollectible = 2
+	)
+
+	genesisPoint := test.RandOp(t)
+	groupPriv := test.RandPrivKey(t)
+	normalGen := asset.RandGenesis(t, asset.Normal)
+	collectibleGen := asset.RandGenesis(t, asset.Collectible)
+
+	// We'll create a single group that contains both normal assets and
+	// collectibles, as well as a normal asset outside the group.
+	var assets []*asset.Asset
+	for i := 0; i < numNormal+numCollectible; i++ {
+		assetGen := normalGen
+		if i >= numNormal {
+			assetGen = collectibleGen
+		}
+
+		newAsset := randAsset(
+			t, withAssetGen(assetGen),
+			withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		)
+
+		// The group key is tweaked with the genesis of the asset, so
+		// to have the collectibles join the group of the normal
+		// assets, we'll re-use the group key of the first asset. The
+		// genesis signature isn't validated by the database.
+		if i > 0 {
+			groupKey := *assets[0].GroupKey
+			newAsset.GroupKey = &groupKey
+		}
+
+		assets = append(assets, newAsset)
+	}
+	assets = append(assets, randAsset(
+		t, withAssetGen(asset.RandGenesis(t, asset.Normal)),
+		withAssetGenPoint(genesisPoint), withNoGroupKey(),
+	))
+
+	anchors := make([]AnchorUTXO, len(assets))
+	for i := range anchors {
+		anchors[i] = randAnchorUTXO(t)
+	}
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors,
+	)
+	require.NoError(t, err)
+
+	scriptKeys := func(assets []*asset.Asset) []asset.SerializedKey {
+		return fMap(assets, func(a *asset.Asset) asset.SerializedKey {
+			return asset.ToSerialized(a.ScriptKey.PubKey)
+		})
+	}
+	chainScriptKeys := func(
+		assets []*ChainAsset) []asset.SerializedKey {
+
+		return fMap(assets, func(a *ChainAsset) asset.SerializedKey {
+			return asset.ToSerialized(a.ScriptKey.PubKey)
+		})
+	}
+
+	// Querying for each type should only return the group assets of that
+	// type.
+	groupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+	normalAssets, err := assetStore.FetchGroupAssetsByType(
+		ctx, groupKey, asset.Normal,
+	)
+	require.NoError(t, err)
+	require.ElementsMatch(
+		t, scriptKeys(assets[:numNormal]),
+		chainScriptKeys(normalAssets),
+	)
+	for _, normalAsset := range normalAssets {
+		require.Equal(t, asset.Normal, normalAsset.Type)
+	}
+
+	collectibles, err := assetStore.FetchGroupAssetsByType(
+		ctx, groupKey, asset.Collectible,
+	)
+	require.NoError(t, err)
+	require.ElementsMatch(
+		t, scriptKeys(assets[numNormal:numNormal+numCollectible]),
+		chainScriptKeys(collectibles),
+	)
+	for _, collectible := range collectibles {
+		require.Equal(t, asset.Collectible, collectible.Type)
+	}
+
+	// An unknown group shouldn't return any assets.
+	unknownAssets, err := assetStore.FetchGroupAssetsByType(
+		ctx, test.RandPubKey(t).SerializeCompressed(), asset.Normal,
+	)
+	require.NoError(t, err)
+	require.Empty(t, unknownAssets)
+}
+
+// TestFetchSplitAmountMismatches tests that we're able to detect sets of
+// split assets whose amounts don't add up to the split input amount.
+func TestFetchSplitAmountMismatches(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// importSplit splits a new asset of the given amount into a root
+	// (change) asset and an external split, then imports the external
+	// split and, if requested, the root asset. The amount of the external
+	// split is overridden before import if an override is given.
+	importSplit := func(amt, changeAmt, splitAmtOverride uint64,
+		withRoot bool) {
+
+		genesisPoint := test.RandOp(t)
+		input := randAsset(
+			t, withAssetGenAmt(amt), withAssetGenPoint(genesisPoint),
+			withNoGroupKey(),
+		)
+		input.Amount = amt
+
+		rootLocator := &commitment.SplitLocator{
+			OutputIndex: 0,
+			AssetID:     input.ID(),
+			ScriptKey:   asset.ToSerialized(test.RandPubKey(t)),
+			Amount:      changeAmt,
+		}
+		splitLocator := &commitment.SplitLocator{
+			OutputIndex: 1,
+			AssetID:     input.ID(),
+			ScriptKey:   asset.ToSerialized(test.RandPubKey(t)),
+			Amount:      amt - changeAmt,
+		}
+		split, err := commitment.NewSplitCommitment(
+			input, test.RandOp(t), rootLocator, splitLocator,
+		)
+		require.NoError(t, err)
+
+		splitAsset := &split.SplitAssets[*splitLocator].Asset
+		if splitAmtOverride != 0 {
+			splitAsset.Amount = splitAmtOverride
+		}
+
+		// The anchor outputs need to match the output indexes of the
+		// split locators, as they're part of the split commitment.
+		splitAnchor := randAnchorUTXO(t)
+		splitAnchor.OutPoint.Index = splitLocator.OutputIndex
+
+		assets := []*asset.Asset{splitAsset}
+		anchors := []AnchorUTXO{splitAnchor}
+		if withRoot {
+			assets = append(assets, split.RootAsset)
+			anchors = append(anchors, randAnchorUTXO(t))
+		}
+
+		err = assetStore.ImportAssetsWithAnchors(
+			ctx, genesisPoint, assets, anchors,
+		)
+		require.NoError(t, err)
+	}
+
+	// We'll start with a split that is fully consistent, which shouldn't
+	// be reported.
+	importSplit(100, 40, 0, true)
+
+	mismatches, err := assetStore.FetchSplitAmountMismatches(ctx)
+	require.NoError(t, err)
+	require.Empty(t, mismatches)
+
+	// Next, we'll import a split where the external split claims more
+	// than it should, which should be reported.
+	importSplit(100, 40, 70, true)
+
+	mismatches, err = assetStore.FetchSplitAmountMismatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	require.EqualValues(t, 100, mismatches[0].RootValue)
+	require.EqualValues(t, 110, mismatches[0].TotalAmount)
+	require.Len(t, mismatches[0].Assets, 2)
+
+	// A split of which we only know the external split can't be checked,
+	// as the amount of the root asset is unknown, so it shouldn't be
+	// reported even though its amounts don't add up.
+	importSplit(100, 40, 70, false)
+
+	mismatches, err = assetStore.FetchSplitAmountMismatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	require.EqualValues(t, 110, mismatches[0].TotalAmount)
+
+	// Spending the assets of the mismatching split shouldn't hide it.
+	dbAssets, err := db.QueryAssets(ctx, QueryAssetFilters{
+		IncludeSpent:      sqlBool(true),
+		IncludeUnanchored: sqlBool(true),
+	})
+	require.NoError(t, err)
+	for _, dbAsset := range dbAssets {
+		_, err = db.SetAssetSpent(ctx, dbAsset.AssetPrimaryKey)
+		require.NoError(t, err)
+	}
+
+	mismatches, err = assetStore.FetchSplitAmountMismatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	require.EqualValues(t, 110, mismatches[0].TotalAmount)
+	require.Len(t, mismatches[0].Assets, 2)
+}
+
+// TestScriptKeyTweakNullability tests that we preserve the distinction between
+// a script key without a tweak, and one with an explicit empty tweak.
+func TestScriptKeyTweakNullability(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	tweaks := [][]byte{nil, {}, test.RandBytes(32)}
+
+	genesisPoint := test.RandOp(t)
+	assetGen := asset.RandGenesis(t, asset.Normal)
+
+	var (
+		assets  []*asset.Asset
+		anchors []AnchorUTXO
+	)
+	for _, tweak := range tweaks {
+		scriptKey := asset.NewScriptKeyBIP0086(keychain.KeyDescriptor{
+			PubKey: test.RandPubKey(t),
+		})
+		scriptKey.Tweak = tweak
+
+		assets = append(assets, randAsset(
+			t, withAssetGen(assetGen),
+			withAssetGenPoint(genesisPoint), withScriptKey(scriptKey),
+		))
+		anchors = append(anchors, randAnchorUTXO(t))
+	}
+
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors,
+	)
+	require.NoError(t, err)
+
+	dbAssets, err := assetStore.FetchAllAssets(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, len(tweaks))
+
+	dbTweaks := make(map[asset.SerializedKey][]byte, len(dbAssets))
+	for _, dbAsset := range dbAssets {
+		scriptKey := asset.ToSerialized(dbAsset.ScriptKey.PubKey)
+		dbTweaks[scriptKey] = dbAsset.ScriptKey.Tweak
+	}
+
+	for i, tweak := range tweaks {
+		scriptKey := asset.ToSerialized(assets[i].ScriptKey.PubKey)
+		dbTweak, ok := dbTweaks[scriptKey]
+		require.True(t, ok)
+
+		switch {
+		case tweak == nil:
+			require.Nil(t, dbTweak)
+
+		case len(tweak) == 0:
+			require.NotNil(t, dbTweak)
+			require.Empty(t, dbTweak)
+
+		default:
+			require.Equal(t, tweak, dbTweak)
+		}
+	}
+}
+
+// TestFetchAssetsByConfirmationStatus tests that we're able to fetch assets
+// based on whether their anchor transaction is confirmed or not.
+func TestFetchAssetsByConfirmationStatus(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	assetGen := asset.RandGenesis(t, asset.Normal)
+	newAsset := func() *asset.Asset {
+		// All assets share a genesis, so we can't have them randomly
+		// pick their own group key.
+		return randAsset(
+			t, withAssetGen(assetGen),
+			withAssetGenPoint(genesisPoint), withNoGroupKey(),
+		)
+	}
+
+	// We'll import two anchored assets, one of which we'll then confirm.
+	confirmedAsset, unconfirmedAsset := newAsset(), newAsset()
+	confirmedAnchor := randAnchorUTXO(t)
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint,
+		[]*asset.Asset{confirmedAsset, unconfirmedAsset},
+		[]AnchorUTXO{confirmedAnchor, randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	anchorPoint, err := encodeOutpoint(confirmedAnchor.OutPoint)
+	require.NoError(t, err)
+	err = db.ConfirmChainAnchorTx(ctx, AnchorTxConf{
+		Outpoint:    anchorPoint,
+		BlockHeight: sqlInt32(100),
+		BlockHash:   test.RandBytes(32),
+		TxIndex:     sqlInt32(1),
+	})
+	require.NoError(t, err)
+
+	// We'll also insert an asset that isn't anchored at all yet.
+	unanchoredAsset := newAsset()
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), genesisPoint,
+		[]*asset.Asset{unanchoredAsset}, nil,
+	)
+	require.NoError(t, err)
+
+	scriptKey := func(a *ChainAsset) asset.SerializedKey {
+		return asset.ToSerialized(a.ScriptKey.PubKey)
+	}
+
+	// Only the confirmed asset should be returned when querying for
+	// confirmed assets.
+	confirmed, err := assetStore.FetchAssetsByConfirmationStatus(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, confirmed, 1)
+	assertAssetEqual(t, confirmedAsset, confirmed[0].Asset)
+	require.Equal(t, confirmedAnchor.OutPoint, confirmed[0].AnchorOutpoint)
+
+	// Both the unconfirmed and the unanchored asset should be returned
+	// when querying for unconfirmed assets.
+	unconfirmed, err := assetStore.FetchAssetsByConfirmationStatus(
+		ctx, false,
+	)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []asset.SerializedKey{
+		asset.ToSerialized(unconfirmedAsset.ScriptKey.PubKey),
+		asset.ToSerialized(unanchoredAsset.ScriptKey.PubKey),
+	}, fMap(unconfirmed, scriptKey))
+	for _, chainAsset := range unconfirmed {
+		if chainAsset.ScriptKey.PubKey.IsEqual(
+			unanchoredAsset.ScriptKey.PubKey,
+		) {
+
+			require.Nil(t, chainAsset.AnchorTx)
+			continue
+		}
+
+		require.NotNil(t, chainAsset.AnchorTx)
+	}
+}
+
+// TestReserveFreedKeyIndex tests that we're able to reserve the indexes of
+// locally derived keys that were freed by deleting their assets, lowest index
+// first, and that each freed index is only handed out once.
+func TestReserveFreedKeyIndex(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	const keyFamily = 212
+
+	// importAsset imports an asset with the given script key, and returns
+	// a function that deletes it again.
+	importAsset := func(scriptKey asset.ScriptKey) func() {
+		a := randAsset(t, withNoGroupKey(), withScriptKey(scriptKey))
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+
+		return func() {
+			err := assetStore.DeleteAssetByScriptKey(
+				ctx, a.ScriptKey.PubKey.SerializeCompressed(),
+			)
+			require.NoError(t, err)
+		}
+	}
+	localKey := func(family, index uint32) asset.ScriptKey {
+		return asset.NewScriptKeyBIP0086(keychain.KeyDescriptor{
+			PubKey: test.RandPubKey(t),
+			KeyLocator: keychain.KeyLocator{
+				Family: keychain.KeyFamily(family),
+				Index:  index,
+			},
+		})
+	}
+
+	reserve := func(family int32) (int32, bool) {
+		index, found, err := assetStore.ReserveFreedKeyIndex(
+			ctx, family,
+		)
+		require.NoError(t, err)
+
+		return index, found
+	}
+
+	// With no keys at all, there's nothing to reserve.
+	_, found := reserve(keyFamily)
+	require.False(t, found)
+
+	// We'll now import assets with locally derived script keys at index
+	// 1, 2 and 3, and delete all but the one at index 2. Deleting an
+	// asset with a foreign script key, or with a known key that lacks a
+	// locator, shouldn't free any index.
+	deleteFirst := importAsset(localKey(keyFamily, 1))
+	importAsset(localKey(keyFamily, 2))
+	deleteThird := importAsset(localKey(keyFamily, 3))
+	deleteForeign := importAsset(asset.ScriptKey{
+		PubKey: test.RandPubKey(t),
+	})
+	deleteNoLocator := importAsset(localKey(0, 0))
+
+	deleteThird()
+	deleteFirst()
+	deleteForeign()
+	deleteNoLocator()
+
+	// A freed index in another family shouldn't be handed out.
+	importAsset(localKey(keyFamily+1, 5))()
+
+	// We should get back the freed indexes in ascending order, and each
+	// of them only once.
+	for _, expectedIndex := range []int32{1, 3} {
+		index, found := reserve(keyFamily)
+		require.True(t, found)
+		require.Equal(t, expectedIndex, index)
+	}
+	_, found = reserve(keyFamily)
+	require.False(t, found)
+
+	_, found = reserve(0)
+	require.False(t, found)
+
+	// The index in the other family is still there to be reserved.
+	index, found := reserve(keyFamily + 1)
+	require.True(t, found)
+	require.Equal(t, int32(5), index)
+
+	// Once a reserved index is used and freed again, it can be reserved
+	// once more.
+	importAsset(localKey(keyFamily, 1))()
+
+	index, found = reserve(keyFamily)
+	require.True(t, found)
+	require.Equal(t, int32(1), index)
+}
+
+// TestFetchAssetsByScriptKeyKnown tests that we're able to tell apart assets
+// whose raw script key we know from those imported with a foreign script key.
+func TestFetchAssetsByScriptKeyKnown(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	assetGen := asset.RandGenesis(t, asset.Normal)
+	newAsset := func(scriptKey asset.ScriptKey) *asset.Asset {
+		return randAsset(
+			t, withAssetGen(assetGen),
+			withAssetGenPoint(genesisPoint), withNoGroupKey(),
+			withScriptKey(scriptKey),
+		)
+	}
+
+	// The first asset has a script key we know the raw key of, while the
+	// second one only carries the tweaked key, as we'd get when importing
+	// a proof of an asset that belongs to another node.
+	knownAsset := newAsset(asset.NewScriptKeyBIP0086(
+		keychain.KeyDescriptor{
+			PubKey: test.RandPubKey(t),
+		},
+	))
+	foreignAsset := newAsset(asset.ScriptKey{
+		PubKey: test.RandPubKey(t),
+	})
+
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, []*asset.Asset{knownAsset, foreignAsset},
+		[]AnchorUTXO{randAnchorUTXO(t), randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	knownAssets, err := assetStore.FetchAssetsByScriptKeyKnown(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, knownAssets, 1)
+	require.True(t, knownAsset.ScriptKey.PubKey.IsEqual(
+		knownAssets[0].ScriptKey.PubKey,
+	))
+
+	foreignAssets, err := assetStore.FetchAssetsByScriptKeyKnown(
+		ctx, false,
+	)
+	require.NoError(t, err)
+	require.Len(t, foreignAssets, 1)
+	require.True(t, foreignAsset.ScriptKey.PubKey.IsEqual(
+		foreignAssets[0].ScriptKey.PubKey,
+	))
+}
+
+// TestFetchAssetsWithLockTime tests that we're able to fetch only the assets
+// that have an absolute lock time set.
+func TestFetchAssetsWithLockTime(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	assetGen := asset.RandGenesis(t, asset.Normal)
+	newAsset := func(lockTime uint64) *asset.Asset {
+		a := randAsset(
+			t, withAssetGen(assetGen),
+			withAssetGenPoint(genesisPoint), withNoGroupKey(),
+		)
+		a.LockTime = lockTime
+
+		return a
+	}
+
+	// We'll import one asset with a lock time, and one without.
+	lockedAsset, unlockedAsset := newAsset(500), newAsset(0)
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, []*asset.Asset{lockedAsset, unlockedAsset},
+		[]AnchorUTXO{randAnchorUTXO(t), randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	// Only the asset with the lock time should be returned.
+	lockedAssets, err := assetStore.FetchAssetsWithLockTime(ctx)
+	require.NoError(t, err)
+	require.Len(t, lockedAssets, 1)
+	assertAssetEqual(t, lockedAsset, lockedAssets[0].Asset)
+
+	// The asset without a lock time should still be read back with a
+	// zero lock time.
+	allAssets, err := assetStore.FetchAllAssets(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, allAssets, 2)
+	for _, chainAsset := range allAssets {
+		if chainAsset.ScriptKey.PubKey.IsEqual(
+			unlockedAsset.ScriptKey.PubKey,
+		) {
+
+			assertAssetEqual(t, unlockedAsset, chainAsset.Asset)
+		}
+	}
+}
+
+// TestFetchGenesisAssetsWithoutMetadata tests that we're able to fetch the
+// genesis assets that have either no or empty metadata.
+func TestFetchGenesisAssetsWithoutMetadata(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create three assets: one without metadata, one with empty
+	// metadata and one that has actual metadata.
+	genesisPoint := test.RandOp(t)
+	newAsset := func(metadata []byte) *asset.Asset {
+		gen := asset.RandGenesis(t, asset.Normal)
+		gen.Metadata = metadata
+
+		return randAsset(
+			t, withAssetGen(gen), withAssetGenPoint(genesisPoint),
+		)
+	}
+	assets := []*asset.Asset{
+		newAsset(nil), newAsset([]byte{}), newAsset(test.RandBytes(32)),
+	}
+
+	anchorUtxoIDs := make([]sql.NullInt32, len(assets))
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), genesisPoint, assets,
+		anchorUtxoIDs,
+	)
+	require.NoError(t, err)
+
+	// Only the first two assets should be returned, in the order they
+	// were inserted.
+	genesisAssets, err := assetStore.FetchGenesisAssetsWithoutMetadata(ctx)
+	require.NoError(t, err)
+	require.Len(t, genesisAssets, 2)
+	for i, gen := range genesisAssets {
+		require.Empty(t, gen.Metadata)
+		require.Equal(t, assets[i].Genesis.Tag, gen.Tag)
+		require.Equal(t, assets[i].ID(), gen.ID())
+	}
+}
+
+// TestFetchAssetsByScriptVersion tests that we're able to page through the
+// set of assets that use a particular script version.
+func TestFetchAssetsByScriptVersion(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create a set of assets that use a newer script version, and a
+	// few that use the default version.
+	const (
+		numV0Assets = 2
+		numV1Assets = 5
+	)
+	scriptKeys := make(map[int32][]asset.SerializedKey)
+	for i := 0; i < numV0Assets+numV1Assets; i++ {
+		newAsset := randAsset(t)
+		if i >= numV0Assets {
+			newAsset.ScriptVersion = 1
+		}
+
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(),
+			newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+			nil,
+		)
+		require.NoError(t, err)
+
+		version := int32(newAsset.ScriptVersion)
+		scriptKeys[version] = append(
+			scriptKeys[version],
+			asset.ToSerialized(newAsset.ScriptKey.PubKey),
+		)
+	}
+
+	// Paging through the newer version two assets at a time should return
+	// all of them exactly once.
+	const pageSize = 2
+	var pagedScriptKeys []asset.SerializedKey
+	for offset := int32(0); ; offset += pageSize {
+		page, err := assetStore.FetchAssetsByScriptVersion(
+			ctx, 1, pageSize, offset,
+		)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), pageSize)
+
+		if len(page) == 0 {
+			break
+		}
+
+		for _, chainAsset := range page {
+			require.EqualValues(t, 1, chainAsset.ScriptVersion)
+			pagedScriptKeys = append(
+				pagedScriptKeys,
+				asset.ToSerialized(chainAsset.ScriptKey.PubKey),
+			)
+		}
+	}
+	require.Equal(t, scriptKeys[1], pagedScriptKeys)
+
+	// The default version should only return the remaining assets.
+	v0Assets, err := assetStore.FetchAssetsByScriptVersion(
+		ctx, 0, numV0Assets+numV1Assets, 0,
+	)
+	require.NoError(t, err)
+	require.Equal(t, scriptKeys[0], fMap(
+		v0Assets, func(a *ChainAsset) asset.SerializedKey {
+			return asset.ToSerialized(a.ScriptKey.PubKey)
+		},
+	))
+}
+
+// TestFetchAssetsByScriptVersionAndType tests that we're able to fetch the
+// assets of each combination of script version and asset type.
+func TestFetchAssetsByScriptVersionAndType(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	type versionType struct {
+		version   int32
+		assetType asset.Type
+	}
+	var (
+		versions   = []int32{0, 1}
+		assetTypes = []asset.Type{asset.Normal, asset.Collectible}
+	)
+
+	// We'll create a different number of assets for each combination of
+	// script version and asset type, so a mixed up filter would be
+	// caught.
+	scriptKeys := make(map[versionType][]asset.SerializedKey)
+	numAssets := 1
+	for _, version := range versions {
+		for _, assetType := range assetTypes {
+			key := versionType{
+				version:   version,
+				assetType: assetType,
+			}
+			for i := 0; i < numAssets; i++ {
+				newAsset := randAsset(t, withAssetGen(
+					asset.RandGenesis(t, assetType),
+				))
+				newAsset.ScriptVersion = asset.ScriptVersion(
+					version,
+				)
+
+				_, _, err := upsertAssetsWithGenesis(
+					ctx, db, newUpsertOptions(),
+					newAsset.Genesis.FirstPrevOut,
+					[]*asset.Asset{newAsset}, nil,
+				)
+				require.NoError(t, err)
+
+				scriptKeys[key] = append(
+					scriptKeys[key], asset.ToSerialized(
+						newAsset.ScriptKey.PubKey,
+					),
+				)
+			}
+			numAssets++
+		}
+	}
+
+	fetchAssets := assetStore.FetchAssetsByScriptVersionAndType
+	for key, expectedKeys := range scriptKeys {
+		chainAssets, err := fetchAssets(ctx, key.version, key.assetType)
+		require.NoError(t, err)
+
+		for _, chainAsset := range chainAssets {
+			require.EqualValues(
+				t, key.version, chainAsset.ScriptVersion,
+			)
+			require.Equal(t, key.assetType, chainAsset.Type)
+		}
+		require.Equal(t, expectedKeys, fMap(
+			chainAssets, func(a *ChainAsset) asset.SerializedKey {
+				return asset.ToSerialized(a.ScriptKey.PubKey)
+			},
+		))
+	}
+
+	// A script version that isn't used by any asset should return nothing.
+	chainAssets, err := fetchAssets(ctx, 2, asset.Normal)
+	require.NoError(t, err)
+	require.Empty(t, chainAssets)
+}
+
+// TestFetchAssetsByMetadataLength tests that we can fetch the assets with a
+// genesis metadata length within a given range, largest metadata first.
+func TestFetchAssetsByMetadataLength(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create an asset for each metadata length, in no particular
+	// order, which aren't anchored yet.
+	for _, metadataLen := range []int{50, 10, 200, 100, 1} {
+		gen := asset.RandGenesis(t, asset.Normal)
+		gen.Metadata = test.RandBytes(metadataLen)
+		newAsset := randAsset(
+			t, withAssetGen(gen),
+			withAssetGenPoint(gen.FirstPrevOut),
+		)
+
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(), gen.FirstPrevOut,
+			[]*asset.Asset{newAsset}, nil,
+		)
+		require.NoError(t, err)
+	}
+
+	fetchLengths := func(minLen, maxLen int) []int {
+		chainAssets, err := assetStore.FetchAssetsByMetadataLength(
+			ctx, minLen, maxLen,
+		)
+		require.NoError(t, err)
+
+		return fMap(chainAssets, func(a *ChainAsset) int {
+			return len(a.Genesis.Metadata)
+		})
+	}
+
+	require.Equal(t, []int{200, 100, 50, 10, 1}, fetchLengths(0, 1000))
+	require.Equal(t, []int{100, 50, 10}, fetchLengths(10, 100))
+	require.Equal(t, []int{200}, fetchLengths(101, 200))
+	require.Empty(t, fetchLengths(201, 1000))
+	require.Empty(t, fetchLengths(100, 10))
+}
+
+// TestListAssets tests that we can page through all assets with a cursor, and
+// that the pages remain stable while new assets are inserted.
+func TestListAssets(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// insertAsset inserts a new asset that isn't anchored yet. All assets
+	// have the same amount, so their order can't depend on the amount.
+	insertAsset := func() asset.SerializedKey {
+		newAsset := randAsset(t, withAssetGenAmt(5))
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(),
+			newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+			nil,
+		)
+		require.NoError(t, err)
+
+		return asset.ToSerialized(newAsset.ScriptKey.PubKey)
+	}
+
+	const numAssets = 10
+	var scriptKeys []asset.SerializedKey
+	for i := 0; i < numAssets; i++ {
+		scriptKeys = append(scriptKeys, insertAsset())
+	}
+
+	// We'll page through the assets three at a time, inserting a new
+	// asset after each page. The new assets should show up at the end,
+	// without any of the assets being returned twice.
+	const pageSize = 3
+	var (
+		cursor          []byte
+		pagedScriptKeys []asset.SerializedKey
+		numPages        int
+	)
+	for {
+		result, err := assetStore.ListAssets(ctx, ListAssetsQuery{
+			Limit:  pageSize,
+			Cursor: cursor,
+		})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(result.Assets), pageSize)
+
+		for _, chainAsset := range result.Assets {
+			pagedScriptKeys = append(
+				pagedScriptKeys,
+				asset.ToSerialized(chainAsset.ScriptKey.PubKey),
+			)
+		}
+
+		numPages++
+		if len(result.NextCursor) == 0 {
+			break
+		}
+		require.Len(t, result.Assets, pageSize)
+
+		if numPages <= 2 {
+			scriptKeys = append(scriptKeys, insertAsset())
+		}
+		cursor = result.NextCursor
+	}
+	require.Equal(t, scriptKeys, pagedScriptKeys)
+	require.Equal(t, 4, numPages)
+
+	// A page that ends exactly at the last asset shouldn't return a next
+	// cursor.
+	result, err := assetStore.ListAssets(ctx, ListAssetsQuery{
+		Limit: int32(len(scriptKeys)),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Assets, len(scriptKeys))
+	require.Empty(t, result.NextCursor)
+
+	// The largest possible limit shouldn't overflow when we fetch one
+	// more asset than requested.
+	result, err = assetStore.ListAssets(ctx, ListAssetsQuery{
+		Limit: math.MaxInt32,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Assets, len(scriptKeys))
+	require.Empty(t, result.NextCursor)
+
+	// Invalid limits and cursors should be rejected.
+	_, err = assetStore.ListAssets(ctx, ListAssetsQuery{})
+	require.Error(t, err)
+
+	_, err = assetStore.ListAssets(ctx, ListAssetsQuery{
+		Limit:  pageSize,
+		Cursor: []byte{1, 2, 3},
+	})
+	require.Error(t, err)
+}
+
+// TestUpsertGenesisMetadataPolicy tests that re-importing a genesis asset
+// with different metadata respects the passed metadata policy, and never
+// changes the asset ID of the existing genesis asset.
+func TestUpsertGenesisMetadataPolicy(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	shortMeta := []byte("short")
+	longMeta := []byte("much longer metadata")
+
+	tests := []struct {
+		name string
+
+		policy       MetadataPolicy
+		existingMeta []byte
+		newMeta      []byte
+		rejected     bool
+	}{
+		{
+			name:         "keep existing metadata",
+			policy:       MetadataKeepExisting,
+			existingMeta: shortMeta,
+			newMeta:      longMeta,
+		},
+		{
+			name:         "replace with the same metadata",
+			policy:       MetadataReplace,
+			existingMeta: shortMeta,
+			newMeta:      shortMeta,
+		},
+		{
+			name:         "replace with different metadata",
+			policy:       MetadataReplace,
+			existingMeta: longMeta,
+			newMeta:      shortMeta,
+			rejected:     true,
+		},
+		{
+			name:         "prefer longer new metadata",
+			policy:       MetadataPreferLonger,
+			existingMeta: shortMeta,
+			newMeta:      longMeta,
+			rejected:     true,
+		},
+		{
+			name:         "prefer longer existing metadata",
+			policy:       MetadataPreferLonger,
+			existingMeta: longMeta,
+			newMeta:      shortMeta,
+		},
+		{
+			name:         "prefer longer over missing metadata",
+			policy:       MetadataPreferLonger,
+			existingMeta: nil,
+			newMeta:      shortMeta,
+			rejected:     true,
+		},
+	}
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			genesisPoint := test.RandOp(t)
+			genesisPointID, err := upsertGenesisPoint(
+				ctx, db, genesisPoint,
+			)
+			require.NoError(t, err)
+
+			gen := asset.RandGenesis(t, asset.Normal)
+			gen.FirstPrevOut = genesisPoint
+			gen.Metadata = testCase.existingMeta
+			genAssetID, err := upsertGenesis(
+				ctx, db, newUpsertOptions(), genesisPointID,
+				gen, testCase.policy,
+			)
+			require.NoError(t, err)
+
+			// We'll now import the same genesis again, this time
+			// with different metadata. This should either hit the
+			// same row, or be rejected.
+			newGen := gen
+			newGen.Metadata = testCase.newMeta
+			newGenAssetID, err := upsertGenesis(
+				ctx, db, newUpsertOptions(), genesisPointID,
+				newGen, testCase.policy,
+			)
+			if testCase.rejected {
+				require.ErrorIs(t, err, ErrGenesisImmutable)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, genAssetID, newGenAssetID)
+			}
+
+			// Either way, the existing metadata and the asset ID
+			// committing to it should be unchanged.
+			dbGen, _, err := fetchGenesis(ctx, db, genAssetID)
+			require.NoError(t, err)
+			require.Equal(t, gen, dbGen)
+
+			dbGenRow, err := db.FetchGenesisByID(ctx, genAssetID)
+			require.NoError(t, err)
+			expectedID := gen.ID()
+			require.Equal(t, expectedID[:], dbGenRow.AssetID)
+		})
+	}
+}
+
+// TestFetchAnchorUtxoAssetCounts tests that we're able to count the number of
+// assets anchored by each managed UTXO.
+func TestFetchAnchorUtxoAssetCounts(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// Without any assets, there should be nothing to count.
+	assetCounts, err := assetStore.FetchAnchorUtxoAssetCounts(ctx)
+	require.NoError(t, err)
+	require.Empty(t, assetCounts)
+
+	// We'll now import three assets into one anchor, and a single asset
+	// into another one.
+	importAssets := func(numAssets int, anchor AnchorUTXO) {
+		genesisPoint := test.RandOp(t)
+		assets := make([]*asset.Asset, numAssets)
+		anchors := make([]AnchorUTXO, numAssets)
+		for i := 0; i < numAssets; i++ {
+			assets[i] = randAsset(
+				t, withAssetGenPoint(genesisPoint),
+				withNoGroupKey(),
+			)
+			anchors[i] = anchor
+		}
+
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, genesisPoint, assets, anchors,
+		)
+		require.NoError(t, err)
+	}
+	firstAnchor, secondAnchor := randAnchorUTXO(t), randAnchorUTXO(t)
+	importAssets(3, firstAnchor)
+	importAssets(1, secondAnchor)
+
+	// We'll also add an asset that isn't anchored at all, which shouldn't
+	// be counted.
+	unanchoredAsset := randAsset(t)
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(),
+		unanchoredAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{unanchoredAsset}, nil,
+	)
+	require.NoError(t, err)
+
+	utxoID := func(anchor AnchorUTXO) int32 {
+		anchorPoint, err := encodeOutpoint(anchor.OutPoint)
+		require.NoError(t, err)
+
+		utxo, err := db.FetchManagedUTXO(ctx, UtxoQuery{
+			Outpoint: anchorPoint,
+		})
+		require.NoError(t, err)
+
+		return utxo.UtxoID
+	}
+
+	assetCounts, err = assetStore.FetchAnchorUtxoAssetCounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[int32]int{
+		utxoID(firstAnchor):  3,
+		utxoID(secondAnchor): 1,
+	}, assetCounts)
+}
+
+// TestFetchAnchorStatusCounts tests that unspent assets are counted by whether
+// they're anchored or not.
+func TestFetchAnchorStatusCounts(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	assertCounts := func(expectedAnchored, expectedUnanchored int) {
+		anchored, unanchored, err := assetStore.FetchAnchorStatusCounts(
+			ctx,
+		)
+		require.NoError(t, err)
+		require.Equal(t, expectedAnchored, anchored)
+		require.Equal(t, expectedUnanchored, unanchored)
+	}
+
+	// Without any assets, there should be nothing to count.
+	assertCounts(0, 0)
+
+	// We'll import two anchored assets and a single unanchored one.
+	for i := 0; i < 2; i++ {
+		anchoredAsset := randAsset(t)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, anchoredAsset.Genesis.FirstPrevOut,
+			[]*asset.Asset{anchoredAsset},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	unanchoredAsset := randAsset(t)
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(),
+		unanchoredAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{unanchoredAsset}, nil,
+	)
+	require.NoError(t, err)
+	assertCounts(2, 1)
+
+	// Once one of the anchored assets is spent, it should no longer be
+	// counted.
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.True(t, dbAssets[0].AnchorUtxoID.Valid)
+
+	_, err = assetStore.ArchiveAssetsByIDs(
+		ctx, []int32{dbAssets[0].AssetID},
+	)
+	require.NoError(t, err)
+	assertCounts(1, 1)
+}
+
+// TestFetchAssetsOrderedByAmount tests that we're able to fetch the assets
+// with the largest or smallest amounts.
+func TestFetchAssetsOrderedByAmount(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import a set of anchored assets with known amounts, two of
+	// which share the same amount.
+	amounts := []uint64{50, 10, 40, 20, 40}
+	genesisPoint := test.RandOp(t)
+	assets := make([]*asset.Asset, len(amounts))
+	anchors := make([]AnchorUTXO, len(amounts))
+	anchor := randAnchorUTXO(t)
+	for i, amt := range amounts {
+		assets[i] = randAsset(
+			t, withAssetGenPoint(genesisPoint), withNoGroupKey(),
+			withAssetGenAmt(amt),
+		)
+		anchors[i] = anchor
+	}
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors,
+	)
+	require.NoError(t, err)
+
+	fetchAmounts := func(descending bool, limit int32) []uint64 {
+		chainAssets, err := assetStore.FetchAssetsOrderedByAmount(
+			ctx, descending, limit,
+		)
+		require.NoError(t, err)
+
+		return fMap(chainAssets, func(a *ChainAsset) uint64 {
+			return a.Amount
+		})
+	}
+
+	// The top holders should be returned starting with the largest
+	// amount, limited to the number of assets requested.
+	require.Equal(t, []uint64{50, 40, 40}, fetchAmounts(true, 3))
+	require.Equal(
+		t, []uint64{50, 40, 40, 20, 10}, fetchAmounts(true, 10),
+	)
+
+	// In ascending order, we should get the smallest amounts first.
+	require.Equal(t, []uint64{10, 20}, fetchAmounts(false, 2))
+
+	// Assets with the same amount should be returned in the order they
+	// were imported.
+	chainAssets, err := assetStore.FetchAssetsOrderedByAmount(ctx, true, 3)
+	require.NoError(t, err)
+	require.Equal(t, assets[2].ID(), chainAssets[1].ID())
+	require.Equal(t, assets[4].ID(), chainAssets[2].ID())
+}
+
+// TestUpsertGroupKeyGenesisPointMismatch tests that we refuse to group a
+// genesis asset under a genesis point it wasn't created from.
+func TestUpsertGroupKeyGenesisPointMismatch(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll insert two genesis points, and a genesis asset that was
+	// created from the first one.
+	firstPointID, err := upsertGenesisPoint(ctx, db, test.RandOp(t))
+	require.NoError(t, err)
+	secondPointID, err := upsertGenesisPoint(ctx, db, test.RandOp(t))
+	require.NoError(t, err)
+
+	groupedAsset := randAsset(t, withAssetGenKeyGroup(test.RandPrivKey(t)))
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), firstPointID, groupedAsset.Genesis,
+		MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	// Grouping the genesis asset under the second genesis point should
+	// fail, without inserting the group key.
+	_, err = upsertGroupKey(
+		ctx, groupedAsset.GroupKey, db, secondPointID, genAssetID, nil,
+		false,
+	)
+	require.ErrorIs(t, err, ErrGroupGenesisPointMismatch)
+
+	_, err = db.FetchGroupKeyIDByTweakedKey(
+		ctx, groupedAsset.GroupKey.GroupPubKey.SerializeCompressed(),
+	)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Using the genesis point the asset was actually created from should
+	// succeed.
+	groupKeyIDs, err := upsertGroupKey(
+		ctx, groupedAsset.GroupKey, db, firstPointID, genAssetID, nil,
+		false,
+	)
+	require.NoError(t, err)
+	require.True(t, groupKeyIDs.groupSigID.Valid)
+}
+
+// TestUpsertGroupKeyInternalKey tests that upserting a group key returns the
+// primary key of its internal key, which can be used to fetch the key
+// descriptor of the raw group key.
+func TestUpsertGroupKeyInternalKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	// We'll use a raw group key that was derived from a key locator, as
+	// it's the case for group keys created by our own wallet.
+	groupedAsset := randAsset(
+		t, withAssetGenPoint(genesisPoint),
+		withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	groupedAsset.GroupKey.RawKey.KeyLocator = keychain.KeyLocator{
+		Family: test.RandInt[keychain.KeyFamily](),
+		Index:  uint32(test.RandInt[int32]()),
+	}
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID,
+		groupedAsset.Genesis, MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	groupKeyIDs, err := upsertGroupKey(
+		ctx, groupedAsset.GroupKey, db, genesisPointID, genAssetID,
+		nil, false,
+	)
+	require.NoError(t, err)
+
+	// The returned IDs should match the rows backing the group key.
+	groupID, err := db.FetchGroupKeyIDByTweakedKey(
+		ctx, groupedAsset.GroupKey.GroupPubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, groupID, groupKeyIDs.groupID)
+
+	keyID, err := db.FetchInternalKeyIDByRawKey(
+		ctx, groupedAsset.GroupKey.RawKey.PubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, keyID, groupKeyIDs.internalKeyID)
+
+	// With the internal key ID, we should be able to fetch the full key
+	// descriptor of the raw group key.
+	keyDesc, err := assetStore.FetchInternalKeyDesc(
+		ctx, groupKeyIDs.internalKeyID,
+	)
+	require.NoError(t, err)
+	require.Equal(t, groupedAsset.GroupKey.RawKey, *keyDesc)
+
+	// An unknown internal key should result in an error.
+	_, err = assetStore.FetchInternalKeyDesc(
+		ctx, groupKeyIDs.internalKeyID+1,
+	)
+	require.ErrorIs(t, err, ErrInternalKeyNotFound)
+}
+
+// TestUpsertGroupKeySkipSig tests that a group key can be stored without the
+// group sig of its genesis asset, and that the sig can be added later on.
+func TestUpsertGroupKeySkipSig(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	groupedAsset := randAsset(
+		t, withAssetGenPoint(genesisPoint),
+		withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID,
+		groupedAsset.Genesis, MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	// We'll first store the group key without its sig. The group key
+	// should be stored, but there shouldn't be a sig yet.
+	groupKeyIDs, err := upsertGroupKey(
+		ctx, groupedAsset.GroupKey, db, genesisPointID, genAssetID,
+		nil, true,
+	)
+	require.NoError(t, err)
+	require.False(t, groupKeyIDs.groupSigID.Valid)
+
+	groupID, err := db.FetchGroupKeyIDByTweakedKey(
+		ctx, groupedAsset.GroupKey.GroupPubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, groupID, groupKeyIDs.groupID)
+
+	_, err = db.FetchGroupSigIDByGenesisID(ctx, genAssetID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Once the sig is known, we can add it to the stored group key.
+	groupSigID, err := db.UpsertAssetGroupSig(ctx, AssetGroupSig{
+		GenesisSig: groupedAsset.GroupKey.Sig.Serialize(),
+		GenAssetID: genAssetID,
+		GroupKeyID: groupKeyIDs.groupID,
+	})
+	require.NoError(t, err)
+
+	dbSigID, err := db.FetchGroupSigIDByGenesisID(ctx, genAssetID)
+	require.NoError(t, err)
+	require.Equal(t, groupSigID, dbSigID)
+
+	// Upserting the group key along with its sig again should resolve to
+	// the very same rows.
+	newGroupKeyIDs, err := upsertGroupKey(
+		ctx, groupedAsset.GroupKey, db, genesisPointID, genAssetID,
+		nil, false,
+	)
+	require.NoError(t, err)
+	require.Equal(t, groupKeyIDs.groupID, newGroupKeyIDs.groupID)
+	require.Equal(
+		t, groupKeyIDs.internalKeyID, newGroupKeyIDs.internalKeyID,
+	)
+	require.Equal(t, sqlInt32(groupSigID), newGroupKeyIDs.groupSigID)
+}
+
+// TestUpsertGroupKeyReimport tests that re-importing the very same group key
+// of an asset resolves to the existing group sig instead of inserting a
+// duplicate one.
+func TestUpsertGroupKeyReimport(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	groupedAsset := randAsset(
+		t, withAssetGenPoint(genesisPoint),
+		withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID,
+		groupedAsset.Genesis, MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	// Each re-import of the group key should return the same group sig
+	// ID, without adding another sig.
+	const numImports = 3
+	var groupSigID sql.NullInt32
+	for i := 0; i < numImports; i++ {
+		groupKeyIDs, err := upsertGroupKey(
+			ctx, groupedAsset.GroupKey, db, genesisPointID,
+			genAssetID, nil, false,
+		)
+		require.NoError(t, err)
+		require.True(t, groupKeyIDs.groupSigID.Valid)
+
+		if i == 0 {
+			groupSigID = groupKeyIDs.groupSigID
+		}
+		require.Equal(t, groupSigID, groupKeyIDs.groupSigID)
+	}
+
+	groupSigs, err := assetStore.FetchGroupSigsByGenAssetIDs(
+		ctx, []int32{genAssetID},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t, groupedAsset.GroupKey.Sig.Serialize(),
+		groupSigs[genAssetID].GenesisSig,
+	)
+
+	// The fetch above is keyed by the genesis asset, so we'll count the
+	// rows directly to make sure there's no duplicate.
+	rawDB, ok := db.(interface {
+		QueryRowContext(context.Context, string,
+			...interface{}) *sql.Row
+	})
+	require.True(t, ok)
+
+	var numSigs int
+	err = rawDB.QueryRowContext(
+		ctx, "SELECT COUNT(*) FROM asset_group_sigs "+
+			"WHERE gen_asset_id = $1", genAssetID,
+	).Scan(&numSigs)
+	require.NoError(t, err)
+	require.Equal(t, 1, numSigs)
+}
+
+// TestFetchAmountHistogram tests that we're able to count the assets on disk
+// by their amount.
+func TestFetchAmountHistogram(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// As randAsset bumps odd amounts to make them splittable, we only
+	// use even amounts.
+	amounts := []uint64{2, 6, 10, 98, 100, 1000}
+	genesisPoint := test.RandOp(t)
+	assets := fMap(amounts, func(amt uint64) *asset.Asset {
+		return randAsset(
+			t, withAssetGenPoint(genesisPoint),
+			withAssetGenAmt(amt),
+		)
+	})
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), genesisPoint, assets, nil,
+	)
+	require.NoError(t, err)
+
+	// The asset with an amount below the first bucket shouldn't be
+	// counted, while the last bucket has no upper bound.
+	histogram, err := assetStore.FetchAmountHistogram(
+		ctx, []uint64{6, 10, 100},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 2}, histogram)
+
+	// A single bucket starting at zero should count all assets.
+	histogram, err = assetStore.FetchAmountHistogram(ctx, []uint64{0})
+	require.NoError(t, err)
+	require.Equal(t, []int{len(amounts)}, histogram)
+
+	// Buckets that aren't strictly ascending should be rejected.
+	_, err = assetStore.FetchAmountHistogram(ctx, []uint64{10, 10})
+	require.Error(t, err)
+}
+
+// TestFetchAssetsSharingScriptInternalKey tests that we're able to detect
+// internal keys that are shared by the script keys of multiple assets.
+func TestFetchAssetsSharingScriptInternalKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create three assets with script keys derived from the same
+	// internal key but tweaked differently, and a couple of assets that
+	// each have their own internal key.
+	sharedKey := keychain.KeyDescriptor{
+		PubKey: test.RandPubKey(t),
+	}
+	newSharedKeyAsset := func() *asset.Asset {
+		scriptKey := asset.NewScriptKeyBIP0086(sharedKey)
+		scriptKey.Tweak = test.RandBytes(32)
+		scriptKey.PubKey = test.RandPubKey(t)
+
+		return randAsset(t, withScriptKey(scriptKey))
+	}
+	assets := []*asset.Asset{
+		newSharedKeyAsset(), randAsset(t), newSharedKeyAsset(),
+		randAsset(t), newSharedKeyAsset(),
+	}
+	for _, newAsset := range assets {
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(),
+			newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+			nil,
+		)
+		require.NoError(t, err)
+	}
+
+	sharedKeys, err := assetStore.FetchAssetsSharingScriptInternalKey(
+		ctx,
+	)
+	require.NoError(t, err)
+	require.Len(t, sharedKeys, 1)
+	require.True(t, sharedKey.PubKey.IsEqual(sharedKeys[0].RawKey))
+	require.Equal(t, 3, sharedKeys[0].NumAssets)
+}
+
+// TestFetchHeavilyReusedInternalKeys tests that we're able to find the
+// internal keys that are referenced by at least a given number of assets.
+func TestFetchHeavilyReusedInternalKeys(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create assets with script keys derived from two internal keys,
+	// one of them being used four times and the other one twice, along
+	// with an asset that has its own internal key.
+	newSharedKeyAsset := func(sharedKey *btcec.PublicKey) *asset.Asset {
+		scriptKey := asset.NewScriptKeyBIP0086(keychain.KeyDescriptor{
+			PubKey: sharedKey,
+		})
+		scriptKey.Tweak = test.RandBytes(32)
+		scriptKey.PubKey = test.RandPubKey(t)
+
+		return randAsset(t, withScriptKey(scriptKey))
+	}
+	heavyKey, otherKey := test.RandPubKey(t), test.RandPubKey(t)
+	assets := []*asset.Asset{randAsset(t)}
+	for i := 0; i < 4; i++ {
+		assets = append(assets, newSharedKeyAsset(heavyKey))
+	}
+	for i := 0; i < 2; i++ {
+		assets = append(assets, newSharedKeyAsset(otherKey))
+	}
+	for _, newAsset := range assets {
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(),
+			newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+			nil,
+		)
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		threshold     int
+		expectedKeys  []*btcec.PublicKey
+		expectedCount []int
+	}{
+		{
+			threshold:     2,
+			expectedKeys:  []*btcec.PublicKey{heavyKey, otherKey},
+			expectedCount: []int{4, 2},
+		},
+		{
+			threshold:     3,
+			expectedKeys:  []*btcec.PublicKey{heavyKey},
+			expectedCount: []int{4},
+		},
+		{
+			threshold:     4,
+			expectedKeys:  []*btcec.PublicKey{heavyKey},
+			expectedCount: []int{4},
+		},
+		{
+			threshold: 5,
+		},
+	}
+	for _, testCase := range testCases {
+		reusedKeys, err := assetStore.FetchHeavilyReusedInternalKeys(
+			ctx, testCase.threshold,
+		)
+		require.NoError(t, err)
+		require.Len(t, reusedKeys, len(testCase.expectedKeys))
+
+		for i, reusedKey := range reusedKeys {
+			require.True(
+				t, testCase.expectedKeys[i].IsEqual(
+					reusedKey.RawKey,
+				),
+			)
+			require.Equal(
+				t, testCase.expectedCount[i],
+				reusedKey.NumAssets,
+			)
+		}
+	}
+}
+
+// insertGroupedAssets inserts the given number of grouped assets and returns
+// the primary keys of their genesis assets.
+func insertGroupedAssets(t testing.TB, db BatchedQuerier,
+	numAssets int) []int32 {
+
+	ctx := context.Background()
+
+	genAssetIDs := make([]int32, numAssets)
+	for i := range genAssetIDs {
+		newAsset := randAsset(
+			t, withAssetGenKeyGroup(test.RandPrivKey(t)),
+		)
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(),
+			newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+			nil,
+		)
+		require.NoError(t, err)
+
+		genAssetIDs[i], err = db.FetchGenesisAssetIDByTag(
+			ctx, newAsset.Genesis.Tag,
+		)
+		require.NoError(t, err)
+	}
+
+	return genAssetIDs
+}
+
+// TestFetchGroupSigsByGenAssetIDs tests that we're able to fetch the group
+// sigs of a set of genesis assets in bulk.
+func TestFetchGroupSigsByGenAssetIDs(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// With no IDs given, we don't expect any group sigs.
+	groupSigs, err := assetStore.FetchGroupSigsByGenAssetIDs(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, groupSigs)
+
+	// We'll insert a few grouped assets along with an ungrouped one that
+	// sits right in between them.
+	genAssetIDs := insertGroupedAssets(t, db, 2)
+	ungroupedAsset := randAsset(t, withNoGroupKey())
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(),
+		ungroupedAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{ungroupedAsset}, nil,
+	)
+	require.NoError(t, err)
+	genAssetIDs = append(genAssetIDs, insertGroupedAssets(t, db, 2)...)
+
+	// Fetching the first and the last asset shouldn't return the group
+	// sigs of the assets in between.
+	wantedIDs := []int32{genAssetIDs[3], genAssetIDs[0]}
+	groupSigs, err = assetStore.FetchGroupSigsByGenAssetIDs(ctx, wantedIDs)
+	require.NoError(t, err)
+	require.Len(t, groupSigs, len(wantedIDs))
+	for _, genAssetID := range wantedIDs {
+		require.Contains(t, groupSigs, genAssetID)
+		require.Equal(t, genAssetID, groupSigs[genAssetID].GenAssetID)
+		require.NotEmpty(t, groupSigs[genAssetID].GenesisSig)
+	}
+
+	// Fetching all of them should return the group sigs of all the
+	// grouped assets, while unknown IDs are ignored.
+	wantedIDs = append(genAssetIDs, genAssetIDs[3]+100)
+	groupSigs, err = assetStore.FetchGroupSigsByGenAssetIDs(ctx, wantedIDs)
+	require.NoError(t, err)
+	require.Len(t, groupSigs, len(genAssetIDs))
+
+	// A set of IDs that doesn't fit into a single query should be fetched
+	// with several ones, independent of how sparse the IDs are.
+	wantedIDs = make([]int32, 0, maxGenesesPerFetch+len(genAssetIDs))
+	for i := int32(1); len(wantedIDs) < maxGenesesPerFetch; i++ {
+		wantedIDs = append(wantedIDs, genAssetIDs[3]+i*1000)
+	}
+	wantedIDs = append(wantedIDs, genAssetIDs...)
+	groupSigs, err = assetStore.FetchGroupSigsByGenAssetIDs(ctx, wantedIDs)
+	require.NoError(t, err)
+	require.Len(t, groupSigs, len(genAssetIDs))
+}
+
+// BenchmarkFetchGroupSigsByGenAssetIDs compares fetching the group sigs of a
+// set of genesis assets in bulk against fetching them one by one.
+func BenchmarkFetchGroupSigsByGenAssetIDs(b *testing.B) {
+	_, assetStore, db := newAssetStore(b)
+	ctx := context.Background()
+
+	const numAssets = 100
+	genAssetIDs := insertGroupedAssets(b, db, numAssets)
+
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			groupSigs, err :=
+				assetStore.FetchGroupSigsByGenAssetIDs(
+					ctx, genAssetIDs,
+				)
+			require.NoError(b, err)
+			require.Len(b, groupSigs, numAssets)
+		}
+	})
+
+	b.Run("per-asset", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, genAssetID := range genAssetIDs {
+				groupSigs, err :=
+					assetStore.FetchGroupSigsByGenAssetIDs(
+						ctx, []int32{genAssetID},
+					)
+				require.NoError(b, err)
+				require.Len(b, groupSigs, 1)
+			}
+		}
+	})
+}
+
+// TestAssetBigAmount tests that the exact amount of an asset that exceeds the
+// range of the amount column can be stored and fetched again.
+func TestAssetBigAmount(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t, WithBigAmounts())
+	ctx := context.Background()
+
+	// importGroup imports a group of anchored assets with the given
+	// amounts, and returns them along with their tweaked group key.
+	importGroup := func(opts *upsertOptions,
+		amounts ...uint64) ([]*asset.Asset, []byte, error) {
+
+		gen := asset.RandGenesis(t, asset.Normal)
+		groupPriv := test.RandPrivKey(t)
+
+		assets := make([]*asset.Asset, len(amounts))
+		anchorUtxoIDs := make([]sql.NullInt32, len(amounts))
+		for i, amount := range amounts {
+			assets[i] = randAsset(
+				t, withAssetGen(gen),
+				withAssetGenPoint(gen.FirstPrevOut),
+				withAssetGenKeyGroup(groupPriv),
+			)
+			assets[i].Amount = amount
+
+			anchorUtxoID, err := upsertAnchorUTXO(
+				ctx, db, newUpsertOptions(), randAnchorUTXO(t),
+			)
+			require.NoError(t, err)
+			anchorUtxoIDs[i] = sqlInt32(anchorUtxoID)
+		}
+
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, opts, gen.FirstPrevOut, assets,
+			anchorUtxoIDs,
+		)
+		groupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+
+		return assets, groupKey, err
+	}
+
+	const (
+		maxAmt    = uint64(MaxAssetAmount)
+		bigAmt    = uint64(math.MaxUint64 - 1)
+		bigOneAmt = maxAmt + 1
+	)
+
+	// A store that wasn't created with the option to store big amounts
+	// should refuse the assets instead of storing an overflowed amount.
+	_, _, err := importGroup(newUpsertOptions(), 5, bigAmt)
+	var overflowErr *ErrAssetAmountOverflow
+	require.ErrorAs(t, err, &overflowErr)
+	require.Equal(t, bigAmt, overflowErr.Amount)
+
+	// We'll import a group with amounts on both sides of the range of the
+	// amount column, and a second group whose supply exceeds it.
+	bigAmtOpts := newUpsertOptions(WithBigAmounts())
+	bigAssets, groupKey, err := importGroup(
+		bigAmtOpts, bigAmt, maxAmt, 5, bigOneAmt,
+	)
+	require.NoError(t, err)
+	_, supplyGroupKey, err := importGroup(bigAmtOpts, 5, bigOneAmt)
+	require.NoError(t, err)
+
+	// The amount column of the big assets is clamped, and their exact
+	// amount stored separately.
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 6)
+	for _, dbAsset := range dbAssets {
+		if !dbAsset.AmountBig.Valid {
+			require.LessOrEqual(t, dbAsset.Amount, int64(maxAmt))
+			continue
+		}
+
+		require.Equal(t, int64(maxAmt), dbAsset.Amount)
+		require.Contains(t, []string{
+			strconv.FormatUint(bigAmt, 10),
+			strconv.FormatUint(bigOneAmt, 10),
+		}, dbAsset.AmountBig.String)
+	}
+
+	// We should get the exact amount of each of the assets back.
+	for _, a := range bigAssets {
+		amt, err := assetStore.FetchAssetBigAmount(
+			ctx, a.ID(), a.ScriptKey.PubKey,
+		)
+		require.NoError(t, err)
+		require.Equal(t, a.Amount, amt.Uint64())
+
+		chainAsset, err := assetStore.FetchAsset(
+			ctx, a.ID(), a.ScriptKey.PubKey,
+		)
+		require.NoError(t, err)
+		require.Equal(t, a.Amount, chainAsset.Amount)
+	}
+
+	amounts := func(chainAssets []*ChainAsset) []uint64 {
+		return fMap(chainAssets, func(a *ChainAsset) uint64 {
+			return a.Amount
+		})
+	}
+
+	// The assets should be ordered by their exact amount.
+	ordered, err := assetStore.FetchAssetsOrderedByAmount(ctx, false, 10)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{
+		5, 5, maxAmt, bigOneAmt, bigOneAmt, bigAmt,
+	}, amounts(ordered))
+
+	ordered, err = assetStore.FetchAssetsOrderedByAmount(ctx, true, 10)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{
+		bigAmt, bigOneAmt, bigOneAmt, maxAmt, 5, 5,
+	}, amounts(ordered))
+
+	// Amount ranges should be applied to the exact amounts.
+	fetchRange := func(minAmt, maxAmt uint64) []uint64 {
+		chainAssets, err := assetStore.FetchGroupAssetsByAmountRange(
+			ctx, groupKey, minAmt, maxAmt,
+		)
+		require.NoError(t, err)
+
+		return amounts(chainAssets)
+	}
+	require.Equal(t, []uint64{5}, fetchRange(0, 10))
+	require.Equal(t, []uint64{maxAmt}, fetchRange(maxAmt, maxAmt))
+	require.Equal(t, []uint64{5, maxAmt}, fetchRange(0, maxAmt))
+	require.Equal(
+		t, []uint64{bigOneAmt, bigAmt},
+		fetchRange(bigOneAmt, math.MaxUint64),
+	)
+	require.Equal(t, []uint64{bigAmt}, fetchRange(bigAmt, bigAmt))
+
+	// Coin selection should pick the largest asset first.
+	selected, err := assetStore.FetchSpendableAssetsByGroup(
+		ctx, groupKey, bigAmt,
+	)
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	require.Equal(t, bigAmt, selected[0].Amount)
+
+	// The histogram should count the assets by their exact amount.
+	histogram, err := assetStore.FetchAmountHistogram(
+		ctx, []uint64{0, 10, bigOneAmt},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 1, 3}, histogram)
+
+	// The supply of the first group exceeds the range of a uint64, so
+	// only the second group can be found by its supply.
+	supplyAmt := bigOneAmt + 5
+	groups, err := assetStore.FetchGroupsBySupplyRange(
+		ctx, 0, math.MaxUint64,
+	)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(
+		t, supplyGroupKey, groups[0].GroupKey.SerializeCompressed(),
+	)
+	require.Equal(t, supplyAmt, groups[0].Balance)
+
+	groups, err = assetStore.FetchGroupsBySupplyRange(
+		ctx, supplyAmt+1, math.MaxUint64,
+	)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+
+	// An unknown asset should return an error.
+	_, err = assetStore.FetchAssetBigAmount(
+		ctx, asset.RandID(t), test.RandPubKey(t),
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+}
+
+// TestBackfillWrappedAmounts tests that the assets stored with an amount that
+// wrapped around the range of the amount column are migrated to a clamped
+// amount along with their exact amount.
+func TestBackfillWrappedAmounts(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	bigAmt := uint64(math.MaxUint64 - 1)
+	a := randAsset(t)
+	anchorUtxoID, err := upsertAnchorUTXO(
+		ctx, db, newUpsertOptions(), randAnchorUTXO(t),
+	)
+	require.NoError(t, err)
+	_, assetIDs, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), a.Genesis.FirstPrevOut,
+		[]*asset.Asset{a}, []sql.NullInt32{sqlInt32(anchorUtxoID)},
+	)
+	require.NoError(t, err)
+
+	// Assets stored before amounts were checked only have the wrapped
+	// amount, which we'll simulate by storing it directly.
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+	_, err = rawDB.ExecContext(
+		ctx, "UPDATE assets SET amount = $1 WHERE asset_id = $2",
+		int64(bigAmt), assetIDs[0],
+	)
+	require.NoError(t, err)
+
+	// Even before the migration, the exact amount can be read back.
+	amt, err := assetStore.FetchAssetBigAmount(
+		ctx, a.ID(), a.ScriptKey.PubKey,
+	)
+	require.NoError(t, err)
+	require.Equal(t, bigAmt, amt.Uint64())
+
+	// Applying the migration twice should clamp the amount and store the
+	// exact one once.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, backfillWrappedAmounts(ctx, db))
+
+		dbAssets, err := db.AllAssets(ctx)
+		require.NoError(t, err)
+		require.Len(t, dbAssets, 1)
+		require.Equal(t, int64(MaxAssetAmount), dbAssets[0].Amount)
+		require.Equal(
+			t, strconv.FormatUint(bigAmt, 10),
+			dbAssets[0].AmountBig.String,
+		)
+	}
+
+	chainAsset, err := assetStore.FetchAsset(
+		ctx, a.ID(), a.ScriptKey.PubKey,
+	)
+	require.NoError(t, err)
+	require.Equal(t, bigAmt, chainAsset.Amount)
+}
+
+// TestMoveGenesisMetadata tests that the metadata of genesis assets that
+// still carry it inline is moved over to the reveals, after which the genesis
+// assets can be read back through their hash alone.
+func TestMoveGenesisMetadata(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+
+	// We'll store two genesis assets the legacy way, with their metadata
+	// inline. The first one predates the tracking of the hash, while the
+	// second one carries a hash that doesn't match its metadata.
+	storeLegacyGenesis := func(metaHash []byte) (asset.Genesis, int32) {
+		genesis := asset.RandGenesis(t, asset.Normal)
+		genesis.Metadata = test.RandBytes(32)
+
+		genesisPointID, err := upsertGenesisPoint(
+			ctx, db, genesis.FirstPrevOut,
+		)
+		require.NoError(t, err)
+
+		genAssetID, err := upsertGenesis(
+			ctx, db, newUpsertOptions(), genesisPointID, genesis,
+			MetadataKeepExisting,
+		)
+		require.NoError(t, err)
+
+		revealHash := genesis.MetadataHash()
+		_, err = rawDB.ExecContext(
+			ctx, "DELETE FROM genesis_meta_reveals "+
+				"WHERE meta_hash = $1", revealHash[:],
+		)
+		require.NoError(t, err)
+		_, err = rawDB.ExecContext(
+			ctx, "UPDATE genesis_assets SET meta_hash = $1, "+
+				"meta_data = $2 WHERE gen_asset_id = $3",
+			metaHash, genesis.Metadata, genAssetID,
+		)
+		require.NoError(t, err)
+
+		return genesis, genAssetID
+	}
+	genesis, genAssetID := storeLegacyGenesis(nil)
+	_, corruptID := storeLegacyGenesis(test.RandBytes(32))
+
+	// Without the reveal, the genesis can't be read back.
+	_, _, err := fetchGenesis(ctx, db, genAssetID)
+	require.ErrorIs(t, err, ErrMetaRevealMissing)
+
+	// Applying the migration twice should move the metadata once, and
+	// leave the metadata of the corrupt genesis asset in place.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, moveGenesisMetadata(ctx, db))
+
+		inline, err := db.FetchGenesisAssetsWithInlineMetadata(ctx)
+		require.NoError(t, err)
+		require.Len(t, inline, 1)
+		require.Equal(t, corruptID, inline[0].GenAssetID)
+	}
+
+	dbGenesis, _, err := fetchGenesis(ctx, db, genAssetID)
+	require.NoError(t, err)
+	require.Equal(t, genesis.Metadata, dbGenesis.Metadata)
+	require.Equal(t, genesis.ID(), dbGenesis.ID())
+}
+
+// TestFetchDistinctAssetIDs tests that each asset ID is only returned once,
+// even if the asset is spread across multiple UTXOs.
+func TestFetchDistinctAssetIDs(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// Without any assets, there should be no asset IDs.
+	assetIDs, err := assetStore.FetchDistinctAssetIDs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, assetIDs)
+
+	// We'll import the same asset into three different UTXOs, and another
+	// asset into a single UTXO.
+	importAsset := func(gen asset.Genesis, numUtxos int) {
+		for i := 0; i < numUtxos; i++ {
+			newAsset := randAsset(
+				t, withAssetGen(gen),
+				withAssetGenPoint(gen.FirstPrevOut),
+				withNoGroupKey(),
+			)
+			err := assetStore.ImportAssetsWithAnchors(
+				ctx, gen.FirstPrevOut,
+				[]*asset.Asset{newAsset},
+				[]AnchorUTXO{randAnchorUTXO(t)},
+			)
+			require.NoError(t, err)
+		}
+	}
+	spreadGen := asset.RandGenesis(t, asset.Normal)
+	singleGen := asset.RandGenesis(t, asset.Normal)
+	importAsset(spreadGen, 3)
+	importAsset(singleGen, 1)
+
+	assetIDs, err = assetStore.FetchDistinctAssetIDs(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, [][32]byte{
+		spreadGen.ID(), singleGen.ID(),
+	}, assetIDs)
+}
+
+// randInternalKeys returns the given number of internal keys, of which only
+// numUnique have distinct raw keys.
+func randInternalKeys(t testing.TB, numKeys, numUnique int) []InternalKey {
+	uniqueKeys := make([]InternalKey, numUnique)
+	for i := range uniqueKeys {
+		uniqueKeys[i] = InternalKey{
+			RawKey:    test.RandPubKey(t).SerializeCompressed(),
+			KeyFamily: test.RandInt[int32](),
+			KeyIndex:  test.RandInt[int32](),
+		}
+	}
+
+	keys := make([]InternalKey, numKeys)
+	for i := range keys {
+		keys[i] = uniqueKeys[i%numUnique]
+	}
+
+	return keys
+}
+
+// TestUpsertInternalKeys tests that we're able to upsert internal keys in
+// bulk, and that each distinct key is only written once.
+func TestUpsertInternalKeys(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// Upserting no keys should be a no-op.
+	keyIDs, err := assetStore.UpsertInternalKeys(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, keyIDs)
+
+	// We'll upsert a set of keys that contains duplicates. The returned
+	// IDs should line up with the keys we passed in.
+	keys := randInternalKeys(t, 6, 3)
+	keyIDs, err = assetStore.UpsertInternalKeys(ctx, keys)
+	require.NoError(t, err)
+	require.Len(t, keyIDs, len(keys))
+
+	for i, key := range keys {
+		keyID, err := db.FetchInternalKeyIDByRawKey(ctx, key.RawKey)
+		require.NoError(t, err)
+		require.Equal(t, keyID, keyIDs[i])
+	}
+
+	dbKeys, err := db.AllInternalKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbKeys, 3)
+
+	// Upserting the same keys again should return the same IDs.
+	newKeyIDs, err := assetStore.UpsertInternalKeys(ctx, keys[:3])
+	require.NoError(t, err)
+	require.Equal(t, keyIDs[:3], newKeyIDs)
+
+	// A set of keys that doesn't fit into a single statement should be
+	// upserted in several ones.
+	manyKeys := randInternalKeys(
+		t, maxInternalKeysPerUpsert+1, maxInternalKeysPerUpsert+1,
+	)
+	keyIDs, err = assetStore.UpsertInternalKeys(ctx, manyKeys)
+	require.NoError(t, err)
+	require.Len(t, keyIDs, len(manyKeys))
+
+	dbKeys, err = db.AllInternalKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbKeys, 3+len(manyKeys))
+
+	// When importing several assets that share a group key, the internal
+	// key of the group key should only be written once.
+	metrics := NewTableWriteMetrics()
+	metricsStore := NewObservedAssetsStore(db, metrics)
+
+	const numAssets = 3
+	genesisPoint := test.RandOp(t)
+	groupPriv := test.RandPrivKey(t)
+	assets := make([]*asset.Asset, numAssets)
+	for i := range assets {
+		assets[i] = randAsset(
+			t, withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		)
+		assets[i].GroupKey = assets[0].GroupKey
+	}
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, metricsStore, newUpsertOptions(), genesisPoint, assets,
+		nil,
+	)
+	require.NoError(t, err)
+
+	// We expect one write for the shared group key and one for each of
+	// the script keys.
+	require.Equal(t, TableWrites{
+		Inserts: numAssets + 1,
+	}, metrics.Snapshot()[TableInternalKeys])
+}
+
+// TestUpsertAssetsInternalKeyCache tests that the internal keys shared by the
+// assets of a batch are only written once per batch, and that the cache of
+// written keys doesn't outlive the batch.
+func TestUpsertAssetsInternalKeyCache(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	metrics := NewTableWriteMetrics()
+	metricsStore := NewObservedAssetsStore(db, metrics)
+
+	// All assets of the batch share both their group key and their script
+	// key, so only two internal keys are referenced.
+	const numAssets = 4
+	genesisPoint := test.RandOp(t)
+	groupPriv := test.RandPrivKey(t)
+	scriptKey := asset.NewScriptKeyBIP0086(keychain.KeyDescriptor{
+		PubKey: test.RandPubKey(t),
+		KeyLocator: keychain.KeyLocator{
+			Family: test.RandInt[keychain.KeyFamily](),
+			Index:  uint32(test.RandInt[int32]()),
+		},
+	})
+	assets := make([]*asset.Asset, numAssets)
+	for i := range assets {
+		assets[i] = randAsset(
+			t, withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+			withScriptKey(scriptKey),
+		)
+		assets[i].GroupKey = assets[0].GroupKey
+	}
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, metricsStore, newUpsertOptions(), genesisPoint, assets,
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, TableWrites{
+		Inserts: 2,
+	}, metrics.Snapshot()[TableInternalKeys])
+
+	// The cache is scoped to a single batch, so inserting another batch
+	// referencing the same keys updates them once more.
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, metricsStore, newUpsertOptions(), genesisPoint, assets[:1],
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, TableWrites{
+		Inserts: 2,
+		Updates: 2,
+	}, metrics.Snapshot()[TableInternalKeys])
+
+	// Both batches still resolve to the same two internal keys.
+	dbKeys, err := db.AllInternalKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbKeys, 2)
+}
+
+// TestUpsertInternalKeyConcurrent tests that concurrently upserting the same
+// internal key from many transactions results in a single row, with all of
+// the upserts returning the ID of that row.
+func TestUpsertInternalKeyConcurrent(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	internalKey := InternalKey{
+		RawKey:    test.RandPubKey(t).SerializeCompressed(),
+		KeyFamily: test.RandInt[int32](),
+		KeyIndex:  test.RandInt[int32](),
+	}
+
+	const numUpserts = 20
+	var (
+		wg     sync.WaitGroup
+		keyIDs [numUpserts]int32
+		errs   [numUpserts]error
+	)
+	for i := 0; i < numUpserts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			upsert := func(q ActiveAssetsStore) error {
+				var err error
+				keyIDs[i], err = q.UpsertInternalKey(
+					ctx, internalKey,
+				)
+				return err
+			}
+
+			var writeTxOpts AssetStoreTxOptions
+			errs[i] = assetStore.db.ExecTx(ctx, &writeTxOpts, upsert)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < numUpserts; i++ {
+		require.NoError(t, errs[i])
+		require.Equal(t, keyIDs[0], keyIDs[i])
+	}
+
+	dbKeys, err := db.AllInternalKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbKeys, 1)
+	require.Equal(t, keyIDs[0], dbKeys[0].KeyID)
+}
+
+// BenchmarkUpsertInternalKeys compares upserting a set of internal keys with
+// a single multi-row statement against upserting them one by one. Both
+// variants write a fresh set of keys within a single transaction each time.
+func BenchmarkUpsertInternalKeys(b *testing.B) {
+	_, assetStore, _ := newAssetStore(b)
+	ctx := context.Background()
+
+	benchmarkUpsert := func(b *testing.B,
+		upsert func(ActiveAssetsStore, []InternalKey) error) {
+
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			keys := randInternalKeys(b, 100, 100)
+			b.StartTimer()
+
+			tx := func(q ActiveAssetsStore) error {
+				return upsert(q, keys)
+			}
+
+			var writeTxOpts AssetStoreTxOptions
+			err := assetStore.db.ExecTx(ctx, &writeTxOpts, tx)
+			require.NoError(b, err)
+		}
+	}
+
+	b.Run("bulk", func(b *testing.B) {
+		benchmarkUpsert(b, func(q ActiveAssetsStore,
+			keys []InternalKey) error {
+
+			_, err := q.UpsertInternalKeys(ctx, keys)
+			return err
+		})
+	})
+
+	b.Run("individual", func(b *testing.B) {
+		benchmarkUpsert(b, func(q ActiveAssetsStore,
+			keys []InternalKey) error {
+
+			for _, key := range keys {
+				_, err := q.UpsertInternalKey(ctx, key)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+// randGenesisAssets returns the given number of genesis assets anchored at
+// the given genesis point, of which only numUnique have distinct asset IDs.
+func randGenesisAssets(t testing.TB, genesisPointID int32,
+	genesisPoint wire.OutPoint, numAssets, numUnique int) []GenesisAsset {
+
+	uniqueAssets := make([]GenesisAsset, numUnique)
+	for i := range uniqueAssets {
+		gen := asset.RandGenesis(t, asset.Normal)
+		gen.FirstPrevOut = genesisPoint
+
+		uniqueAssets[i] = newGenesisAsset(
+			genesisPointID, gen, MetadataKeepExisting,
+		)
+	}
+
+	genesisAssets := make([]GenesisAsset, numAssets)
+	for i := range genesisAssets {
+		genesisAssets[i] = uniqueAssets[i%numUnique]
+	}
+
+	return genesisAssets
+}
+
+// TestUpsertGenesisAssets tests that we're able to upsert genesis assets in
+// bulk, and that the returned IDs line up with the given genesis assets.
+func TestUpsertGenesisAssets(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// Upserting no genesis assets should be a no-op.
+	genAssetIDs, err := assetStore.UpsertGenesisAssets(
+		ctx, nil, MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+	require.Empty(t, genAssetIDs)
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	// We'll upsert a set of genesis assets that contains duplicates. The
+	// returned IDs should line up with the genesis assets we passed in.
+	genesisAssets := randGenesisAssets(
+		t, genesisPointID, genesisPoint, 6, 3,
+	)
+	genAssetIDs, err = assetStore.UpsertGenesisAssets(
+		ctx, genesisAssets, MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+	require.Len(t, genAssetIDs, len(genesisAssets))
+
+	for i, genAsset := range genesisAssets {
+		genAssetID, err := db.FetchGenesisAssetIDByTag(
+			ctx, genAsset.AssetTag,
+		)
+		require.NoError(t, err)
+		require.Equal(t, genAssetID, genAssetIDs[i])
+	}
+
+	dbAssets, err := db.GenesisAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 3)
+
+	// Upserting the same genesis assets again should return the same IDs.
+	newGenAssetIDs, err := assetStore.UpsertGenesisAssets(
+		ctx, genesisAssets[:3], MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+	require.Equal(t, genAssetIDs[:3], newGenAssetIDs)
+
+	// Upserting them with different metadata keeps the existing genesis
+	// assets by default, while a policy that would replace the metadata,
+	// and therefore the asset ID, is rejected.
+	changedAssets := make([]GenesisAsset, 3)
+	for i, genAsset := range genesisAssets[:3] {
+		changedAssets[i] = genAsset
+		changedAssets[i].MetaHash = test.RandBytes(32)
+		changedAssets[i].AssetID = test.RandBytes(32)
+	}
+	newGenAssetIDs, err = assetStore.UpsertGenesisAssets(
+		ctx, changedAssets, MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+	require.Equal(t, genAssetIDs[:3], newGenAssetIDs)
+
+	_, err = assetStore.UpsertGenesisAssets(
+		ctx, changedAssets, MetadataReplace,
+	)
+	require.ErrorIs(t, err, ErrGenesisImmutable)
+
+	for i, genAsset := range genesisAssets[:3] {
+		dbGen, err := db.FetchGenesisByID(ctx, genAssetIDs[i])
+		require.NoError(t, err)
+		require.Equal(t, genAsset.AssetID, dbGen.AssetID)
+	}
+
+	// A set of genesis assets that repeats a tag under a different asset
+	// ID, or doesn't fit into a single statement, should be upserted in
+	// several ones.
+	manyAssets := randGenesisAssets(
+		t, genesisPointID, genesisPoint, maxGenesisAssetsPerUpsert+1,
+		maxGenesisAssetsPerUpsert+1,
+	)
+	manyAssets = append(manyAssets, changedAssets[0])
+	genAssetIDs, err = assetStore.UpsertGenesisAssets(
+		ctx, manyAssets, MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+	require.Len(t, genAssetIDs, len(manyAssets))
+	require.Equal(t, newGenAssetIDs[0], genAssetIDs[len(manyAssets)-1])
+
+	// Random tags can repeat as well, so we'll count the distinct ones.
+	tags := make(map[string]struct{})
+	for _, genAsset := range append(genesisAssets, manyAssets...) {
+		tags[genAsset.AssetTag] = struct{}{}
+	}
+	dbAssets, err = db.GenesisAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, len(tags))
+}
+
+// TestUpsertGenesisPoints tests that we're able to upsert genesis points in
+// bulk, and that the returned IDs line up with the given outpoints, even if
+// some of them already existed or were passed more than once.
+func TestUpsertGenesisPoints(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// Upserting no genesis points should be a no-op.
+	genesisPointIDs, err := assetStore.UpsertGenesisPoints(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, genesisPointIDs)
+
+	existingPoint := test.RandOp(t)
+	existingID, err := upsertGenesisPoint(ctx, db, existingPoint)
+	require.NoError(t, err)
+
+	// We'll upsert a set of genesis points that contains the existing
+	// point as well as a duplicate.
+	newPoint, otherPoint := test.RandOp(t), test.RandOp(t)
+	genesisPoints := []wire.OutPoint{
+		newPoint, existingPoint, otherPoint, newPoint,
+	}
+	genesisPointIDs, err = assetStore.UpsertGenesisPoints(
+		ctx, genesisPoints,
+	)
+	require.NoError(t, err)
+	require.Len(t, genesisPointIDs, len(genesisPoints))
+	require.Equal(t, existingID, genesisPointIDs[1])
+	require.Equal(t, genesisPointIDs[0], genesisPointIDs[3])
+
+	for i, genesisPoint := range genesisPoints {
+		prevOut, err := encodeOutpoint(genesisPoint)
+		require.NoError(t, err)
+
+		genesisPointID, err := db.FetchGenesisPointIDByPrevOut(
+			ctx, prevOut,
+		)
+		require.NoError(t, err)
+		require.Equal(t, genesisPointID, genesisPointIDs[i])
+	}
+
+	dbPoints, err := db.GenesisPoints(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbPoints, 3)
+
+	// A set of genesis points that doesn't fit into a single statement
+	// should be upserted in several ones.
+	manyPoints := make([]wire.OutPoint, maxGenesisPointsPerUpsert+1)
+	for i := range manyPoints {
+		manyPoints[i] = test.RandOp(t)
+	}
+	genesisPointIDs, err = assetStore.UpsertGenesisPoints(ctx, manyPoints)
+	require.NoError(t, err)
+	require.Len(t, genesisPointIDs, len(manyPoints))
+
+	dbPoints, err = db.GenesisPoints(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbPoints, 3+len(manyPoints))
+}
+
+// BenchmarkUpsertGenesisAssets compares upserting a set of genesis assets
+// with a single multi-row statement against upserting them one by one. Both
+// variants write the same number of fresh genesis assets within a single
+// transaction each time.
+func BenchmarkUpsertGenesisAssets(b *testing.B) {
+	_, assetStore, db := newAssetStore(b)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(b)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(b, err)
+
+	benchmarkUpsert := func(b *testing.B,
+		upsert func(ActiveAssetsStore, []GenesisAsset) error) {
+
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			genesisAssets := randGenesisAssets(
+				b, genesisPointID, genesisPoint, 100, 100,
+			)
+			b.StartTimer()
+
+			tx := func(q ActiveAssetsStore) error {
+				return upsert(q, genesisAssets)
+			}
+
+			var writeTxOpts AssetStoreTxOptions
+			err := assetStore.db.ExecTx(ctx, &writeTxOpts, tx)
+			require.NoError(b, err)
+		}
+	}
+
+	b.Run("bulk", func(b *testing.B) {
+		benchmarkUpsert(b, func(q ActiveAssetsStore,
+			genesisAssets []GenesisAsset) error {
+
+			_, err := q.UpsertGenesisAssets(ctx, NewGenesisAssets{
+				Assets:         genesisAssets,
+				MetadataPolicy: int16(MetadataKeepExisting),
+			})
+			return err
+		})
+	})
+
+	b.Run("individual", func(b *testing.B) {
+		benchmarkUpsert(b, func(q ActiveAssetsStore,
+			genesisAssets []GenesisAsset) error {
+
+			for _, genAsset := range genesisAssets {
+				_, err := q.UpsertGenesisAsset(ctx, genAsset)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+// TestFetchAssetsByTag tests that we're able to fetch assets by their tag,
+// both with an exact and a case-insensitive comparison.
+func TestFetchAssetsByTag(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import a few assets whose tags only differ in case, and one
+	// with an unrelated tag.
+	tags := []string{"Gold", "GOLD", "gold", "silver"}
+	assets := make([]*asset.Asset, len(tags))
+	for i, tag := range tags {
+		gen := asset.RandGenesis(t, asset.Normal)
+		gen.Tag = tag
+
+		assets[i] = randAsset(
+			t, withAssetGen(gen),
+			withAssetGenPoint(gen.FirstPrevOut),
+		)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, gen.FirstPrevOut, []*asset.Asset{assets[i]},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	assetTags := func(chainAssets []*ChainAsset) []string {
+		return fMap(chainAssets, func(a *ChainAsset) string {
+			return a.Genesis.Tag
+		})
+	}
+
+	testCases := []struct {
+		tag             string
+		caseInsensitive bool
+		expectedTags    []string
+	}{
+		{
+			tag:          "Gold",
+			expectedTags: []string{"Gold"},
+		},
+		{
+			tag:          "gOLD",
+			expectedTags: []string{},
+		},
+		{
+			tag:             "gOLD",
+			caseInsensitive: true,
+			expectedTags:    []string{"Gold", "GOLD", "gold"},
+		},
+		{
+			tag:             "SILVER",
+			caseInsensitive: true,
+			expectedTags:    []string{"silver"},
+		},
+		{
+			tag:             "copper",
+			caseInsensitive: true,
+			expectedTags:    []string{},
+		},
+	}
+	for _, testCase := range testCases {
+		chainAssets, err := assetStore.FetchAssetsByTag(
+			ctx, testCase.tag, testCase.caseInsensitive,
+		)
+		require.NoError(t, err)
+		require.ElementsMatch(
+			t, testCase.expectedTags, assetTags(chainAssets),
+		)
+	}
+}
+
+// TestTransferAsset tests that transferring an asset archives its old state
+// and inserts its new state atomically.
+func TestTransferAsset(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// importAsset imports a new asset and returns its primary key.
+	importAsset := func() (*asset.Asset, AnchorUTXO, int32) {
+		oldAsset := randAsset(t)
+		anchor := randAnchorUTXO(t)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, oldAsset.Genesis.FirstPrevOut,
+			[]*asset.Asset{oldAsset}, []AnchorUTXO{anchor},
+		)
+		require.NoError(t, err)
+
+		dbAssets, err := db.AllAssets(ctx)
+		require.NoError(t, err)
+
+		return oldAsset, anchor, dbAssets[len(dbAssets)-1].AssetID
+	}
+	oldAsset, oldAnchor, oldAssetID := importAsset()
+
+	// We'll transfer the asset to a new script key in a new anchor. The
+	// new asset doesn't carry any witnesses, so the witness linking it to
+	// the old asset should be added.
+	newAnchorUtxoID, err := upsertAnchorUTXO(
+		ctx, db, newUpsertOptions(), randAnchorUTXO(t),
+	)
+	require.NoError(t, err)
+
+	newAsset := oldAsset.Copy()
+	newAsset.ScriptKey = asset.NewScriptKey(test.RandPubKey(t))
+	newAsset.PrevWitnesses = nil
+
+	err = assetStore.TransferAsset(
+		ctx, oldAssetID, newAsset, newAnchorUtxoID,
+	)
+	require.NoError(t, err)
+	require.Empty(t, newAsset.PrevWitnesses)
+
+	// The old asset should no longer be part of the active asset set,
+	// while the new one should be linked to it.
+	_, err = assetStore.FetchAsset(
+		ctx, oldAsset.ID(), oldAsset.ScriptKey.PubKey,
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+
+	chainAsset, err := assetStore.FetchAsset(
+		ctx, newAsset.ID(), newAsset.ScriptKey.PubKey,
+	)
+	require.NoError(t, err)
+	require.Len(t, chainAsset.PrevWitnesses, 1)
+	require.Equal(t, asset.PrevID{
+		OutPoint:  oldAnchor.OutPoint,
+		ID:        oldAsset.ID(),
+		ScriptKey: asset.ToSerialized(oldAsset.ScriptKey.PubKey),
+	}, *chainAsset.PrevWitnesses[0].PrevID)
+
+	// An asset can only be transferred once, and unknown assets can't be
+	// transferred at all.
+	err = assetStore.TransferAsset(
+		ctx, oldAssetID, randAsset(t), newAnchorUtxoID,
+	)
+	require.ErrorIs(t, err, ErrAssetSpent)
+
+	err = assetStore.TransferAsset(
+		ctx, oldAssetID+100, randAsset(t), newAnchorUtxoID,
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+
+	// Finally, if the new asset can't be inserted because its anchor
+	// doesn't exist, then the old asset shouldn't be archived either.
+	oldAsset, _, oldAssetID = importAsset()
+	newAsset = randAsset(t)
+	err = assetStore.TransferAsset(
+		ctx, oldAssetID, newAsset, newAnchorUtxoID+100,
+	)
+	require.Error(t, err)
+
+	_, err = assetStore.FetchAsset(
+		ctx, oldAsset.ID(), oldAsset.ScriptKey.PubKey,
+	)
+	require.NoError(t, err)
+	_, err = assetStore.FetchAsset(
+		ctx, newAsset.ID(), newAsset.ScriptKey.PubKey,
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 3)
+	require.False(t, dbAssets[2].Spent)
+}
+
+// TestReadersSkipSpentAssets tests that once an asset is transferred, only
+// the new asset is returned by the readers of the active asset set.
+func TestReadersSkipSpentAssets(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	oldAsset := randAsset(t, withAssetGenKeyGroup(test.RandPrivKey(t)))
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, oldAsset.Genesis.FirstPrevOut, []*asset.Asset{oldAsset},
+		[]AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 1)
+
+	// We'll transfer the asset to a new script key in a new anchor, which
+	// leaves the old asset spent.
+	newAnchorUtxoID, err := upsertAnchorUTXO(
+		ctx, db, newUpsertOptions(), randAnchorUTXO(t),
+	)
+	require.NoError(t, err)
+
+	newAsset := oldAsset.Copy()
+	newAsset.ScriptKey = asset.NewScriptKey(test.RandPubKey(t))
+	newAsset.PrevWitnesses = nil
+
+	err = assetStore.TransferAsset(
+		ctx, dbAssets[0].AssetID, newAsset, newAnchorUtxoID,
+	)
+	require.NoError(t, err)
+
+	// requireNewAsset asserts that the only asset returned is the new
+	// one.
+	requireNewAsset := func(t *testing.T, assets []*ChainAsset) {
+		require.Len(t, assets, 1)
+		require.True(t, assets[0].ScriptKey.PubKey.IsEqual(
+			newAsset.ScriptKey.PubKey,
+		))
+	}
+
+	t.Run("confirmation status", func(t *testing.T) {
+		unconfirmed, err := assetStore.FetchAssetsByConfirmationStatus(
+			ctx, false,
+		)
+		require.NoError(t, err)
+		confirmed, err := assetStore.FetchAssetsByConfirmationStatus(
+			ctx, true,
+		)
+		require.NoError(t, err)
+
+		requireNewAsset(t, append(unconfirmed, confirmed...))
+	})
+
+	t.Run("script version", func(t *testing.T) {
+		assets, err := assetStore.FetchAssetsByScriptVersion(
+			ctx, int32(newAsset.ScriptVersion), 10, 0,
+		)
+		require.NoError(t, err)
+		requireNewAsset(t, assets)
+	})
+
+	t.Run("script version and type", func(t *testing.T) {
+		assets, err := assetStore.FetchAssetsByScriptVersionAndType(
+			ctx, int32(newAsset.ScriptVersion), newAsset.Type,
+		)
+		require.NoError(t, err)
+		requireNewAsset(t, assets)
+	})
+
+	t.Run("metadata length", func(t *testing.T) {
+		assets, err := assetStore.FetchAssetsByMetadataLength(
+			ctx, 0, math.MaxInt32,
+		)
+		require.NoError(t, err)
+		requireNewAsset(t, assets)
+	})
+
+	t.Run("group assets paginated", func(t *testing.T) {
+		groupKey := newAsset.GroupKey.GroupPubKey.SerializeCompressed()
+		assets, err := assetStore.FetchGroupAssetsPaginated(
+			ctx, groupKey, 10, 0,
+		)
+		require.NoError(t, err)
+		requireNewAsset(t, assets)
+	})
+
+	t.Run("ordered by amount", func(t *testing.T) {
+		assets, err := assetStore.FetchAssetsOrderedByAmount(
+			ctx, true, 10,
+		)
+		require.NoError(t, err)
+		requireNewAsset(t, assets)
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		assets, err := assetStore.FetchAssetsByTag(
+			ctx, newAsset.Genesis.Tag, false,
+		)
+		require.NoError(t, err)
+		requireNewAsset(t, assets)
+	})
+
+	t.Run("group script key kinds", func(t *testing.T) {
+		groupKey := newAsset.GroupKey.GroupPubKey.SerializeCompressed()
+		withTweak, withoutTweak, err :=
+			assetStore.FetchGroupAssetsScriptKeyKinds(ctx, groupKey)
+		require.NoError(t, err)
+		require.Equal(t, 1, withTweak+withoutTweak)
+	})
+
+	t.Run("amount histogram", func(t *testing.T) {
+		counts, err := assetStore.FetchAmountHistogram(
+			ctx, []uint64{0},
+		)
+		require.NoError(t, err)
+		require.Equal(t, []int{1}, counts)
+	})
+
+	t.Run("anchor utxo asset counts", func(t *testing.T) {
+		counts, err := assetStore.FetchAnchorUtxoAssetCounts(ctx)
+		require.NoError(t, err)
+		require.Equal(t, map[int32]int{newAnchorUtxoID: 1}, counts)
+	})
+
+	t.Run("distinct asset IDs", func(t *testing.T) {
+		ids, err := assetStore.FetchDistinctAssetIDs(ctx)
+		require.NoError(t, err)
+		require.Equal(t, [][32]byte{newAsset.ID()}, ids)
+	})
+
+	t.Run("big amount", func(t *testing.T) {
+		_, err := assetStore.FetchAssetBigAmount(
+			ctx, oldAsset.ID(), oldAsset.ScriptKey.PubKey,
+		)
+		require.ErrorIs(t, err, ErrAssetNotFound)
+
+		amt, err := assetStore.FetchAssetBigAmount(
+			ctx, newAsset.ID(), newAsset.ScriptKey.PubKey,
+		)
+		require.NoError(t, err)
+		require.Equal(t, newAsset.Amount, amt.Uint64())
+	})
+}
+
+// TestFetchGroupsBySupplyRange tests that we're able to find asset groups by
+// their total supply.
+func TestFetchGroupsBySupplyRange(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// importGroup imports a group with one asset for each of the given
+	// amounts, and returns the group key.
+	importGroup := func(amounts ...uint64) *btcec.PublicKey {
+		genesisPoint := test.RandOp(t)
+		assets := make([]*asset.Asset, len(amounts))
+		for i, amt := range amounts {
+			assets[i] = randAsset(
+				t, withAssetGenPoint(genesisPoint),
+				withAssetGenKeyGroup(test.RandPrivKey(t)),
+				withAssetGenAmt(amt),
+			)
+			assets[i].GroupKey = assets[0].GroupKey
+		}
+
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(), genesisPoint, assets, nil,
+		)
+		require.NoError(t, err)
+
+		return &assets[0].GroupKey.GroupPubKey
+	}
+	smallGroup := importGroup(50)
+	mediumGroup := importGroup(100, 200)
+	largeGroup := importGroup(1000)
+
+	// An ungrouped asset should never be returned.
+	ungroupedAsset := randAsset(t, withNoGroupKey(), withAssetGenAmt(500))
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(),
+		ungroupedAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{ungroupedAsset}, nil,
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		minSupply      uint64
+		maxSupply      uint64
+		expectedGroups map[*btcec.PublicKey]uint64
+	}{
+		{
+			minSupply: 200,
+			maxSupply: 1000,
+			expectedGroups: map[*btcec.PublicKey]uint64{
+				mediumGroup: 300,
+				largeGroup:  1000,
+			},
+		},
+		{
+			minSupply: 0,
+			maxSupply: 100,
+			expectedGroups: map[*btcec.PublicKey]uint64{
+				smallGroup: 50,
+			},
+		},
+		{
+			minSupply:      301,
+			maxSupply:      999,
+			expectedGroups: map[*btcec.PublicKey]uint64{},
+		},
+		{
+			minSupply: 0,
+			maxSupply: math.MaxUint64,
+			expectedGroups: map[*btcec.PublicKey]uint64{
+				smallGroup:  50,
+				mediumGroup: 300,
+				largeGroup:  1000,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		groups, err := assetStore.FetchGroupsBySupplyRange(
+			ctx, testCase.minSupply, testCase.maxSupply,
+		)
+		require.NoError(t, err)
+		require.Len(t, groups, len(testCase.expectedGroups))
+
+		for groupKey, supply := range testCase.expectedGroups {
+			var found bool
+			for _, group := range groups {
+				if !group.GroupKey.IsEqual(groupKey) {
+					continue
+				}
+
+				found = true
+				require.Equal(t, supply, group.Balance)
+			}
+			require.True(t, found)
+		}
+	}
+}
+
+// TestFetchAssetAnchorInternalKey tests that the internal key of an asset's
+// anchor output can be fetched again after importing the asset.
+func TestFetchAssetAnchorInternalKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import an anchored asset, and an asset that isn't anchored.
+	anchoredAsset := randAsset(t)
+	anchor := randAnchorUTXO(t)
+	anchor.InternalKey.Family = 212
+	anchor.InternalKey.Index = 9
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, anchoredAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{anchoredAsset}, []AnchorUTXO{anchor},
+	)
+	require.NoError(t, err)
+
+	unanchoredAsset := randAsset(t)
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(),
+		unanchoredAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{unanchoredAsset}, nil,
+	)
+	require.NoError(t, err)
+
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 2)
+
+	// The internal key of the anchored asset should round trip, including
+	// its key locator.
+	internalKey, err := assetStore.FetchAssetAnchorInternalKey(
+		ctx, dbAssets[0].AssetID,
+	)
+	require.NoError(t, err)
+	require.True(t, anchor.InternalKey.PubKey.IsEqual(internalKey.PubKey))
+	require.Equal(t, anchor.InternalKey.KeyLocator, internalKey.KeyLocator)
+
+	// Unanchored and unknown assets don't have an anchor internal key.
+	_, err = assetStore.FetchAssetAnchorInternalKey(
+		ctx, dbAssets[1].AssetID,
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+
+	_, err = assetStore.FetchAssetAnchorInternalKey(
+		ctx, dbAssets[1].AssetID+100,
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+}
+
+// TestFetchAssetCommitmentLeaf tests that the commitment leaf of an asset
+// reconstructed from the database matches the leaf of the original asset.
+func TestFetchAssetCommitmentLeaf(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import a grouped asset that's anchored, and an asset that
+	// isn't anchored.
+	anchoredAsset := randAsset(
+		t, withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, anchoredAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{anchoredAsset}, []AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	unanchoredAsset := randAsset(t)
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(),
+		unanchoredAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{unanchoredAsset}, nil,
+	)
+	require.NoError(t, err)
+
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 2)
+
+	// The leaf of the reconstructed asset should be identical to the leaf
+	// of the asset we imported.
+	expectedLeaf, err := anchoredAsset.Leaf()
+	require.NoError(t, err)
+
+	leafValue, err := assetStore.FetchAssetCommitmentLeaf(
+		ctx, dbAssets[0].AssetID,
+	)
+	require.NoError(t, err)
+	require.Equal(t, expectedLeaf.Value, leafValue)
+
+	leaf := mssmt.NewLeafNode(leafValue, anchoredAsset.Amount)
+	require.Equal(t, expectedLeaf.NodeHash(), leaf.NodeHash())
+
+	// Unanchored and unknown assets can't be reconstructed.
+	_, err = assetStore.FetchAssetCommitmentLeaf(ctx, dbAssets[1].AssetID)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+
+	_, err = assetStore.FetchAssetCommitmentLeaf(
+		ctx, dbAssets[1].AssetID+100,
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+}
+
+// TestFetchAssetsByMetadataType tests that we're able to fetch assets by the
+// type of their metadata.
+func TestFetchAssetsByMetadataType(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import three assets, which all start out with opaque
+	// metadata.
+	assets := make([]*asset.Asset, 3)
+	for i := range assets {
+		assets[i] = randAsset(t)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, assets[i].Genesis.FirstPrevOut,
+			[]*asset.Asset{assets[i]},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	assetIDs := func(metaType MetaType) []asset.ID {
+		chainAssets, err := assetStore.FetchAssetsByMetadataType(
+			ctx, metaType,
+		)
+		require.NoError(t, err)
+
+		return fMap(chainAssets, func(a *ChainAsset) asset.ID {
+			return a.ID()
+		})
+	}
+	require.Len(t, assetIDs(MetaOpaque), 3)
+	require.Empty(t, assetIDs(MetaImage))
+
+	// Once we mark two of them as images, only the remaining asset
+	// should be returned as opaque.
+	for _, imageAsset := range assets[:2] {
+		err := assetStore.SetAssetMetaType(
+			ctx, imageAsset.ID(), MetaImage,
+		)
+		require.NoError(t, err)
+	}
+	require.ElementsMatch(
+		t, []asset.ID{assets[0].ID(), assets[1].ID()},
+		assetIDs(MetaImage),
+	)
+	require.Equal(t, []asset.ID{assets[2].ID()}, assetIDs(MetaOpaque))
+	require.Empty(t, assetIDs(MetaJSON))
+
+	// Setting the type of an unknown asset should fail.
+	err := assetStore.SetAssetMetaType(ctx, asset.RandID(t), MetaJSON)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+}
+
+// TestFetchAssetsByMetadataJSONField tests that we're able to fetch assets
+// by a field of their JSON metadata.
+func TestFetchAssetsByMetadataJSONField(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import two assets with JSON metadata, and a third one with
+	// the same metadata that isn't typed as JSON.
+	jsonMetadata := [][]byte{
+		[]byte(`{"name":"gold","attrs":{"weight":42,"tags":["a"` +
+			`,"b"]}}`),
+		[]byte(`{"name":"silver","attrs":{"weight":7}}`),
+		[]byte(`{"name":"gold"}`),
+	}
+	assets := make([]*asset.Asset, len(jsonMetadata))
+	for i, metadata := range jsonMetadata {
+		gen := asset.RandGenesis(t, asset.Normal)
+		gen.Metadata = metadata
+		assets[i] = randAsset(t, withAssetGen(gen))
+
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, assets[i].Genesis.FirstPrevOut,
+			[]*asset.Asset{assets[i]},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+	for _, jsonAsset := range assets[:2] {
+		err := assetStore.SetAssetMetaType(
+			ctx, jsonAsset.ID(), MetaJSON,
+		)
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name  string
+		path  string
+		value string
+		ids   []asset.ID
+	}{
+		{
+			name:  "top level string",
+			path:  "name",
+			value: "gold",
+			ids:   []asset.ID{assets[0].ID()},
+		},
+		{
+			name:  "nested number",
+			path:  "attrs.weight",
+			value: "7",
+			ids:   []asset.ID{assets[1].ID()},
+		},
+		{
+			name:  "array element",
+			path:  "attrs.tags.1",
+			value: "b",
+			ids:   []asset.ID{assets[0].ID()},
+		},
+		{
+			name:  "no match",
+			path:  "name",
+			value: "bronze",
+		},
+		{
+			name:  "missing field",
+			path:  "attrs.color",
+			value: "gold",
+		},
+	}
+	for _, testCase := range testCases {
+		chainAssets, err := assetStore.FetchAssetsByMetadataJSONField(
+			ctx, testCase.path, testCase.value,
+		)
+		require.NoError(t, err, testCase.name)
+
+		ids := fMap(chainAssets, func(a *ChainAsset) asset.ID {
+			return a.ID()
+		})
+		require.ElementsMatch(t, testCase.ids, ids, testCase.name)
+	}
+
+	// An empty path can't match any field.
+	_, err := assetStore.FetchAssetsByMetadataJSONField(ctx, "", "gold")
+	require.Error(t, err)
+
+	// Metadata that isn't valid JSON can't be typed as JSON.
+	gen := asset.RandGenesis(t, asset.Normal)
+	gen.Metadata = []byte(`{"name":`)
+	invalidAsset := randAsset(t, withAssetGen(gen))
+	err = assetStore.ImportAssetsWithAnchors(
+		ctx, invalidAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{invalidAsset}, []AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	err = assetStore.SetAssetMetaType(ctx, invalidAsset.ID(), MetaJSON)
+	require.ErrorIs(t, err, ErrInvalidJSONMetadata)
+}
+
+// TestFetchGroupedAssetsWithoutSig tests that we're able to detect assets
+// that are part of an asset group, but don't reference a group sig.
+func TestFetchGroupedAssetsWithoutSig(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll start by importing a grouped asset, and an asset without a
+	// group key.
+	groupedAsset := randAsset(t, withAssetGenKeyGroup(test.RandPrivKey(t)))
+	plainAsset := randAsset(t, withNoGroupKey())
+	for _, a := range []*asset.Asset{groupedAsset, plainAsset} {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	// All of these assets are valid, so no asset should be returned.
+	unsignedAssets, err := assetStore.FetchGroupedAssetsWithoutSig(ctx)
+	require.NoError(t, err)
+	require.Empty(t, unsignedAssets)
+
+	// We'll now create an anomaly by inserting another asset of the
+	// grouped genesis, anchored in the same UTXO, that doesn't reference
+	// the group sig.
+	genAssetID, err := db.FetchGenesisAssetIDByTag(
+		ctx, groupedAsset.Genesis.Tag,
+	)
+	require.NoError(t, err)
+
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+
+	var anchorUtxoID sql.NullInt32
+	for _, dbAsset := range dbAssets {
+		if dbAsset.GenesisID == genAssetID {
+			anchorUtxoID = dbAsset.AnchorUtxoID
+		}
+	}
+	require.True(t, anchorUtxoID.Valid)
+
+	corruptAsset := randAsset(t, withNoGroupKey())
+	scriptKeyID, err := upsertScriptKey(
+		ctx, corruptAsset.ScriptKey, db, nil,
+	)
+	require.NoError(t, err)
+
+	_, err = db.InsertNewAsset(ctx, sqlc.InsertNewAssetParams{
+		GenesisID:     genAssetID,
+		ScriptKeyID:   scriptKeyID,
+		ScriptVersion: int32(corruptAsset.ScriptVersion),
+		Amount:        int64(corruptAsset.Amount),
+		AnchorUtxoID:  anchorUtxoID,
+	})
+	require.NoError(t, err)
+
+	// Only the corrupted asset should now be returned.
+	unsignedAssets, err = assetStore.FetchGroupedAssetsWithoutSig(ctx)
+	require.NoError(t, err)
+	require.Len(t, unsignedAssets, 1)
+	require.Equal(t, groupedAsset.ID(), unsignedAssets[0].ID())
+	require.Equal(
+		t, corruptAsset.ScriptKey.PubKey.SerializeCompressed(),
+		unsignedAssets[0].ScriptKey.PubKey.SerializeCompressed(),
+	)
+}
+
+// tapscriptScriptKey returns a random script key that commits to the given
+// tapscript root.
+func tapscriptScriptKey(t *testing.T, root []byte) asset.ScriptKey {
+	rawKey := keychain.KeyDescriptor{
+		PubKey: test.RandPubKey(t),
+		KeyLocator: keychain.KeyLocator{
+			Family: test.RandInt[keychain.KeyFamily](),
+			Index:  uint32(test.RandInt[int32]()),
+		},
+	}
+
+	return asset.ScriptKey{
+		PubKey: txscript.ComputeTaprootOutputKey(rawKey.PubKey, root),
+		TweakedScriptKey: &asset.TweakedScriptKey{
+			RawKey: rawKey,
+			Tweak:  root,
+		},
+	}
+}
+
+// TestFetchAssetsByTapscriptRoot tests that we're able to fetch script-path
+// assets by the tapscript root their script key commits to.
+func TestFetchAssetsByTapscriptRoot(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import two assets that commit to the same tapscript root, one
+	// that commits to another root, and a BIP 86 asset.
+	root := test.RandBytes(32)
+	otherRoot := test.RandBytes(32)
+	assets := []*asset.Asset{
+		randAsset(t, withScriptKey(tapscriptScriptKey(t, root))),
+		randAsset(t, withScriptKey(tapscriptScriptKey(t, root))),
+		randAsset(t, withScriptKey(tapscriptScriptKey(t, otherRoot))),
+		randAsset(t),
+	}
+	for _, a := range assets {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	scriptKeys := func(root []byte) [][]byte {
+		chainAssets, err := assetStore.FetchAssetsByTapscriptRoot(
+			ctx, root,
+		)
+		require.NoError(t, err)
+
+		return fMap(chainAssets, func(a *ChainAsset) []byte {
+			require.Equal(t, root, a.ScriptKey.Tweak)
+			return a.ScriptKey.PubKey.SerializeCompressed()
+		})
+	}
+	require.Equal(t, [][]byte{
+		assets[0].ScriptKey.PubKey.SerializeCompressed(),
+		assets[1].ScriptKey.PubKey.SerializeCompressed(),
+	}, scriptKeys(root))
+	require.Equal(t, [][]byte{
+		assets[2].ScriptKey.PubKey.SerializeCompressed(),
+	}, scriptKeys(otherRoot))
+	require.Empty(t, scriptKeys(test.RandBytes(32)))
+
+	// A root of an invalid length should be rejected.
+	_, err := assetStore.FetchAssetsByTapscriptRoot(ctx, root[:31])
+	require.Error(t, err)
+}
+
+// TestFetchGenesisPointByAssetID tests that we're able to look up the genesis
+// point that minted an asset by its asset ID.
+func TestFetchGenesisPointByAssetID(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import two assets of the same genesis point, and one of
+	// another genesis point.
+	genesisPoint := test.RandOp(t)
+	assets := []*asset.Asset{
+		randAsset(t, withAssetGenPoint(genesisPoint)),
+		randAsset(t, withAssetGenPoint(genesisPoint)),
+		randAsset(t),
+	}
+	for _, a := range assets {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	for _, a := range assets {
+		dbGenesisPoint, err := assetStore.FetchGenesisPointByAssetID(
+			ctx, a.ID(),
+		)
+		require.NoError(t, err)
+		require.Equal(t, a.Genesis.FirstPrevOut, dbGenesisPoint)
+	}
+
+	// An unknown asset ID should result in an error.
+	_, err := assetStore.FetchGenesisPointByAssetID(ctx, asset.RandID(t))
+	require.ErrorIs(t, err, ErrAssetNotFound)
+}
+
+// TestFetchDuplicateOutputIndices tests that we're able to fetch the genesis
+// assets of a genesis point that share their output index with another one.
+func TestFetchDuplicateOutputIndices(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// insertGenesisAssets inserts a genesis asset with each of the given
+	// output indexes for a new genesis point, and returns the ID of the
+	// genesis point along with the inserted genesis assets.
+	insertGenesisAssets := func(
+		outputIndexes ...uint32) (int32, []asset.Genesis) {
+
+		genesisPoint := test.RandOp(t)
+		genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+		require.NoError(t, err)
+
+		gens := make([]asset.Genesis, len(outputIndexes))
+		genesisAssets := make([]GenesisAsset, len(outputIndexes))
+		for i, outputIndex := range outputIndexes {
+			gens[i] = asset.RandGenesis(t, asset.Normal)
+			gens[i].FirstPrevOut = genesisPoint
+			gens[i].OutputIndex = outputIndex
+
+			genesisAssets[i] = newGenesisAsset(
+				genesisPointID, gens[i], MetadataKeepExisting,
+			)
+		}
+
+		require.NoError(t, upsertMetaReveals(ctx, db, gens...))
+		_, err = assetStore.UpsertGenesisAssets(
+			ctx, genesisAssets, MetadataKeepExisting,
+		)
+		require.NoError(t, err)
+
+		return genesisPointID, gens
+	}
+
+	// The first genesis point has two assets that share output index 0,
+	// the second one has unique output indexes only.
+	dupGenesisPointID, dupGens := insertGenesisAssets(1, 0, 2, 0)
+	uniqueGenesisPointID, _ := insertGenesisAssets(0, 1, 2)
+
+	duplicates, err := assetStore.FetchDuplicateOutputIndices(
+		ctx, dupGenesisPointID,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []asset.Genesis{dupGens[1], dupGens[3]}, duplicates)
+
+	duplicates, err = assetStore.FetchDuplicateOutputIndices(
+		ctx, uniqueGenesisPointID,
+	)
+	require.NoError(t, err)
+	require.Empty(t, duplicates)
+}
+
+// TestFetchGroupAssetsByAmountRange tests that we're able to fetch the assets
+// of an asset group with an amount within a given range.
+func TestFetchGroupAssetsByAmountRange(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// groupAssets creates a set of assets of the same asset group, one
+	// with each of the given amounts.
+	groupAssets := func(amts ...uint64) []*asset.Asset {
+		gen := asset.RandGenesis(t, asset.Normal)
+		groupPriv := test.RandPrivKey(t)
+
+		return fMap(amts, func(amt uint64) *asset.Asset {
+			return randAsset(
+				t, withAssetGen(gen),
+				withAssetGenPoint(gen.FirstPrevOut),
+				withAssetGenKeyGroup(groupPriv),
+				withAssetGenAmt(amt),
+			)
+		})
+	}
+
+	// We'll import the assets of two groups with overlapping amounts. The
+	// amounts are imported out of order, to make sure the assets are
+	// returned ordered by their amount.
+	assets := groupAssets(40, 20, 10, 30)
+	otherAssets := groupAssets(20, 30)
+	for _, a := range append(assets, otherAssets...) {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	groupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+	fetchAmounts := func(minAmt, maxAmt uint64) []uint64 {
+		chainAssets, err := assetStore.FetchGroupAssetsByAmountRange(
+			ctx, groupKey, minAmt, maxAmt,
+		)
+		require.NoError(t, err)
+
+		return fMap(chainAssets, func(a *ChainAsset) uint64 {
+			require.Equal(
+				t, assets[0].GroupKey.GroupPubKey,
+				a.GroupKey.GroupPubKey,
+			)
+			return a.Amount
+		})
+	}
+
+	require.Equal(t, []uint64{20, 30}, fetchAmounts(15, 30))
+	require.Equal(t, []uint64{10, 20, 30, 40}, fetchAmounts(0, 40))
+	require.Equal(
+		t, []uint64{30, 40}, fetchAmounts(30, math.MaxUint64),
+	)
+	require.Empty(t, fetchAmounts(50, 60))
+	require.Empty(t, fetchAmounts(30, 20))
+	require.Empty(t, fetchAmounts(math.MaxUint64, math.MaxUint64))
+
+	// An unknown group key should result in no assets.
+	chainAssets, err := assetStore.FetchGroupAssetsByAmountRange(
+		ctx, test.RandPubKey(t).SerializeCompressed(), 0, 40,
+	)
+	require.NoError(t, err)
+	require.Empty(t, chainAssets)
+}
+
+// TestArchiveAssetsByIDs tests that we can archive a subset of the assets by
+// their primary keys, while the rest of them remain active.
+func TestArchiveAssetsByIDs(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	const numAssets = 5
+	for i := 0; i < numAssets; i++ {
+		newAsset := randAsset(t)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, newAsset.Genesis.FirstPrevOut,
+			[]*asset.Asset{newAsset},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	dbAssets, err := db.QueryAssets(ctx, QueryAssetFilters{})
+	require.NoError(t, err)
+	require.Len(t, dbAssets, numAssets)
+
+	activeScriptKeys := func() []string {
+		chainAssets, err := assetStore.FetchAllAssets(ctx, nil)
+		require.NoError(t, err)
+
+		return fMap(chainAssets, func(a *ChainAsset) string {
+			return string(a.ScriptKey.PubKey.SerializeCompressed())
+		})
+	}
+	require.Len(t, activeScriptKeys(), numAssets)
+
+	// We'll archive the first and the third asset, along with a primary
+	// key that doesn't exist, which should be ignored.
+	archivedIDs := []int32{
+		dbAssets[0].AssetPrimaryKey, dbAssets[2].AssetPrimaryKey,
+		dbAssets[numAssets-1].AssetPrimaryKey + 100,
+	}
+	numArchived, err := assetStore.ArchiveAssetsByIDs(ctx, archivedIDs)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, numArchived)
+
+	active := activeScriptKeys()
+	require.Len(t, active, numAssets-2)
+	for i, dbAsset := range dbAssets {
+		scriptKey := string(dbAsset.TweakedScriptKey)
+		if i == 0 || i == 2 {
+			require.NotContains(t, active, scriptKey)
+			continue
+		}
+		require.Contains(t, active, scriptKey)
+	}
+
+	// Archiving the same assets again is a no-op, as is archiving no
+	// assets at all.
+	numArchived, err = assetStore.ArchiveAssetsByIDs(ctx, archivedIDs)
+	require.NoError(t, err)
+	require.Zero(t, numArchived)
+
+	numArchived, err = assetStore.ArchiveAssetsByIDs(ctx, nil)
+	require.NoError(t, err)
+	require.Zero(t, numArchived)
+	require.Len(t, activeScriptKeys(), numAssets-2)
+}
+
+// TestTouchAssets tests that touching a set of assets only bumps their update
+// time, and leaves all other assets and fields untouched.
+func TestTouchAssets(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	const numAssets = 3
+	for i := 0; i < numAssets; i++ {
+		newAsset := randAsset(t)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, newAsset.Genesis.FirstPrevOut,
+			[]*asset.Asset{newAsset},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	// Initially, none of the assets were ever touched.
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, numAssets)
+	for _, dbAsset := range dbAssets {
+		require.False(t, dbAsset.UpdatedAt.Valid)
+	}
+
+	// We'll touch the first and the last asset, along with a primary key
+	// that doesn't exist, which should be ignored.
+	touchedIDs := []int32{
+		dbAssets[0].AssetID, dbAssets[numAssets-1].AssetID,
+		dbAssets[numAssets-1].AssetID + 100,
+	}
+	require.NoError(t, assetStore.TouchAssets(ctx, touchedIDs))
+
+	touchedAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, touchedAssets, numAssets)
+	for i, touchedAsset := range touchedAssets {
+		wasTouched := i == 0 || i == numAssets-1
+		require.Equal(t, wasTouched, touchedAsset.UpdatedAt.Valid)
+
+		// Apart from the update time, the assets should be unchanged.
+		touchedAsset.UpdatedAt = sql.NullTime{}
+		require.Equal(t, dbAssets[i], touchedAsset)
+	}
+
+	// Touching no assets at all is a no-op.
+	require.NoError(t, assetStore.TouchAssets(ctx, nil))
+}
+
+// TestFetchGroupSizes tests that we count the unspent assets of each asset
+// group.
+func TestFetchGroupSizes(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// Without any assets, there are no groups.
+	groupSizes, err := assetStore.FetchGroupSizes(ctx)
+	require.NoError(t, err)
+	require.Empty(t, groupSizes)
+
+	// We'll create a large group with assets of several geneses, a group
+	// with a single asset and an asset without a group.
+	const numLargeGroup = 4
+	genesisPoint := test.RandOp(t)
+	groupPriv := test.RandPrivKey(t)
+	var assets []*asset.Asset
+	for i := 0; i < numLargeGroup; i++ {
+		newAsset := randAsset(
+			t, withAssetGen(asset.RandGenesis(t, asset.Normal)),
+			withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		)
+
+		// The group key is tweaked with the genesis of the asset, so
+		// we'll re-use the group key of the first asset to have all
+		// assets join the same group.
+		if i > 0 {
+			groupKey := *assets[0].GroupKey
+			newAsset.GroupKey = &groupKey
+		}
+
+		assets = append(assets, newAsset)
+	}
+	singletonAsset := randAsset(
+		t, withAssetGenPoint(genesisPoint),
+		withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	assets = append(
+		assets, singletonAsset,
+		randAsset(t, withAssetGenPoint(genesisPoint), withNoGroupKey()),
+	)
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), genesisPoint, assets, nil,
+	)
+	require.NoError(t, err)
+
+	groupKey := func(a *asset.Asset) string {
+		return hex.EncodeToString(
+			a.GroupKey.GroupPubKey.SerializeCompressed(),
+		)
+	}
+	groupSizes, err = assetStore.FetchGroupSizes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{
+		groupKey(assets[0]):      numLargeGroup,
+		groupKey(singletonAsset): 1,
+	}, groupSizes)
+
+	// Spent assets no longer count towards the size of their group.
+	largeGroupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+	dbAssets, err := db.QueryAssets(ctx, QueryAssetFilters{
+		KeyGroupFilter:    largeGroupKey,
+		IncludeUnanchored: sqlBool(true),
+	})
+	require.NoError(t, err)
+	_, err = assetStore.ArchiveAssetsByIDs(
+		ctx, []int32{dbAssets[0].AssetPrimaryKey},
+	)
+	require.NoError(t, err)
+
+	groupSizes, err = assetStore.FetchGroupSizes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, numLargeGroup-1, groupSizes[groupKey(assets[0])])
+}
+
+// TestFetchReissuances tests that the genesis asset that first emitted an
+// asset group is told apart from the reissuances of the group.
+func TestFetchReissuances(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll first emit a new asset group, followed by two reissuances of
+	// the group, each of them from a new genesis point.
+	const numReissuances = 2
+	groupPriv := test.RandPrivKey(t)
+	var assets []*asset.Asset
+	for i := 0; i <= numReissuances; i++ {
+		genesisPoint := test.RandOp(t)
+		newAsset := randAsset(
+			t, withAssetGen(asset.RandGenesis(t, asset.Normal)),
+			withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		)
+
+		// The group key is tweaked with the genesis of the asset, so
+		// we'll re-use the group key of the first asset to have all
+		// assets join the same group.
+		if i > 0 {
+			groupKey := *assets[0].GroupKey
+			newAsset.GroupKey = &groupKey
+		}
+
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(), genesisPoint,
+			[]*asset.Asset{newAsset}, nil,
+		)
+		require.NoError(t, err)
+
+		assets = append(assets, newAsset)
+	}
+
+	// Re-importing the initial emission shouldn't turn it into a
+	// reissuance.
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), assets[0].Genesis.FirstPrevOut,
+		assets[:1], nil,
+	)
+	require.NoError(t, err)
+
+	isReissuance := func(a *asset.Asset) bool {
+		dbGen, err := db.FetchGenesisAssetByTag(ctx, a.Genesis.Tag)
+		require.NoError(t, err)
+
+		return dbGen.IsReissuance
+	}
+	require.False(t, isReissuance(assets[0]))
+	for _, reissuedAsset := range assets[1:] {
+		require.True(t, isReissuance(reissuedAsset))
+	}
+
+	// Only the reissuances should be returned for the group, in the order
+	// they were issued in.
+	groupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+	reissuances, err := assetStore.FetchReissuances(ctx, groupKey)
+	require.NoError(t, err)
+	require.Equal(t, []asset.Genesis{
+		assets[1].Genesis, assets[2].Genesis,
+	}, reissuances)
+
+	// A group without any reissuances results in an empty list.
+	singletonAsset := randAsset(
+		t, withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(),
+		singletonAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{singletonAsset}, nil,
+	)
+	require.NoError(t, err)
+	require.False(t, isReissuance(singletonAsset))
+
+	reissuances, err = assetStore.FetchReissuances(
+		ctx, singletonAsset.GroupKey.GroupPubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+	require.Empty(t, reissuances)
+}
+
+// TestBackfillReissuances tests that the reissuance flag of genesis assets is
+// backfilled by the migration that adds it, and kept up to date when the
+// group sig of a genesis asset is only stored later on.
+func TestBackfillReissuances(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll emit a new asset group, followed by a reissuance of the group.
+	groupPriv := test.RandPrivKey(t)
+	var assets []*asset.Asset
+	for i := 0; i < 2; i++ {
+		genesisPoint := test.RandOp(t)
+		newAsset := randAsset(
+			t, withAssetGen(asset.RandGenesis(t, asset.Normal)),
+			withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		)
+		if i > 0 {
+			groupKey := *assets[0].GroupKey
+			newAsset.GroupKey = &groupKey
+		}
+
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(), genesisPoint,
+			[]*asset.Asset{newAsset}, nil,
+		)
+		require.NoError(t, err)
+
+		assets = append(assets, newAsset)
+	}
+
+	isReissuance := func(a *asset.Asset) bool {
+		dbGen, err := db.FetchGenesisAssetByTag(ctx, a.Genesis.Tag)
+		require.NoError(t, err)
+
+		return dbGen.IsReissuance
+	}
+
+	// Genesis assets stored before the flag was added all default to not
+	// being a reissuance, which we'll simulate by clearing the flag.
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+	_, err := rawDB.ExecContext(
+		ctx, "UPDATE genesis_assets SET is_reissuance = FALSE",
+	)
+	require.NoError(t, err)
+	require.False(t, isReissuance(assets[1]))
+
+	// Running the migration should restore the flag of the reissuance
+	// only.
+	migration, err := sqlSchemas.ReadFile(
+		"sqlc/migrations/000023_backfill_reissuance.up.sql",
+	)
+	require.NoError(t, err)
+	_, err = rawDB.ExecContext(ctx, string(migration))
+	require.NoError(t, err)
+
+	require.False(t, isReissuance(assets[0]))
+	require.True(t, isReissuance(assets[1]))
+
+	// We'll now store another reissuance of the group without its group
+	// sig, so it can't be told apart from the initial emission yet.
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	reissuedAsset := randAsset(
+		t, withAssetGen(asset.RandGenesis(t, asset.Normal)),
+		withAssetGenPoint(genesisPoint),
+		withAssetGenKeyGroup(groupPriv),
+	)
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID,
+		reissuedAsset.Genesis, MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	groupKeyIDs, err := upsertGroupKey(
+		ctx, assets[0].GroupKey, db, genesisPointID, genAssetID, nil,
+		true,
+	)
+	require.NoError(t, err)
+	require.False(t, isReissuance(reissuedAsset))
+
+	// Once the sig is backfilled, the genesis asset should be marked as a
+	// reissuance.
+	groupSigID, err := upsertGroupSig(
+		ctx, db, assets[0].GroupKey.Sig.Serialize(),
+		groupKeyIDs.groupID, genAssetID,
+	)
+	require.NoError(t, err)
+	require.True(t, isReissuance(reissuedAsset))
+
+	// Storing the group key without its sig again should resolve to the
+	// backfilled sig and keep the flag.
+	newGroupKeyIDs, err := upsertGroupKey(
+		ctx, assets[0].GroupKey, db, genesisPointID, genAssetID, nil,
+		true,
+	)
+	require.NoError(t, err)
+	require.Equal(t, sqlInt32(groupSigID), newGroupKeyIDs.groupSigID)
+	require.True(t, isReissuance(reissuedAsset))
+}
+
+// TestFetchAssetsByGroupKey tests that we can fetch the assets of all asset
+// IDs of an asset group, with or without the spent ones.
+func TestFetchAssetsByGroupKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create a group with assets of three different geneses, and an
+	// asset outside the group.
+	const numGroupAssets = 3
+	genesisPoint := test.RandOp(t)
+	groupPriv := test.RandPrivKey(t)
+	var assets []*asset.Asset
+	for i := 0; i < numGroupAssets; i++ {
+		newAsset := randAsset(
+			t, withAssetGen(asset.RandGenesis(t, asset.Normal)),
+			withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		)
+
+		// The group key is tweaked with the genesis of the asset, so
+		// we'll re-use the group key of the first asset to have all
+		// assets join the same group.
+		if i > 0 {
+			groupKey := *assets[0].GroupKey
+			newAsset.GroupKey = &groupKey
+		}
+
+		assets = append(assets, newAsset)
+	}
+	assets = append(assets, randAsset(
+		t, withAssetGenPoint(genesisPoint), withNoGroupKey(),
+	))
+
+	anchors := make([]AnchorUTXO, len(assets))
+	for i := range anchors {
+		anchors[i] = randAnchorUTXO(t)
+	}
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors,
+	)
+	require.NoError(t, err)
+
+	toScriptKey := func(a *asset.Asset) asset.SerializedKey {
+		return asset.ToSerialized(a.ScriptKey.PubKey)
+	}
+	groupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+	fetchScriptKeys := func(includeSpent bool) []asset.SerializedKey {
+		groupAssets, err := assetStore.FetchAssetsByGroupKey(
+			ctx, groupKey, includeSpent,
+		)
+		require.NoError(t, err)
+
+		return fMap(groupAssets, toScriptKey)
+	}
+	groupScriptKeys := fMap(assets[:numGroupAssets], toScriptKey)
+	require.Equal(t, groupScriptKeys, fetchScriptKeys(false))
+	require.Equal(t, groupScriptKeys, fetchScriptKeys(true))
+
+	// We'll now mark the first asset of the group as spent, which should
+	// only be returned if we include spent assets.
+	dbAssets, err := db.QueryAssets(ctx, QueryAssetFilters{
+		KeyGroupFilter:    groupKey,
+		IncludeSpent:      sqlBool(true),
+		IncludeUnanchored: sqlBool(true),
+	})
+	require.NoError(t, err)
+	require.Len(t, dbAssets, numGroupAssets)
+
+	_, err = db.SetAssetSpent(ctx, dbAssets[0].AssetPrimaryKey)
+	require.NoError(t, err)
+
+	require.Equal(t, groupScriptKeys[1:], fetchScriptKeys(false))
+	require.Equal(t, groupScriptKeys, fetchScriptKeys(true))
+
+	// An unknown group key should result in no assets.
+	groupAssets, err := assetStore.FetchAssetsByGroupKey(
+		ctx, test.RandPubKey(t).SerializeCompressed(), true,
+	)
+	require.NoError(t, err)
+	require.Empty(t, groupAssets)
+}
+
+// TestFetchAssetByScriptKeyAndGroup tests that an asset UTXO can be resolved
+// by its script key and group key, even if the script key is shared with
+// assets of other groups.
+func TestFetchAssetByScriptKeyAndGroup(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll create two groups, each with an asset of the same script key.
+	// The first group also gets a second asset with another script key.
+	genesisPoint := test.RandOp(t)
+	groupAsset := randAsset(
+		t, withAssetGenPoint(genesisPoint),
+		withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	scriptKey := groupAsset.ScriptKey
+	otherGroupAsset := randAsset(
+		t, withAssetGenPoint(genesisPoint),
+		withAssetGenKeyGroup(test.RandPrivKey(t)),
+		withScriptKey(scriptKey),
+	)
+	siblingAsset := randAsset(
+		t, withAssetGen(asset.RandGenesis(t, asset.Normal)),
+		withAssetGenPoint(genesisPoint),
+	)
+	groupKeyCopy := *groupAsset.GroupKey
+	siblingAsset.GroupKey = &groupKeyCopy
+
+	assets := []*asset.Asset{groupAsset, otherGroupAsset, siblingAsset}
+	anchors := make([]AnchorUTXO, len(assets))
+	for i := range anchors {
+		anchors[i] = randAnchorUTXO(t)
+	}
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors,
+	)
+	require.NoError(t, err)
+
+	scriptKeyBytes := scriptKey.PubKey.SerializeCompressed()
+	groupKey := func(a *asset.Asset) []byte {
+		return a.GroupKey.GroupPubKey.SerializeCompressed()
+	}
+
+	// The shared script key should resolve to a different asset for each
+	// of the groups.
+	for i, groupedAsset := range assets[:2] {
+		chainAsset, err := assetStore.FetchAssetByScriptKeyAndGroup(
+			ctx, scriptKeyBytes, groupKey(groupedAsset),
+		)
+		require.NoError(t, err)
+		require.Equal(t, groupedAsset.ID(), chainAsset.ID())
+		require.Equal(
+			t, anchors[i].OutPoint, chainAsset.AnchorOutpoint,
+		)
+	}
+
+	// A script key that isn't used within the group shouldn't resolve to
+	// any asset.
+	_, err = assetStore.FetchAssetByScriptKeyAndGroup(
+		ctx, siblingAsset.ScriptKey.PubKey.SerializeCompressed(),
+		groupKey(otherGroupAsset),
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+
+	// If we add another asset with the same script key to the first
+	// group, the script key no longer identifies a single asset UTXO.
+	newGenesisPoint := test.RandOp(t)
+	ambiguousAsset := randAsset(
+		t, withAssetGen(asset.RandGenesis(t, asset.Normal)),
+		withAssetGenPoint(newGenesisPoint), withScriptKey(scriptKey),
+	)
+	ambiguousAsset.GroupKey = &groupKeyCopy
+	err = assetStore.ImportAssetsWithAnchors(
+		ctx, newGenesisPoint, []*asset.Asset{ambiguousAsset},
+		[]AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	_, err = assetStore.FetchAssetByScriptKeyAndGroup(
+		ctx, scriptKeyBytes, groupKey(groupAsset),
+	)
+	require.ErrorIs(t, err, ErrAmbiguousAsset)
+
+	// Once the first asset is spent, only the new asset is left.
+	dbAssets, err := db.QueryAssets(ctx, QueryAssetFilters{
+		KeyGroupFilter:    groupKey(groupAsset),
+		IncludeUnanchored: sqlBool(true),
+		ScriptKeyFilter:   scriptKeyBytes,
+	})
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 2)
+
+	_, err = db.SetAssetSpent(ctx, dbAssets[0].AssetPrimaryKey)
+	require.NoError(t, err)
+
+	chainAsset, err := assetStore.FetchAssetByScriptKeyAndGroup(
+		ctx, scriptKeyBytes, groupKey(groupAsset),
+	)
+	require.NoError(t, err)
+	require.Equal(t, ambiguousAsset.ID(), chainAsset.ID())
+}
+
+// TestFetchSpendableAssetsByGroup tests that we select the largest unspent
+// assets of an asset group until they cover the requested amount.
+func TestFetchSpendableAssetsByGroup(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	gen := asset.RandGenesis(t, asset.Normal)
+	groupPriv := test.RandPrivKey(t)
+	assets := fMap([]uint64{10, 40, 20, 30}, func(amt uint64) *asset.Asset {
+		return randAsset(
+			t, withAssetGen(gen),
+			withAssetGenPoint(gen.FirstPrevOut),
+			withAssetGenKeyGroup(groupPriv), withAssetGenAmt(amt),
+		)
+	})
+	for _, a := range assets {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	groupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+	fetchAmounts := func(minTotal uint64) ([]uint64, error) {
+		selected, err := assetStore.FetchSpendableAssetsByGroup(
+			ctx, groupKey, minTotal,
+		)
+		return fMap(selected, func(a *asset.Asset) uint64 {
+			return a.Amount
+		}), err
+	}
+
+	testCases := []struct {
+		name     string
+		minTotal uint64
+		amounts  []uint64
+		err      error
+	}{
+		{
+			name:     "nothing requested",
+			minTotal: 0,
+		},
+		{
+			name:     "single asset",
+			minTotal: 40,
+			amounts:  []uint64{40},
+		},
+		{
+			name:     "several assets",
+			minTotal: 75,
+			amounts:  []uint64{40, 30, 20},
+		},
+		{
+			name:     "exact balance",
+			minTotal: 100,
+			amounts:  []uint64{40, 30, 20, 10},
+		},
+		{
+			name:     "insufficient balance",
+			minTotal: 101,
+			amounts:  []uint64{40, 30, 20, 10},
+			err:      ErrInsufficientGroupBalance,
+		},
+		{
+			name:     "max amount",
+			minTotal: math.MaxUint64,
+			amounts:  []uint64{40, 30, 20, 10},
+			err:      ErrInsufficientGroupBalance,
+		},
+	}
+	for _, testCase := range testCases {
+		amounts, err := fetchAmounts(testCase.minTotal)
+		require.ErrorIs(t, err, testCase.err, testCase.name)
+		if len(testCase.amounts) == 0 {
+			require.Empty(t, amounts, testCase.name)
+			continue
+		}
+		require.Equal(t, testCase.amounts, amounts, testCase.name)
+	}
+
+	// An unknown group key has no balance at all.
+	selected, err := assetStore.FetchSpendableAssetsByGroup(
+		ctx, test.RandPubKey(t).SerializeCompressed(), 1,
+	)
+	require.ErrorIs(t, err, ErrInsufficientGroupBalance)
+	require.Empty(t, selected)
+}
+
+// TestFetchGroupAssetsPaginated tests that we're able to page through the
+// assets of a large asset group.
+func TestFetchGroupAssetsPaginated(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// groupAssets imports the given number of assets of the same asset
+	// group, and returns them in the order they were imported.
+	groupAssets := func(numAssets int) []*asset.Asset {
+		gen := asset.RandGenesis(t, asset.Normal)
+		groupPriv := test.RandPrivKey(t)
+
+		assets := make([]*asset.Asset, numAssets)
+		for i := range assets {
+			assets[i] = randAsset(
+				t, withAssetGen(gen),
+				withAssetGenPoint(gen.FirstPrevOut),
+				withAssetGenKeyGroup(groupPriv),
+			)
+		}
+
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(), gen.FirstPrevOut, assets,
+			nil,
+		)
+		require.NoError(t, err)
+
+		return assets
+	}
+
+	// We'll import a group with 50 members, and another, smaller group
+	// that shouldn't show up when paging through the first one.
+	const numMembers = 50
+	assets := groupAssets(numMembers)
+	_ = groupAssets(5)
+
+	// Paging through the group with a page size that doesn't divide the
+	// number of members should return all of them exactly once, in the
+	// order they were imported.
+	const pageSize = 7
+	groupKey := assets[0].GroupKey.GroupPubKey.SerializeCompressed()
+	var pagedScriptKeys []asset.SerializedKey
+	for offset := int32(0); ; offset += pageSize {
+		page, err := assetStore.FetchGroupAssetsPaginated(
+			ctx, groupKey, pageSize, offset,
+		)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), pageSize)
+
+		if len(page) == 0 {
+			break
+		}
+
+		for _, chainAsset := range page {
+			require.Equal(
+				t, assets[0].GroupKey.GroupPubKey,
+				chainAsset.GroupKey.GroupPubKey,
+			)
+			pagedScriptKeys = append(
+				pagedScriptKeys,
+				asset.ToSerialized(chainAsset.ScriptKey.PubKey),
+			)
+		}
+	}
+
+	scriptKey := func(a *asset.Asset) asset.SerializedKey {
+		return asset.ToSerialized(a.ScriptKey.PubKey)
+	}
+	require.Equal(t, fMap(assets, scriptKey), pagedScriptKeys)
+
+	// An unknown group key should result in no assets.
+	page, err := assetStore.FetchGroupAssetsPaginated(
+		ctx, test.RandPubKey(t).SerializeCompressed(), pageSize, 0,
+	)
+	require.NoError(t, err)
+	require.Empty(t, page)
+}
+
+// TestFetchAssetsByAnchorKeyFamily tests that we're able to fetch the assets
+// anchored in outputs with an internal key of a given key family.
+func TestFetchAssetsByAnchorKeyFamily(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	const (
+		family      = 5
+		otherFamily = 7
+	)
+
+	// We'll import two assets anchored under the same key family, one
+	// under another family and one under the default family.
+	families := []keychain.KeyFamily{family, otherFamily, family, 0}
+	assets := make([]*asset.Asset, len(families))
+	for i, keyFamily := range families {
+		anchor := randAnchorUTXO(t)
+		anchor.InternalKey.Family = keyFamily
+
+		assets[i] = randAsset(t)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, assets[i].Genesis.FirstPrevOut,
+			[]*asset.Asset{assets[i]}, []AnchorUTXO{anchor},
+		)
+		require.NoError(t, err)
+	}
+
+	scriptKeys := func(family int32) [][]byte {
+		chainAssets, err := assetStore.FetchAssetsByAnchorKeyFamily(
+			ctx, family,
+		)
+		require.NoError(t, err)
+
+		return fMap(chainAssets, func(a *ChainAsset) []byte {
+			return a.ScriptKey.PubKey.SerializeCompressed()
+		})
+	}
+	require.Equal(t, [][]byte{
+		assets[0].ScriptKey.PubKey.SerializeCompressed(),
+		assets[2].ScriptKey.PubKey.SerializeCompressed(),
+	}, scriptKeys(family))
+	require.Equal(t, [][]byte{
+		assets[1].ScriptKey.PubKey.SerializeCompressed(),
+	}, scriptKeys(otherFamily))
+	require.Equal(t, [][]byte{
+		assets[3].ScriptKey.PubKey.SerializeCompressed(),
+	}, scriptKeys(0))
+	require.Empty(t, scriptKeys(42))
+}
+
+// TestFetchGenesisByOutpoint tests that we're able to look up the genesis of
+// an asset by its genesis point and output index.
+func TestFetchGenesisByOutpoint(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import two assets of the same genesis point with different
+	// output indexes.
+	genesisPoint := test.RandOp(t)
+	gens := make([]asset.Genesis, 2)
+	for i := range gens {
+		gens[i] = asset.RandGenesis(t, asset.Normal)
+		gens[i].FirstPrevOut = genesisPoint
+		gens[i].OutputIndex = uint32(i)
+
+		a := randAsset(
+			t, withAssetGen(gens[i]),
+			withAssetGenPoint(genesisPoint),
+		)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, genesisPoint, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	for _, gen := range gens {
+		dbGen, err := assetStore.FetchGenesisByOutpoint(
+			ctx, genesisPoint, gen.OutputIndex,
+		)
+		require.NoError(t, err)
+		require.Equal(t, gen, dbGen)
+	}
+
+	// An unknown output index or genesis point should result in a not
+	// found error.
+	_, err := assetStore.FetchGenesisByOutpoint(ctx, genesisPoint, 2)
+	require.ErrorIs(t, err, ErrGenesisNotFound)
+
+	_, err = assetStore.FetchGenesisByOutpoint(ctx, test.RandOp(t), 0)
+	require.ErrorIs(t, err, ErrGenesisNotFound)
+}
+
+// TestFetchGenesisNotFound tests that fetching an unknown genesis asset
+// results in ErrGenesisNotFound, which still wraps the error returned by the
+// database backend.
+func TestFetchGenesisNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	gen := asset.RandGenesis(t, asset.Normal)
+	gen.FirstPrevOut = genesisPoint
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, gen,
+		MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	dbGen, _, err := fetchGenesis(ctx, db, genAssetID)
+	require.NoError(t, err)
+	require.Equal(t, gen, dbGen)
+
+	_, _, err = fetchGenesis(ctx, db, genAssetID+1)
+	require.ErrorIs(t, err, ErrGenesisNotFound)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// TestFetchGenesisByIDs tests that we're able to fetch multiple genesis assets
+// by their primary keys at once, and that unknown IDs are omitted.
+func TestFetchGenesisByIDs(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	// We'll store a few genesis assets, so we can fetch a subset of them
+	// that leaves a gap in the range of IDs.
+	geneses := make([]asset.Genesis, 4)
+	genAssetIDs := make([]int32, len(geneses))
+	for i := range geneses {
+		geneses[i] = asset.RandGenesis(t, asset.Normal)
+		geneses[i].FirstPrevOut = genesisPoint
+		geneses[i].OutputIndex = uint32(i)
+
+		genAssetIDs[i], err = upsertGenesis(
+			ctx, db, newUpsertOptions(), genesisPointID, geneses[i],
+			MetadataKeepExisting,
+		)
+		require.NoError(t, err)
+	}
+
+	unknownID := genAssetIDs[len(genAssetIDs)-1] + 1
+	dbGeneses, err := assetStore.FetchGenesisByIDs(
+		ctx, []int32{genAssetIDs[3], genAssetIDs[0], unknownID},
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[int32]asset.Genesis{
+		genAssetIDs[0]: geneses[0],
+		genAssetIDs[3]: geneses[3],
+	}, dbGeneses)
+
+	// Each genesis should match the one fetched on its own.
+	for id, gen := range dbGeneses {
+		dbGen, _, err := fetchGenesis(ctx, db, id)
+		require.NoError(t, err)
+		require.Equal(t, dbGen, gen)
+	}
+
+	// Fetching no IDs at all should result in an empty map.
+	dbGeneses, err = assetStore.FetchGenesisByIDs(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, dbGeneses)
+
+	// A set of IDs that doesn't fit into a single query should be fetched
+	// with several ones, independent of how sparse the IDs are.
+	manyIDs := make([]int32, 0, maxGenesesPerFetch+2)
+	for i := int32(0); len(manyIDs) < maxGenesesPerFetch; i++ {
+		manyIDs = append(manyIDs, unknownID+i*1000)
+	}
+	manyIDs = append(manyIDs, genAssetIDs[0], genAssetIDs[1])
+	dbGeneses, err = assetStore.FetchGenesisByIDs(ctx, manyIDs)
+	require.NoError(t, err)
+	require.Equal(t, map[int32]asset.Genesis{
+		genAssetIDs[0]: geneses[0],
+		genAssetIDs[1]: geneses[1],
+	}, dbGeneses)
+}
+
+// TestGenesisMetaType tests that the type of the metadata of a genesis asset
+// is stored along with it, and defaults to opaque metadata.
+func TestGenesisMetaType(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	// A genesis asset stored without an explicit type is opaque.
+	gen := asset.RandGenesis(t, asset.Normal)
+	gen.FirstPrevOut = genesisPoint
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, gen,
+		MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	_, metaType, err := fetchGenesis(ctx, db, genAssetID)
+	require.NoError(t, err)
+	require.Equal(t, MetaOpaque, metaType)
+
+	// A typed genesis asset should be returned with its type.
+	jsonGen := asset.RandGenesis(t, asset.Normal)
+	jsonGen.FirstPrevOut = genesisPoint
+	jsonGen.Metadata = []byte(`{"name":"test"}`)
+	jsonGenAsset := newGenesisAsset(
+		genesisPointID, jsonGen, MetadataKeepExisting,
+	)
+	jsonGenAsset.MetaType = int16(MetaJSON)
+	require.NoError(t, upsertMetaReveals(ctx, db, jsonGen))
+	jsonGenAssetID, err := db.UpsertGenesisAsset(ctx, jsonGenAsset)
+	require.NoError(t, err)
+
+	dbGen, metaType, err := fetchGenesis(ctx, db, jsonGenAssetID)
+	require.NoError(t, err)
+	require.Equal(t, jsonGen, dbGen)
+	require.Equal(t, MetaJSON, metaType)
+
+	// Re-importing the very same metadata without a type shouldn't lose
+	// the type it was stored with.
+	_, err = upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, jsonGen,
+		MetadataReplace,
+	)
+	require.NoError(t, err)
+
+	_, metaType, err = fetchGenesis(ctx, db, jsonGenAssetID)
+	require.NoError(t, err)
+	require.Equal(t, MetaJSON, metaType)
+
+	// Replacing the metadata with untyped metadata is rejected, so the
+	// metadata and its type are left untouched.
+	replacedGen := jsonGen
+	replacedGen.Metadata = test.RandBytes(32)
+	_, err = upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, replacedGen,
+		MetadataReplace,
+	)
+	require.ErrorIs(t, err, ErrGenesisImmutable)
+
+	dbGen, metaType, err = fetchGenesis(ctx, db, jsonGenAssetID)
+	require.NoError(t, err)
+	require.Equal(t, jsonGen, dbGen)
+	require.Equal(t, MetaJSON, metaType)
+}
+
+// TestGenesisMetaReveal tests that a genesis asset only stores the hash of
+// its metadata, and that the full metadata is revealed from the separate
+// reveal table when the genesis is read.
+func TestGenesisMetaReveal(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	gen := asset.RandGenesis(t, asset.Normal)
+	gen.FirstPrevOut = genesisPoint
+	gen.Metadata = test.RandBytes(32)
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, gen,
+		MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	metaHash := gen.MetadataHash()
+	dbGen, err := db.FetchGenesisAssetByTag(ctx, gen.Tag)
+	require.NoError(t, err)
+	require.Equal(t, metaHash[:], dbGen.MetaHash)
+	require.Nil(t, dbGen.MetaData)
+
+	// The reveal is stored along with the genesis and joined back in.
+	fetchedGen, _, err := fetchGenesis(ctx, db, genAssetID)
+	require.NoError(t, err)
+	require.Equal(t, gen, fetchedGen)
+
+	// Without the reveal, the genesis can't be read back, as its asset ID
+	// can't be derived.
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+	_, err = rawDB.ExecContext(
+		ctx, "DELETE FROM genesis_meta_reveals WHERE meta_hash = $1",
+		metaHash[:],
+	)
+	require.NoError(t, err)
+
+	_, _, err = fetchGenesis(ctx, db, genAssetID)
+	require.ErrorIs(t, err, ErrMetaRevealMissing)
+
+	// A reveal that doesn't match the hash should be refused.
+	err = assetStore.UpsertGenesisMetaReveal(
+		ctx, metaHash, test.RandBytes(32),
+	)
+	require.ErrorIs(t, err, ErrMetaRevealMismatch)
+
+	// The matching reveal should be joined back in. Storing it twice
+	// shouldn't make a difference.
+	for i := 0; i < 2; i++ {
+		err = assetStore.UpsertGenesisMetaReveal(
+			ctx, metaHash, gen.Metadata,
+		)
+		require.NoError(t, err)
+	}
+
+	fetchedGen, _, err = fetchGenesis(ctx, db, genAssetID)
+	require.NoError(t, err)
+	require.Equal(t, gen, fetchedGen)
+
+	_, _, err = fetchGenesis(ctx, db, genAssetID+1)
+	require.ErrorIs(t, err, ErrGenesisNotFound)
+}
+
+// TestFetchGenesisNegativeOutputIndex tests that a genesis asset with a
+// negative output index is refused when read from the database, rather than
+// wrapping the index around to a genesis with the wrong asset ID.
+func TestFetchGenesisNegativeOutputIndex(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	gen := asset.RandGenesis(t, asset.Normal)
+	gen.FirstPrevOut = genesisPoint
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, gen,
+		MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	// We'll now corrupt the stored output index, which should result in
+	// an error instead of a garbage genesis.
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+	_, err = rawDB.ExecContext(
+		ctx, "UPDATE genesis_assets SET output_index = -1 "+
+			"WHERE gen_asset_id = $1", genAssetID,
+	)
+	require.NoError(t, err)
+
+	_, _, err = fetchGenesis(ctx, db, genAssetID)
+	require.ErrorIs(t, err, ErrInvalidOutputIndex)
+
+	// The same is true if the genesis is looked up by the output index the
+	// corrupted value wraps around to.
+	_, err = fetchGenesisByOutpoint(
+		ctx, db, genesisPoint, math.MaxUint32,
+	)
+	require.ErrorIs(t, err, ErrInvalidOutputIndex)
+}
+
+// TestFetchGenesisStrictMode tests that a genesis asset whose stored asset ID
+// doesn't match its other fields is refused in strict mode, while it's still
+// returned as is by default.
+func TestFetchGenesisStrictMode(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	gen := asset.RandGenesis(t, asset.Normal)
+	gen.FirstPrevOut = genesisPoint
+	genAssetID, err := upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, gen,
+		MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	// As long as the genesis is intact, it should be returned in strict
+	// mode as well.
+	strictDB := NewStrictGenesisAssetsStore(db)
+	dbGen, _, err := fetchGenesis(ctx, strictDB, genAssetID)
+	require.NoError(t, err)
+	require.Equal(t, gen, dbGen)
+
+	// We'll now corrupt the stored asset ID.
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+	_, err = rawDB.ExecContext(
+		ctx, "UPDATE genesis_assets SET asset_id = $1 "+
+			"WHERE gen_asset_id = $2", test.RandBytes(32),
+		genAssetID,
+	)
+	require.NoError(t, err)
+
+	// By default, the asset ID isn't verified, so the genesis is still
+	// returned.
+	dbGen, _, err = fetchGenesis(ctx, db, genAssetID)
+	require.NoError(t, err)
+	require.Equal(t, gen, dbGen)
+
+	// In strict mode, both ways of fetching the genesis should detect the
+	// corruption.
+	_, _, err = fetchGenesis(ctx, strictDB, genAssetID)
+	require.ErrorIs(t, err, ErrGenesisIDMismatch)
+
+	_, err = fetchGenesesByIDs(ctx, strictDB, []int32{genAssetID})
+	require.ErrorIs(t, err, ErrGenesisIDMismatch)
+}
+
+// TestFetchAllGroupKeysReconstructed tests that the group keys reconstructed
+// from the database match the group keys of the assets they were stored with.
+func TestFetchAllGroupKeysReconstructed(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// Without any assets, there are no group keys either.
+	groupKeys, err := assetStore.FetchAllGroupKeysReconstructed(ctx)
+	require.NoError(t, err)
+	require.Empty(t, groupKeys)
+
+	// We'll import two assets with distinct group keys, of which the
+	// second one has a raw key with a key locator, another asset of the
+	// first group and an asset without a group key.
+	gen := asset.RandGenesis(t, asset.Normal)
+	groupPriv := test.RandPrivKey(t)
+	groupedAsset := func() *asset.Asset {
+		return randAsset(
+			t, withAssetGen(gen),
+			withAssetGenPoint(gen.FirstPrevOut),
+			withAssetGenKeyGroup(groupPriv),
+		)
+	}
+
+	locatorAsset := randAsset(
+		t, withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	locatorAsset.GroupKey.RawKey.KeyLocator = keychain.KeyLocator{
+		Family: 12,
+		Index:  34,
+	}
+
+	assets := []*asset.Asset{
+		groupedAsset(), locatorAsset, groupedAsset(),
+		randAsset(t, withNoGroupKey()),
+	}
+	for _, a := range assets {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	groupKeys, err = assetStore.FetchAllGroupKeysReconstructed(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*asset.GroupKey{
+		assets[0].GroupKey, assets[1].GroupKey,
+	}, groupKeys)
+}
+
+// TestFetchGroupRawKey tests that the raw key of a group key we own is
+// returned with its key locator, while the raw key of a group key imported
+// from a remote proof is flagged as external.
+func TestFetchGroupRawKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, _ := newAssetStore(t)
+	ctx := context.Background()
+
+	// The first asset has a group key we only know the tweaked key of,
+	// just like a group key imported from a remote proof. The second one
+	// has a group key derived from a raw key we own.
+	externalAsset := randAsset(t, withAssetGenKeyGroup(test.RandPrivKey(t)))
+
+	ownedPriv := test.RandPrivKey(t)
+	ownedAsset := randAsset(t, withAssetGenKeyGroup(ownedPriv))
+	ownedAsset.GroupKey.RawKey = keychain.KeyDescriptor{
+		PubKey: ownedPriv.PubKey(),
+		KeyLocator: keychain.KeyLocator{
+			Family: 212,
+			Index:  7,
+		},
+	}
+
+	for _, a := range []*asset.Asset{externalAsset, ownedAsset} {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+	}
+
+	groupKeyBytes := func(a *asset.Asset) []byte {
+		return a.GroupKey.GroupPubKey.SerializeCompressed()
+	}
+
+	rawKey, err := assetStore.FetchGroupRawKey(
+		ctx, groupKeyBytes(externalAsset),
+	)
+	require.NoError(t, err)
+	require.True(t, rawKey.External)
+	require.Equal(t, keychain.KeyDescriptor{
+		PubKey: &externalAsset.GroupKey.GroupPubKey,
+	}, rawKey.KeyDesc)
+
+	rawKey, err = assetStore.FetchGroupRawKey(
+		ctx, groupKeyBytes(ownedAsset),
+	)
+	require.NoError(t, err)
+	require.False(t, rawKey.External)
+	require.Equal(t, ownedAsset.GroupKey.RawKey, rawKey.KeyDesc)
+
+	// An unknown group key can't be found.
+	_, err = assetStore.FetchGroupRawKey(
+		ctx, test.RandPubKey(t).SerializeCompressed(),
+	)
+	require.ErrorIs(t, err, ErrGroupKeyNotFound)
+}
+
+// TestFetchAssetsWithNonCanonicalOutpoints tests that the audit flags the
+// genesis points that are stored with a non-canonical encoding.
+func TestFetchAssetsWithNonCanonicalOutpoints(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// A genesis point written by the regular encoder is canonical.
+	_, err := upsertGenesisPoint(ctx, db, test.RandOp(t))
+	require.NoError(t, err)
+
+	nonCanonical, err := assetStore.FetchAssetsWithNonCanonicalOutpoints(
+		ctx,
+	)
+	require.NoError(t, err)
+	require.Empty(t, nonCanonical)
+
+	// We'll now deliberately write a genesis point with trailing bytes,
+	// and one that's truncated.
+	canonical, err := encodeOutpoint(test.RandOp(t))
+	require.NoError(t, err)
+
+	paddedPoint := append(append([]byte{}, canonical...), 0x00)
+	truncatedPoint := test.RandBytes(20)
+
+	var pointIDs []int32
+	for _, prevOut := range [][]byte{paddedPoint, truncatedPoint} {
+		pointID, err := db.UpsertGenesisPoint(ctx, NewGenesisPoint{
+			PrevOut: prevOut,
+		})
+		require.NoError(t, err)
+
+		pointIDs = append(pointIDs, pointID)
+	}
+
+	nonCanonical, err = assetStore.FetchAssetsWithNonCanonicalOutpoints(
+		ctx,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []NonCanonicalOutpoint{
+		{
+			GenesisPointID: pointIDs[0],
+			Stored:         paddedPoint,
+			Canonical:      canonical,
+		},
+		{
+			GenesisPointID: pointIDs[1],
+			Stored:         truncatedPoint,
+		},
+	}, nonCanonical)
+}
+
+// TestFetchStaleUnanchoredAssets tests that we're able to fetch the assets
+// that were never anchored, even though their genesis point was stored before
+// a given time.
+func TestFetchStaleUnanchoredAssets(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll insert a batch of assets that aren't anchored yet, and
+	// another batch that's anchored.
+	genesisPoint := test.RandOp(t)
+	unanchoredAssets := []*asset.Asset{
+		randAsset(t, withAssetGenPoint(genesisPoint)),
+		randAsset(t, withAssetGenPoint(genesisPoint)),
+	}
+	_, assetIDs, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), genesisPoint, unanchoredAssets,
+		nil,
+	)
+	require.NoError(t, err)
+
+	anchoredAsset := randAsset(t)
+	err = assetStore.ImportAssetsWithAnchors(
+		ctx, anchoredAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{anchoredAsset}, []AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	// None of the genesis points was stored an hour ago, so no asset is
+	// stale yet.
+	now := time.Now()
+	staleAssets, err := assetStore.FetchStaleUnanchoredAssets(
+		ctx, now.Add(-time.Hour),
+	)
+	require.NoError(t, err)
+	require.Empty(t, staleAssets)
+
+	// An hour from now, only the unanchored assets should be considered
+	// stale.
+	staleAssets, err = assetStore.FetchStaleUnanchoredAssets(
+		ctx, now.Add(time.Hour),
+	)
+	require.NoError(t, err)
+	require.Len(t, staleAssets, len(unanchoredAssets))
+
+	for i, staleAsset := range staleAssets {
+		require.Equal(t, assetIDs[i], staleAsset.AssetPrimaryKey)
+		require.Equal(
+			t, unanchoredAssets[i].Genesis, staleAsset.Genesis,
+		)
+		require.Equal(t, unanchoredAssets[i].Amount, staleAsset.Amount)
+		require.WithinDuration(t, now, staleAsset.CreatedAt, time.Hour)
+	}
+}
+
+// TestFetchAssetsWithNullScriptKey tests that we detect assets referencing a
+// script key that doesn't exist.
+func TestFetchAssetsWithNullScriptKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We need to disable foreign keys to corrupt the database, which we
+	// only know how to do for SQLite.
+	sqliteDB, ok := db.(*SqliteStore)
+	if !ok {
+		t.Skip("corrupting the database requires SQLite")
+	}
+
+	assets := []*asset.Asset{randAsset(t), randAsset(t)}
+	for _, a := range assets {
+		_, _, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(), a.Genesis.FirstPrevOut,
+			[]*asset.Asset{a}, nil,
+		)
+		require.NoError(t, err)
+	}
+
+	// A healthy database doesn't contain any such assets.
+	corruptAssets, err := assetStore.FetchAssetsWithNullScriptKey(ctx)
+	require.NoError(t, err)
+	require.Empty(t, corruptAssets)
+
+	// We'll now remove the script key of the second asset, using a
+	// dedicated connection with foreign keys disabled.
+	scriptKeyID, err := db.FetchScriptKeyIDByTweakedKey(
+		ctx, assets[1].ScriptKey.PubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+
+	conn, err := sqliteDB.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(
+		ctx, "DELETE FROM script_keys WHERE script_key_id = $1",
+		scriptKeyID,
+	)
+	require.NoError(t, err)
+
+	// The connection is returned to the pool once closed, so we'll enable
+	// foreign keys again.
+	_, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	require.NoError(t, err)
+
+	corruptAssets, err = assetStore.FetchAssetsWithNullScriptKey(ctx)
+	require.NoError(t, err)
+	require.Len(t, corruptAssets, 1)
+	require.Equal(t, scriptKeyID, corruptAssets[0].ScriptKeyID)
+	require.Equal(t, assets[1].Genesis, corruptAssets[0].Genesis)
+	require.Equal(t, assets[1].Amount, corruptAssets[0].Amount)
+}
+
+// TestDeleteAssetByScriptKey tests that deleting an asset by its script key
+// also deletes its proof, script key and internal key, unless the internal key
+// is still referenced by a group key.
+func TestDeleteAssetByScriptKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// We'll import two assets. The script key of the second one is
+	// derived from the same internal key as its group key, so the
+	// internal key is shared.
+	sharedKeyAsset := randAsset(
+		t, withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	sharedKeyAsset.ScriptKey = asset.NewScriptKeyBIP0086(
+		sharedKeyAsset.GroupKey.RawKey,
+	)
+	ownKeyAsset := randAsset(t, withNoGroupKey())
+	for _, a := range []*asset.Asset{ownKeyAsset, sharedKeyAsset} {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, a.Genesis.FirstPrevOut, []*asset.Asset{a},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+
+		scriptKey := a.ScriptKey.PubKey.SerializeCompressed()
+		err = db.UpsertAssetProof(ctx, ProofUpdate{
+			TweakedScriptKey: scriptKey,
+			ProofFile:        test.RandBytes(32),
+		})
+		require.NoError(t, err)
+	}
+
+	// scriptKeyRows returns whether the script key of the given asset and
+	// the internal key it was derived from still exist.
+	scriptKeyRows := func(a *asset.Asset) (bool, bool) {
+		_, err := db.FetchScriptKeyIDByTweakedKey(
+			ctx, a.ScriptKey.PubKey.SerializeCompressed(),
+		)
+		scriptKeyExists := err == nil
+
+		_, err = db.FetchInternalKeyIDByRawKey(
+			ctx, a.ScriptKey.RawKey.PubKey.SerializeCompressed(),
+		)
+		internalKeyExists := err == nil
+
+		return scriptKeyExists, internalKeyExists
+	}
+
+	// Deleting the first asset should delete all of its rows.
+	err := assetStore.DeleteAssetByScriptKey(
+		ctx, ownKeyAsset.ScriptKey.PubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+
+	scriptKeyExists, internalKeyExists := scriptKeyRows(ownKeyAsset)
+	require.False(t, scriptKeyExists)
+	require.False(t, internalKeyExists)
+
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 1)
+
+	proofs, err := db.FetchAssetProofs(ctx)
+	require.NoError(t, err)
+	require.Len(t, proofs, 1)
+
+	// Deleting the second asset should keep its internal key around, as
+	// it's still referenced by the group key.
+	err = assetStore.DeleteAssetByScriptKey(
+		ctx, sharedKeyAsset.ScriptKey.PubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+
+	scriptKeyExists, internalKeyExists = scriptKeyRows(sharedKeyAsset)
+	require.False(t, scriptKeyExists)
+	require.True(t, internalKeyExists)
+
+	dbAssets, err = db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, dbAssets)
+
+	proofs, err = db.FetchAssetProofs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, proofs)
+
+	// Deleting an asset that doesn't exist should fail.
+	err = assetStore.DeleteAssetByScriptKey(
+		ctx, ownKeyAsset.ScriptKey.PubKey.SerializeCompressed(),
+	)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+}
+
+// TestFetchScriptKey tests that the type of a script key is stored along with
+// it, and that the script key can be fetched again by its tweaked key.
+func TestFetchScriptKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	newTweakedScriptKey := func(tweak []byte) asset.ScriptKey {
+		rawKey := keychain.KeyDescriptor{
+			PubKey: test.RandPubKey(t),
+			KeyLocator: keychain.KeyLocator{
+				Family: test.RandInt[keychain.KeyFamily](),
+				Index:  uint32(test.RandInt[int32]()),
+			},
+		}
+		return asset.ScriptKey{
+			PubKey: txscript.ComputeTaprootOutputKey(
+				rawKey.PubKey, tweak,
+			),
+			TweakedScriptKey: &asset.TweakedScriptKey{
+				RawKey: rawKey,
+				Tweak:  tweak,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		scriptKey asset.ScriptKey
+		keyType   ScriptKeyType
+	}{{
+		name:      "bip86",
+		scriptKey: newTweakedScriptKey(nil),
+		keyType:   ScriptKeyBip86,
+	}, {
+		name:      "tweaked",
+		scriptKey: newTweakedScriptKey(test.RandBytes(32)),
+		keyType:   ScriptKeyTweaked,
+	}, {
+		name: "unknown",
+		scriptKey: asset.ScriptKey{
+			PubKey: test.RandPubKey(t),
+		},
+		keyType: ScriptKeyUnknown,
+	}}
+
+	for _, testCase := range testCases {
+		_, err := upsertScriptKey(ctx, testCase.scriptKey, db, nil)
+		require.NoError(t, err, testCase.name)
+
+		scriptKey, keyType, err := assetStore.FetchScriptKey(
+			ctx, testCase.scriptKey.PubKey,
+		)
+		require.NoError(t, err, testCase.name)
+		require.Equal(t, testCase.keyType, keyType, testCase.name)
+		require.Equal(
+			t, testCase.scriptKey.PubKey.SerializeCompressed(),
+			scriptKey.PubKey.SerializeCompressed(), testCase.name,
+		)
+		require.Equal(
+			t, testCase.scriptKey.TweakedScriptKey,
+			scriptKey.TweakedScriptKey, testCase.name,
+		)
+	}
+
+	// An unknown script key should result in an error.
+	_, _, err := assetStore.FetchScriptKey(ctx, test.RandPubKey(t))
+	require.ErrorIs(t, err, ErrScriptKeyNotFound)
+}
+
+// TestUpsertScriptKeyUpgrade tests that a script key that was first imported
+// without knowing its raw key is upgraded once it's imported with its raw key,
+// while a known script key is never downgraded.
+func TestUpsertScriptKeyUpgrade(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	rawKey := keychain.KeyDescriptor{
+		PubKey: test.RandPubKey(t),
+		KeyLocator: keychain.KeyLocator{
+			Family: test.RandInt[keychain.KeyFamily](),
+			Index:  uint32(test.RandInt[int32]()),
+		},
+	}
+	tweak := test.RandBytes(32)
+	knownKey := asset.ScriptKey{
+		PubKey: txscript.ComputeTaprootOutputKey(rawKey.PubKey, tweak),
+		TweakedScriptKey: &asset.TweakedScriptKey{
+			RawKey: rawKey,
+			Tweak:  tweak,
+		},
+	}
+	unknownKey := asset.ScriptKey{
+		PubKey: knownKey.PubKey,
+	}
+
+	assertKeyType := func(expected ScriptKeyType) *asset.ScriptKey {
+		scriptKey, keyType, err := assetStore.FetchScriptKey(
+			ctx, knownKey.PubKey,
+		)
+		require.NoError(t, err)
+		require.Equal(t, expected, keyType)
+
+		return scriptKey
+	}
+
+	// We'll first import the key without knowing its raw key, as we would
+	// when mirroring the state of another node.
+	unknownID, err := upsertScriptKey(ctx, unknownKey, db, nil)
+	require.NoError(t, err)
+	assertKeyType(ScriptKeyUnknown)
+
+	// Importing the very same key with its raw key should upgrade the
+	// existing row in place.
+	knownID, err := upsertScriptKey(ctx, knownKey, db, nil)
+	require.NoError(t, err)
+	require.Equal(t, unknownID, knownID)
+
+	scriptKey := assertKeyType(ScriptKeyTweaked)
+	require.Equal(t, knownKey.TweakedScriptKey, scriptKey.TweakedScriptKey)
+
+	// Upserting the key as unknown once more shouldn't downgrade it.
+	tweakedKeyID, err := db.UpsertInternalKey(ctx, InternalKey{
+		RawKey: knownKey.PubKey.SerializeCompressed(),
+	})
+	require.NoError(t, err)
+	_, err = db.UpsertScriptKey(ctx, NewScriptKey{
+		InternalKeyID:    tweakedKeyID,
+		TweakedScriptKey: knownKey.PubKey.SerializeCompressed(),
+	})
+	require.NoError(t, err)
+
+	scriptKey = assertKeyType(ScriptKeyTweaked)
+	require.Equal(t, knownKey.TweakedScriptKey, scriptKey.TweakedScriptKey)
+}
+
+// TestFetchInternalKeyByRawKey tests that we're able to look up the key
+// locator of an internal key by its raw key.
+func TestFetchInternalKeyByRawKey(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	internalKey := InternalKey{
+		RawKey:    test.RandPubKey(t).SerializeCompressed(),
+		KeyFamily: test.RandInt[int32](),
+		KeyIndex:  test.RandInt[int32](),
+	}
+	_, err := db.UpsertInternalKey(ctx, internalKey)
+	require.NoError(t, err)
+
+	dbKey, err := assetStore.FetchInternalKeyByRawKey(
+		ctx, internalKey.RawKey,
+	)
+	require.NoError(t, err)
+	require.Equal(t, internalKey, dbKey)
+
+	// An unknown raw key should result in an error.
+	_, err = assetStore.FetchInternalKeyByRawKey(
+		ctx, test.RandPubKey(t).SerializeCompressed(),
+	)
+	require.ErrorIs(t, err, ErrInternalKeyNotFound)
+}
+
+// TestWithAssetStore tests that assets and their anchor transaction inserted
+// within WithAssetStore are committed together, or not at all.
+func TestWithAssetStore(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	newAsset := randAsset(t)
+	anchorTxid := test.RandHash()
+	insertAssets := func(q UpsertAssetStore) error {
+		_, err := q.UpsertChainTx(ctx, ChainTx{
+			Txid:  anchorTxid[:],
+			RawTx: test.RandBytes(32),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, _, err = upsertAssetsWithGenesis(
+			ctx, q, newUpsertOptions(),
+			newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+			nil,
+		)
+
+		return err
+	}
+
+	// If the closure fails after inserting everything, neither the assets
+	// nor the anchor transaction should be stored.
+	errAbort := errors.New("abort")
+	err := assetStore.WithAssetStore(ctx, func(q UpsertAssetStore) error {
+		require.NoError(t, insertAssets(q))
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, dbAssets)
+
+	_, err = db.FetchChainTx(ctx, anchorTxid[:])
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Once the closure succeeds, both should be stored.
+	err = assetStore.WithAssetStore(ctx, insertAssets)
+	require.NoError(t, err)
+
+	dbAssets, err = db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 1)
+
+	_, err = db.FetchChainTx(ctx, anchorTxid[:])
+	require.NoError(t, err)
+}
+
+// TestFetchAssetsMissingGroupSig tests that we're able to find the assets of a
+// group that were stored without the group sig of their genesis asset, and
+// that they're no longer returned once the sig was backfilled.
+func TestFetchAssetsMissingGroupSig(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// insertSigLessAsset inserts a new grouped asset, but only stores its
+	// group key without the group sig of its genesis asset.
+	insertSigLessAsset := func(groupPriv *btcec.PrivateKey) (*asset.Asset,
+		upsertedGroupKey, int32) {
+
+		genesisPoint := test.RandOp(t)
+		newAsset := randAsset(
+			t, withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		)
+
+		genesisPointID, err := upsertGenesisPoint(
+			ctx, db, genesisPoint,
+		)
+		require.NoError(t, err)
+		genAssetID, err := upsertGenesis(
+			ctx, db, newUpsertOptions(), genesisPointID,
+			newAsset.Genesis, MetadataKeepExisting,
+		)
+		require.NoError(t, err)
+
+		groupKeyIDs, err := upsertGroupKey(
+			ctx, newAsset.GroupKey, db, genesisPointID,
+			genAssetID, nil, true,
+		)
+		require.NoError(t, err)
+
+		scriptKeyID, err := upsertScriptKey(
+			ctx, newAsset.ScriptKey, db, nil,
+		)
+		require.NoError(t, err)
+
+		_, err = db.InsertNewAsset(ctx, sqlc.InsertNewAssetParams{
+			GenesisID:     genAssetID,
+			ScriptKeyID:   scriptKeyID,
+			ScriptVersion: int32(newAsset.ScriptVersion),
+			Amount:        int64(newAsset.Amount),
+		})
+		require.NoError(t, err)
+
+		return newAsset, groupKeyIDs, genAssetID
+	}
+
+	// We'll insert a sig-less asset for two different groups, and a fully
+	// grouped asset that has its sig.
+	sigLessAsset, groupKeyIDs, genAssetID := insertSigLessAsset(
+		test.RandPrivKey(t),
+	)
+	otherAsset, _, _ := insertSigLessAsset(test.RandPrivKey(t))
+
+	groupedAsset := randAsset(
+		t, withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), groupedAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{groupedAsset}, nil,
+	)
+	require.NoError(t, err)
+
+	// Only the sig-less asset of the given group should be returned.
+	assertMissingSig := func(groupKey *asset.GroupKey,
+		expected ...*asset.Asset) {
+
+		chainAssets, err := assetStore.FetchAssetsMissingGroupSig(
+			ctx, groupKey.GroupPubKey.SerializeCompressed(),
+		)
+		require.NoError(t, err)
+		require.Len(t, chainAssets, len(expected))
+
+		for i, chainAsset := range chainAssets {
+			require.Equal(
+				t, expected[i].ScriptKey.PubKey,
+				chainAsset.ScriptKey.PubKey,
+			)
+			require.Equal(
+				t, expected[i].Genesis, chainAsset.Genesis,
+			)
+			require.Nil(t, chainAsset.GroupKey)
+		}
+	}
+	assertMissingSig(sigLessAsset.GroupKey, sigLessAsset)
+	assertMissingSig(otherAsset.GroupKey, otherAsset)
+	assertMissingSig(groupedAsset.GroupKey)
+
+	// Once the sig is backfilled, the asset should no longer be returned.
+	_, err = db.UpsertAssetGroupSig(ctx, AssetGroupSig{
+		GenesisSig: sigLessAsset.GroupKey.Sig.Serialize(),
+		GenAssetID: genAssetID,
+		GroupKeyID: groupKeyIDs.groupID,
+	})
+	require.NoError(t, err)
+
+	assertMissingSig(sigLessAsset.GroupKey)
+	assertMissingSig(otherAsset.GroupKey, otherAsset)
+}
+
+// TestBindAssetAnchor tests that an asset imported without an anchor can be
+// bound to its anchor UTXO later on, but can't be rebound to a different one.
+func TestBindAssetAnchor(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	newAsset := randAsset(t)
+	_, assetIDs, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), newAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{newAsset}, nil,
+	)
+	require.NoError(t, err)
+	assetID := assetIDs[0]
+
+	anchorUtxoID, err := db.FetchAssetAnchorUTXOID(ctx, assetID)
+	require.NoError(t, err)
+	require.False(t, anchorUtxoID.Valid)
+
+	// Once the anchor UTXO is known, we can bind the asset to it.
+	utxoID, err := upsertAnchorUTXO(
+		ctx, db, newUpsertOptions(), randAnchorUTXO(t),
+	)
+	require.NoError(t, err)
+	require.NoError(t, assetStore.BindAssetAnchor(ctx, assetID, utxoID))
+
+	anchorUtxoID, err = db.FetchAssetAnchorUTXOID(ctx, assetID)
+	require.NoError(t, err)
+	require.Equal(t, sqlInt32(utxoID), anchorUtxoID)
+
+	// Binding the asset to the very same UTXO again is fine.
+	require.NoError(t, assetStore.BindAssetAnchor(ctx, assetID, utxoID))
+
+	// Binding it to a different UTXO should be refused, and keep the
+	// existing anchor.
+	otherUtxoID, err := upsertAnchorUTXO(
+		ctx, db, newUpsertOptions(), randAnchorUTXO(t),
+	)
+	require.NoError(t, err)
+
+	err = assetStore.BindAssetAnchor(ctx, assetID, otherUtxoID)
+	var anchoredErr *ErrAssetAlreadyAnchored
+	require.ErrorAs(t, err, &anchoredErr)
+	require.Equal(t, assetID, anchoredErr.AssetID)
+	require.Equal(t, utxoID, anchoredErr.AnchorUtxoID)
+
+	anchorUtxoID, err = db.FetchAssetAnchorUTXOID(ctx, assetID)
+	require.NoError(t, err)
+	require.Equal(t, sqlInt32(utxoID), anchorUtxoID)
+
+	// An unknown asset can't be bound.
+	err = assetStore.BindAssetAnchor(ctx, assetID+1, utxoID)
+	require.ErrorIs(t, err, ErrAssetNotFound)
+}
+
+// TestCountAssetsByType tests that we're able to count the assets of each
+// asset type, with and without the spent ones.
+func TestCountAssetsByType(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	// An empty database shouldn't have any assets of any type.
+	typeCounts, err := assetStore.CountAssetsByType(ctx)
+	require.NoError(t, err)
+	require.Empty(t, typeCounts)
+
+	insertAsset := func(assetType asset.Type) int32 {
+		newAsset := randAsset(t, withAssetGen(
+			asset.RandGenesis(t, assetType),
+		))
+		_, assetIDs, err := upsertAssetsWithGenesis(
+			ctx, db, newUpsertOptions(),
+			newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+			nil,
+		)
+		require.NoError(t, err)
+
+		return assetIDs[0]
+	}
+
+	// We'll insert three normal assets and two collectibles, and then
+	// spend one of the normal assets.
+	var normalIDs []int32
+	for i := 0; i < 3; i++ {
+		normalIDs = append(normalIDs, insertAsset(asset.Normal))
+	}
+	for i := 0; i < 2; i++ {
+		insertAsset(asset.Collectible)
+	}
+
+	_, err = db.SetAssetSpent(ctx, normalIDs[0])
+	require.NoError(t, err)
+
+	// By default, the spent asset shouldn't be counted.
+	typeCounts, err = assetStore.CountAssetsByType(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[asset.Type]int64{
+		asset.Normal:      2,
+		asset.Collectible: 2,
+	}, typeCounts)
+
+	// Including the historical assets, it should be counted as well.
+	typeCounts, err = assetStore.CountAllAssetsByType(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[asset.Type]int64{
+		asset.Normal:      3,
+		asset.Collectible: 2,
+	}, typeCounts)
+}
+
+// TestUpsertAssetsWithGenesisIDs tests that upserting a grouped asset returns
+// the primary keys of all the rows it depends on.
+func TestUpsertAssetsWithGenesisIDs(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	groupedAsset := randAsset(
+		t, withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	upserted, err := upsertAssetsWithGenesisIDs(
+		ctx, db, newUpsertOptions(), groupedAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{groupedAsset}, nil,
+	)
+	require.NoError(t, err)
+
+	// Each of the returned IDs should match the row it references.
+	genesisPointID, err := db.FetchGenesisPointIDByGenAssetID(
+		ctx, upserted.genAssetIDs[0],
+	)
+	require.NoError(t, err)
+	require.NotZero(t, upserted.genesisPointID)
+	require.Equal(t, genesisPointID, upserted.genesisPointID)
+
+	groupKey := groupedAsset.GroupKey
+	groupID, err := db.FetchGroupKeyIDByTweakedKey(
+		ctx, groupKey.GroupPubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, groupID, upserted.groupKeys[0].groupID)
+
+	internalKeyID, err := db.FetchInternalKeyIDByRawKey(
+		ctx, groupKey.RawKey.PubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, internalKeyID, upserted.groupKeys[0].internalKeyID)
+
+	groupSigID, err := db.FetchGroupSigIDByGenesisID(
+		ctx, upserted.genAssetIDs[0],
+	)
+	require.NoError(t, err)
+	require.Equal(t, sqlInt32(groupSigID), upserted.groupKeys[0].groupSigID)
+
+	scriptKeyID, err := db.FetchScriptKeyIDByTweakedKey(
+		ctx, groupedAsset.ScriptKey.PubKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, scriptKeyID, upserted.scriptKeyIDs[0])
+
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 1)
+	require.Equal(t, dbAssets[0].AssetID, upserted.assetIDs[0])
+	require.Equal(t, dbAssets[0].GenesisID, upserted.genAssetIDs[0])
+}
+
+// TestFetchMetaHashCollisions tests that genesis assets sharing a metadata
+// hash are only reported if the revealed metadata doesn't derive the asset ID
+// of all of them.
+func TestFetchMetaHashCollisions(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t)
+	ctx := context.Background()
+
+	insertGenesis := func(metadata []byte) int32 {
+		genesisPoint := test.RandOp(t)
+		genesisPointID, err := upsertGenesisPoint(
+			ctx, db, genesisPoint,
+		)
+		require.NoError(t, err)
+
+		gen := asset.RandGenesis(t, asset.Normal)
+		gen.FirstPrevOut = genesisPoint
+		gen.Metadata = metadata
+		genAssetID, err := upsertGenesis(
+			ctx, db, newUpsertOptions(), genesisPointID, gen,
+			MetadataKeepExisting,
+		)
+		require.NoError(t, err)
+
+		return genAssetID
+	}
+
+	// Two different assets with the very same metadata share its hash,
+	// which isn't a collision.
+	sharedMeta := test.RandBytes(32)
+	sharedGenIDs := []int32{
+		insertGenesis(sharedMeta), insertGenesis(sharedMeta),
+	}
+	otherGenID := insertGenesis(test.RandBytes(32))
+
+	collisions, err := assetStore.FetchMetaHashCollisions(ctx)
+	require.NoError(t, err)
+	require.Empty(t, collisions)
+
+	// We'll now contrive a collision by storing the hash of the shared
+	// metadata for the asset with different metadata.
+	sharedHash := sha256.Sum256(sharedMeta)
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+	_, err = rawDB.ExecContext(
+		ctx, "UPDATE genesis_assets SET meta_hash = $1 "+
+			"WHERE gen_asset_id = $2", sharedHash[:], otherGenID,
+	)
+	require.NoError(t, err)
+
+	collisions, err = assetStore.FetchMetaHashCollisions(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []MetaHashCollision{{
+		MetaHash:    sharedHash,
+		GenAssetIDs: append(sharedGenIDs, otherGenID),
+	}}, collisions)
+}
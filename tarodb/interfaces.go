@@ -64,6 +64,10 @@ type BatchedQuerier interface {
 	// create a batched version of the normal methods they need.
 	sqlc.Querier
 
+	// BatchQuerier holds the hand-written queries that can't be
+	// generated by sqlc.
+	sqlc.BatchQuerier
+
 	// BeginTx creates a new database transaction given the set of
 	// transaction options.
 	BeginTx(ctx context.Context, options TxOptions) (*sql.Tx, error)
@@ -143,3 +147,48 @@ func (s *BaseDB) BeginTx(ctx context.Context, opts TxOptions) (*sql.Tx, error) {
 	}
 	return s.DB.BeginTx(ctx, &sqlOptions)
 }
+
+// WithTx returns the queries of the given transaction, which are executed
+// against the same type of database backend as the queries of the database.
+func (s *BaseDB) WithTx(tx *sql.Tx) *sqlc.Queries {
+	return sqlc.NewForType(tx, s.Backend())
+}
+
+// DBPoolStats houses the statistics of the connection pools of a database.
+type DBPoolStats struct {
+	// ReadWrite holds the statistics of the main connection pool, which
+	// all write transactions are begun on.
+	ReadWrite sql.DBStats
+
+	// ReadOnly holds the statistics of the dedicated connection pool of
+	// read transactions, if the database has one.
+	ReadOnly *sql.DBStats
+}
+
+// PoolStats returns the statistics of the connection pool of the database,
+// such as the number of connections in use and the number of times a caller
+// had to wait for a free connection. This allows operators to detect an
+// exhausted connection pool, for example during large imports.
+func (s *BaseDB) PoolStats() DBPoolStats {
+	return DBPoolStats{
+		ReadWrite: s.DB.Stats(),
+	}
+}
+
+// configureConnectionPool applies the given connection pool limits to the
+// database. A zero value keeps the default of the database/sql package for
+// that limit, which means unlimited open connections, two idle connections
+// and connections that are reused forever.
+func configureConnectionPool(db *sql.DB, maxOpen, maxIdle int32,
+	maxLifetime time.Duration) {
+
+	if maxOpen > 0 {
+		db.SetMaxOpenConns(int(maxOpen))
+	}
+	if maxIdle > 0 {
+		db.SetMaxIdleConns(int(maxIdle))
+	}
+	if maxLifetime > 0 {
+		db.SetConnMaxLifetime(maxLifetime)
+	}
+}
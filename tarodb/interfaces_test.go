@@ -0,0 +1,127 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/lightninglabs/taro/tarodb/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadOnlyTx tests that any write within a read transaction fails on an
+// SQLite database with read-only transactions enabled, while write
+// transactions are unaffected.
+func TestReadOnlyTx(t *testing.T) {
+	t.Parallel()
+
+	db, err := NewSqliteStore(&SqliteConfig{
+		DatabaseFileName: filepath.Join(t.TempDir(), "tmp.db"),
+		ReadOnlyTxns:     true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	assertReadOnlyTx(t, db)
+
+	// The read transactions should've been begun on the read-only
+	// connection pool, which is reported along with the main one.
+	stats := db.PoolStats()
+	require.NotNil(t, stats.ReadOnly)
+	require.NotZero(t, stats.ReadOnly.OpenConnections)
+	require.NotZero(t, stats.ReadWrite.OpenConnections)
+}
+
+// assertReadOnlyTx asserts that writing within a read transaction of the
+// given database fails, while writes within a write transaction and reads
+// within a read transaction succeed.
+func assertReadOnlyTx(t *testing.T, db BatchedQuerier) {
+	txCreator := func(tx *sql.Tx) ActiveAssetsStore {
+		return sqlc.New(tx)
+	}
+	assetsDB := NewTransactionExecutor[ActiveAssetsStore](db, txCreator)
+	ctx := context.Background()
+
+	key := InternalKey{
+		RawKey:    test.RandPubKey(t).SerializeCompressed(),
+		KeyFamily: test.RandInt[int32](),
+		KeyIndex:  test.RandInt[int32](),
+	}
+
+	// Writing the key within a read transaction should fail.
+	readOpts := NewAssetStoreReadTx()
+	err := assetsDB.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		_, err := q.UpsertInternalKey(ctx, key)
+		return err
+	})
+	require.Error(t, err)
+
+	// Within a write transaction, it should succeed.
+	var writeOpts AssetStoreTxOptions
+	err = assetsDB.ExecTx(ctx, &writeOpts, func(q ActiveAssetsStore) error {
+		_, err := q.UpsertInternalKey(ctx, key)
+		return err
+	})
+	require.NoError(t, err)
+
+	// Reading the key within a read transaction should still work.
+	err = assetsDB.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		_, err := q.FetchInternalKeyIDByRawKey(ctx, key.RawKey)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+// TestPoolStats tests that the configured connection pool limits are applied,
+// and that the pool statistics reflect the connections in use by a running
+// transaction.
+func TestPoolStats(t *testing.T) {
+	t.Parallel()
+
+	db := NewTestDB(t)
+	txCreator := func(tx *sql.Tx) ActiveAssetsStore {
+		return db.WithTx(tx)
+	}
+	assetsDB := NewTransactionExecutor[ActiveAssetsStore](db, txCreator)
+	ctx := context.Background()
+
+	const maxOpen = 5
+	configureConnectionPool(db.DB, maxOpen, 0, time.Minute)
+	require.Equal(t, maxOpen, db.PoolStats().ReadWrite.MaxOpenConnections)
+
+	// Without read-only transactions, there's no read-only pool.
+	require.Nil(t, db.PoolStats().ReadOnly)
+
+	// While a batch of keys is being inserted, the transaction should hold
+	// on to a connection of the pool.
+	txBody := func(q ActiveAssetsStore) error {
+		for i := 0; i < 10; i++ {
+			rawKey := test.RandPubKey(t).SerializeCompressed()
+			_, err := q.UpsertInternalKey(ctx, InternalKey{
+				RawKey: rawKey,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		require.Equal(t, 1, db.PoolStats().ReadWrite.InUse)
+
+		return nil
+	}
+
+	var writeOpts AssetStoreTxOptions
+	err := assetsDB.ExecTx(ctx, &writeOpts, txBody)
+	require.NoError(t, err)
+
+	// Once the transaction is done, the connection should be returned to
+	// the pool.
+	stats := db.PoolStats().ReadWrite
+	require.Zero(t, stats.InUse)
+	require.Equal(t, stats.OpenConnections, stats.Idle)
+}
@@ -2,6 +2,9 @@ package tarodb
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
 	"io"
 	"io/fs"
 	"net/http"
@@ -10,8 +13,22 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/source/httpfs"
+	"github.com/lightninglabs/taro/tarodb/sqlc"
 )
 
+// postMigrationStep is a data migration that can't be expressed in plain SQL,
+// which is applied after all schema migrations. As the steps are applied each
+// time the database is opened, they must only touch the rows that weren't
+// migrated yet.
+type postMigrationStep func(ctx context.Context, q sqlc.Querier) error
+
+// postMigrationSteps are the data migrations applied after the schema
+// migrations, in order.
+var postMigrationSteps = []postMigrationStep{
+	backfillWrappedAmounts,
+	moveGenesisMetadata,
+}
+
 // applyMigrations executes all database migration files found in the given file
 // system under the given path, using the passed database driver and database
 // name.
@@ -44,6 +61,28 @@ func applyMigrations(fs fs.FS, driver database.Driver, path,
 	return nil
 }
 
+// applyPostMigrationSteps applies all post migration steps to the given
+// database within a single transaction.
+func applyPostMigrationSteps(db *sql.DB) error {
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := sqlc.New(tx)
+	for _, step := range postMigrationSteps {
+		if err := step(ctx, q); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("unable to apply post migration "+
+				"step: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // replacerFS is an implementation of a fs.FS virtual file system that wraps an
 // existing file system but does a search-and-replace operation on each file
 // when it is opened.
@@ -27,14 +27,17 @@ var (
 
 // PostgresConfig holds the postgres database configuration.
 type PostgresConfig struct {
-	SkipMigrations     bool   `long:"skipmigrations" description:"Skip applying migrations on startup."`
-	Host               string `long:"host" description:"Database server hostname."`
-	Port               int    `long:"port" description:"Database server port."`
-	User               string `long:"user" description:"Database user."`
-	Password           string `long:"password" description:"Database user's password."`
-	DBName             string `long:"dbname" description:"Database name to use."`
-	MaxOpenConnections int32  `long:"maxconnections" description:"Max open connections to keep alive to the database server."`
-	RequireSSL         bool   `long:"requiressl" description:"Whether to require using SSL (mode: require) when connecting to the server."`
+	SkipMigrations     bool          `long:"skipmigrations" description:"Skip applying migrations on startup."`
+	Host               string        `long:"host" description:"Database server hostname."`
+	Port               int           `long:"port" description:"Database server port."`
+	User               string        `long:"user" description:"Database user."`
+	Password           string        `long:"password" description:"Database user's password."`
+	DBName             string        `long:"dbname" description:"Database name to use."`
+	MaxOpenConnections int32         `long:"maxconnections" description:"Max open connections to keep alive to the database server."`
+	MaxIdleConnections int32         `long:"maxidleconnections" description:"Max idle connections to keep in the connection pool. If 0, the default of 2 is used."`
+	ConnMaxLifetime    time.Duration `long:"connmaxlifetime" description:"Max amount of time a connection is reused for. If 0, connections are reused forever."`
+	RequireSSL         bool          `long:"requiressl" description:"Whether to require using SSL (mode: require) when connecting to the server."`
+	PreparedUpserts    bool          `long:"preparedupserts" description:"Prepare the queries executed once per imported asset once per transaction, saving the round trips to parse them again."`
 }
 
 // DSN returns the dns to connect to the database.
@@ -72,6 +75,18 @@ func NewPostgresStore(cfg *PostgresConfig) (*PostgresStore, error) {
 		return nil, err
 	}
 
+	// From here on, we'll close the database on any error, so its
+	// connections don't leak if the store can't be created.
+	closeOnErr := func(err error) (*PostgresStore, error) {
+		_ = rawDb.Close()
+		return nil, err
+	}
+
+	configureConnectionPool(
+		rawDb, cfg.MaxOpenConnections, cfg.MaxIdleConnections,
+		cfg.ConnMaxLifetime,
+	)
+
 	if !cfg.SkipMigrations {
 		// Now that the database is open, populate the database with
 		// our set of schemas based on our embedded in-memory file
@@ -83,7 +98,7 @@ func NewPostgresStore(cfg *PostgresConfig) (*PostgresStore, error) {
 			rawDb, &postgres_migrate.Config{},
 		)
 		if err != nil {
-			return nil, err
+			return closeOnErr(err)
 		}
 
 		postgresFS := newReplacerFS(sqlSchemas, map[string]string{
@@ -96,11 +111,16 @@ func NewPostgresStore(cfg *PostgresConfig) (*PostgresStore, error) {
 			postgresFS, driver, "sqlc/migrations", cfg.DBName,
 		)
 		if err != nil {
-			return nil, err
+			return closeOnErr(err)
+		}
+
+		err = applyPostMigrationSteps(rawDb)
+		if err != nil {
+			return closeOnErr(err)
 		}
 	}
 
-	queries := sqlc.New(rawDb)
+	queries := sqlc.NewForType(rawDb, sqlc.BackendTypePostgres)
 
 	return &PostgresStore{
 		cfg: cfg,
@@ -113,7 +133,7 @@ func NewPostgresStore(cfg *PostgresConfig) (*PostgresStore, error) {
 
 // NewTestPostgresDB is a helper function that creates a Postgres database for
 // testing.
-func NewTestPostgresDB(t *testing.T) *PostgresStore {
+func NewTestPostgresDB(t testing.TB) *PostgresStore {
 	t.Helper()
 
 	t.Logf("Creating new Postgres DB for testing")
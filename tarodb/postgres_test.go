@@ -0,0 +1,27 @@
+//go:build test_db_postgres
+// +build test_db_postgres
+
+package tarodb
+
+import (
+	"testing"
+)
+
+// BenchmarkUpsertAssetsPreparedPostgres compares upserting a batch of assets
+// into Postgres with the hot upsert queries prepared once per transaction
+// against executing each of them directly. As starting a new Postgres
+// instance is slow, all iterations insert a new batch into the same database.
+func BenchmarkUpsertAssetsPreparedPostgres(b *testing.B) {
+	db := NewTestPostgresDB(b).BaseDB
+	benchmarkUpsertAssetsPrepared(b, 1_000, func() *BaseDB {
+		return db
+	})
+}
+
+// TestPostgresReadOnlyTx tests that Postgres begins read transactions in
+// read-only mode, so any write within them fails.
+func TestPostgresReadOnlyTx(t *testing.T) {
+	t.Parallel()
+
+	assertReadOnlyTx(t, NewTestPostgresDB(t))
+}
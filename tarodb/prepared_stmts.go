@@ -0,0 +1,148 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/lightninglabs/taro/tarodb/sqlc"
+)
+
+// preparedUpsertQueries are the names of the queries that are executed once
+// per asset when importing assets, and are therefore worth preparing once per
+// transaction.
+var preparedUpsertQueries = map[string]struct{}{
+	"UpsertInternalKey": {},
+	"UpsertScriptKey":   {},
+	"InsertNewAsset":    {},
+}
+
+// queryName returns the name sqlc embeds at the start of each of its queries,
+// or an empty string if the query isn't named.
+func queryName(query string) string {
+	const namePrefix = "-- name: "
+
+	if !strings.HasPrefix(query, namePrefix) {
+		return ""
+	}
+
+	name := query[len(namePrefix):]
+	if end := strings.IndexAny(name, " \n"); end >= 0 {
+		name = name[:end]
+	}
+
+	return name
+}
+
+// preparedStmtTx wraps a database transaction, and prepares a set of queries
+// the first time they're executed within it, so repeated executions only bind
+// their arguments. All other queries are passed through to the transaction.
+// The prepared statements are closed by the database once the transaction is
+// committed or rolled back.
+type preparedStmtTx struct {
+	*sql.Tx
+
+	// queryNames is the set of queries that are prepared.
+	queryNames map[string]struct{}
+
+	// stmts holds the statements prepared so far, keyed by query.
+	stmts map[string]*sql.Stmt
+}
+
+// newPreparedStmtTx creates a new preparedStmtTx that prepares the queries
+// with the given names within the passed transaction.
+func newPreparedStmtTx(tx *sql.Tx,
+	queryNames map[string]struct{}) *preparedStmtTx {
+
+	return &preparedStmtTx{
+		Tx:         tx,
+		queryNames: queryNames,
+		stmts:      make(map[string]*sql.Stmt),
+	}
+}
+
+// stmt returns the prepared statement of the given query, preparing it if it
+// wasn't yet. False is returned if the query isn't prepared, or preparing it
+// failed, in which case it should be executed directly.
+func (p *preparedStmtTx) stmt(ctx context.Context,
+	query string) (*sql.Stmt, bool) {
+
+	if stmt, ok := p.stmts[query]; ok {
+		return stmt, true
+	}
+
+	if _, ok := p.queryNames[queryName(query)]; !ok {
+		return nil, false
+	}
+
+	// If we fail to prepare the statement, executing the query directly
+	// will surface the same error to the caller.
+	stmt, err := p.Tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, false
+	}
+	p.stmts[query] = stmt
+
+	return stmt, true
+}
+
+// ExecContext executes a query that doesn't return rows, using its prepared
+// statement if there is one.
+//
+// NOTE: This implements the sqlc.DBTX interface.
+func (p *preparedStmtTx) ExecContext(ctx context.Context, query string,
+	args ...interface{}) (sql.Result, error) {
+
+	if stmt, ok := p.stmt(ctx, query); ok {
+		return stmt.ExecContext(ctx, args...)
+	}
+
+	return p.Tx.ExecContext(ctx, query, args...)
+}
+
+// QueryContext executes a query that returns rows, using its prepared
+// statement if there is one.
+//
+// NOTE: This implements the sqlc.DBTX interface.
+func (p *preparedStmtTx) QueryContext(ctx context.Context, query string,
+	args ...interface{}) (*sql.Rows, error) {
+
+	if stmt, ok := p.stmt(ctx, query); ok {
+		return stmt.QueryContext(ctx, args...)
+	}
+
+	return p.Tx.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one
+// row, using its prepared statement if there is one.
+//
+// NOTE: This implements the sqlc.DBTX interface.
+func (p *preparedStmtTx) QueryRowContext(ctx context.Context, query string,
+	args ...interface{}) *sql.Row {
+
+	if stmt, ok := p.stmt(ctx, query); ok {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+
+	return p.Tx.QueryRowContext(ctx, query, args...)
+}
+
+// WithPreparedUpsertsTx returns the queries of the given transaction, with
+// the queries executed once per imported asset prepared the first time
+// they're used, and reused for the rest of the transaction.
+//
+// NOTE: On SQLite, importing 10k assets this way isn't measurably faster than
+// through the plain queries (see BenchmarkUpsertAssetsPrepared), so it's only
+// used for the asset store on Postgres, and only if enabled with the
+// PreparedUpserts option of the PostgresConfig (see
+// BenchmarkUpsertAssetsPreparedPostgres).
+func (s *BaseDB) WithPreparedUpsertsTx(tx *sql.Tx) *sqlc.Queries {
+	return sqlc.NewForType(
+		newPreparedStmtTx(tx, preparedUpsertQueries), s.Backend(),
+	)
+}
+
+// A compile-time assertion to ensure that preparedStmtTx meets the sqlc.DBTX
+// interface.
+var _ sqlc.DBTX = (*preparedStmtTx)(nil)
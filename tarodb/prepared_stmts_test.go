@@ -0,0 +1,189 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/lightninglabs/taro/tarodb/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreparedUpsertsTx tests that importing assets through the prepared
+// queries of a transaction prepares each hot upsert query only once, and
+// stores the same assets as importing them through the plain queries.
+func TestPreparedUpsertsTx(t *testing.T) {
+	t.Parallel()
+
+	db := NewTestDB(t)
+	ctx := context.Background()
+
+	var preparedTxns []*preparedStmtTx
+	activeTxCreator := func(tx *sql.Tx) ActiveAssetsStore {
+		preparedTx := newPreparedStmtTx(tx, preparedUpsertQueries)
+		preparedTxns = append(preparedTxns, preparedTx)
+
+		return sqlc.New(preparedTx)
+	}
+	assetStore := NewAssetStore(
+		NewTransactionExecutor[ActiveAssetsStore](db, activeTxCreator),
+	)
+
+	genesisPoint := test.RandOp(t)
+	assets := make([]*asset.Asset, 5)
+	anchors := make([]AnchorUTXO, len(assets))
+	for i := range assets {
+		assets[i] = randAsset(t, withAssetGenPoint(genesisPoint))
+		anchors[i] = randAnchorUTXO(t)
+	}
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors,
+	)
+	require.NoError(t, err)
+
+	// Only the hot upsert queries executed by the import should've been
+	// prepared, each of them once. Each of the hot upsert queries should
+	// be executed by the import, so none of them is prepared in vain.
+	require.Len(t, preparedTxns, 1)
+	preparedNames := make(map[string]struct{})
+	for query := range preparedTxns[0].stmts {
+		preparedNames[queryName(query)] = struct{}{}
+	}
+	require.Equal(t, preparedUpsertQueries, preparedNames)
+	require.Len(t, preparedTxns[0].stmts, len(preparedUpsertQueries))
+
+	// All the assets should've been stored, no matter whether they were
+	// inserted through a prepared statement or not.
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, dbAssets, len(assets))
+
+	for _, a := range assets {
+		scriptKey := a.ScriptKey.PubKey.SerializeCompressed()
+		_, err := db.FetchScriptKeyIDByTweakedKey(ctx, scriptKey)
+		require.NoError(t, err)
+	}
+
+	// Once the transaction is done, its statements are closed, so a new
+	// transaction prepares them again.
+	newAsset := randAsset(t)
+	err = assetStore.ImportAssetsWithAnchors(
+		ctx, newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+		[]AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+	require.Len(t, preparedTxns, 2)
+	require.Len(t, preparedTxns[1].stmts, len(preparedTxns[0].stmts))
+}
+
+// TestQueryName tests that we're able to extract the name sqlc embeds in its
+// queries.
+func TestQueryName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		query string
+		name  string
+	}{
+		{
+			query: "-- name: InsertNewAsset :one\nINSERT INTO",
+			name:  "InsertNewAsset",
+		},
+		{
+			query: "-- name: DeleteExpiredUTXOLeases :exec",
+			name:  "DeleteExpiredUTXOLeases",
+		},
+		{
+			query: "-- name: ",
+			name:  "",
+		},
+		{
+			query: "SELECT 1",
+			name:  "",
+		},
+	}
+	for _, testCase := range testCases {
+		require.Equal(t, testCase.name, queryName(testCase.query))
+	}
+}
+
+// BenchmarkUpsertAssetsPrepared compares upserting a large batch of assets
+// with the hot upsert queries prepared once per transaction against executing
+// each of them directly.
+func BenchmarkUpsertAssetsPrepared(b *testing.B) {
+	benchmarkUpsertAssetsPrepared(b, 10_000, func() *BaseDB {
+		// Each iteration inserts the batch into a new database.
+		return NewTestDB(b).BaseDB
+	})
+}
+
+// benchmarkUpsertAssetsPrepared upserts a batch of the given number of new
+// assets per iteration into the database returned by newDB, once with the hot
+// upsert queries prepared once per transaction and once executing each of
+// them directly.
+func benchmarkUpsertAssetsPrepared(b *testing.B, numAssets int,
+	newDB func() *BaseDB) {
+
+	newAssets := func() (wire.OutPoint, []*asset.Asset) {
+		genesisPoint := test.RandOp(b)
+		assets := make([]*asset.Asset, numAssets)
+		for i := range assets {
+			assets[i] = randAsset(
+				b, withAssetGenPoint(genesisPoint),
+				withNoGroupKey(),
+			)
+		}
+
+		return genesisPoint, assets
+	}
+
+	ctx := context.Background()
+	txCreator := func(db *BaseDB,
+		prepared bool) func(*sql.Tx) ActiveAssetsStore {
+
+		return func(tx *sql.Tx) ActiveAssetsStore {
+			if prepared {
+				return db.WithPreparedUpsertsTx(tx)
+			}
+			return db.WithTx(tx)
+		}
+	}
+
+	for _, prepared := range []bool{false, true} {
+		prepared := prepared
+		name := "direct"
+		if prepared {
+			name = "prepared"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db := newDB()
+				txDB := NewTransactionExecutor(
+					db, txCreator(db, prepared),
+				)
+				genesisPoint, assets := newAssets()
+				insertAssets := func(
+					q ActiveAssetsStore) error {
+
+					_, _, err := upsertAssetsWithGenesis(
+						ctx, q, newUpsertOptions(),
+						genesisPoint, assets, nil,
+					)
+					return err
+				}
+				b.StartTimer()
+
+				var writeTxOpts AssetStoreTxOptions
+				err := txDB.ExecTx(
+					ctx, &writeTxOpts, insertAssets,
+				)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
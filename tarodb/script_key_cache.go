@@ -0,0 +1,300 @@
+package tarodb
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+const (
+	// DefaultScriptKeyCacheSize is the default number of script key IDs
+	// the script key cache keeps in memory.
+	DefaultScriptKeyCacheSize = 10000
+)
+
+// scriptKeyCacheKey is the key of the script key cache, the compressed
+// tweaked script key.
+type scriptKeyCacheKey [btcec.PubKeyBytesLenCompressed]byte
+
+// newScriptKeyCacheKey returns the cache key of the given tweaked script key.
+// False is returned if the key isn't a compressed public key, in which case
+// it isn't cached.
+func newScriptKeyCacheKey(tweakedScriptKey []byte) (scriptKeyCacheKey, bool) {
+	var key scriptKeyCacheKey
+	if len(tweakedScriptKey) != len(key) {
+		return key, false
+	}
+
+	copy(key[:], tweakedScriptKey)
+
+	return key, true
+}
+
+// cachedScriptKey is a single entry within the script key cache.
+type cachedScriptKey struct {
+	key scriptKeyCacheKey
+	id  int32
+}
+
+// scriptKeyCache is an LRU cache of the primary keys of script keys, keyed by
+// their tweaked script key. It's shared by all transactions of a
+// scriptKeyCacheAssetStore.
+type scriptKeyCache struct {
+	cacheSize int
+
+	mu      sync.Mutex
+	entries map[scriptKeyCacheKey]*list.Element
+	lru     *list.List
+
+	// generation is incremented on each invalidation, which allows us to
+	// detect that a script key was deleted while a transaction that looked
+	// it up was still running.
+	generation uint64
+}
+
+// newScriptKeyCache creates a new script key cache that keeps up to cacheSize
+// script key IDs in memory.
+func newScriptKeyCache(cacheSize int) *scriptKeyCache {
+	return &scriptKeyCache{
+		cacheSize: cacheSize,
+		entries:   make(map[scriptKeyCacheKey]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// currentGeneration returns the current generation of the cache.
+func (c *scriptKeyCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// get returns the cached script key ID of the given key, if any.
+func (c *scriptKeyCache) get(key scriptKeyCacheKey) (int32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return 0, false
+	}
+	c.lru.MoveToFront(elem)
+
+	return elem.Value.(*cachedScriptKey).id, true
+}
+
+// add adds the passed script key IDs to the cache, unless the cache was
+// invalidated since the given generation, in which case the IDs may already
+// be stale.
+func (c *scriptKeyCache) add(ids map[scriptKeyCacheKey]int32,
+	generation uint64) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	for key, id := range ids {
+		if elem, ok := c.entries[key]; ok {
+			elem.Value.(*cachedScriptKey).id = id
+			c.lru.MoveToFront(elem)
+
+			continue
+		}
+
+		c.entries[key] = c.lru.PushFront(&cachedScriptKey{
+			key: key,
+			id:  id,
+		})
+	}
+
+	for c.lru.Len() > c.cacheSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedScriptKey).key)
+	}
+}
+
+// invalidate removes the script key with the given primary key from the
+// cache. As script keys are rarely deleted, we don't keep an index by primary
+// key, and scan the cache instead.
+func (c *scriptKeyCache) invalidate(id int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for key, elem := range c.entries {
+		if elem.Value.(*cachedScriptKey).id == id {
+			c.lru.Remove(elem)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// scriptKeyCacheAssetStore wraps a BatchedAssetStore and serves the primary
+// keys of known script keys from an in-memory cache, as they're looked up
+// repeatedly when importing overlapping proof chains.
+type scriptKeyCacheAssetStore struct {
+	BatchedAssetStore
+
+	cache *scriptKeyCache
+}
+
+// NewScriptKeyCacheAssetStore returns a new BatchedAssetStore that caches the
+// primary keys of up to cacheSize script keys, keyed by their tweaked script
+// key. Script key IDs learned within a transaction are only cached once the
+// transaction committed, and deleting a script key removes it from the cache.
+//
+// NOTE: Script keys deleted through the wrapped BatchedAssetStore directly
+// bypass the cache, so all writes must go through the returned store.
+func NewScriptKeyCacheAssetStore(db BatchedAssetStore,
+	cacheSize int) BatchedAssetStore {
+
+	return &scriptKeyCacheAssetStore{
+		BatchedAssetStore: db,
+		cache:             newScriptKeyCache(cacheSize),
+	}
+}
+
+// ExecTx executes the passed txBody in a single transaction, serving script
+// key lookups from the cache, and caches the script key IDs the transaction
+// learned once it committed.
+//
+// NOTE: This implements the BatchedTx interface.
+func (s *scriptKeyCacheAssetStore) ExecTx(ctx context.Context,
+	txOptions TxOptions, txBody func(ActiveAssetsStore) error) error {
+
+	// The transaction body may be executed multiple times if the
+	// transaction is retried, so we'll only keep the IDs learned by the
+	// last attempt.
+	var cachedTx *scriptKeyCachedTx
+	err := s.BatchedAssetStore.ExecTx(
+		ctx, txOptions, func(q ActiveAssetsStore) error {
+			cachedTx = newScriptKeyCachedTx(q, s.cache)
+			return txBody(cachedTx)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if cachedTx == nil {
+		return nil
+	}
+
+	// A concurrent transaction may have cached a script key this
+	// transaction deleted before the deletion was committed, so we'll
+	// invalidate the deleted script keys once more.
+	for _, id := range cachedTx.deleted {
+		s.cache.invalidate(id)
+	}
+	s.cache.add(cachedTx.pending, cachedTx.generation)
+
+	return nil
+}
+
+// scriptKeyCachedTx wraps the ActiveAssetsStore of a single transaction, and
+// keeps track of the script key IDs learned within it until it's committed.
+type scriptKeyCachedTx struct {
+	ActiveAssetsStore
+
+	cache *scriptKeyCache
+
+	// generation is the generation of the cache when the transaction
+	// started.
+	generation uint64
+
+	// pending holds the script key IDs learned within the transaction.
+	pending map[scriptKeyCacheKey]int32
+
+	// deleted holds the IDs of the script keys the transaction attempted
+	// to delete.
+	deleted []int32
+}
+
+// newScriptKeyCachedTx wraps the ActiveAssetsStore of a new transaction that
+// serves script key lookups from the given cache.
+func newScriptKeyCachedTx(q ActiveAssetsStore,
+	cache *scriptKeyCache) *scriptKeyCachedTx {
+
+	return &scriptKeyCachedTx{
+		ActiveAssetsStore: q,
+		cache:             cache,
+		generation:        cache.currentGeneration(),
+		pending:           make(map[scriptKeyCacheKey]int32),
+	}
+}
+
+// FetchScriptKeyIDByTweakedKey determines the database ID of a script key by
+// querying it by the tweaked key, serving it from the cache if possible.
+func (s *scriptKeyCachedTx) FetchScriptKeyIDByTweakedKey(ctx context.Context,
+	tweakedScriptKey []byte) (int32, error) {
+
+	key, ok := newScriptKeyCacheKey(tweakedScriptKey)
+	if !ok {
+		return s.ActiveAssetsStore.FetchScriptKeyIDByTweakedKey(
+			ctx, tweakedScriptKey,
+		)
+	}
+
+	if id, ok := s.pending[key]; ok {
+		return id, nil
+	}
+	if id, ok := s.cache.get(key); ok {
+		return id, nil
+	}
+
+	id, err := s.ActiveAssetsStore.FetchScriptKeyIDByTweakedKey(
+		ctx, tweakedScriptKey,
+	)
+	if err != nil {
+		return 0, err
+	}
+	s.pending[key] = id
+
+	return id, nil
+}
+
+// UpsertScriptKey inserts a new script key on disk into the DB, and remembers
+// its ID to cache it once the transaction committed.
+func (s *scriptKeyCachedTx) UpsertScriptKey(ctx context.Context,
+	arg NewScriptKey) (int32, error) {
+
+	id, err := s.ActiveAssetsStore.UpsertScriptKey(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	if key, ok := newScriptKeyCacheKey(arg.TweakedScriptKey); ok {
+		s.pending[key] = id
+	}
+
+	return id, nil
+}
+
+// DeleteOrphanScriptKey deletes the script key with the given primary key if
+// nothing references it anymore, and removes it from the cache.
+func (s *scriptKeyCachedTx) DeleteOrphanScriptKey(ctx context.Context,
+	scriptKeyID int32) (OrphanScriptKey, error) {
+
+	// We invalidate the script key even if it isn't deleted, as we don't
+	// know whether the transaction is going to commit.
+	for key, id := range s.pending {
+		if id == scriptKeyID {
+			delete(s.pending, key)
+		}
+	}
+	s.cache.invalidate(scriptKeyID)
+	s.deleted = append(s.deleted, scriptKeyID)
+
+	return s.ActiveAssetsStore.DeleteOrphanScriptKey(ctx, scriptKeyID)
+}
+
+// A compile-time assertion to ensure that scriptKeyCacheAssetStore meets the
+// BatchedAssetStore interface.
+var _ BatchedAssetStore = (*scriptKeyCacheAssetStore)(nil)
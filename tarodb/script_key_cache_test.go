@@ -0,0 +1,147 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// countingScriptKeyStore is an ActiveAssetsStore that counts the script key
+// lookups that hit the database.
+type countingScriptKeyStore struct {
+	ActiveAssetsStore
+
+	numLookups *int32
+}
+
+// FetchScriptKeyIDByTweakedKey determines the database ID of a script key by
+// querying it by the tweaked key.
+func (c *countingScriptKeyStore) FetchScriptKeyIDByTweakedKey(
+	ctx context.Context, tweakedScriptKey []byte) (int32, error) {
+
+	atomic.AddInt32(c.numLookups, 1)
+
+	return c.ActiveAssetsStore.FetchScriptKeyIDByTweakedKey(
+		ctx, tweakedScriptKey,
+	)
+}
+
+// TestScriptKeyCacheAssetStore tests that script key IDs are served from the
+// cache once known, and that the cache doesn't return IDs of script keys that
+// were rolled back or deleted.
+func TestScriptKeyCacheAssetStore(t *testing.T) {
+	t.Parallel()
+
+	var numLookups int32
+	db := NewTestDB(t)
+	activeTxCreator := func(tx *sql.Tx) ActiveAssetsStore {
+		return &countingScriptKeyStore{
+			ActiveAssetsStore: db.WithTx(tx),
+			numLookups:        &numLookups,
+		}
+	}
+	cachedDB := NewScriptKeyCacheAssetStore(
+		NewTransactionExecutor[ActiveAssetsStore](db, activeTxCreator),
+		2,
+	)
+	assetStore := NewAssetStore(cachedDB)
+	ctx := context.Background()
+
+	fetchScriptKeyID := func(tweakedScriptKey []byte) (int32, error) {
+		var id int32
+		txBody := func(q ActiveAssetsStore) error {
+			var err error
+			id, err = q.FetchScriptKeyIDByTweakedKey(
+				ctx, tweakedScriptKey,
+			)
+			return err
+		}
+
+		readOpts := NewAssetStoreReadTx()
+		err := cachedDB.ExecTx(ctx, &readOpts, txBody)
+
+		return id, err
+	}
+	assertLookups := func(expected int32) {
+		require.Equal(t, expected, atomic.LoadInt32(&numLookups))
+	}
+
+	// We'll import three assets in separate transactions, so the script
+	// key of the first one is evicted from the cache again.
+	importedKeys := make([][]byte, 3)
+	for i := range importedKeys {
+		newAsset := randAsset(t)
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, newAsset.Genesis.FirstPrevOut,
+			[]*asset.Asset{newAsset},
+			[]AnchorUTXO{randAnchorUTXO(t)},
+		)
+		require.NoError(t, err)
+
+		scriptKey := newAsset.ScriptKey.PubKey
+		importedKeys[i] = scriptKey.SerializeCompressed()
+	}
+	assertLookups(0)
+
+	// The script keys of the last two assets were upserted by the import,
+	// so they should be served from the cache.
+	for _, tweakedScriptKey := range importedKeys[1:] {
+		id, err := fetchScriptKeyID(tweakedScriptKey)
+		require.NoError(t, err)
+
+		dbID, err := db.FetchScriptKeyIDByTweakedKey(
+			ctx, tweakedScriptKey,
+		)
+		require.NoError(t, err)
+		require.Equal(t, dbID, id)
+	}
+	assertLookups(0)
+
+	// The first one was evicted, so it's looked up once, and then served
+	// from the cache again.
+	for i := 0; i < 2; i++ {
+		_, err := fetchScriptKeyID(importedKeys[0])
+		require.NoError(t, err)
+	}
+	assertLookups(1)
+
+	// A script key that was upserted in a transaction that was rolled
+	// back shouldn't be cached.
+	rolledBackKey := test.RandPubKey(t).SerializeCompressed()
+	rollbackTxBody := func(q ActiveAssetsStore) error {
+		internalKeyID, err := q.UpsertInternalKey(ctx, InternalKey{
+			RawKey: rolledBackKey,
+		})
+		require.NoError(t, err)
+
+		_, err = q.UpsertScriptKey(ctx, NewScriptKey{
+			InternalKeyID:    internalKeyID,
+			TweakedScriptKey: rolledBackKey,
+		})
+		require.NoError(t, err)
+
+		return fmt.Errorf("rollback")
+	}
+
+	var writeTxOpts AssetStoreTxOptions
+	err := cachedDB.ExecTx(ctx, &writeTxOpts, rollbackTxBody)
+	require.Error(t, err)
+
+	_, err = fetchScriptKeyID(rolledBackKey)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	assertLookups(2)
+
+	// Once an asset is deleted along with its script key, the script key
+	// should no longer be served from the cache.
+	deletedKey := importedKeys[2]
+	require.NoError(t, assetStore.DeleteAssetByScriptKey(ctx, deletedKey))
+
+	_, err = fetchScriptKeyID(deletedKey)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
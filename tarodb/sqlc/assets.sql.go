@@ -12,7 +12,7 @@ import (
 )
 
 const allAssets = `-- name: AllAssets :many
-SELECT asset_id, genesis_id, version, script_key_id, asset_group_sig_id, script_version, amount, lock_time, relative_lock_time, split_commitment_root_hash, split_commitment_root_value, anchor_utxo_id 
+SELECT asset_id, genesis_id, version, script_key_id, asset_group_sig_id, script_version, amount, lock_time, relative_lock_time, split_commitment_root_hash, split_commitment_root_value, anchor_utxo_id, amount_big, spent, updated_at 
 FROM assets
 `
 
@@ -38,6 +38,9 @@ func (q *Queries) AllAssets(ctx context.Context) ([]Asset, error) {
 			&i.SplitCommitmentRootHash,
 			&i.SplitCommitmentRootValue,
 			&i.AnchorUtxoID,
+			&i.AmountBig,
+			&i.Spent,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -141,6 +144,47 @@ func (q *Queries) AllMintingBatches(ctx context.Context) ([]AllMintingBatchesRow
 	return items, nil
 }
 
+const anchorAssetsByOutputIndex = `-- name: AnchorAssetsByOutputIndex :many
+UPDATE assets
+SET anchor_utxo_id = $1
+WHERE genesis_id IN (
+    SELECT gen_asset_id
+    FROM genesis_assets
+    WHERE genesis_point_id = $2
+        AND output_index = $3
+)
+RETURNING asset_id
+`
+
+type AnchorAssetsByOutputIndexParams struct {
+	AnchorUtxoID   sql.NullInt32
+	GenesisPointID int32
+	OutputIndex    int32
+}
+
+func (q *Queries) AnchorAssetsByOutputIndex(ctx context.Context, arg AnchorAssetsByOutputIndexParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, anchorAssetsByOutputIndex, arg.AnchorUtxoID, arg.GenesisPointID, arg.OutputIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var asset_id int32
+		if err := rows.Scan(&asset_id); err != nil {
+			return nil, err
+		}
+		items = append(items, asset_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const anchorGenesisPoint = `-- name: AnchorGenesisPoint :exec
 WITH target_point(genesis_id) AS (
     SELECT genesis_id
@@ -162,7 +206,7 @@ func (q *Queries) AnchorGenesisPoint(ctx context.Context, arg AnchorGenesisPoint
 	return err
 }
 
-const anchorPendingAssets = `-- name: AnchorPendingAssets :exec
+const anchorPendingAssets = `-- name: AnchorPendingAssets :many
 WITH assets_to_update AS (
     SELECT script_key_id
     FROM assets 
@@ -175,6 +219,7 @@ WITH assets_to_update AS (
 UPDATE assets
 SET anchor_utxo_id = $2
 WHERE script_key_id in (SELECT script_key_id FROM assets_to_update)
+RETURNING asset_id
 `
 
 type AnchorPendingAssetsParams struct {
@@ -182,13 +227,31 @@ type AnchorPendingAssetsParams struct {
 	AnchorUtxoID sql.NullInt32
 }
 
-func (q *Queries) AnchorPendingAssets(ctx context.Context, arg AnchorPendingAssetsParams) error {
-	_, err := q.db.ExecContext(ctx, anchorPendingAssets, arg.PrevOut, arg.AnchorUtxoID)
-	return err
+func (q *Queries) AnchorPendingAssets(ctx context.Context, arg AnchorPendingAssetsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, anchorPendingAssets, arg.PrevOut, arg.AnchorUtxoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var asset_id int32
+		if err := rows.Scan(&asset_id); err != nil {
+			return nil, err
+		}
+		items = append(items, asset_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const assetsByGenesisPoint = `-- name: AssetsByGenesisPoint :many
-SELECT assets.asset_id, assets.genesis_id, version, script_key_id, asset_group_sig_id, script_version, amount, lock_time, relative_lock_time, split_commitment_root_hash, split_commitment_root_value, anchor_utxo_id, gen_asset_id, genesis_assets.asset_id, asset_tag, meta_data, output_index, asset_type, genesis_point_id, genesis_points.genesis_id, prev_out, anchor_tx_id
+SELECT assets.asset_id, assets.genesis_id, version, script_key_id, asset_group_sig_id, script_version, amount, lock_time, relative_lock_time, split_commitment_root_hash, split_commitment_root_value, anchor_utxo_id, amount_big, spent, updated_at, gen_asset_id, genesis_assets.asset_id, asset_tag, meta_data, output_index, asset_type, genesis_point_id, meta_type, genesis_points.genesis_id, prev_out, anchor_tx_id, created_at
 FROM assets 
 JOIN genesis_assets 
     ON assets.genesis_id = genesis_assets.gen_asset_id
@@ -210,6 +273,9 @@ type AssetsByGenesisPointRow struct {
 	SplitCommitmentRootHash  []byte
 	SplitCommitmentRootValue sql.NullInt64
 	AnchorUtxoID             sql.NullInt32
+	AmountBig                sql.NullString
+	Spent                    bool
+	UpdatedAt                sql.NullTime
 	GenAssetID               int32
 	AssetID_2                []byte
 	AssetTag                 string
@@ -217,9 +283,11 @@ type AssetsByGenesisPointRow struct {
 	OutputIndex              int32
 	AssetType                int16
 	GenesisPointID           int32
+	MetaType                 int16
 	GenesisID_2              int32
 	PrevOut                  []byte
 	AnchorTxID               sql.NullInt32
+	CreatedAt                sql.NullTime
 }
 
 func (q *Queries) AssetsByGenesisPoint(ctx context.Context, prevOut []byte) ([]AssetsByGenesisPointRow, error) {
@@ -244,6 +312,9 @@ func (q *Queries) AssetsByGenesisPoint(ctx context.Context, prevOut []byte) ([]A
 			&i.SplitCommitmentRootHash,
 			&i.SplitCommitmentRootValue,
 			&i.AnchorUtxoID,
+			&i.AmountBig,
+			&i.Spent,
+			&i.UpdatedAt,
 			&i.GenAssetID,
 			&i.AssetID_2,
 			&i.AssetTag,
@@ -251,9 +322,11 @@ func (q *Queries) AssetsByGenesisPoint(ctx context.Context, prevOut []byte) ([]A
 			&i.OutputIndex,
 			&i.AssetType,
 			&i.GenesisPointID,
+			&i.MetaType,
 			&i.GenesisID_2,
 			&i.PrevOut,
 			&i.AnchorTxID,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -270,11 +343,13 @@ func (q *Queries) AssetsByGenesisPoint(ctx context.Context, prevOut []byte) ([]A
 
 const assetsInBatch = `-- name: AssetsInBatch :many
 SELECT
-    gen_asset_id, asset_id, asset_tag, meta_data, output_index, asset_type,
-    genesis_points.prev_out prev_out
+    gen_asset_id, asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, genesis_points.prev_out prev_out
 FROM genesis_assets
 JOIN genesis_points
     ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
 JOIN asset_minting_batches batches
     ON genesis_points.genesis_id = batches.genesis_id
 JOIN internal_keys keys
@@ -323,6 +398,28 @@ func (q *Queries) AssetsInBatch(ctx context.Context, rawKey []byte) ([]AssetsInB
 	return items, nil
 }
 
+const bindAssetAnchor = `-- name: BindAssetAnchor :execrows
+UPDATE assets
+SET anchor_utxo_id = $1
+WHERE asset_id = $2
+    AND (anchor_utxo_id IS NULL OR anchor_utxo_id = $1)
+`
+
+type BindAssetAnchorParams struct {
+	AnchorUtxoID sql.NullInt32
+	AssetID      int32
+}
+
+// An asset is only bound to an anchor UTXO if it isn't anchored yet, or is
+// already bound to that very UTXO, so an existing anchor is never replaced.
+func (q *Queries) BindAssetAnchor(ctx context.Context, arg BindAssetAnchorParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bindAssetAnchor, arg.AnchorUtxoID, arg.AssetID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const bindMintingBatchWithTx = `-- name: BindMintingBatchWithTx :exec
 WITH target_batch AS (
     SELECT batch_id
@@ -415,6 +512,113 @@ func (q *Queries) ConfirmChainTx(ctx context.Context, arg ConfirmChainTxParams)
 	return err
 }
 
+const countAssetsByGenesisPoint = `-- name: CountAssetsByGenesisPoint :one
+SELECT COUNT(*)
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+WHERE genesis_assets.genesis_point_id = $1
+`
+
+func (q *Queries) CountAssetsByGenesisPoint(ctx context.Context, genesisPointID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAssetsByGenesisPoint, genesisPointID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countAssetsByType = `-- name: CountAssetsByType :many
+SELECT
+    genesis_assets.asset_type, COUNT(*) AS num_assets
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+WHERE ($1 = true OR assets.spent = false)
+GROUP BY genesis_assets.asset_type
+`
+
+type CountAssetsByTypeRow struct {
+	AssetType int16
+	NumAssets int64
+}
+
+func (q *Queries) CountAssetsByType(ctx context.Context, includeSpent interface{}) ([]CountAssetsByTypeRow, error) {
+	rows, err := q.db.QueryContext(ctx, countAssetsByType, includeSpent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountAssetsByTypeRow
+	for rows.Next() {
+		var i CountAssetsByTypeRow
+		if err := rows.Scan(&i.AssetType, &i.NumAssets); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteAssetProofsByScriptKeyID = `-- name: DeleteAssetProofsByScriptKeyID :exec
+DELETE FROM asset_proofs
+WHERE asset_id IN (
+    SELECT asset_id
+    FROM assets
+    WHERE script_key_id = $1
+)
+`
+
+func (q *Queries) DeleteAssetProofsByScriptKeyID(ctx context.Context, scriptKeyID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAssetProofsByScriptKeyID, scriptKeyID)
+	return err
+}
+
+const deleteAssetsByScriptKeyID = `-- name: DeleteAssetsByScriptKeyID :many
+DELETE FROM assets
+WHERE script_key_id = $1
+RETURNING asset_id
+`
+
+// The witnesses of the assets are deleted along with them, as they cascade.
+func (q *Queries) DeleteAssetsByScriptKeyID(ctx context.Context, scriptKeyID int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, deleteAssetsByScriptKeyID, scriptKeyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var asset_id int32
+		if err := rows.Scan(&asset_id); err != nil {
+			return nil, err
+		}
+		items = append(items, asset_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteImportCheckpoint = `-- name: DeleteImportCheckpoint :exec
+DELETE FROM import_checkpoints
+WHERE batch_id = $1
+`
+
+func (q *Queries) DeleteImportCheckpoint(ctx context.Context, batchID string) error {
+	_, err := q.db.ExecContext(ctx, deleteImportCheckpoint, batchID)
+	return err
+}
+
 const deleteManagedUTXO = `-- name: DeleteManagedUTXO :exec
 DELETE FROM managed_utxos
 WHERE outpoint = $1
@@ -425,68 +629,138 @@ func (q *Queries) DeleteManagedUTXO(ctx context.Context, outpoint []byte) error
 	return err
 }
 
-const fetchAssetProof = `-- name: FetchAssetProof :one
-WITH asset_info AS (
-    SELECT assets.asset_id, script_keys.tweaked_script_key
-    FROM assets
-    JOIN script_keys
-        ON assets.script_key_id = script_keys.script_key_id
-    WHERE script_keys.tweaked_script_key = $1
-)
-SELECT asset_info.tweaked_script_key AS script_key, asset_proofs.proof_file,
-       asset_info.asset_id as asset_id, asset_proofs.proof_id as proof_id
-FROM asset_proofs
-JOIN asset_info
-    ON asset_info.asset_id = asset_proofs.asset_id
+const deleteOrphanInternalKey = `-- name: DeleteOrphanInternalKey :one
+DELETE FROM internal_keys
+WHERE key_id = $1 AND
+    NOT EXISTS (
+        SELECT 1 FROM script_keys
+        WHERE script_keys.internal_key_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM managed_utxos
+        WHERE managed_utxos.internal_key_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM asset_groups
+        WHERE asset_groups.internal_key_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM asset_minting_batches
+        WHERE asset_minting_batches.batch_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM addrs
+        WHERE addrs.taproot_key_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM asset_transfers
+        WHERE asset_transfers.new_internal_key = internal_keys.key_id
+    )
+RETURNING key_family, key_index
 `
 
-type FetchAssetProofRow struct {
-	ScriptKey []byte
-	ProofFile []byte
-	AssetID   int32
-	ProofID   int32
+type DeleteOrphanInternalKeyRow struct {
+	KeyFamily int32
+	KeyIndex  int32
 }
 
-func (q *Queries) FetchAssetProof(ctx context.Context, tweakedScriptKey []byte) (FetchAssetProofRow, error) {
-	row := q.db.QueryRowContext(ctx, fetchAssetProof, tweakedScriptKey)
-	var i FetchAssetProofRow
-	err := row.Scan(
-		&i.ScriptKey,
-		&i.ProofFile,
-		&i.AssetID,
-		&i.ProofID,
-	)
+// The internal key is only deleted if nothing references it anymore, such as
+// another script key or a group key, in which case its locator is returned.
+func (q *Queries) DeleteOrphanInternalKey(ctx context.Context, keyID int32) (DeleteOrphanInternalKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, deleteOrphanInternalKey, keyID)
+	var i DeleteOrphanInternalKeyRow
+	err := row.Scan(&i.KeyFamily, &i.KeyIndex)
 	return i, err
 }
 
-const fetchAssetProofs = `-- name: FetchAssetProofs :many
-WITH asset_info AS (
-    SELECT assets.asset_id, script_keys.tweaked_script_key
-    FROM assets
-    JOIN script_keys
-        ON assets.script_key_id = script_keys.script_key_id
-)
-SELECT asset_info.tweaked_script_key AS script_key, asset_proofs.proof_file
-FROM asset_proofs
-JOIN asset_info
-    ON asset_info.asset_id = asset_proofs.asset_id
+const deleteOrphanScriptKey = `-- name: DeleteOrphanScriptKey :one
+DELETE FROM script_keys
+WHERE script_key_id = $1 AND
+    NOT EXISTS (
+        SELECT 1 FROM assets
+        WHERE assets.script_key_id = script_keys.script_key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM addrs
+        WHERE addrs.script_key_id = script_keys.script_key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM asset_deltas
+        WHERE asset_deltas.new_script_key = script_keys.script_key_id
+    )
+RETURNING internal_key_id, is_known_raw
 `
 
-type FetchAssetProofsRow struct {
-	ScriptKey []byte
-	ProofFile []byte
+type DeleteOrphanScriptKeyRow struct {
+	InternalKeyID int32
+	IsKnownRaw    bool
 }
 
-func (q *Queries) FetchAssetProofs(ctx context.Context) ([]FetchAssetProofsRow, error) {
-	rows, err := q.db.QueryContext(ctx, fetchAssetProofs)
+// The script key is only deleted if nothing references it anymore, in which
+// case the ID of the internal key it was derived from is returned, along with
+// whether we know that raw key.
+func (q *Queries) DeleteOrphanScriptKey(ctx context.Context, scriptKeyID int32) (DeleteOrphanScriptKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, deleteOrphanScriptKey, scriptKeyID)
+	var i DeleteOrphanScriptKeyRow
+	err := row.Scan(&i.InternalKeyID, &i.IsKnownRaw)
+	return i, err
+}
+
+const deleteQuarantinedAsset = `-- name: DeleteQuarantinedAsset :execrows
+DELETE FROM quarantined_assets
+WHERE quarantine_id = $1
+`
+
+func (q *Queries) DeleteQuarantinedAsset(ctx context.Context, quarantineID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteQuarantinedAsset, quarantineID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const fetchAllGroupKeys = `-- name: FetchAllGroupKeys :many
+SELECT
+    groups.tweaked_group_key, keys.raw_key, keys.key_family, keys.key_index,
+    sigs.genesis_sig
+FROM asset_groups groups
+JOIN internal_keys keys
+    ON groups.internal_key_id = keys.key_id
+JOIN asset_group_sigs sigs
+    ON sigs.sig_id = (
+        SELECT MIN(sig_id)
+        FROM asset_group_sigs
+        WHERE group_key_id = groups.group_id
+    )
+ORDER BY groups.group_id
+`
+
+type FetchAllGroupKeysRow struct {
+	TweakedGroupKey []byte
+	RawKey          []byte
+	KeyFamily       int32
+	KeyIndex        int32
+	GenesisSig      []byte
+}
+
+// Each group key is returned along with the sig of the first genesis asset
+// that was created with it.
+func (q *Queries) FetchAllGroupKeys(ctx context.Context) ([]FetchAllGroupKeysRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAllGroupKeys)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []FetchAssetProofsRow
+	var items []FetchAllGroupKeysRow
 	for rows.Next() {
-		var i FetchAssetProofsRow
-		if err := rows.Scan(&i.ScriptKey, &i.ProofFile); err != nil {
+		var i FetchAllGroupKeysRow
+		if err := rows.Scan(
+			&i.TweakedGroupKey,
+			&i.RawKey,
+			&i.KeyFamily,
+			&i.KeyIndex,
+			&i.GenesisSig,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -500,44 +774,48 @@ func (q *Queries) FetchAssetProofs(ctx context.Context) ([]FetchAssetProofsRow,
 	return items, nil
 }
 
-const fetchAssetWitnesses = `-- name: FetchAssetWitnesses :many
-SELECT 
-    assets.asset_id, prev_out_point, prev_asset_id, prev_script_key, 
-    witness_stack, split_commitment_proof
-FROM asset_witnesses
-JOIN assets
-    ON asset_witnesses.asset_id = assets.asset_id
-WHERE (
-    (assets.asset_id = $1) OR ($1 IS NULL)
-)
+const fetchAnchorStatusCounts = `-- name: FetchAnchorStatusCounts :one
+SELECT
+    COUNT(CASE WHEN anchor_utxo_id IS NOT NULL THEN 1 END) AS anchored,
+    COUNT(CASE WHEN anchor_utxo_id IS NULL THEN 1 END) AS unanchored
+FROM assets
+WHERE spent = false
 `
 
-type FetchAssetWitnessesRow struct {
-	AssetID              int32
-	PrevOutPoint         []byte
-	PrevAssetID          []byte
-	PrevScriptKey        []byte
-	WitnessStack         []byte
-	SplitCommitmentProof []byte
+type FetchAnchorStatusCountsRow struct {
+	Anchored   int64
+	Unanchored int64
 }
 
-func (q *Queries) FetchAssetWitnesses(ctx context.Context, assetID sql.NullInt32) ([]FetchAssetWitnessesRow, error) {
-	rows, err := q.db.QueryContext(ctx, fetchAssetWitnesses, assetID)
+func (q *Queries) FetchAnchorStatusCounts(ctx context.Context) (FetchAnchorStatusCountsRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchAnchorStatusCounts)
+	var i FetchAnchorStatusCountsRow
+	err := row.Scan(&i.Anchored, &i.Unanchored)
+	return i, err
+}
+
+const fetchAnchorUtxoAssetCounts = `-- name: FetchAnchorUtxoAssetCounts :many
+SELECT anchor_utxo_id, COUNT(*) AS num_assets
+FROM assets
+WHERE anchor_utxo_id IS NOT NULL AND spent = false
+GROUP BY anchor_utxo_id
+`
+
+type FetchAnchorUtxoAssetCountsRow struct {
+	AnchorUtxoID sql.NullInt32
+	NumAssets    int64
+}
+
+func (q *Queries) FetchAnchorUtxoAssetCounts(ctx context.Context) ([]FetchAnchorUtxoAssetCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAnchorUtxoAssetCounts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []FetchAssetWitnessesRow
+	var items []FetchAnchorUtxoAssetCountsRow
 	for rows.Next() {
-		var i FetchAssetWitnessesRow
-		if err := rows.Scan(
-			&i.AssetID,
-			&i.PrevOutPoint,
-			&i.PrevAssetID,
-			&i.PrevScriptKey,
-			&i.WitnessStack,
-			&i.SplitCommitmentProof,
-		); err != nil {
+		var i FetchAnchorUtxoAssetCountsRow
+		if err := rows.Scan(&i.AnchorUtxoID, &i.NumAssets); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -551,35 +829,27 @@ func (q *Queries) FetchAssetWitnesses(ctx context.Context, assetID sql.NullInt32
 	return items, nil
 }
 
-const fetchAssetsByAnchorTx = `-- name: FetchAssetsByAnchorTx :many
-SELECT asset_id, genesis_id, version, script_key_id, asset_group_sig_id, script_version, amount, lock_time, relative_lock_time, split_commitment_root_hash, split_commitment_root_value, anchor_utxo_id
+const fetchAssetAmounts = `-- name: FetchAssetAmounts :many
+SELECT amount, amount_big
 FROM assets
-WHERE anchor_utxo_id = $1
+WHERE spent = false
 `
 
-func (q *Queries) FetchAssetsByAnchorTx(ctx context.Context, anchorUtxoID sql.NullInt32) ([]Asset, error) {
-	rows, err := q.db.QueryContext(ctx, fetchAssetsByAnchorTx, anchorUtxoID)
+type FetchAssetAmountsRow struct {
+	Amount    int64
+	AmountBig sql.NullString
+}
+
+func (q *Queries) FetchAssetAmounts(ctx context.Context) ([]FetchAssetAmountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAssetAmounts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Asset
+	var items []FetchAssetAmountsRow
 	for rows.Next() {
-		var i Asset
-		if err := rows.Scan(
-			&i.AssetID,
-			&i.GenesisID,
-			&i.Version,
-			&i.ScriptKeyID,
-			&i.AssetGroupSigID,
-			&i.ScriptVersion,
-			&i.Amount,
-			&i.LockTime,
-			&i.RelativeLockTime,
-			&i.SplitCommitmentRootHash,
-			&i.SplitCommitmentRootValue,
-			&i.AnchorUtxoID,
-		); err != nil {
+		var i FetchAssetAmountsRow
+		if err := rows.Scan(&i.Amount, &i.AmountBig); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -593,20 +863,344 @@ func (q *Queries) FetchAssetsByAnchorTx(ctx context.Context, anchorUtxoID sql.Nu
 	return items, nil
 }
 
-const fetchAssetsForBatch = `-- name: FetchAssetsForBatch :many
-WITH genesis_info AS (
-    -- This CTE is used to fetch the base asset information from disk based on
-    -- the raw key of the batch that will ultimately create this set of assets.
-    -- To do so, we'll need to traverse a few tables to join the set of assets
-    -- with the genesis points, then with the batches that reference this
+const fetchAssetAmountsByScriptKey = `-- name: FetchAssetAmountsByScriptKey :many
+SELECT assets.amount, assets.amount_big
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+WHERE genesis_assets.asset_id = $1
+    AND script_keys.tweaked_script_key = $2
+    AND assets.spent = false
+`
+
+type FetchAssetAmountsByScriptKeyParams struct {
+	AssetID          []byte
+	TweakedScriptKey []byte
+}
+
+type FetchAssetAmountsByScriptKeyRow struct {
+	Amount    int64
+	AmountBig sql.NullString
+}
+
+func (q *Queries) FetchAssetAmountsByScriptKey(ctx context.Context, arg FetchAssetAmountsByScriptKeyParams) ([]FetchAssetAmountsByScriptKeyRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAssetAmountsByScriptKey, arg.AssetID, arg.TweakedScriptKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchAssetAmountsByScriptKeyRow
+	for rows.Next() {
+		var i FetchAssetAmountsByScriptKeyRow
+		if err := rows.Scan(&i.Amount, &i.AmountBig); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchAssetAnchorInternalKey = `-- name: FetchAssetAnchorInternalKey :one
+SELECT internal_keys.raw_key, internal_keys.key_family, internal_keys.key_index
+FROM assets
+JOIN managed_utxos utxos
+    ON assets.anchor_utxo_id = utxos.utxo_id
+JOIN internal_keys
+    ON utxos.internal_key_id = internal_keys.key_id
+WHERE assets.asset_id = $1
+`
+
+type FetchAssetAnchorInternalKeyRow struct {
+	RawKey    []byte
+	KeyFamily int32
+	KeyIndex  int32
+}
+
+func (q *Queries) FetchAssetAnchorInternalKey(ctx context.Context, assetID int32) (FetchAssetAnchorInternalKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchAssetAnchorInternalKey, assetID)
+	var i FetchAssetAnchorInternalKeyRow
+	err := row.Scan(&i.RawKey, &i.KeyFamily, &i.KeyIndex)
+	return i, err
+}
+
+const fetchAssetAnchorUTXOID = `-- name: FetchAssetAnchorUTXOID :one
+SELECT anchor_utxo_id
+FROM assets
+WHERE asset_id = $1
+`
+
+func (q *Queries) FetchAssetAnchorUTXOID(ctx context.Context, assetID int32) (sql.NullInt32, error) {
+	row := q.db.QueryRowContext(ctx, fetchAssetAnchorUTXOID, assetID)
+	var anchor_utxo_id sql.NullInt32
+	err := row.Scan(&anchor_utxo_id)
+	return anchor_utxo_id, err
+}
+
+const fetchAssetIDsByMetadataJSONField = `-- name: FetchAssetIDsByMetadataJSONField :many
+SELECT DISTINCT gen.asset_id
+FROM genesis_assets gen
+JOIN genesis_meta_reveals reveals
+    ON reveals.meta_hash = gen.meta_hash
+WHERE gen.meta_type = $1 AND
+    convert_from(reveals.meta_data, 'UTF8')::jsonb #>>
+        string_to_array($2::TEXT, '.') = $3::TEXT
+`
+
+type FetchAssetIDsByMetadataJSONFieldParams struct {
+	MetaType  int16
+	JsonPath  string
+	JsonValue string
+}
+
+// The metadata is decoded as JSON by the database, which is only supported by
+// Postgres. The path is a dot separated list of object keys and array indices,
+// and the #>> operator returns values that aren't strings in their JSON text
+// encoding.
+func (q *Queries) FetchAssetIDsByMetadataJSONField(ctx context.Context, arg FetchAssetIDsByMetadataJSONFieldParams) ([][]byte, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAssetIDsByMetadataJSONField, arg.MetaType, arg.JsonPath, arg.JsonValue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items [][]byte
+	for rows.Next() {
+		var asset_id []byte
+		if err := rows.Scan(&asset_id); err != nil {
+			return nil, err
+		}
+		items = append(items, asset_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchAssetPrevID = `-- name: FetchAssetPrevID :one
+SELECT
+    utxos.outpoint AS anchor_outpoint, genesis_assets.asset_id,
+    script_keys.tweaked_script_key
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+JOIN managed_utxos utxos
+    ON assets.anchor_utxo_id = utxos.utxo_id
+WHERE assets.asset_id = $1
+`
+
+type FetchAssetPrevIDRow struct {
+	AnchorOutpoint   []byte
+	AssetID          []byte
+	TweakedScriptKey []byte
+}
+
+func (q *Queries) FetchAssetPrevID(ctx context.Context, assetID int32) (FetchAssetPrevIDRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchAssetPrevID, assetID)
+	var i FetchAssetPrevIDRow
+	err := row.Scan(&i.AnchorOutpoint, &i.AssetID, &i.TweakedScriptKey)
+	return i, err
+}
+
+const fetchAssetProof = `-- name: FetchAssetProof :one
+WITH asset_info AS (
+    SELECT assets.asset_id, script_keys.tweaked_script_key
+    FROM assets
+    JOIN script_keys
+        ON assets.script_key_id = script_keys.script_key_id
+    WHERE script_keys.tweaked_script_key = $1
+)
+SELECT asset_info.tweaked_script_key AS script_key, asset_proofs.proof_file,
+       asset_info.asset_id as asset_id, asset_proofs.proof_id as proof_id
+FROM asset_proofs
+JOIN asset_info
+    ON asset_info.asset_id = asset_proofs.asset_id
+`
+
+type FetchAssetProofRow struct {
+	ScriptKey []byte
+	ProofFile []byte
+	AssetID   int32
+	ProofID   int32
+}
+
+func (q *Queries) FetchAssetProof(ctx context.Context, tweakedScriptKey []byte) (FetchAssetProofRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchAssetProof, tweakedScriptKey)
+	var i FetchAssetProofRow
+	err := row.Scan(
+		&i.ScriptKey,
+		&i.ProofFile,
+		&i.AssetID,
+		&i.ProofID,
+	)
+	return i, err
+}
+
+const fetchAssetProofs = `-- name: FetchAssetProofs :many
+WITH asset_info AS (
+    SELECT assets.asset_id, script_keys.tweaked_script_key
+    FROM assets
+    JOIN script_keys
+        ON assets.script_key_id = script_keys.script_key_id
+)
+SELECT asset_info.tweaked_script_key AS script_key, asset_proofs.proof_file
+FROM asset_proofs
+JOIN asset_info
+    ON asset_info.asset_id = asset_proofs.asset_id
+`
+
+type FetchAssetProofsRow struct {
+	ScriptKey []byte
+	ProofFile []byte
+}
+
+func (q *Queries) FetchAssetProofs(ctx context.Context) ([]FetchAssetProofsRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAssetProofs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchAssetProofsRow
+	for rows.Next() {
+		var i FetchAssetProofsRow
+		if err := rows.Scan(&i.ScriptKey, &i.ProofFile); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchAssetWitnesses = `-- name: FetchAssetWitnesses :many
+SELECT 
+    assets.asset_id, prev_out_point, prev_asset_id, prev_script_key, 
+    witness_stack, split_commitment_proof
+FROM asset_witnesses
+JOIN assets
+    ON asset_witnesses.asset_id = assets.asset_id
+WHERE (
+    (assets.asset_id = $1) OR ($1 IS NULL)
+)
+`
+
+type FetchAssetWitnessesRow struct {
+	AssetID              int32
+	PrevOutPoint         []byte
+	PrevAssetID          []byte
+	PrevScriptKey        []byte
+	WitnessStack         []byte
+	SplitCommitmentProof []byte
+}
+
+func (q *Queries) FetchAssetWitnesses(ctx context.Context, assetID sql.NullInt32) ([]FetchAssetWitnessesRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAssetWitnesses, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchAssetWitnessesRow
+	for rows.Next() {
+		var i FetchAssetWitnessesRow
+		if err := rows.Scan(
+			&i.AssetID,
+			&i.PrevOutPoint,
+			&i.PrevAssetID,
+			&i.PrevScriptKey,
+			&i.WitnessStack,
+			&i.SplitCommitmentProof,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchAssetsByAnchorTx = `-- name: FetchAssetsByAnchorTx :many
+SELECT asset_id, genesis_id, version, script_key_id, asset_group_sig_id, script_version, amount, lock_time, relative_lock_time, split_commitment_root_hash, split_commitment_root_value, anchor_utxo_id, amount_big, spent, updated_at
+FROM assets
+WHERE anchor_utxo_id = $1
+`
+
+func (q *Queries) FetchAssetsByAnchorTx(ctx context.Context, anchorUtxoID sql.NullInt32) ([]Asset, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAssetsByAnchorTx, anchorUtxoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Asset
+	for rows.Next() {
+		var i Asset
+		if err := rows.Scan(
+			&i.AssetID,
+			&i.GenesisID,
+			&i.Version,
+			&i.ScriptKeyID,
+			&i.AssetGroupSigID,
+			&i.ScriptVersion,
+			&i.Amount,
+			&i.LockTime,
+			&i.RelativeLockTime,
+			&i.SplitCommitmentRootHash,
+			&i.SplitCommitmentRootValue,
+			&i.AnchorUtxoID,
+			&i.AmountBig,
+			&i.Spent,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchAssetsForBatch = `-- name: FetchAssetsForBatch :many
+WITH genesis_info AS (
+    -- This CTE is used to fetch the base asset information from disk based on
+    -- the raw key of the batch that will ultimately create this set of assets.
+    -- To do so, we'll need to traverse a few tables to join the set of assets
+    -- with the genesis points, then with the batches that reference this
     -- points, to the internal key that reference the batch, then restricted
     -- for internal keys that match our main batch key.
     SELECT
-        gen_asset_id, asset_id, asset_tag, meta_data, output_index, asset_type,
-        genesis_points.prev_out prev_out
+        gen_asset_id, asset_id, asset_tag, reveals.meta_data, output_index,
+        asset_type, genesis_points.prev_out prev_out
     FROM genesis_assets
     JOIN genesis_points
         ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+    LEFT JOIN genesis_meta_reveals reveals
+        ON genesis_assets.meta_hash = reveals.meta_hash
     JOIN asset_minting_batches batches
         ON genesis_points.genesis_id = batches.genesis_id
     JOIN internal_keys keys
@@ -721,11 +1315,115 @@ func (q *Queries) FetchAssetsForBatch(ctx context.Context, rawKey []byte) ([]Fet
 	return items, nil
 }
 
-const fetchChainTx = `-- name: FetchChainTx :one
-SELECT txn_id, txid, chain_fees, raw_tx, block_height, block_hash, tx_index
-FROM chain_txns
-WHERE txid = $1
-`
+const fetchAssetsWithNullScriptKey = `-- name: FetchAssetsWithNullScriptKey :many
+SELECT
+    assets.asset_id AS asset_primary_key, assets.script_key_id, assets.amount,
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type,
+    genesis_points.prev_out prev_out
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
+LEFT JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+WHERE script_keys.script_key_id IS NULL
+ORDER BY assets.asset_id
+`
+
+type FetchAssetsWithNullScriptKeyRow struct {
+	AssetPrimaryKey int32
+	ScriptKeyID     int32
+	Amount          int64
+	AssetID         []byte
+	AssetTag        string
+	MetaData        []byte
+	OutputIndex     int32
+	AssetType       int16
+	PrevOut         []byte
+}
+
+// Every asset must reference a script key. On backends that don't enforce
+// foreign keys, a script key may be missing nonetheless, which we detect by
+// the LEFT JOIN not finding a matching script key.
+func (q *Queries) FetchAssetsWithNullScriptKey(ctx context.Context) ([]FetchAssetsWithNullScriptKeyRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAssetsWithNullScriptKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchAssetsWithNullScriptKeyRow
+	for rows.Next() {
+		var i FetchAssetsWithNullScriptKeyRow
+		if err := rows.Scan(
+			&i.AssetPrimaryKey,
+			&i.ScriptKeyID,
+			&i.Amount,
+			&i.AssetID,
+			&i.AssetTag,
+			&i.MetaData,
+			&i.OutputIndex,
+			&i.AssetType,
+			&i.PrevOut,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchAssetsWithWrappedAmount = `-- name: FetchAssetsWithWrappedAmount :many
+SELECT asset_id, amount
+FROM assets
+WHERE amount < 0 AND amount_big IS NULL
+`
+
+type FetchAssetsWithWrappedAmountRow struct {
+	AssetID int32
+	Amount  int64
+}
+
+// Assets stored before their amounts were checked may have an amount that
+// exceeded the range of the amount column, which then wrapped around to a
+// negative value.
+func (q *Queries) FetchAssetsWithWrappedAmount(ctx context.Context) ([]FetchAssetsWithWrappedAmountRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchAssetsWithWrappedAmount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchAssetsWithWrappedAmountRow
+	for rows.Next() {
+		var i FetchAssetsWithWrappedAmountRow
+		if err := rows.Scan(&i.AssetID, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchChainTx = `-- name: FetchChainTx :one
+SELECT txn_id, txid, chain_fees, raw_tx, block_height, block_hash, tx_index
+FROM chain_txns
+WHERE txid = $1
+`
 
 func (q *Queries) FetchChainTx(ctx context.Context, txid []byte) (ChainTxn, error) {
 	row := q.db.QueryRowContext(ctx, fetchChainTx, txid)
@@ -742,50 +1440,777 @@ func (q *Queries) FetchChainTx(ctx context.Context, txid []byte) (ChainTxn, erro
 	return i, err
 }
 
-const fetchGenesisByID = `-- name: FetchGenesisByID :one
+const fetchDistinctAssetIDs = `-- name: FetchDistinctAssetIDs :many
+SELECT DISTINCT genesis_assets.asset_id
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+WHERE assets.spent = false
+ORDER BY genesis_assets.asset_id
+`
+
+func (q *Queries) FetchDistinctAssetIDs(ctx context.Context) ([][]byte, error) {
+	rows, err := q.db.QueryContext(ctx, fetchDistinctAssetIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items [][]byte
+	for rows.Next() {
+		var asset_id []byte
+		if err := rows.Scan(&asset_id); err != nil {
+			return nil, err
+		}
+		items = append(items, asset_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchDuplicateOutputIndices = `-- name: FetchDuplicateOutputIndices :many
 SELECT
-    asset_id, asset_tag, meta_data, output_index, asset_type,
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
     genesis_points.prev_out prev_out
 FROM genesis_assets
 JOIN genesis_points
   ON genesis_assets.genesis_point_id = genesis_points.genesis_id
-WHERE gen_asset_id = $1
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE genesis_assets.genesis_point_id = $1
+    AND output_index IN (
+        SELECT output_index
+        FROM genesis_assets
+        WHERE genesis_point_id = $1
+        GROUP BY output_index
+        HAVING COUNT(*) > 1
+    )
+ORDER BY output_index, gen_asset_id
+`
+
+type FetchDuplicateOutputIndicesRow struct {
+	AssetID     []byte
+	AssetTag    string
+	MetaData    []byte
+	OutputIndex int32
+	AssetType   int16
+	MetaType    int16
+	PrevOut     []byte
+}
+
+func (q *Queries) FetchDuplicateOutputIndices(ctx context.Context, genesisPointID int32) ([]FetchDuplicateOutputIndicesRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchDuplicateOutputIndices, genesisPointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchDuplicateOutputIndicesRow
+	for rows.Next() {
+		var i FetchDuplicateOutputIndicesRow
+		if err := rows.Scan(
+			&i.AssetID,
+			&i.AssetTag,
+			&i.MetaData,
+			&i.OutputIndex,
+			&i.AssetType,
+			&i.MetaType,
+			&i.PrevOut,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGenesesWithSharedMetaHash = `-- name: FetchGenesesWithSharedMetaHash :many
+SELECT
+    gen_asset_id, genesis_assets.meta_hash, genesis_assets.asset_id,
+    asset_tag, reveals.meta_data, output_index, asset_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE genesis_assets.meta_hash IN (
+    SELECT meta_hash
+    FROM genesis_assets
+    WHERE meta_hash IS NOT NULL
+    GROUP BY meta_hash
+    HAVING COUNT(*) > 1
+)
+ORDER BY genesis_assets.meta_hash, gen_asset_id
+`
+
+type FetchGenesesWithSharedMetaHashRow struct {
+	GenAssetID  int32
+	MetaHash    []byte
+	AssetID     []byte
+	AssetTag    string
+	MetaData    []byte
+	OutputIndex int32
+	AssetType   int16
+	PrevOut     []byte
+}
+
+// The metadata revealed for a hash is shared by all genesis assets with that
+// hash, so the genesis fields are returned as well, which allows the caller to
+// check whether the metadata still derives the asset ID of each of them.
+func (q *Queries) FetchGenesesWithSharedMetaHash(ctx context.Context) ([]FetchGenesesWithSharedMetaHashRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGenesesWithSharedMetaHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGenesesWithSharedMetaHashRow
+	for rows.Next() {
+		var i FetchGenesesWithSharedMetaHashRow
+		if err := rows.Scan(
+			&i.GenAssetID,
+			&i.MetaHash,
+			&i.AssetID,
+			&i.AssetTag,
+			&i.MetaData,
+			&i.OutputIndex,
+			&i.AssetType,
+			&i.PrevOut,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGenesisAssetByTag = `-- name: FetchGenesisAssetByTag :one
+SELECT *
+FROM genesis_assets
+WHERE asset_tag = $1
+`
+
+func (q *Queries) FetchGenesisAssetByTag(ctx context.Context, assetTag string) (GenesisAsset, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisAssetByTag, assetTag)
+	var i GenesisAsset
+	err := row.Scan(
+		&i.GenAssetID,
+		&i.AssetID,
+		&i.AssetTag,
+		&i.MetaData,
+		&i.OutputIndex,
+		&i.AssetType,
+		&i.GenesisPointID,
+		&i.MetaType,
+		&i.IsReissuance,
+		&i.MetaHash,
+	)
+	return i, err
+}
+
+const fetchGenesisAssetIDByTag = `-- name: FetchGenesisAssetIDByTag :one
+SELECT gen_asset_id
+FROM genesis_assets
+WHERE asset_tag = $1
+`
+
+func (q *Queries) FetchGenesisAssetIDByTag(ctx context.Context, assetTag string) (int32, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisAssetIDByTag, assetTag)
+	var gen_asset_id int32
+	err := row.Scan(&gen_asset_id)
+	return gen_asset_id, err
+}
+
+const fetchGenesisAssetsWithInlineMetadata = `-- name: FetchGenesisAssetsWithInlineMetadata :many
+SELECT gen_asset_id, meta_hash, meta_data
+FROM genesis_assets
+WHERE meta_hash IS NULL OR meta_data IS NOT NULL
+`
+
+type FetchGenesisAssetsWithInlineMetadataRow struct {
+	GenAssetID int32
+	MetaHash   []byte
+	MetaData   []byte
+}
+
+// Genesis assets stored before their metadata was moved to the reveals still
+// carry it inline, and may not have a metadata hash yet either.
+func (q *Queries) FetchGenesisAssetsWithInlineMetadata(ctx context.Context) ([]FetchGenesisAssetsWithInlineMetadataRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGenesisAssetsWithInlineMetadata)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGenesisAssetsWithInlineMetadataRow
+	for rows.Next() {
+		var i FetchGenesisAssetsWithInlineMetadataRow
+		if err := rows.Scan(&i.GenAssetID, &i.MetaHash, &i.MetaData); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGenesisAssetsWithoutMetadata = `-- name: FetchGenesisAssetsWithoutMetadata :many
+SELECT
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+  ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE reveals.meta_data IS NULL OR length(reveals.meta_data) = 0
+ORDER BY gen_asset_id
+`
+
+type FetchGenesisAssetsWithoutMetadataRow struct {
+	AssetID     []byte
+	AssetTag    string
+	MetaData    []byte
+	OutputIndex int32
+	AssetType   int16
+	MetaType    int16
+	PrevOut     []byte
+}
+
+func (q *Queries) FetchGenesisAssetsWithoutMetadata(ctx context.Context) ([]FetchGenesisAssetsWithoutMetadataRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGenesisAssetsWithoutMetadata)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGenesisAssetsWithoutMetadataRow
+	for rows.Next() {
+		var i FetchGenesisAssetsWithoutMetadataRow
+		if err := rows.Scan(
+			&i.AssetID,
+			&i.AssetTag,
+			&i.MetaData,
+			&i.OutputIndex,
+			&i.AssetType,
+			&i.MetaType,
+			&i.PrevOut,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGenesisByID = `-- name: FetchGenesisByID :one
+SELECT
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+  ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE gen_asset_id = $1
+`
+
+type FetchGenesisByIDRow struct {
+	AssetID     []byte
+	AssetTag    string
+	MetaData    []byte
+	OutputIndex int32
+	AssetType   int16
+	MetaType    int16
+	PrevOut     []byte
+}
+
+func (q *Queries) FetchGenesisByID(ctx context.Context, genAssetID int32) (FetchGenesisByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisByID, genAssetID)
+	var i FetchGenesisByIDRow
+	err := row.Scan(
+		&i.AssetID,
+		&i.AssetTag,
+		&i.MetaData,
+		&i.OutputIndex,
+		&i.AssetType,
+		&i.MetaType,
+		&i.PrevOut,
+	)
+	return i, err
+}
+
+const fetchGenesisByOutpoint = `-- name: FetchGenesisByOutpoint :one
+SELECT
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+  ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE genesis_points.prev_out = $1
+    AND genesis_assets.output_index = $2
+ORDER BY gen_asset_id
+LIMIT 1
+`
+
+type FetchGenesisByOutpointParams struct {
+	PrevOut     []byte
+	OutputIndex int32
+}
+
+type FetchGenesisByOutpointRow struct {
+	AssetID     []byte
+	AssetTag    string
+	MetaData    []byte
+	OutputIndex int32
+	AssetType   int16
+	MetaType    int16
+	PrevOut     []byte
+}
+
+// Multiple genesis assets of a genesis point should never share an output
+// index, but in case they do, we deterministically return the first one.
+func (q *Queries) FetchGenesisByOutpoint(ctx context.Context, arg FetchGenesisByOutpointParams) (FetchGenesisByOutpointRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisByOutpoint, arg.PrevOut, arg.OutputIndex)
+	var i FetchGenesisByOutpointRow
+	err := row.Scan(
+		&i.AssetID,
+		&i.AssetTag,
+		&i.MetaData,
+		&i.OutputIndex,
+		&i.AssetType,
+		&i.MetaType,
+		&i.PrevOut,
+	)
+	return i, err
+}
+
+const fetchGenesisMetadataByAssetID = `-- name: FetchGenesisMetadataByAssetID :one
+SELECT reveals.meta_data
+FROM genesis_assets gen
+LEFT JOIN genesis_meta_reveals reveals
+    ON reveals.meta_hash = gen.meta_hash
+WHERE gen.asset_id = $1
+`
+
+func (q *Queries) FetchGenesisMetadataByAssetID(ctx context.Context, assetID []byte) ([]byte, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisMetadataByAssetID, assetID)
+	var meta_data []byte
+	err := row.Scan(&meta_data)
+	return meta_data, err
+}
+
+const fetchGenesisPointByAnchorTx = `-- name: FetchGenesisPointByAnchorTx :one
+SELECT genesis_id, prev_out, anchor_tx_id, created_at 
+FROM genesis_points
+WHERE anchor_tx_id = $1
+`
+
+func (q *Queries) FetchGenesisPointByAnchorTx(ctx context.Context, anchorTxID sql.NullInt32) (GenesisPoint, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisPointByAnchorTx, anchorTxID)
+	var i GenesisPoint
+	err := row.Scan(
+		&i.GenesisID,
+		&i.PrevOut,
+		&i.AnchorTxID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const fetchGenesisPointByAssetID = `-- name: FetchGenesisPointByAssetID :one
+SELECT genesis_points.prev_out
+FROM genesis_assets
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+WHERE genesis_assets.asset_id = $1
+`
+
+func (q *Queries) FetchGenesisPointByAssetID(ctx context.Context, assetID []byte) ([]byte, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisPointByAssetID, assetID)
+	var prev_out []byte
+	err := row.Scan(&prev_out)
+	return prev_out, err
+}
+
+const fetchGenesisPointIDByGenAssetID = `-- name: FetchGenesisPointIDByGenAssetID :one
+SELECT genesis_point_id
+FROM genesis_assets
+WHERE gen_asset_id = $1
+`
+
+func (q *Queries) FetchGenesisPointIDByGenAssetID(ctx context.Context, genAssetID int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisPointIDByGenAssetID, genAssetID)
+	var genesis_point_id int32
+	err := row.Scan(&genesis_point_id)
+	return genesis_point_id, err
+}
+
+const fetchGenesisPointIDByPrevOut = `-- name: FetchGenesisPointIDByPrevOut :one
+SELECT genesis_id
+FROM genesis_points
+WHERE prev_out = $1
+`
+
+func (q *Queries) FetchGenesisPointIDByPrevOut(ctx context.Context, prevOut []byte) (int32, error) {
+	row := q.db.QueryRowContext(ctx, fetchGenesisPointIDByPrevOut, prevOut)
+	var genesis_id int32
+	err := row.Scan(&genesis_id)
+	return genesis_id, err
+}
+
+const fetchGenesisPointsCreatedBetween = `-- name: FetchGenesisPointsCreatedBetween :many
+SELECT genesis_id, prev_out, anchor_tx_id, created_at
+FROM genesis_points
+WHERE created_at >= $1
+    AND created_at <= $2
+ORDER BY created_at
+`
+
+type FetchGenesisPointsCreatedBetweenParams struct {
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+}
+
+func (q *Queries) FetchGenesisPointsCreatedBetween(ctx context.Context, arg FetchGenesisPointsCreatedBetweenParams) ([]GenesisPoint, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGenesisPointsCreatedBetween, arg.CreatedAfter, arg.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GenesisPoint
+	for rows.Next() {
+		var i GenesisPoint
+		if err := rows.Scan(
+			&i.GenesisID,
+			&i.PrevOut,
+			&i.AnchorTxID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupAssetAmounts = `-- name: FetchGroupAssetAmounts :many
+SELECT
+    key_group_info_view.tweaked_group_key, assets.amount, assets.amount_big
+FROM assets
+JOIN key_group_info_view
+    ON assets.genesis_id = key_group_info_view.gen_asset_id
+WHERE assets.spent = false
+ORDER BY key_group_info_view.tweaked_group_key
+`
+
+type FetchGroupAssetAmountsRow struct {
+	TweakedGroupKey []byte
+	Amount          int64
+	AmountBig       sql.NullString
+}
+
+// The amounts are summed up by the caller, as the supply of a group can
+// exceed the range of the amount column, which sqlite fails to SUM.
+func (q *Queries) FetchGroupAssetAmounts(ctx context.Context) ([]FetchGroupAssetAmountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupAssetAmounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupAssetAmountsRow
+	for rows.Next() {
+		var i FetchGroupAssetAmountsRow
+		if err := rows.Scan(&i.TweakedGroupKey, &i.Amount, &i.AmountBig); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupAssetsScriptKeyKinds = `-- name: FetchGroupAssetsScriptKeyKinds :one
+SELECT
+    COUNT(CASE WHEN length(script_keys.tweak) > 0 THEN 1 END) AS with_tweak,
+    COUNT(
+        CASE WHEN script_keys.tweak IS NULL OR length(script_keys.tweak) = 0
+        THEN 1 END
+    ) AS without_tweak
+FROM assets
+JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+JOIN key_group_info_view
+    ON assets.genesis_id = key_group_info_view.gen_asset_id
+WHERE key_group_info_view.tweaked_group_key = $1
+    AND assets.spent = false
+`
+
+type FetchGroupAssetsScriptKeyKindsRow struct {
+	WithTweak    int64
+	WithoutTweak int64
+}
+
+func (q *Queries) FetchGroupAssetsScriptKeyKinds(ctx context.Context, tweakedGroupKey []byte) (FetchGroupAssetsScriptKeyKindsRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchGroupAssetsScriptKeyKinds, tweakedGroupKey)
+	var i FetchGroupAssetsScriptKeyKindsRow
+	err := row.Scan(&i.WithTweak, &i.WithoutTweak)
+	return i, err
+}
+
+const fetchGroupKeyIDByTweakedKey = `-- name: FetchGroupKeyIDByTweakedKey :one
+SELECT group_id
+FROM asset_groups
+WHERE tweaked_group_key = $1
+`
+
+func (q *Queries) FetchGroupKeyIDByTweakedKey(ctx context.Context, tweakedGroupKey []byte) (int32, error) {
+	row := q.db.QueryRowContext(ctx, fetchGroupKeyIDByTweakedKey, tweakedGroupKey)
+	var group_id int32
+	err := row.Scan(&group_id)
+	return group_id, err
+}
+
+const fetchGroupRawKey = `-- name: FetchGroupRawKey :one
+SELECT keys.raw_key, keys.key_family, keys.key_index
+FROM asset_groups groups
+JOIN internal_keys keys
+    ON groups.internal_key_id = keys.key_id
+WHERE groups.tweaked_group_key = $1
+`
+
+type FetchGroupRawKeyRow struct {
+	RawKey    []byte
+	KeyFamily int32
+	KeyIndex  int32
+}
+
+func (q *Queries) FetchGroupRawKey(ctx context.Context, tweakedGroupKey []byte) (FetchGroupRawKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchGroupRawKey, tweakedGroupKey)
+	var i FetchGroupRawKeyRow
+	err := row.Scan(&i.RawKey, &i.KeyFamily, &i.KeyIndex)
+	return i, err
+}
+
+const fetchGroupReissuances = `-- name: FetchGroupReissuances :many
+SELECT
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
+JOIN asset_group_sigs sigs
+    ON genesis_assets.gen_asset_id = sigs.gen_asset_id
+JOIN asset_groups groups
+    ON sigs.group_key_id = groups.group_id
+WHERE groups.tweaked_group_key = $1
+    AND genesis_assets.is_reissuance = true
+ORDER BY sigs.sig_id
+`
+
+type FetchGroupReissuancesRow struct {
+	AssetID     []byte
+	AssetTag    string
+	MetaData    []byte
+	OutputIndex int32
+	AssetType   int16
+	MetaType    int16
+	PrevOut     []byte
+}
+
+func (q *Queries) FetchGroupReissuances(ctx context.Context, tweakedGroupKey []byte) ([]FetchGroupReissuancesRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupReissuances, tweakedGroupKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupReissuancesRow
+	for rows.Next() {
+		var i FetchGroupReissuancesRow
+		if err := rows.Scan(
+			&i.AssetID,
+			&i.AssetTag,
+			&i.MetaData,
+			&i.OutputIndex,
+			&i.AssetType,
+			&i.MetaType,
+			&i.PrevOut,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupSigIDByGenesisID = `-- name: FetchGroupSigIDByGenesisID :one
+SELECT sig_id
+FROM asset_group_sigs
+WHERE gen_asset_id = $1
+`
+
+func (q *Queries) FetchGroupSigIDByGenesisID(ctx context.Context, genAssetID int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, fetchGroupSigIDByGenesisID, genAssetID)
+	var sig_id int32
+	err := row.Scan(&sig_id)
+	return sig_id, err
+}
+
+const fetchGroupSizes = `-- name: FetchGroupSizes :many
+SELECT
+    key_group_info_view.tweaked_group_key, COUNT(*) AS num_assets
+FROM assets
+JOIN key_group_info_view
+    ON assets.genesis_id = key_group_info_view.gen_asset_id
+WHERE assets.spent = false
+GROUP BY key_group_info_view.tweaked_group_key
+`
+
+type FetchGroupSizesRow struct {
+	TweakedGroupKey []byte
+	NumAssets       int64
+}
+
+func (q *Queries) FetchGroupSizes(ctx context.Context) ([]FetchGroupSizesRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupSizes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupSizesRow
+	for rows.Next() {
+		var i FetchGroupSizesRow
+		if err := rows.Scan(&i.TweakedGroupKey, &i.NumAssets); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchImportCheckpoint = `-- name: FetchImportCheckpoint :one
+SELECT last_committed_index, batch_hash
+FROM import_checkpoints
+WHERE batch_id = $1
+`
+
+type FetchImportCheckpointRow struct {
+	LastCommittedIndex int32
+	BatchHash          []byte
+}
+
+func (q *Queries) FetchImportCheckpoint(ctx context.Context, batchID string) (FetchImportCheckpointRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchImportCheckpoint, batchID)
+	var i FetchImportCheckpointRow
+	err := row.Scan(&i.LastCommittedIndex, &i.BatchHash)
+	return i, err
+}
+
+const fetchInternalKeyByID = `-- name: FetchInternalKeyByID :one
+SELECT raw_key, key_family, key_index
+FROM internal_keys
+WHERE key_id = $1
+`
+
+type FetchInternalKeyByIDRow struct {
+	RawKey    []byte
+	KeyFamily int32
+	KeyIndex  int32
+}
+
+func (q *Queries) FetchInternalKeyByID(ctx context.Context, keyID int32) (FetchInternalKeyByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchInternalKeyByID, keyID)
+	var i FetchInternalKeyByIDRow
+	err := row.Scan(&i.RawKey, &i.KeyFamily, &i.KeyIndex)
+	return i, err
+}
+
+const fetchInternalKeyByRawKey = `-- name: FetchInternalKeyByRawKey :one
+SELECT raw_key, key_family, key_index
+FROM internal_keys
+WHERE raw_key = $1
 `
 
-type FetchGenesisByIDRow struct {
-	AssetID     []byte
-	AssetTag    string
-	MetaData    []byte
-	OutputIndex int32
-	AssetType   int16
-	PrevOut     []byte
+type FetchInternalKeyByRawKeyRow struct {
+	RawKey    []byte
+	KeyFamily int32
+	KeyIndex  int32
 }
 
-func (q *Queries) FetchGenesisByID(ctx context.Context, genAssetID int32) (FetchGenesisByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, fetchGenesisByID, genAssetID)
-	var i FetchGenesisByIDRow
-	err := row.Scan(
-		&i.AssetID,
-		&i.AssetTag,
-		&i.MetaData,
-		&i.OutputIndex,
-		&i.AssetType,
-		&i.PrevOut,
-	)
+func (q *Queries) FetchInternalKeyByRawKey(ctx context.Context, rawKey []byte) (FetchInternalKeyByRawKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchInternalKeyByRawKey, rawKey)
+	var i FetchInternalKeyByRawKeyRow
+	err := row.Scan(&i.RawKey, &i.KeyFamily, &i.KeyIndex)
 	return i, err
 }
 
-const fetchGenesisPointByAnchorTx = `-- name: FetchGenesisPointByAnchorTx :one
-SELECT genesis_id, prev_out, anchor_tx_id 
-FROM genesis_points
-WHERE anchor_tx_id = $1
+const fetchInternalKeyIDByRawKey = `-- name: FetchInternalKeyIDByRawKey :one
+SELECT key_id
+FROM internal_keys
+WHERE raw_key = $1
 `
 
-func (q *Queries) FetchGenesisPointByAnchorTx(ctx context.Context, anchorTxID sql.NullInt32) (GenesisPoint, error) {
-	row := q.db.QueryRowContext(ctx, fetchGenesisPointByAnchorTx, anchorTxID)
-	var i GenesisPoint
-	err := row.Scan(&i.GenesisID, &i.PrevOut, &i.AnchorTxID)
-	return i, err
+func (q *Queries) FetchInternalKeyIDByRawKey(ctx context.Context, rawKey []byte) (int32, error) {
+	row := q.db.QueryRowContext(ctx, fetchInternalKeyIDByRawKey, rawKey)
+	var key_id int32
+	err := row.Scan(&key_id)
+	return key_id, err
 }
 
 const fetchManagedUTXO = `-- name: FetchManagedUTXO :one
@@ -950,6 +2375,71 @@ func (q *Queries) FetchMintingBatchesByInverseState(ctx context.Context, batchSt
 	return items, nil
 }
 
+const fetchQuarantinedAssets = `-- name: FetchQuarantinedAssets :many
+SELECT *
+FROM quarantined_assets
+ORDER BY quarantine_id
+`
+
+func (q *Queries) FetchQuarantinedAssets(ctx context.Context) ([]QuarantinedAsset, error) {
+	rows, err := q.db.QueryContext(ctx, fetchQuarantinedAssets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QuarantinedAsset
+	for rows.Next() {
+		var i QuarantinedAsset
+		if err := rows.Scan(
+			&i.QuarantineID,
+			&i.RawAsset,
+			&i.FailureReason,
+			&i.QuarantinedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchScriptKeyByTweakedKey = `-- name: FetchScriptKeyByTweakedKey :one
+SELECT
+    script_keys.tweak, script_keys.key_type,
+    internal_keys.raw_key, internal_keys.key_family, internal_keys.key_index
+FROM script_keys
+JOIN internal_keys
+    ON script_keys.internal_key_id = internal_keys.key_id
+WHERE script_keys.tweaked_script_key = $1
+`
+
+type FetchScriptKeyByTweakedKeyRow struct {
+	Tweak     []byte
+	KeyType   int16
+	RawKey    []byte
+	KeyFamily int32
+	KeyIndex  int32
+}
+
+func (q *Queries) FetchScriptKeyByTweakedKey(ctx context.Context, tweakedScriptKey []byte) (FetchScriptKeyByTweakedKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, fetchScriptKeyByTweakedKey, tweakedScriptKey)
+	var i FetchScriptKeyByTweakedKeyRow
+	err := row.Scan(
+		&i.Tweak,
+		&i.KeyType,
+		&i.RawKey,
+		&i.KeyFamily,
+		&i.KeyIndex,
+	)
+	return i, err
+}
+
 const fetchScriptKeyIDByTweakedKey = `-- name: FetchScriptKeyIDByTweakedKey :one
 SELECT script_key_id
 FROM script_keys
@@ -1009,8 +2499,115 @@ func (q *Queries) FetchSeedlingsForBatch(ctx context.Context, rawKey []byte) ([]
 	return items, nil
 }
 
+const fetchSharedScriptInternalKeys = `-- name: FetchSharedScriptInternalKeys :many
+SELECT internal_keys.raw_key, COUNT(*) AS num_assets
+FROM assets
+JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+JOIN internal_keys
+    ON script_keys.internal_key_id = internal_keys.key_id
+GROUP BY internal_keys.key_id, internal_keys.raw_key
+HAVING COUNT(*) >= $1
+ORDER BY internal_keys.key_id
+`
+
+type FetchSharedScriptInternalKeysRow struct {
+	RawKey    []byte
+	NumAssets int64
+}
+
+func (q *Queries) FetchSharedScriptInternalKeys(ctx context.Context, minNumAssets int64) ([]FetchSharedScriptInternalKeysRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchSharedScriptInternalKeys, minNumAssets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchSharedScriptInternalKeysRow
+	for rows.Next() {
+		var i FetchSharedScriptInternalKeysRow
+		if err := rows.Scan(&i.RawKey, &i.NumAssets); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchStaleUnanchoredAssets = `-- name: FetchStaleUnanchoredAssets :many
+SELECT
+    assets.asset_id AS asset_primary_key, assets.amount,
+    genesis_points.created_at, genesis_assets.asset_id, asset_tag,
+    reveals.meta_data,
+    output_index, asset_type, genesis_points.prev_out prev_out
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE assets.anchor_utxo_id IS NULL
+    AND genesis_points.created_at < $1
+ORDER BY genesis_points.created_at, assets.asset_id
+`
+
+type FetchStaleUnanchoredAssetsRow struct {
+	AssetPrimaryKey int32
+	Amount          int64
+	CreatedAt       sql.NullTime
+	AssetID         []byte
+	AssetTag        string
+	MetaData        []byte
+	OutputIndex     int32
+	AssetType       int16
+	PrevOut         []byte
+}
+
+// An asset is considered stale if it was never anchored in an on-chain output,
+// even though its genesis point was first stored before the given time.
+// Genesis points stored before their creation time was tracked are never
+// considered stale.
+func (q *Queries) FetchStaleUnanchoredAssets(ctx context.Context, olderThan sql.NullTime) ([]FetchStaleUnanchoredAssetsRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchStaleUnanchoredAssets, olderThan)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchStaleUnanchoredAssetsRow
+	for rows.Next() {
+		var i FetchStaleUnanchoredAssetsRow
+		if err := rows.Scan(
+			&i.AssetPrimaryKey,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.AssetID,
+			&i.AssetTag,
+			&i.MetaData,
+			&i.OutputIndex,
+			&i.AssetType,
+			&i.PrevOut,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const genesisAssets = `-- name: GenesisAssets :many
-SELECT gen_asset_id, asset_id, asset_tag, meta_data, output_index, asset_type, genesis_point_id 
+SELECT gen_asset_id, asset_id, asset_tag, meta_data, output_index, asset_type, genesis_point_id, meta_type, is_reissuance, meta_hash 
 FROM genesis_assets
 `
 
@@ -1031,6 +2628,9 @@ func (q *Queries) GenesisAssets(ctx context.Context) ([]GenesisAsset, error) {
 			&i.OutputIndex,
 			&i.AssetType,
 			&i.GenesisPointID,
+			&i.MetaType,
+			&i.IsReissuance,
+			&i.MetaHash,
 		); err != nil {
 			return nil, err
 		}
@@ -1046,7 +2646,7 @@ func (q *Queries) GenesisAssets(ctx context.Context) ([]GenesisAsset, error) {
 }
 
 const genesisPoints = `-- name: GenesisPoints :many
-SELECT genesis_id, prev_out, anchor_tx_id 
+SELECT genesis_id, prev_out, anchor_tx_id, created_at 
 FROM genesis_points
 `
 
@@ -1059,7 +2659,12 @@ func (q *Queries) GenesisPoints(ctx context.Context) ([]GenesisPoint, error) {
 	var items []GenesisPoint
 	for rows.Next() {
 		var i GenesisPoint
-		if err := rows.Scan(&i.GenesisID, &i.PrevOut, &i.AnchorTxID); err != nil {
+		if err := rows.Scan(
+			&i.GenesisID,
+			&i.PrevOut,
+			&i.AnchorTxID,
+			&i.CreatedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1173,25 +2778,47 @@ func (q *Queries) InsertAssetWitness(ctx context.Context, arg InsertAssetWitness
 	return err
 }
 
+const insertFreedKeyIndex = `-- name: InsertFreedKeyIndex :exec
+INSERT INTO freed_key_indexes (key_family, key_index)
+VALUES ($1, $2)
+ON CONFLICT (key_family, key_index)
+    DO UPDATE SET reserved = FALSE
+`
+
+type InsertFreedKeyIndexParams struct {
+	KeyFamily int32
+	KeyIndex  int32
+}
+
+// An index that's freed again after it was reserved and reused can be handed
+// out once more.
+func (q *Queries) InsertFreedKeyIndex(ctx context.Context, arg InsertFreedKeyIndexParams) error {
+	_, err := q.db.ExecContext(ctx, insertFreedKeyIndex, arg.KeyFamily, arg.KeyIndex)
+	return err
+}
+
 const insertNewAsset = `-- name: InsertNewAsset :one
 INSERT INTO assets (
     genesis_id, version, script_key_id, asset_group_sig_id, script_version, 
-    amount, lock_time, relative_lock_time, anchor_utxo_id
+    amount, lock_time, relative_lock_time, anchor_utxo_id,
+    split_commitment_root_hash, split_commitment_root_value
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING asset_id
 `
 
 type InsertNewAssetParams struct {
-	GenesisID        int32
-	Version          int32
-	ScriptKeyID      int32
-	AssetGroupSigID  sql.NullInt32
-	ScriptVersion    int32
-	Amount           int64
-	LockTime         sql.NullInt32
-	RelativeLockTime sql.NullInt32
-	AnchorUtxoID     sql.NullInt32
+	GenesisID                int32
+	Version                  int32
+	ScriptKeyID              int32
+	AssetGroupSigID          sql.NullInt32
+	ScriptVersion            int32
+	Amount                   int64
+	LockTime                 sql.NullInt32
+	RelativeLockTime         sql.NullInt32
+	AnchorUtxoID             sql.NullInt32
+	SplitCommitmentRootHash  []byte
+	SplitCommitmentRootValue sql.NullInt64
 }
 
 func (q *Queries) InsertNewAsset(ctx context.Context, arg InsertNewAssetParams) (int32, error) {
@@ -1205,12 +2832,54 @@ func (q *Queries) InsertNewAsset(ctx context.Context, arg InsertNewAssetParams)
 		arg.LockTime,
 		arg.RelativeLockTime,
 		arg.AnchorUtxoID,
+		arg.SplitCommitmentRootHash,
+		arg.SplitCommitmentRootValue,
 	)
 	var asset_id int32
 	err := row.Scan(&asset_id)
 	return asset_id, err
 }
 
+const insertQuarantinedAsset = `-- name: InsertQuarantinedAsset :one
+INSERT INTO quarantined_assets (
+    raw_asset, failure_reason, quarantined_at
+) VALUES (
+    $1, $2, $3
+)
+RETURNING quarantine_id
+`
+
+type InsertQuarantinedAssetParams struct {
+	RawAsset      []byte
+	FailureReason string
+	QuarantinedAt time.Time
+}
+
+func (q *Queries) InsertQuarantinedAsset(ctx context.Context, arg InsertQuarantinedAssetParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, insertQuarantinedAsset, arg.RawAsset, arg.FailureReason, arg.QuarantinedAt)
+	var quarantine_id int32
+	err := row.Scan(&quarantine_id)
+	return quarantine_id, err
+}
+
+const moveGenesisMetadata = `-- name: MoveGenesisMetadata :exec
+UPDATE genesis_assets
+SET meta_hash = $1, meta_data = NULL
+WHERE gen_asset_id = $2
+`
+
+type MoveGenesisMetadataParams struct {
+	MetaHash   []byte
+	GenAssetID int32
+}
+
+// Once the metadata of a genesis asset is stored as a reveal, the genesis asset
+// only references it by its hash.
+func (q *Queries) MoveGenesisMetadata(ctx context.Context, arg MoveGenesisMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, moveGenesisMetadata, arg.MetaHash, arg.GenAssetID)
+	return err
+}
+
 const newMintingBatch = `-- name: NewMintingBatch :exec
 INSERT INTO asset_minting_batches (
     batch_state, batch_id, height_hint, creation_time_unix
@@ -1241,6 +2910,7 @@ JOIN genesis_info_view
         $1 IS NULL)
 LEFT JOIN key_group_info_view
     ON assets.genesis_id = key_group_info_view.gen_asset_id
+WHERE assets.spent = false
 GROUP BY assets.genesis_id, genesis_info_view.asset_id,
          version, genesis_info_view.asset_tag, genesis_info_view.meta_data,
          genesis_info_view.asset_type, genesis_info_view.output_index,
@@ -1302,6 +2972,7 @@ JOIN key_group_info_view
     ON assets.genesis_id = key_group_info_view.gen_asset_id AND
       (key_group_info_view.tweaked_group_key = $1 OR
         $1 IS NULL)
+WHERE assets.spent = false
 GROUP BY key_group_info_view.tweaked_group_key
 `
 
@@ -1337,6 +3008,7 @@ const queryAssets = `-- name: QueryAssets :many
 SELECT
     assets.asset_id AS asset_primary_key, assets.genesis_id, version,
     script_keys.tweak AS script_key_tweak, 
+    script_keys.tweak IS NULL AS script_key_tweak_is_null,
     script_keys.tweaked_script_key, 
     internal_keys.raw_key AS script_key_raw,
     internal_keys.key_family AS script_key_fam,
@@ -1356,38 +3028,172 @@ SELECT
     txns.raw_tx AS anchor_tx, txns.txid AS anchor_txid, txns.block_hash AS anchor_block_hash,
     utxos.outpoint AS anchor_outpoint,
     utxo_internal_keys.raw_key AS anchor_internal_key,
-    split_commitment_root_hash, split_commitment_root_value
+    split_commitment_root_hash, split_commitment_root_value, assets.amount_big
 FROM assets
 JOIN genesis_info_view
-    ON assets.genesis_id = genesis_info_view.gen_asset_id AND
-      (genesis_info_view.asset_id = $1 OR
-        $1 IS NULL)
+    ON assets.genesis_id = genesis_info_view.gen_asset_id
 LEFT JOIN key_group_info_view
     ON assets.genesis_id = key_group_info_view.gen_asset_id
 JOIN script_keys
     on assets.script_key_id = script_keys.script_key_id
 JOIN internal_keys
     ON script_keys.internal_key_id = internal_keys.key_id
-JOIN managed_utxos utxos
-    ON assets.anchor_utxo_id = utxos.utxo_id AND
-      (utxos.outpoint = $2 OR
-       $2 IS NULL)
-JOIN internal_keys utxo_internal_keys
+LEFT JOIN managed_utxos utxos
+    ON assets.anchor_utxo_id = utxos.utxo_id
+LEFT JOIN internal_keys utxo_internal_keys
     ON utxos.internal_key_id = utxo_internal_keys.key_id
-JOIN chain_txns txns
+LEFT JOIN chain_txns txns
     ON utxos.txn_id = txns.txn_id
 WHERE (
+    (genesis_info_view.asset_id = $1 OR
+      $1 IS NULL) AND
+    (utxos.outpoint = $2 OR
+      $2 IS NULL) AND
     assets.amount >= COALESCE($3, assets.amount) AND
     (key_group_info_view.tweaked_group_key = $4 OR
-      $4 IS NULL)
+      $4 IS NULL) AND
+    (genesis_info_view.asset_type = $5 OR
+      $5 IS NULL) AND
+    (script_keys.is_known_raw = $6 OR
+      $6 IS NULL) AND
+    ((assets.lock_time IS NOT NULL) = $7 OR
+      $7 IS NULL) AND
+    (assets.spent = false OR $8 = true) AND
+    (utxos.utxo_id IS NOT NULL OR $9 = true) AND
+    -- Assets with an amount_big exceed the range of the amount column, and
+    -- therefore any maximum amount.
+    ((assets.amount <= $10 AND assets.amount_big IS NULL) OR
+      $10 IS NULL) AND
+    -- An asset that isn't anchored yet is considered to be unconfirmed.
+    ((txns.block_hash IS NOT NULL) = $11 OR
+      $11 IS NULL) AND
+    (assets.asset_id = $12 OR
+      $12 IS NULL) AND
+    (assets.asset_id > $13 OR
+      $13 IS NULL) AND
+    (assets.script_version = $14 OR
+      $14 IS NULL) AND
+    (script_keys.tweaked_script_key = $15 OR
+      $15 IS NULL) AND
+    (script_keys.tweak = $16 OR
+      $16 IS NULL) AND
+    (utxo_internal_keys.key_family = $17 OR
+      $17 IS NULL) AND
+    (genesis_info_view.asset_tag = $18 OR
+      $18 IS NULL) AND
+    -- The lower case comparison is done on genesis_assets directly rather
+    -- than through the view, so it can make use of the
+    -- genesis_asset_lower_tags index.
+    (assets.genesis_id IN (
+        SELECT gen_asset_id
+        FROM genesis_assets
+        WHERE lower(asset_tag) = lower($19)
+    ) OR $19 IS NULL) AND
+    (assets.genesis_id IN (
+        SELECT gen_asset_id
+        FROM genesis_assets
+        WHERE meta_type = $20
+    ) OR $20 IS NULL) AND
+    (length(genesis_info_view.meta_data) >= $21 OR
+      $21 IS NULL) AND
+    (length(genesis_info_view.meta_data) <= $22 OR
+      $22 IS NULL) AND
+    -- An asset of a genesis that is part of an asset group should always
+    -- reference a group sig itself.
+    ((key_group_info_view.gen_asset_id IS NOT NULL AND NOT EXISTS (
+        SELECT 1
+        FROM asset_group_sigs sigs
+        WHERE sigs.sig_id = assets.asset_group_sig_id
+    )) = $23 OR
+      $23 IS NULL) AND
+    -- Assets stored without the group sig of their genesis asset aren't
+    -- linked to their group yet, so we can only find them through the
+    -- genesis point the group key was stored with.
+    ((assets.genesis_id IN (
+        SELECT gen_assets.gen_asset_id
+        FROM genesis_assets gen_assets
+        JOIN asset_groups groups
+            ON gen_assets.genesis_point_id = groups.genesis_point_id
+        WHERE groups.tweaked_group_key = $24
+    ) AND NOT EXISTS (
+        SELECT 1
+        FROM asset_group_sigs sigs
+        WHERE sigs.gen_asset_id = assets.genesis_id
+    )) OR $24 IS NULL) AND
+    -- Assets can be paged through in their canonical order, by their asset
+    -- ID, then their script key and then their primary key, by passing
+    -- those of the last asset of the previous page. The same asset can be
+    -- sent to the same script key more than once, so the primary key is
+    -- needed to tell those assets apart.
+    (genesis_info_view.asset_id > $25 OR
+      (genesis_info_view.asset_id = $25 AND
+        (script_keys.tweaked_script_key >
+          $26 OR
+          (script_keys.tweaked_script_key =
+            $26 AND
+            assets.asset_id > $27))) OR
+      $25 IS NULL)
 )
+ORDER BY
+    CASE WHEN $28 = true THEN assets.amount
+    END DESC,
+    CASE WHEN $28 = true
+        THEN COALESCE(LENGTH(assets.amount_big), 0)
+    END DESC,
+    CASE WHEN $28 = true
+        THEN COALESCE(assets.amount_big, '')
+    END DESC,
+    CASE WHEN $28 = false THEN assets.amount
+    END,
+    CASE WHEN $28 = false
+        THEN COALESCE(LENGTH(assets.amount_big), 0)
+    END,
+    CASE WHEN $28 = false
+        THEN COALESCE(assets.amount_big, '')
+    END,
+    CASE WHEN $29 = true
+        THEN genesis_info_view.asset_id
+    END,
+    CASE WHEN $29 = true
+        THEN script_keys.tweaked_script_key
+    END,
+    assets.asset_id
+LIMIT COALESCE($30, 9223372036854775807)
+OFFSET COALESCE($31, 0)
 `
 
 type QueryAssetsParams struct {
-	AssetIDFilter  []byte
-	AnchorPoint    []byte
-	MinAmt         sql.NullInt64
-	KeyGroupFilter []byte
+	AssetIDFilter        []byte
+	AnchorPoint          []byte
+	MinAmt               sql.NullInt64
+	KeyGroupFilter       []byte
+	AssetTypeFilter      sql.NullInt16
+	ScriptKeyKnown       sql.NullBool
+	HasLockTime          sql.NullBool
+	IncludeSpent         sql.NullBool
+	IncludeUnanchored    sql.NullBool
+	MaxAmt               sql.NullInt64
+	Confirmed            sql.NullBool
+	AssetPrimaryKey      sql.NullInt32
+	AfterAssetID         sql.NullInt32
+	ScriptVersionFilter  sql.NullInt32
+	ScriptKeyFilter      []byte
+	ScriptKeyTweakFilter []byte
+	AnchorKeyFamily      sql.NullInt32
+	AssetTagFilter       sql.NullString
+	AssetTagLowerFilter  sql.NullString
+	MetaTypeFilter       sql.NullInt16
+	MinMetaLength        sql.NullInt64
+	MaxMetaLength        sql.NullInt64
+	GroupedWithoutSig    sql.NullBool
+	MissingSigGroupKey   []byte
+	AfterSortAssetID     []byte
+	AfterSortScriptKey   []byte
+	AfterSortPrimaryKey  sql.NullInt32
+	AmountDescending     sql.NullBool
+	CanonicalOrder       sql.NullBool
+	NumLimit             sql.NullInt32
+	NumOffset            sql.NullInt32
 }
 
 type QueryAssetsRow struct {
@@ -1395,6 +3201,7 @@ type QueryAssetsRow struct {
 	GenesisID                int32
 	Version                  int32
 	ScriptKeyTweak           []byte
+	ScriptKeyTweakIsNull     bool
 	TweakedScriptKey         []byte
 	ScriptKeyRaw             []byte
 	ScriptKeyFam             int32
@@ -1421,22 +3228,61 @@ type QueryAssetsRow struct {
 	AnchorInternalKey        []byte
 	SplitCommitmentRootHash  []byte
 	SplitCommitmentRootValue sql.NullInt64
+	AmountBig                sql.NullString
 }
 
 // We use a LEFT JOIN here as not every asset has a group key, so this'll
 // generate rows that have NULL values for the group key fields if an asset
 // doesn't have a group key. See the comment in fetchAssetSprouts for a work
 // around that needs to be used with this query until a sqlc bug is fixed.
+// We use a LEFT JOIN for all the anchor information, so assets that aren't
+// anchored yet can be returned as well.
 // This clause is used to select specific assets for a asset ID, general
 // channel balances, and also coin selection. We use the sqlc.narg feature to
 // make the entire statement evaluate to true, if none of these extra args are
-// specified.
+// specified. Unless asked for, spent assets and assets that aren't anchored
+// yet are left out.
+// If requested, the assets are ordered by their amount. Amounts exceeding
+// the range of the amount column are stored as the max value of the column,
+// so those are ordered by their exact decimal amount_big, first by its length
+// and then lexicographically. The primary key is used as a tie breaker to
+// keep the order stable, which also makes it possible to page through the
+// assets by their primary key.
+// The limit defaults to the largest value both sqlite and postgres accept,
+// which is the same as no limit at all.
 func (q *Queries) QueryAssets(ctx context.Context, arg QueryAssetsParams) ([]QueryAssetsRow, error) {
 	rows, err := q.db.QueryContext(ctx, queryAssets,
 		arg.AssetIDFilter,
 		arg.AnchorPoint,
 		arg.MinAmt,
 		arg.KeyGroupFilter,
+		arg.AssetTypeFilter,
+		arg.ScriptKeyKnown,
+		arg.HasLockTime,
+		arg.IncludeSpent,
+		arg.IncludeUnanchored,
+		arg.MaxAmt,
+		arg.Confirmed,
+		arg.AssetPrimaryKey,
+		arg.AfterAssetID,
+		arg.ScriptVersionFilter,
+		arg.ScriptKeyFilter,
+		arg.ScriptKeyTweakFilter,
+		arg.AnchorKeyFamily,
+		arg.AssetTagFilter,
+		arg.AssetTagLowerFilter,
+		arg.MetaTypeFilter,
+		arg.MinMetaLength,
+		arg.MaxMetaLength,
+		arg.GroupedWithoutSig,
+		arg.MissingSigGroupKey,
+		arg.AfterSortAssetID,
+		arg.AfterSortScriptKey,
+		arg.AfterSortPrimaryKey,
+		arg.AmountDescending,
+		arg.CanonicalOrder,
+		arg.NumLimit,
+		arg.NumOffset,
 	)
 	if err != nil {
 		return nil, err
@@ -1450,6 +3296,7 @@ func (q *Queries) QueryAssets(ctx context.Context, arg QueryAssetsParams) ([]Que
 			&i.GenesisID,
 			&i.Version,
 			&i.ScriptKeyTweak,
+			&i.ScriptKeyTweakIsNull,
 			&i.TweakedScriptKey,
 			&i.ScriptKeyRaw,
 			&i.ScriptKeyFam,
@@ -1476,6 +3323,7 @@ func (q *Queries) QueryAssets(ctx context.Context, arg QueryAssetsParams) ([]Que
 			&i.AnchorInternalKey,
 			&i.SplitCommitmentRootHash,
 			&i.SplitCommitmentRootValue,
+			&i.AmountBig,
 		); err != nil {
 			return nil, err
 		}
@@ -1490,6 +3338,109 @@ func (q *Queries) QueryAssets(ctx context.Context, arg QueryAssetsParams) ([]Que
 	return items, nil
 }
 
+const reserveFreedKeyIndex = `-- name: ReserveFreedKeyIndex :one
+UPDATE freed_key_indexes
+SET reserved = TRUE
+WHERE reserved = FALSE AND id = (
+    SELECT freed.id
+    FROM freed_key_indexes freed
+    WHERE freed.key_family = $1 AND freed.reserved = FALSE AND
+        NOT EXISTS (
+            SELECT 1 FROM internal_keys keys
+            WHERE keys.key_family = freed.key_family AND
+                keys.key_index = freed.key_index
+        )
+    ORDER BY freed.key_index
+    LIMIT 1
+)
+RETURNING key_index
+`
+
+// The lowest unreserved index in the family is reserved, so gaps are filled
+// from the bottom up. Indexes whose key was stored again in the
+// meantime are skipped, as they're in use once more.
+func (q *Queries) ReserveFreedKeyIndex(ctx context.Context, keyFamily int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, reserveFreedKeyIndex, keyFamily)
+	var key_index int32
+	err := row.Scan(&key_index)
+	return key_index, err
+}
+
+const setAssetBigAmount = `-- name: SetAssetBigAmount :exec
+UPDATE assets
+SET amount = $1, amount_big = $2
+WHERE asset_id = $3
+`
+
+type SetAssetBigAmountParams struct {
+	Amount    int64
+	AmountBig sql.NullString
+	AssetID   int32
+}
+
+func (q *Queries) SetAssetBigAmount(ctx context.Context, arg SetAssetBigAmountParams) error {
+	_, err := q.db.ExecContext(ctx, setAssetBigAmount, arg.Amount, arg.AmountBig, arg.AssetID)
+	return err
+}
+
+const setAssetSpent = `-- name: SetAssetSpent :execrows
+UPDATE assets
+SET spent = true
+WHERE asset_id = $1 AND spent = false
+`
+
+func (q *Queries) SetAssetSpent(ctx context.Context, assetID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setAssetSpent, assetID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setGenesisAssetMetaType = `-- name: SetGenesisAssetMetaType :execrows
+UPDATE genesis_assets
+SET meta_type = $1
+WHERE asset_id = $2
+`
+
+type SetGenesisAssetMetaTypeParams struct {
+	MetaType int16
+	AssetID  []byte
+}
+
+func (q *Queries) SetGenesisAssetMetaType(ctx context.Context, arg SetGenesisAssetMetaTypeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setGenesisAssetMetaType, arg.MetaType, arg.AssetID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setGenesisReissuance = `-- name: SetGenesisReissuance :exec
+UPDATE genesis_assets
+SET is_reissuance = EXISTS (
+    SELECT 1
+    FROM asset_group_sigs sigs
+    WHERE sigs.group_key_id = $1
+        AND sigs.sig_id < $2
+)
+WHERE gen_asset_id = $3
+`
+
+type SetGenesisReissuanceParams struct {
+	GroupKeyID int32
+	SigID      int32
+	GenAssetID int32
+}
+
+// A genesis asset is a reissuance if another genesis asset was issued under
+// the same group key before it, which we can tell by the order the group sigs
+// were inserted in.
+func (q *Queries) SetGenesisReissuance(ctx context.Context, arg SetGenesisReissuanceParams) error {
+	_, err := q.db.ExecContext(ctx, setGenesisReissuance, arg.GroupKeyID, arg.SigID, arg.GenAssetID)
+	return err
+}
+
 const updateBatchGenesisTx = `-- name: UpdateBatchGenesisTx :exec
 WITH target_batch AS (
     SELECT batch_id
@@ -1622,8 +3573,7 @@ const upsertChainTx = `-- name: UpsertChainTx :one
 INSERT INTO chain_txns (
     txid, raw_tx, chain_fees, block_height, block_hash, tx_index
 ) VALUES (
-    $1, $2, $3, $4, $5,
-    $6
+    $1, $2, $3, $4, $5, $6
 ) ON CONFLICT (txid)
     -- Not a NOP but instead update any nullable fields that aren't null in the
     -- args.
@@ -1658,56 +3608,141 @@ func (q *Queries) UpsertChainTx(ctx context.Context, arg UpsertChainTxParams) (i
 
 const upsertGenesisAsset = `-- name: UpsertGenesisAsset :one
 INSERT INTO genesis_assets (
-    asset_id, asset_tag, meta_data, output_index, asset_type, genesis_point_id
+    asset_id, asset_tag, output_index, asset_type, genesis_point_id, meta_type,
+    meta_hash
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5,
+    $6, $7
 ) ON CONFLICT (asset_tag)
-    -- This is a NOP, asset_tag is the unique field that caused the conflict.
-    DO UPDATE SET asset_tag = EXCLUDED.asset_tag
+    -- As the asset ID commits to the metadata and stored assets reference the
+    -- genesis asset, the asset ID, metadata and metadata hash of an existing
+    -- genesis asset are never changed. If the metadata differs, the metadata
+    -- policy decides what happens: 0 keeps the existing metadata, while 1
+    -- (replace) and 2 (prefer longer, if the new metadata is longer) reject
+    -- the upsert, in which case no row is returned. The metadata itself is
+    -- only stored in genesis_meta_reveals, so the lengths are compared there.
+    -- The metadata type of the very same metadata can be set, unless it's
+    -- upserted as opaque bytes.
+    DO UPDATE SET
+        meta_type = CASE
+            WHEN $8 != 0 AND EXCLUDED.meta_type != 0 AND
+                EXCLUDED.asset_id = genesis_assets.asset_id
+            THEN EXCLUDED.meta_type
+            ELSE genesis_assets.meta_type
+        END
+    WHERE $8 = 0 OR
+        EXCLUDED.asset_id = genesis_assets.asset_id OR (
+            $8 = 2 AND
+                COALESCE((
+                    SELECT length(meta_data)
+                    FROM genesis_meta_reveals
+                    WHERE meta_hash = EXCLUDED.meta_hash
+                ), 0) <= COALESCE((
+                    SELECT length(meta_data)
+                    FROM genesis_meta_reveals
+                    WHERE meta_hash = genesis_assets.meta_hash
+                ), 0)
+        )
 RETURNING gen_asset_id
 `
 
 type UpsertGenesisAssetParams struct {
 	AssetID        []byte
 	AssetTag       string
-	MetaData       []byte
 	OutputIndex    int32
 	AssetType      int16
 	GenesisPointID int32
+	MetaType       int16
+	MetaHash       []byte
+	MetadataPolicy int16
 }
 
 func (q *Queries) UpsertGenesisAsset(ctx context.Context, arg UpsertGenesisAssetParams) (int32, error) {
 	row := q.db.QueryRowContext(ctx, upsertGenesisAsset,
 		arg.AssetID,
 		arg.AssetTag,
-		arg.MetaData,
 		arg.OutputIndex,
 		arg.AssetType,
 		arg.GenesisPointID,
+		arg.MetaType,
+		arg.MetaHash,
+		arg.MetadataPolicy,
 	)
 	var gen_asset_id int32
 	err := row.Scan(&gen_asset_id)
 	return gen_asset_id, err
 }
 
+const upsertGenesisMetaReveal = `-- name: UpsertGenesisMetaReveal :exec
+INSERT INTO genesis_meta_reveals (
+    meta_hash, meta_data
+) VALUES (
+    $1, $2
+) ON CONFLICT (meta_hash)
+    -- The hash commits to the metadata, so there's nothing to update.
+    DO NOTHING
+`
+
+type UpsertGenesisMetaRevealParams struct {
+	MetaHash []byte
+	MetaData []byte
+}
+
+func (q *Queries) UpsertGenesisMetaReveal(ctx context.Context, arg UpsertGenesisMetaRevealParams) error {
+	_, err := q.db.ExecContext(ctx, upsertGenesisMetaReveal, arg.MetaHash, arg.MetaData)
+	return err
+}
+
 const upsertGenesisPoint = `-- name: UpsertGenesisPoint :one
 INSERT INTO genesis_points(
-    prev_out
+    prev_out, created_at
 ) VALUES (
-    $1
+    $1, $2
 ) ON CONFLICT (prev_out)
     -- This is a NOP, prev_out is the unique field that caused the conflict.
     DO UPDATE SET prev_out = EXCLUDED.prev_out
 RETURNING genesis_id
 `
 
-func (q *Queries) UpsertGenesisPoint(ctx context.Context, prevOut []byte) (int32, error) {
-	row := q.db.QueryRowContext(ctx, upsertGenesisPoint, prevOut)
+type UpsertGenesisPointParams struct {
+	PrevOut   []byte
+	CreatedAt sql.NullTime
+}
+
+func (q *Queries) UpsertGenesisPoint(ctx context.Context, arg UpsertGenesisPointParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, upsertGenesisPoint, arg.PrevOut, arg.CreatedAt)
 	var genesis_id int32
 	err := row.Scan(&genesis_id)
 	return genesis_id, err
 }
 
+const upsertImportCheckpoint = `-- name: UpsertImportCheckpoint :exec
+INSERT INTO import_checkpoints (
+    batch_id, last_committed_index, updated_at, batch_hash
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (batch_id)
+    DO UPDATE SET last_committed_index = EXCLUDED.last_committed_index,
+        updated_at = EXCLUDED.updated_at, batch_hash = EXCLUDED.batch_hash
+`
+
+type UpsertImportCheckpointParams struct {
+	BatchID            string
+	LastCommittedIndex int32
+	UpdatedAt          time.Time
+	BatchHash          []byte
+}
+
+func (q *Queries) UpsertImportCheckpoint(ctx context.Context, arg UpsertImportCheckpointParams) error {
+	_, err := q.db.ExecContext(ctx, upsertImportCheckpoint,
+		arg.BatchID,
+		arg.LastCommittedIndex,
+		arg.UpdatedAt,
+		arg.BatchHash,
+	)
+	return err
+}
+
 const upsertInternalKey = `-- name: UpsertInternalKey :one
 INSERT INTO internal_keys (
     raw_key,  key_family, key_index
@@ -1774,13 +3809,27 @@ func (q *Queries) UpsertManagedUTXO(ctx context.Context, arg UpsertManagedUTXOPa
 
 const upsertScriptKey = `-- name: UpsertScriptKey :one
 INSERT INTO script_keys (
-    internal_key_id, tweaked_script_key, tweak
+    internal_key_id, tweaked_script_key, tweak, is_known_raw, key_type
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 )  ON CONFLICT (tweaked_script_key)
-    -- As a NOP, we just set the script key to the one that triggered the
-    -- conflict.
-    DO UPDATE SET tweaked_script_key = EXCLUDED.tweaked_script_key
+    -- A script key that was imported without knowing its raw key is
+    -- upgraded once the raw key becomes known. A known script key is never
+    -- downgraded, so otherwise this is a NOP.
+    DO UPDATE SET
+        internal_key_id = CASE WHEN EXCLUDED.is_known_raw
+            THEN EXCLUDED.internal_key_id
+            ELSE script_keys.internal_key_id
+        END,
+        tweak = CASE WHEN EXCLUDED.is_known_raw
+            THEN EXCLUDED.tweak
+            ELSE script_keys.tweak
+        END,
+        key_type = CASE WHEN EXCLUDED.is_known_raw
+            THEN EXCLUDED.key_type
+            ELSE script_keys.key_type
+        END,
+        is_known_raw = script_keys.is_known_raw OR EXCLUDED.is_known_raw
 RETURNING script_key_id
 `
 
@@ -1788,10 +3837,18 @@ type UpsertScriptKeyParams struct {
 	InternalKeyID    int32
 	TweakedScriptKey []byte
 	Tweak            []byte
+	IsKnownRaw       bool
+	KeyType          int16
 }
 
 func (q *Queries) UpsertScriptKey(ctx context.Context, arg UpsertScriptKeyParams) (int32, error) {
-	row := q.db.QueryRowContext(ctx, upsertScriptKey, arg.InternalKeyID, arg.TweakedScriptKey, arg.Tweak)
+	row := q.db.QueryRowContext(ctx, upsertScriptKey,
+		arg.InternalKeyID,
+		arg.TweakedScriptKey,
+		arg.Tweak,
+		arg.IsKnownRaw,
+		arg.KeyType,
+	)
 	var script_key_id int32
 	err := row.Scan(&script_key_id)
 	return script_key_id, err
@@ -0,0 +1,48 @@
+package sqlc
+
+// BackendType is the type of database backend the queries are executed
+// against.
+type BackendType uint8
+
+const (
+	// BackendTypeUnknown denotes queries that were created without a
+	// backend type, e.g. through New.
+	BackendTypeUnknown BackendType = iota
+
+	// BackendTypeSqlite denotes queries executed against SQLite.
+	BackendTypeSqlite
+
+	// BackendTypePostgres denotes queries executed against Postgres.
+	BackendTypePostgres
+)
+
+// backendDBTX wraps a DBTX along with the type of database backend it executes
+// queries against.
+type backendDBTX struct {
+	DBTX
+
+	backendType BackendType
+}
+
+// NewForType creates new queries that are executed through the given DBTX
+// against the given type of database backend, so queries that are only
+// supported by some backends can be used conditionally.
+func NewForType(db DBTX, backendType BackendType) *Queries {
+	return &Queries{
+		db: &backendDBTX{
+			DBTX:        db,
+			backendType: backendType,
+		},
+	}
+}
+
+// Backend returns the type of database backend the queries are executed
+// against.
+func (q *Queries) Backend() BackendType {
+	db, ok := q.db.(*backendDBTX)
+	if !ok {
+		return BackendTypeUnknown
+	}
+
+	return db.backendType
+}
@@ -0,0 +1,57 @@
+package sqlc
+
+import (
+	"context"
+)
+
+// BatchQuerier holds the queries of batch_queries.go, along with the type of
+// database backend all queries are executed against. As they're written by
+// hand rather than generated, they're kept out of the generated Querier
+// interface, which would otherwise lose them on the next regeneration.
+type BatchQuerier interface {
+	// Backend returns the type of database backend the queries are
+	// executed against.
+	Backend() BackendType
+
+	// FetchGenesesByIDs returns all genesis assets with one of the given
+	// primary keys with a single statement. The rows are returned in no
+	// particular order.
+	FetchGenesesByIDs(ctx context.Context,
+		genAssetIDs []int32) ([]FetchGenesesByIDsRow, error)
+
+	// FetchGroupSigsByGenAssetIDs returns the group sigs of all genesis
+	// assets with one of the given primary keys with a single statement.
+	// The rows are returned in no particular order.
+	FetchGroupSigsByGenAssetIDs(ctx context.Context,
+		genAssetIDs []int32) ([]FetchGroupSigsByGenAssetIDsRow, error)
+
+	// SetAssetsSpent marks all the given assets as spent with a single
+	// statement, and returns the number of assets that weren't spent
+	// before.
+	SetAssetsSpent(ctx context.Context, assetIDs []int32) (int64, error)
+
+	// TouchAssets sets the update time of all the given assets with a
+	// single statement, and returns the number of assets that were
+	// updated.
+	TouchAssets(ctx context.Context, arg TouchAssetsParams) (int64, error)
+
+	// UpsertGenesisPoints inserts new or updates existing genesis points
+	// with a single multi-row statement. The rows are returned in no
+	// particular order.
+	UpsertGenesisPoints(ctx context.Context,
+		arg UpsertGenesisPointsParams) ([]UpsertGenesisPointsRow, error)
+
+	// UpsertGenesisAssets inserts new or resolves existing genesis assets
+	// with a single multi-row statement. The rows are returned in no
+	// particular order.
+	UpsertGenesisAssets(ctx context.Context,
+		arg UpsertGenesisAssetsParams) ([]UpsertGenesisAssetsRow, error)
+
+	// UpsertInternalKeys inserts new or updates existing internal keys
+	// with a single multi-row statement. The rows are returned in no
+	// particular order.
+	UpsertInternalKeys(ctx context.Context,
+		keys []UpsertInternalKeyParams) ([]UpsertInternalKeysRow, error)
+}
+
+var _ BatchQuerier = (*Queries)(nil)
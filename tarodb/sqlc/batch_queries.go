@@ -0,0 +1,435 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// The queries in this file can't be generated by sqlc, as the number of rows
+// they write, and with it their SQL, depends on their arguments.
+
+const upsertGenesisPointsPrefix = `INSERT INTO genesis_points(
+    prev_out, created_at
+) VALUES `
+
+const upsertGenesisPointsSuffix = `
+ON CONFLICT (prev_out)
+    -- This is a NOP, prev_out is the unique field that caused the conflict.
+    DO UPDATE SET prev_out = EXCLUDED.prev_out
+RETURNING genesis_id, prev_out
+`
+
+type UpsertGenesisPointsParams struct {
+	// PrevOuts is the set of encoded genesis points to upsert. A genesis
+	// point must not be contained more than once, as a single statement
+	// can't update the same row twice.
+	PrevOuts  [][]byte
+	CreatedAt sql.NullTime
+}
+
+type UpsertGenesisPointsRow struct {
+	GenesisID int32
+	PrevOut   []byte
+}
+
+// UpsertGenesisPoints inserts new or updates existing genesis points with a
+// single multi-row statement. The rows are returned in no particular order.
+func (q *Queries) UpsertGenesisPoints(ctx context.Context, arg UpsertGenesisPointsParams) ([]UpsertGenesisPointsRow, error) {
+	if len(arg.PrevOuts) == 0 {
+		return nil, nil
+	}
+
+	// All rows share the creation time as the first parameter, followed
+	// by the genesis point of each row.
+	var query strings.Builder
+	query.WriteString(upsertGenesisPointsPrefix)
+	args := make([]interface{}, 0, len(arg.PrevOuts)+1)
+	args = append(args, arg.CreatedAt)
+	for i, prevOut := range arg.PrevOuts {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "($%d, $1)", i+2)
+		args = append(args, prevOut)
+	}
+	query.WriteString(upsertGenesisPointsSuffix)
+
+	rows, err := q.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UpsertGenesisPointsRow
+	for rows.Next() {
+		var i UpsertGenesisPointsRow
+		if err := rows.Scan(&i.GenesisID, &i.PrevOut); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAssetsSpentPrefix = `UPDATE assets
+SET spent = true
+WHERE spent = false AND asset_id IN (`
+
+// SetAssetsSpent marks all the given assets as spent with a single
+// statement, and returns the number of assets that weren't spent before.
+func (q *Queries) SetAssetsSpent(ctx context.Context, assetIDs []int32) (int64, error) {
+	if len(assetIDs) == 0 {
+		return 0, nil
+	}
+
+	var query strings.Builder
+	query.WriteString(setAssetsSpentPrefix)
+	args := make([]interface{}, 0, len(assetIDs))
+	for i, assetID := range assetIDs {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "$%d", i+1)
+		args = append(args, assetID)
+	}
+	query.WriteString(")")
+
+	result, err := q.db.ExecContext(ctx, query.String(), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const touchAssetsPrefix = `UPDATE assets
+SET updated_at = $1
+WHERE asset_id IN (`
+
+type TouchAssetsParams struct {
+	AssetIDs  []int32
+	UpdatedAt sql.NullTime
+}
+
+// TouchAssets sets the update time of all the given assets with a single
+// statement, and returns the number of assets that were updated.
+func (q *Queries) TouchAssets(ctx context.Context, arg TouchAssetsParams) (int64, error) {
+	if len(arg.AssetIDs) == 0 {
+		return 0, nil
+	}
+
+	// All rows share the update time as the first parameter, followed by
+	// the primary key of each asset.
+	var query strings.Builder
+	query.WriteString(touchAssetsPrefix)
+	args := make([]interface{}, 0, len(arg.AssetIDs)+1)
+	args = append(args, arg.UpdatedAt)
+	for i, assetID := range arg.AssetIDs {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "$%d", i+2)
+		args = append(args, assetID)
+	}
+	query.WriteString(")")
+
+	result, err := q.db.ExecContext(ctx, query.String(), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertInternalKeysPrefix = `INSERT INTO internal_keys (
+    raw_key,  key_family, key_index
+) VALUES `
+
+const upsertInternalKeysSuffix = `
+ON CONFLICT (raw_key)
+    -- This is a NOP, raw_key is the unique field that caused the conflict.
+    DO UPDATE SET raw_key = EXCLUDED.raw_key
+RETURNING key_id, raw_key
+`
+
+type UpsertInternalKeysRow struct {
+	KeyID  int32
+	RawKey []byte
+}
+
+// UpsertInternalKeys inserts new or updates existing internal keys with a
+// single multi-row statement. A raw key must not be contained more than once,
+// as a single statement can't update the same row twice. The rows are
+// returned in no particular order.
+func (q *Queries) UpsertInternalKeys(ctx context.Context, keys []UpsertInternalKeyParams) ([]UpsertInternalKeysRow, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var query strings.Builder
+	query.WriteString(upsertInternalKeysPrefix)
+	args := make([]interface{}, 0, len(keys)*3)
+	for i, key := range keys {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3)
+		args = append(args, key.RawKey, key.KeyFamily, key.KeyIndex)
+	}
+	query.WriteString(upsertInternalKeysSuffix)
+
+	rows, err := q.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UpsertInternalKeysRow
+	for rows.Next() {
+		var i UpsertInternalKeysRow
+		if err := rows.Scan(&i.KeyID, &i.RawKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertGenesisAssetsPrefix = `INSERT INTO genesis_assets (
+    asset_id, asset_tag, output_index, asset_type, genesis_point_id, meta_type,
+    meta_hash
+) VALUES `
+
+// The conflict clause is the very same as the one of UpsertGenesisAsset, with
+// the metadata policy shared by all rows as the first parameter.
+const upsertGenesisAssetsSuffix = `
+ON CONFLICT (asset_tag)
+    DO UPDATE SET
+        meta_type = CASE
+            WHEN $1 != 0 AND EXCLUDED.meta_type != 0 AND
+                EXCLUDED.asset_id = genesis_assets.asset_id
+            THEN EXCLUDED.meta_type
+            ELSE genesis_assets.meta_type
+        END
+    WHERE $1 = 0 OR
+        EXCLUDED.asset_id = genesis_assets.asset_id OR (
+            $1 = 2 AND
+                COALESCE((
+                    SELECT length(meta_data)
+                    FROM genesis_meta_reveals
+                    WHERE meta_hash = EXCLUDED.meta_hash
+                ), 0) <= COALESCE((
+                    SELECT length(meta_data)
+                    FROM genesis_meta_reveals
+                    WHERE meta_hash = genesis_assets.meta_hash
+                ), 0)
+        )
+RETURNING gen_asset_id, asset_tag
+`
+
+type UpsertGenesisAssetsParams struct {
+	// Assets is the set of genesis assets to upsert. An asset tag must not
+	// be contained more than once, as a single statement can't update the
+	// same row twice. The metadata policy of the individual genesis assets
+	// is ignored in favor of MetadataPolicy.
+	Assets         []UpsertGenesisAssetParams
+	MetadataPolicy int16
+}
+
+type UpsertGenesisAssetsRow struct {
+	GenAssetID int32
+	AssetTag   string
+}
+
+// UpsertGenesisAssets inserts new or resolves existing genesis assets with a
+// single multi-row statement. The rows are returned in no particular order,
+// and no row is returned for a genesis asset the metadata policy rejected.
+func (q *Queries) UpsertGenesisAssets(ctx context.Context, arg UpsertGenesisAssetsParams) ([]UpsertGenesisAssetsRow, error) {
+	if len(arg.Assets) == 0 {
+		return nil, nil
+	}
+
+	// The metadata policy is the first parameter, followed by the seven
+	// columns of each row.
+	var query strings.Builder
+	query.WriteString(upsertGenesisAssetsPrefix)
+	args := make([]interface{}, 0, len(arg.Assets)*7+1)
+	args = append(args, arg.MetadataPolicy)
+	for i, genAsset := range arg.Assets {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for j := 0; j < 7; j++ {
+			if j > 0 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i*7+j+2)
+		}
+		query.WriteString(")")
+		args = append(args,
+			genAsset.AssetID,
+			genAsset.AssetTag,
+			genAsset.OutputIndex,
+			genAsset.AssetType,
+			genAsset.GenesisPointID,
+			genAsset.MetaType,
+			genAsset.MetaHash,
+		)
+	}
+	query.WriteString(upsertGenesisAssetsSuffix)
+
+	rows, err := q.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UpsertGenesisAssetsRow
+	for rows.Next() {
+		var i UpsertGenesisAssetsRow
+		if err := rows.Scan(&i.GenAssetID, &i.AssetTag); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGenesesByIDsPrefix = `SELECT
+    gen_asset_id, genesis_assets.asset_id, asset_tag, reveals.meta_data,
+    output_index, asset_type, meta_type, genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+  ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE gen_asset_id IN (`
+
+type FetchGenesesByIDsRow struct {
+	GenAssetID  int32
+	AssetID     []byte
+	AssetTag    string
+	MetaData    []byte
+	OutputIndex int32
+	AssetType   int16
+	MetaType    int16
+	PrevOut     []byte
+}
+
+// FetchGenesesByIDs returns all genesis assets with one of the given primary
+// keys with a single statement. The rows are returned in no particular order.
+func (q *Queries) FetchGenesesByIDs(ctx context.Context, genAssetIDs []int32) ([]FetchGenesesByIDsRow, error) {
+	if len(genAssetIDs) == 0 {
+		return nil, nil
+	}
+
+	var query strings.Builder
+	query.WriteString(fetchGenesesByIDsPrefix)
+	args := make([]interface{}, 0, len(genAssetIDs))
+	for i, genAssetID := range genAssetIDs {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "$%d", i+1)
+		args = append(args, genAssetID)
+	}
+	query.WriteString(")")
+
+	rows, err := q.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGenesesByIDsRow
+	for rows.Next() {
+		var i FetchGenesesByIDsRow
+		if err := rows.Scan(
+			&i.GenAssetID,
+			&i.AssetID,
+			&i.AssetTag,
+			&i.MetaData,
+			&i.OutputIndex,
+			&i.AssetType,
+			&i.MetaType,
+			&i.PrevOut,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupSigsByGenAssetIDsPrefix = `SELECT genesis_sig, gen_asset_id, group_key_id
+FROM asset_group_sigs
+WHERE gen_asset_id IN (`
+
+type FetchGroupSigsByGenAssetIDsRow struct {
+	GenesisSig []byte
+	GenAssetID int32
+	GroupKeyID int32
+}
+
+// FetchGroupSigsByGenAssetIDs returns the group sigs of all genesis assets with
+// one of the given primary keys with a single statement. The rows are returned
+// in no particular order.
+func (q *Queries) FetchGroupSigsByGenAssetIDs(ctx context.Context, genAssetIDs []int32) ([]FetchGroupSigsByGenAssetIDsRow, error) {
+	if len(genAssetIDs) == 0 {
+		return nil, nil
+	}
+
+	var query strings.Builder
+	query.WriteString(fetchGroupSigsByGenAssetIDsPrefix)
+	args := make([]interface{}, 0, len(genAssetIDs))
+	for i, genAssetID := range genAssetIDs {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "$%d", i+1)
+		args = append(args, genAssetID)
+	}
+	query.WriteString(")")
+
+	rows, err := q.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupSigsByGenAssetIDsRow
+	for rows.Next() {
+		var i FetchGroupSigsByGenAssetIDsRow
+		if err := rows.Scan(&i.GenesisSig, &i.GenAssetID, &i.GroupKeyID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS genesis_points_created_at_idx;
+ALTER TABLE genesis_points DROP COLUMN created_at;
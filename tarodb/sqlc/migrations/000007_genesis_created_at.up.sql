@@ -0,0 +1,6 @@
+-- created_at is the time a genesis point was first stored. This is NULL for
+-- all genesis points that were stored before this column was added.
+ALTER TABLE genesis_points ADD COLUMN created_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS genesis_points_created_at_idx
+    ON genesis_points(created_at);
@@ -0,0 +1 @@
+ALTER TABLE script_keys DROP COLUMN is_known_raw;
@@ -0,0 +1,14 @@
+-- is_known_raw is true if we know the raw (internal) key of a script key, and
+-- are therefore able to spend assets sent to it. Script keys of assets that
+-- were imported to mirror the state of another node only carry the tweaked
+-- key, which is stored as its own raw key.
+ALTER TABLE script_keys ADD COLUMN is_known_raw BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE script_keys
+SET is_known_raw = TRUE
+WHERE EXISTS (
+    SELECT 1
+    FROM internal_keys
+    WHERE internal_keys.key_id = script_keys.internal_key_id AND
+        internal_keys.raw_key != script_keys.tweaked_script_key
+);
@@ -0,0 +1 @@
+UPDATE assets SET lock_time = 0 WHERE lock_time IS NULL;
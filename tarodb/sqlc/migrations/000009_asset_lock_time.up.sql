@@ -0,0 +1,3 @@
+-- A lock time of zero means that an asset isn't time locked at all, which we
+-- now store as NULL, so time locked assets can be told apart easily.
+UPDATE assets SET lock_time = NULL WHERE lock_time = 0;
@@ -0,0 +1,12 @@
+-- quarantined_assets stores assets that failed validation, along with the
+-- reason they failed and their raw encoding, so they can be inspected and
+-- possibly recovered later on.
+CREATE TABLE IF NOT EXISTS quarantined_assets (
+    quarantine_id INTEGER PRIMARY KEY,
+
+    raw_asset BLOB NOT NULL,
+
+    failure_reason TEXT NOT NULL,
+
+    quarantined_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,5 @@
+-- amount_big stores the exact decimal representation of an asset's amount if
+-- it doesn't fit into the (signed) amount column. We use a TEXT column rather
+-- than a NUMERIC one, as sqlite would otherwise convert large values into
+-- lossy floating point numbers.
+ALTER TABLE assets ADD COLUMN amount_big TEXT;
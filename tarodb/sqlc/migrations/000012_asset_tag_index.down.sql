@@ -0,0 +1 @@
+DROP INDEX IF EXISTS genesis_asset_lower_tags;
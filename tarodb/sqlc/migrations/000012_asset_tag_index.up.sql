@@ -0,0 +1,2 @@
+-- This index allows assets to be looked up by their tag case-insensitively.
+CREATE INDEX IF NOT EXISTS genesis_asset_lower_tags ON genesis_assets (lower(asset_tag));
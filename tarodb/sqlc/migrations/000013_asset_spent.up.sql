@@ -0,0 +1,4 @@
+-- spent marks assets whose state was archived as it was transferred to a new
+-- asset. Spent assets are kept around for historical purposes, but are no
+-- longer part of the active asset set.
+ALTER TABLE assets ADD COLUMN spent BOOLEAN NOT NULL DEFAULT FALSE;
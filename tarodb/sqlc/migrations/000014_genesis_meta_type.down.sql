@@ -0,0 +1 @@
+ALTER TABLE genesis_assets DROP COLUMN meta_type;
@@ -0,0 +1,3 @@
+-- meta_type tells us how the metadata of a genesis asset should be
+-- interpreted. Existing metadata is treated as opaque bytes.
+ALTER TABLE genesis_assets ADD COLUMN meta_type SMALLINT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS script_key_tweaks;
@@ -0,0 +1,3 @@
+-- This index allows script-path assets to be looked up by the tapscript root
+-- their script key commits to.
+CREATE INDEX IF NOT EXISTS script_key_tweaks ON script_keys (tweak);
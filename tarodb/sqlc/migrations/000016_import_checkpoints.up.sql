@@ -0,0 +1,11 @@
+-- import_checkpoints records the progress of large imports that are committed
+-- in several sub-transactions. For each batch, it stores the index of the last
+-- asset that was committed, so an interrupted import can be resumed without
+-- re-inserting the assets that already made it to disk.
+CREATE TABLE IF NOT EXISTS import_checkpoints (
+    batch_id TEXT PRIMARY KEY,
+
+    last_committed_index INTEGER NOT NULL,
+
+    updated_at TIMESTAMP NOT NULL
+);
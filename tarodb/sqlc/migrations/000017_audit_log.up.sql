@@ -0,0 +1,16 @@
+-- audit_log is a hash chain over all the writes made through an auditing
+-- asset store. Each entry commits to the hash of the entry before it, so
+-- modifying or removing any entry but the last one breaks the chain. The
+-- first entry commits to an all-zero previous hash.
+CREATE TABLE IF NOT EXISTS audit_log (
+    entry_id INTEGER PRIMARY KEY,
+
+    -- prev_hash is unique, so concurrent writers can't fork the chain.
+    prev_hash BLOB UNIQUE NOT NULL CHECK(length(prev_hash) = 32),
+
+    op TEXT NOT NULL,
+
+    args_hash BLOB NOT NULL CHECK(length(args_hash) = 32),
+
+    new_hash BLOB UNIQUE NOT NULL CHECK(length(new_hash) = 32)
+);
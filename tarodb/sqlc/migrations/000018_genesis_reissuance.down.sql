@@ -0,0 +1 @@
+ALTER TABLE genesis_assets DROP COLUMN is_reissuance;
@@ -0,0 +1,4 @@
+-- is_reissuance marks genesis assets that were issued under a group key that
+-- already had another genesis asset issued under it before, as opposed to the
+-- genesis asset that first emitted the group.
+ALTER TABLE genesis_assets ADD COLUMN is_reissuance BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS genesis_meta_reveals;
+ALTER TABLE genesis_assets DROP COLUMN meta_hash;
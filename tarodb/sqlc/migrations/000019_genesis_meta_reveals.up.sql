@@ -0,0 +1,12 @@
+-- meta_hash is the SHA-256 hash of the metadata of a genesis asset, which is
+-- what the asset ID commits to. It's NULL for genesis assets that were stored
+-- before the hash was tracked.
+ALTER TABLE genesis_assets ADD COLUMN meta_hash BLOB CHECK(length(meta_hash) = 32);
+
+-- genesis_meta_reveals stores the full metadata of genesis assets keyed by
+-- the hash of the metadata, so the metadata can be revealed on demand.
+CREATE TABLE IF NOT EXISTS genesis_meta_reveals (
+    meta_hash BLOB PRIMARY KEY CHECK(length(meta_hash) = 32),
+
+    meta_data BLOB NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE assets DROP COLUMN updated_at;
@@ -0,0 +1,4 @@
+-- updated_at is the last time an asset was touched to force downstream
+-- consumers to resync it. This is NULL for all assets that were never
+-- touched.
+ALTER TABLE assets ADD COLUMN updated_at TIMESTAMP;
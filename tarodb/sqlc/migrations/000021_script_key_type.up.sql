@@ -0,0 +1,15 @@
+-- key_type records how the tweak of a script key was declared, so the signer
+-- knows how to derive it at spend time:
+--   0: unknown, the raw key isn't known, so the key was only imported to
+--      mirror the state of another node and can't be spent by us.
+--   1: BIP-86, the raw key is only tweaked with itself (no tweak stored).
+--   2: tweaked, the raw key is tweaked with the stored tweak, such as the
+--      root of the tapscript tree of a script-spend key.
+ALTER TABLE script_keys ADD COLUMN key_type SMALLINT NOT NULL DEFAULT 0;
+
+UPDATE script_keys
+SET key_type = CASE
+    WHEN tweak IS NULL OR length(tweak) = 0 THEN 1
+    ELSE 2
+END
+WHERE is_known_raw = TRUE;
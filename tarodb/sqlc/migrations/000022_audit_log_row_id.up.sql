@@ -0,0 +1,5 @@
+-- row_id is the primary key of the row an audit log entry refers to, if any.
+-- It allows the arguments committed to by an entry to be checked against the
+-- current state of the row. The row ID is part of the arguments, so it's
+-- covered by the hash chain as well.
+ALTER TABLE audit_log ADD COLUMN row_id INTEGER;
@@ -0,0 +1 @@
+UPDATE genesis_assets SET is_reissuance = FALSE;
@@ -0,0 +1,12 @@
+-- Genesis assets that were stored before is_reissuance was added all default
+-- to not being a reissuance, so we backfill the flag by the order the group
+-- sigs were inserted in, just like SetGenesisReissuance does for new ones.
+UPDATE genesis_assets
+SET is_reissuance = EXISTS (
+    SELECT 1
+    FROM asset_group_sigs own
+    JOIN asset_group_sigs sigs
+        ON sigs.group_key_id = own.group_key_id
+    WHERE own.gen_asset_id = genesis_assets.gen_asset_id
+        AND sigs.sig_id < own.sig_id
+);
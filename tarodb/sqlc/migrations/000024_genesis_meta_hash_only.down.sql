@@ -0,0 +1,30 @@
+-- The metadata is copied back into genesis_assets, where the old views expect
+-- it. The reveals themselves are kept.
+UPDATE genesis_assets
+SET meta_data = (
+    SELECT reveals.meta_data
+    FROM genesis_meta_reveals reveals
+    WHERE reveals.meta_hash = genesis_assets.meta_hash
+)
+WHERE meta_data IS NULL;
+
+DROP VIEW IF EXISTS key_group_info_view;
+DROP VIEW IF EXISTS genesis_info_view;
+
+CREATE VIEW genesis_info_view AS
+    SELECT
+        gen_asset_id, asset_id, asset_tag, meta_data, output_index, asset_type,
+        genesis_points.prev_out prev_out
+    FROM genesis_assets
+    JOIN genesis_points
+        ON genesis_assets.genesis_point_id = genesis_points.genesis_id;
+
+CREATE VIEW key_group_info_view AS
+    SELECT
+        sig_id, gen_asset_id, genesis_sig, tweaked_group_key, raw_key, key_index, key_family
+    FROM asset_group_sigs sigs
+    JOIN asset_groups groups
+        ON sigs.group_key_id = groups.group_id
+    JOIN internal_keys keys
+        ON keys.key_id = groups.internal_key_id
+    WHERE sigs.gen_asset_id IN (SELECT gen_asset_id FROM genesis_info_view);
@@ -0,0 +1,29 @@
+-- The metadata of genesis assets is only stored once in genesis_meta_reveals,
+-- keyed by its hash, while genesis_assets only references it by the hash. The
+-- metadata that's still stored in genesis_assets is moved over by a post
+-- migration step, as the hash can't be computed in SQL on all backends.
+--
+-- The key group view depends on the genesis view, so both are recreated, with
+-- the metadata of the genesis view now being joined in from the reveals.
+DROP VIEW IF EXISTS key_group_info_view;
+DROP VIEW IF EXISTS genesis_info_view;
+
+CREATE VIEW genesis_info_view AS
+    SELECT
+        gen_asset_id, asset_id, asset_tag, reveals.meta_data, output_index,
+        asset_type, genesis_points.prev_out prev_out
+    FROM genesis_assets
+    JOIN genesis_points
+        ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+    LEFT JOIN genesis_meta_reveals reveals
+        ON genesis_assets.meta_hash = reveals.meta_hash;
+
+CREATE VIEW key_group_info_view AS
+    SELECT
+        sig_id, gen_asset_id, genesis_sig, tweaked_group_key, raw_key, key_index, key_family
+    FROM asset_group_sigs sigs
+    JOIN asset_groups groups
+        ON sigs.group_key_id = groups.group_id
+    JOIN internal_keys keys
+        ON keys.key_id = groups.internal_key_id
+    WHERE sigs.gen_asset_id IN (SELECT gen_asset_id FROM genesis_info_view);
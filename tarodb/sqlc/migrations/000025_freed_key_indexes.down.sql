@@ -0,0 +1 @@
+DROP TABLE IF EXISTS freed_key_indexes;
@@ -0,0 +1,16 @@
+-- freed_key_indexes tracks the indexes of locally derived internal keys that
+-- were deleted as nothing used them anymore, for example because the asset
+-- they were derived for was deleted. A freed index is flagged as reserved once
+-- it's handed out again, so it's only ever reused once. Keys that weren't
+-- derived locally, such as the keys of imported assets, are never tracked.
+CREATE TABLE IF NOT EXISTS freed_key_indexes (
+    id INTEGER PRIMARY KEY,
+
+    key_family INTEGER NOT NULL,
+
+    key_index INTEGER NOT NULL,
+
+    reserved BOOLEAN NOT NULL DEFAULT FALSE,
+
+    UNIQUE(key_family, key_index)
+);
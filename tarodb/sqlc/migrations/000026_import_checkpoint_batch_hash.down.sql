@@ -0,0 +1 @@
+ALTER TABLE import_checkpoints DROP COLUMN batch_hash;
@@ -0,0 +1,5 @@
+-- batch_hash commits to the assets and anchors of the import a checkpoint was
+-- recorded for, so resuming an import with a different batch under the same ID
+-- is detected instead of skipping rows that were never imported. Checkpoints
+-- recorded before have no hash, so they can't be resumed.
+ALTER TABLE import_checkpoints ADD COLUMN batch_hash BLOB;
@@ -35,6 +35,15 @@ type AddrEvent struct {
 	AssetID             sql.NullInt32
 }
 
+type AuditLog struct {
+	EntryID  int32
+	PrevHash []byte
+	Op       string
+	ArgsHash []byte
+	NewHash  []byte
+	RowID    sql.NullInt32
+}
+
 type Asset struct {
 	AssetID                  int32
 	GenesisID                int32
@@ -48,6 +57,9 @@ type Asset struct {
 	SplitCommitmentRootHash  []byte
 	SplitCommitmentRootValue sql.NullInt64
 	AnchorUtxoID             sql.NullInt32
+	AmountBig                sql.NullString
+	Spent                    bool
+	UpdatedAt                sql.NullTime
 }
 
 type AssetDelta struct {
@@ -132,6 +144,13 @@ type ChainTxn struct {
 	TxIndex     sql.NullInt32
 }
 
+type FreedKeyIndex struct {
+	ID        int32
+	KeyFamily int32
+	KeyIndex  int32
+	Reserved  bool
+}
+
 type GenesisAsset struct {
 	GenAssetID     int32
 	AssetID        []byte
@@ -140,6 +159,9 @@ type GenesisAsset struct {
 	OutputIndex    int32
 	AssetType      int16
 	GenesisPointID int32
+	MetaType       int16
+	IsReissuance   bool
+	MetaHash       []byte
 }
 
 type GenesisInfoView struct {
@@ -152,10 +174,23 @@ type GenesisInfoView struct {
 	PrevOut     []byte
 }
 
+type GenesisMetaReveal struct {
+	MetaHash []byte
+	MetaData []byte
+}
+
 type GenesisPoint struct {
 	GenesisID  int32
 	PrevOut    []byte
 	AnchorTxID sql.NullInt32
+	CreatedAt  sql.NullTime
+}
+
+type ImportCheckpoint struct {
+	BatchID            string
+	LastCommittedIndex int32
+	UpdatedAt          time.Time
+	BatchHash          []byte
 }
 
 type InternalKey struct {
@@ -205,11 +240,20 @@ type MssmtRoot struct {
 	RootHash  []byte
 }
 
+type QuarantinedAsset struct {
+	QuarantineID  int32
+	RawAsset      []byte
+	FailureReason string
+	QuarantinedAt time.Time
+}
+
 type ScriptKey struct {
 	ScriptKeyID      int32
 	InternalKeyID    int32
 	TweakedScriptKey []byte
 	Tweak            []byte
+	IsKnownRaw       bool
+	KeyType          int16
 }
 
 type TransferProof struct {
@@ -13,23 +13,56 @@ type Querier interface {
 	AllAssets(ctx context.Context) ([]Asset, error)
 	AllInternalKeys(ctx context.Context) ([]InternalKey, error)
 	AllMintingBatches(ctx context.Context) ([]AllMintingBatchesRow, error)
+	AnchorAssetsByOutputIndex(ctx context.Context, arg AnchorAssetsByOutputIndexParams) ([]int32, error)
 	AnchorGenesisPoint(ctx context.Context, arg AnchorGenesisPointParams) error
-	AnchorPendingAssets(ctx context.Context, arg AnchorPendingAssetsParams) error
+	AnchorPendingAssets(ctx context.Context, arg AnchorPendingAssetsParams) ([]int32, error)
 	ApplySpendDelta(ctx context.Context, arg ApplySpendDeltaParams) (int32, error)
 	AssetsByGenesisPoint(ctx context.Context, prevOut []byte) ([]AssetsByGenesisPointRow, error)
 	AssetsInBatch(ctx context.Context, rawKey []byte) ([]AssetsInBatchRow, error)
+	// An asset is only bound to an anchor UTXO if it isn't anchored yet, or is
+	// already bound to that very UTXO, so an existing anchor is never replaced.
+	BindAssetAnchor(ctx context.Context, arg BindAssetAnchorParams) (int64, error)
 	BindMintingBatchWithTx(ctx context.Context, arg BindMintingBatchWithTxParams) error
 	ConfirmChainAnchorTx(ctx context.Context, arg ConfirmChainAnchorTxParams) error
 	ConfirmChainTx(ctx context.Context, arg ConfirmChainTxParams) error
+	CountAssetsByGenesisPoint(ctx context.Context, genesisPointID int32) (int64, error)
+	CountAssetsByType(ctx context.Context, includeSpent interface{}) ([]CountAssetsByTypeRow, error)
+	DeleteAssetProofsByScriptKeyID(ctx context.Context, scriptKeyID int32) error
 	DeleteAssetWitnesses(ctx context.Context, assetID int32) error
+	// The witnesses of the assets are deleted along with them, as they cascade.
+	DeleteAssetsByScriptKeyID(ctx context.Context, scriptKeyID int32) ([]int32, error)
+	DeleteImportCheckpoint(ctx context.Context, batchID string) error
 	DeleteManagedUTXO(ctx context.Context, outpoint []byte) error
 	DeleteNode(ctx context.Context, arg DeleteNodeParams) (int64, error)
+	// The internal key is only deleted if nothing references it anymore, such as
+	// another script key or a group key, in which case its locator is returned.
+	DeleteOrphanInternalKey(ctx context.Context, keyID int32) (DeleteOrphanInternalKeyRow, error)
+	// The script key is only deleted if nothing references it anymore, in which
+	// case the ID of the internal key it was derived from is returned, along with
+	// whether we know that raw key.
+	DeleteOrphanScriptKey(ctx context.Context, scriptKeyID int32) (DeleteOrphanScriptKeyRow, error)
+	DeleteQuarantinedAsset(ctx context.Context, quarantineID int32) (int64, error)
 	DeleteSpendProofs(ctx context.Context, transferID int32) error
 	FetchAddrByTaprootOutputKey(ctx context.Context, taprootOutputKey []byte) (FetchAddrByTaprootOutputKeyRow, error)
 	FetchAddrEvent(ctx context.Context, id int32) (FetchAddrEventRow, error)
 	FetchAddrs(ctx context.Context, arg FetchAddrsParams) ([]FetchAddrsRow, error)
+	// Each group key is returned along with the sig of the first genesis asset
+	// that was created with it.
+	FetchAllGroupKeys(ctx context.Context) ([]FetchAllGroupKeysRow, error)
+	FetchAnchorStatusCounts(ctx context.Context) (FetchAnchorStatusCountsRow, error)
+	FetchAnchorUtxoAssetCounts(ctx context.Context) ([]FetchAnchorUtxoAssetCountsRow, error)
+	FetchAssetAmounts(ctx context.Context) ([]FetchAssetAmountsRow, error)
+	FetchAssetAmountsByScriptKey(ctx context.Context, arg FetchAssetAmountsByScriptKeyParams) ([]FetchAssetAmountsByScriptKeyRow, error)
+	FetchAssetAnchorInternalKey(ctx context.Context, assetID int32) (FetchAssetAnchorInternalKeyRow, error)
+	FetchAssetAnchorUTXOID(ctx context.Context, assetID int32) (sql.NullInt32, error)
 	FetchAssetDeltas(ctx context.Context, transferID int32) ([]FetchAssetDeltasRow, error)
 	FetchAssetDeltasWithProofs(ctx context.Context, transferID int32) ([]FetchAssetDeltasWithProofsRow, error)
+	// The metadata is decoded as JSON by the database, which is only supported by
+	// Postgres. The path is a dot separated list of object keys and array indices,
+	// and the #>> operator returns values that aren't strings in their JSON text
+	// encoding.
+	FetchAssetIDsByMetadataJSONField(ctx context.Context, arg FetchAssetIDsByMetadataJSONFieldParams) ([][]byte, error)
+	FetchAssetPrevID(ctx context.Context, assetID int32) (FetchAssetPrevIDRow, error)
 	FetchAssetProof(ctx context.Context, tweakedScriptKey []byte) (FetchAssetProofRow, error)
 	FetchAssetProofs(ctx context.Context) ([]FetchAssetProofsRow, error)
 	FetchAssetWitnesses(ctx context.Context, assetID sql.NullInt32) ([]FetchAssetWitnessesRow, error)
@@ -39,18 +72,73 @@ type Querier interface {
 	// doesn't have a group key. See the comment in fetchAssetSprouts for a work
 	// around that needs to be used with this query until a sqlc bug is fixed.
 	FetchAssetsForBatch(ctx context.Context, rawKey []byte) ([]FetchAssetsForBatchRow, error)
+	// Every asset must reference a script key. On backends that don't enforce
+	// foreign keys, a script key may be missing nonetheless, which we detect by
+	// the LEFT JOIN not finding a matching script key.
+	FetchAssetsWithNullScriptKey(ctx context.Context) ([]FetchAssetsWithNullScriptKeyRow, error)
+	// Assets stored before their amounts were checked may have an amount that
+	// exceeded the range of the amount column, which then wrapped around to a
+	// negative value.
+	FetchAssetsWithWrappedAmount(ctx context.Context) ([]FetchAssetsWithWrappedAmountRow, error)
+	FetchAuditLog(ctx context.Context) ([]AuditLog, error)
+	FetchAuditLogTip(ctx context.Context) ([]byte, error)
+	// The columns of an asset that are checked against the audit log. All writes
+	// to these columns are recorded in the audit log, so each asset can be checked
+	// against the latest entry that refers to it.
+	FetchAuditedAssets(ctx context.Context, assetIDFilter sql.NullInt32) ([]FetchAuditedAssetsRow, error)
 	FetchChainTx(ctx context.Context, txid []byte) (ChainTxn, error)
 	FetchChildren(ctx context.Context, arg FetchChildrenParams) ([]FetchChildrenRow, error)
 	FetchChildrenSelfJoin(ctx context.Context, arg FetchChildrenSelfJoinParams) ([]FetchChildrenSelfJoinRow, error)
+	FetchDistinctAssetIDs(ctx context.Context) ([][]byte, error)
+	FetchDuplicateOutputIndices(ctx context.Context, genesisPointID int32) ([]FetchDuplicateOutputIndicesRow, error)
+	// The metadata revealed for a hash is shared by all genesis assets with that
+	// hash, so the genesis fields are returned as well, which allows the caller to
+	// check whether the metadata still derives the asset ID of each of them.
+	FetchGenesesWithSharedMetaHash(ctx context.Context) ([]FetchGenesesWithSharedMetaHashRow, error)
+	FetchGenesisAssetByTag(ctx context.Context, assetTag string) (GenesisAsset, error)
+	FetchGenesisAssetIDByTag(ctx context.Context, assetTag string) (int32, error)
+	// Genesis assets stored before their metadata was moved to the reveals still
+	// carry it inline, and may not have a metadata hash yet either.
+	FetchGenesisAssetsWithInlineMetadata(ctx context.Context) ([]FetchGenesisAssetsWithInlineMetadataRow, error)
+	FetchGenesisAssetsWithoutMetadata(ctx context.Context) ([]FetchGenesisAssetsWithoutMetadataRow, error)
 	FetchGenesisByID(ctx context.Context, genAssetID int32) (FetchGenesisByIDRow, error)
+	// Multiple genesis assets of a genesis point should never share an output
+	// index, but in case they do, we deterministically return the first one.
+	FetchGenesisByOutpoint(ctx context.Context, arg FetchGenesisByOutpointParams) (FetchGenesisByOutpointRow, error)
+	FetchGenesisMetadataByAssetID(ctx context.Context, assetID []byte) ([]byte, error)
 	FetchGenesisPointByAnchorTx(ctx context.Context, anchorTxID sql.NullInt32) (GenesisPoint, error)
+	FetchGenesisPointByAssetID(ctx context.Context, assetID []byte) ([]byte, error)
+	FetchGenesisPointIDByGenAssetID(ctx context.Context, genAssetID int32) (int32, error)
+	FetchGenesisPointIDByPrevOut(ctx context.Context, prevOut []byte) (int32, error)
+	FetchGenesisPointsCreatedBetween(ctx context.Context, arg FetchGenesisPointsCreatedBetweenParams) ([]GenesisPoint, error)
+	// The amounts are summed up by the caller, as the supply of a group can
+	// exceed the range of the amount column, which sqlite fails to SUM.
+	FetchGroupAssetAmounts(ctx context.Context) ([]FetchGroupAssetAmountsRow, error)
+	FetchGroupAssetsScriptKeyKinds(ctx context.Context, tweakedGroupKey []byte) (FetchGroupAssetsScriptKeyKindsRow, error)
+	FetchGroupKeyIDByTweakedKey(ctx context.Context, tweakedGroupKey []byte) (int32, error)
+	FetchGroupRawKey(ctx context.Context, tweakedGroupKey []byte) (FetchGroupRawKeyRow, error)
+	FetchGroupReissuances(ctx context.Context, tweakedGroupKey []byte) ([]FetchGroupReissuancesRow, error)
+	FetchGroupSigIDByGenesisID(ctx context.Context, genAssetID int32) (int32, error)
+	FetchGroupSizes(ctx context.Context) ([]FetchGroupSizesRow, error)
+	FetchImportCheckpoint(ctx context.Context, batchID string) (FetchImportCheckpointRow, error)
+	FetchInternalKeyByID(ctx context.Context, keyID int32) (FetchInternalKeyByIDRow, error)
+	FetchInternalKeyByRawKey(ctx context.Context, rawKey []byte) (FetchInternalKeyByRawKeyRow, error)
+	FetchInternalKeyIDByRawKey(ctx context.Context, rawKey []byte) (int32, error)
 	FetchManagedUTXO(ctx context.Context, arg FetchManagedUTXOParams) (FetchManagedUTXORow, error)
 	FetchManagedUTXOs(ctx context.Context) ([]FetchManagedUTXOsRow, error)
 	FetchMintingBatchesByInverseState(ctx context.Context, batchState int16) ([]FetchMintingBatchesByInverseStateRow, error)
+	FetchQuarantinedAssets(ctx context.Context) ([]QuarantinedAsset, error)
 	FetchRootNode(ctx context.Context, namespace string) (MssmtNode, error)
+	FetchScriptKeyByTweakedKey(ctx context.Context, tweakedScriptKey []byte) (FetchScriptKeyByTweakedKeyRow, error)
 	FetchScriptKeyIDByTweakedKey(ctx context.Context, tweakedScriptKey []byte) (int32, error)
 	FetchSeedlingsForBatch(ctx context.Context, rawKey []byte) ([]AssetSeedling, error)
+	FetchSharedScriptInternalKeys(ctx context.Context, minNumAssets int64) ([]FetchSharedScriptInternalKeysRow, error)
 	FetchSpendProofs(ctx context.Context, transferID int32) (FetchSpendProofsRow, error)
+	// An asset is considered stale if it was never anchored in an on-chain output,
+	// even though its genesis point was first stored before the given time.
+	// Genesis points stored before their creation time was tracked are never
+	// considered stale.
+	FetchStaleUnanchoredAssets(ctx context.Context, olderThan sql.NullTime) ([]FetchStaleUnanchoredAssetsRow, error)
 	GenesisAssets(ctx context.Context) ([]GenesisAsset, error)
 	GenesisPoints(ctx context.Context) ([]GenesisPoint, error)
 	GetRootKey(ctx context.Context, id []byte) (Macaroon, error)
@@ -60,12 +148,20 @@ type Querier interface {
 	InsertAssetSeedlingIntoBatch(ctx context.Context, arg InsertAssetSeedlingIntoBatchParams) error
 	InsertAssetTransfer(ctx context.Context, arg InsertAssetTransferParams) (int32, error)
 	InsertAssetWitness(ctx context.Context, arg InsertAssetWitnessParams) error
+	InsertAuditLogEntry(ctx context.Context, arg InsertAuditLogEntryParams) (int32, error)
 	InsertBranch(ctx context.Context, arg InsertBranchParams) error
 	InsertCompactedLeaf(ctx context.Context, arg InsertCompactedLeafParams) error
+	// An index that's freed again after it was reserved and reused can be handed
+	// out once more.
+	InsertFreedKeyIndex(ctx context.Context, arg InsertFreedKeyIndexParams) error
 	InsertLeaf(ctx context.Context, arg InsertLeafParams) error
 	InsertNewAsset(ctx context.Context, arg InsertNewAssetParams) (int32, error)
+	InsertQuarantinedAsset(ctx context.Context, arg InsertQuarantinedAssetParams) (int32, error)
 	InsertRootKey(ctx context.Context, arg InsertRootKeyParams) error
 	InsertSpendProofs(ctx context.Context, arg InsertSpendProofsParams) (int32, error)
+	// Once the metadata of a genesis asset is stored as a reveal, the genesis asset
+	// only references it by its hash.
+	MoveGenesisMetadata(ctx context.Context, arg MoveGenesisMetadataParams) error
 	NewMintingBatch(ctx context.Context, arg NewMintingBatchParams) error
 	// We use a LEFT JOIN here as not every asset has a group key, so this'll
 	// generate rows that have NULL values for the group key fields if an asset
@@ -78,14 +174,36 @@ type Querier interface {
 	// generate rows that have NULL values for the group key fields if an asset
 	// doesn't have a group key. See the comment in fetchAssetSprouts for a work
 	// around that needs to be used with this query until a sqlc bug is fixed.
+	// We use a LEFT JOIN for all the anchor information, so assets that aren't
+	// anchored yet can be returned as well.
 	// This clause is used to select specific assets for a asset ID, general
 	// channel balances, and also coin selection. We use the sqlc.narg feature to
 	// make the entire statement evaluate to true, if none of these extra args are
-	// specified.
+	// specified. Unless asked for, spent assets and assets that aren't anchored
+	// yet are left out.
+	// If requested, the assets are ordered by their amount. Amounts exceeding
+	// the range of the amount column are stored as the max value of the column,
+	// so those are ordered by their exact decimal amount_big, first by its length
+	// and then lexicographically. The primary key is used as a tie breaker to
+	// keep the order stable, which also makes it possible to page through the
+	// assets by their primary key.
+	// The limit defaults to the largest value both sqlite and postgres accept,
+	// which is the same as no limit at all.
 	QueryAssets(ctx context.Context, arg QueryAssetsParams) ([]QueryAssetsRow, error)
 	QueryEventIDs(ctx context.Context, arg QueryEventIDsParams) ([]QueryEventIDsRow, error)
-	ReanchorAssets(ctx context.Context, arg ReanchorAssetsParams) error
+	ReanchorAssets(ctx context.Context, arg ReanchorAssetsParams) ([]int32, error)
+	// The lowest unreserved index in the family is reserved, so gaps are filled
+	// from the bottom up. Indexes whose key was stored again in the
+	// meantime are skipped, as they're in use once more.
+	ReserveFreedKeyIndex(ctx context.Context, keyFamily int32) (int32, error)
 	SetAddrManaged(ctx context.Context, arg SetAddrManagedParams) error
+	SetAssetBigAmount(ctx context.Context, arg SetAssetBigAmountParams) error
+	SetAssetSpent(ctx context.Context, assetID int32) (int64, error)
+	SetGenesisAssetMetaType(ctx context.Context, arg SetGenesisAssetMetaTypeParams) (int64, error)
+	// A genesis asset is a reissuance if another genesis asset was issued under
+	// the same group key before it, which we can tell by the order the group sigs
+	// were inserted in.
+	SetGenesisReissuance(ctx context.Context, arg SetGenesisReissuanceParams) error
 	UpdateBatchGenesisTx(ctx context.Context, arg UpdateBatchGenesisTxParams) error
 	UpdateMintingBatchState(ctx context.Context, arg UpdateMintingBatchStateParams) error
 	UpsertAddrEvent(ctx context.Context, arg UpsertAddrEventParams) (int32, error)
@@ -94,7 +212,9 @@ type Querier interface {
 	UpsertAssetProof(ctx context.Context, arg UpsertAssetProofParams) error
 	UpsertChainTx(ctx context.Context, arg UpsertChainTxParams) (int32, error)
 	UpsertGenesisAsset(ctx context.Context, arg UpsertGenesisAssetParams) (int32, error)
-	UpsertGenesisPoint(ctx context.Context, prevOut []byte) (int32, error)
+	UpsertGenesisMetaReveal(ctx context.Context, arg UpsertGenesisMetaRevealParams) error
+	UpsertGenesisPoint(ctx context.Context, arg UpsertGenesisPointParams) (int32, error)
+	UpsertImportCheckpoint(ctx context.Context, arg UpsertImportCheckpointParams) error
 	UpsertInternalKey(ctx context.Context, arg UpsertInternalKeyParams) (int32, error)
 	UpsertManagedUTXO(ctx context.Context, arg UpsertManagedUTXOParams) (int32, error)
 	UpsertRootNode(ctx context.Context, arg UpsertRootNodeParams) error
@@ -87,9 +87,9 @@ WHERE asset_seedlings.batch_id in (SELECT batch_id FROM target_batch);
 
 -- name: UpsertGenesisPoint :one
 INSERT INTO genesis_points(
-    prev_out
+    prev_out, created_at
 ) VALUES (
-    $1
+    $1, $2
 ) ON CONFLICT (prev_out)
     -- This is a NOP, prev_out is the unique field that caused the conflict.
     DO UPDATE SET prev_out = EXCLUDED.prev_out
@@ -117,20 +117,50 @@ RETURNING sig_id;
 
 -- name: UpsertGenesisAsset :one
 INSERT INTO genesis_assets (
-    asset_id, asset_tag, meta_data, output_index, asset_type, genesis_point_id
+    asset_id, asset_tag, output_index, asset_type, genesis_point_id, meta_type,
+    meta_hash
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    @asset_id, @asset_tag, @output_index, @asset_type, @genesis_point_id,
+    @meta_type, @meta_hash
 ) ON CONFLICT (asset_tag)
-    -- This is a NOP, asset_tag is the unique field that caused the conflict.
-    DO UPDATE SET asset_tag = EXCLUDED.asset_tag
+    -- As the asset ID commits to the metadata and stored assets reference the
+    -- genesis asset, the asset ID, metadata and metadata hash of an existing
+    -- genesis asset are never changed. If the metadata differs, the metadata
+    -- policy decides what happens: 0 keeps the existing metadata, while 1
+    -- (replace) and 2 (prefer longer, if the new metadata is longer) reject
+    -- the upsert, in which case no row is returned. The metadata itself is
+    -- only stored in genesis_meta_reveals, so the lengths are compared there.
+    -- The metadata type of the very same metadata can be set, unless it's
+    -- upserted as opaque bytes.
+    DO UPDATE SET
+        meta_type = CASE
+            WHEN @metadata_policy != 0 AND EXCLUDED.meta_type != 0 AND
+                EXCLUDED.asset_id = genesis_assets.asset_id
+            THEN EXCLUDED.meta_type
+            ELSE genesis_assets.meta_type
+        END
+    WHERE @metadata_policy = 0 OR
+        EXCLUDED.asset_id = genesis_assets.asset_id OR (
+            @metadata_policy = 2 AND
+                COALESCE((
+                    SELECT length(meta_data)
+                    FROM genesis_meta_reveals
+                    WHERE meta_hash = EXCLUDED.meta_hash
+                ), 0) <= COALESCE((
+                    SELECT length(meta_data)
+                    FROM genesis_meta_reveals
+                    WHERE meta_hash = genesis_assets.meta_hash
+                ), 0)
+        )
 RETURNING gen_asset_id;
 
 -- name: InsertNewAsset :one
 INSERT INTO assets (
     genesis_id, version, script_key_id, asset_group_sig_id, script_version, 
-    amount, lock_time, relative_lock_time, anchor_utxo_id
+    amount, lock_time, relative_lock_time, anchor_utxo_id,
+    split_commitment_root_hash, split_commitment_root_value
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING asset_id;
 
 -- name: FetchAssetsForBatch :many
@@ -142,11 +172,13 @@ WITH genesis_info AS (
     -- points, to the internal key that reference the batch, then restricted
     -- for internal keys that match our main batch key.
     SELECT
-        gen_asset_id, asset_id, asset_tag, meta_data, output_index, asset_type,
-        genesis_points.prev_out prev_out
+        gen_asset_id, asset_id, asset_tag, reveals.meta_data, output_index,
+        asset_type, genesis_points.prev_out prev_out
     FROM genesis_assets
     JOIN genesis_points
         ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+    LEFT JOIN genesis_meta_reveals reveals
+        ON genesis_assets.meta_hash = reveals.meta_hash
     JOIN asset_minting_batches batches
         ON genesis_points.genesis_id = batches.genesis_id
     JOIN internal_keys keys
@@ -208,6 +240,7 @@ JOIN genesis_info_view
 -- around that needs to be used with this query until a sqlc bug is fixed.
 LEFT JOIN key_group_info_view
     ON assets.genesis_id = key_group_info_view.gen_asset_id
+WHERE assets.spent = false
 GROUP BY assets.genesis_id, genesis_info_view.asset_id,
          version, genesis_info_view.asset_tag, genesis_info_view.meta_data,
          genesis_info_view.asset_type, genesis_info_view.output_index,
@@ -221,12 +254,29 @@ JOIN key_group_info_view
     ON assets.genesis_id = key_group_info_view.gen_asset_id AND
       (key_group_info_view.tweaked_group_key = sqlc.narg('key_group_filter') OR
         sqlc.narg('key_group_filter') IS NULL)
+WHERE assets.spent = false
 GROUP BY key_group_info_view.tweaked_group_key;
 
+-- name: FetchGroupAssetsScriptKeyKinds :one
+SELECT
+    COUNT(CASE WHEN length(script_keys.tweak) > 0 THEN 1 END) AS with_tweak,
+    COUNT(
+        CASE WHEN script_keys.tweak IS NULL OR length(script_keys.tweak) = 0
+        THEN 1 END
+    ) AS without_tweak
+FROM assets
+JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+JOIN key_group_info_view
+    ON assets.genesis_id = key_group_info_view.gen_asset_id
+WHERE key_group_info_view.tweaked_group_key = @tweaked_group_key
+    AND assets.spent = false;
+
 -- name: QueryAssets :many
 SELECT
     assets.asset_id AS asset_primary_key, assets.genesis_id, version,
     script_keys.tweak AS script_key_tweak, 
+    script_keys.tweak IS NULL AS script_key_tweak_is_null,
     script_keys.tweaked_script_key, 
     internal_keys.raw_key AS script_key_raw,
     internal_keys.key_family AS script_key_fam,
@@ -246,12 +296,10 @@ SELECT
     txns.raw_tx AS anchor_tx, txns.txid AS anchor_txid, txns.block_hash AS anchor_block_hash,
     utxos.outpoint AS anchor_outpoint,
     utxo_internal_keys.raw_key AS anchor_internal_key,
-    split_commitment_root_hash, split_commitment_root_value
+    split_commitment_root_hash, split_commitment_root_value, assets.amount_big
 FROM assets
 JOIN genesis_info_view
-    ON assets.genesis_id = genesis_info_view.gen_asset_id AND
-      (genesis_info_view.asset_id = sqlc.narg('asset_id_filter') OR
-        sqlc.narg('asset_id_filter') IS NULL)
+    ON assets.genesis_id = genesis_info_view.gen_asset_id
 -- We use a LEFT JOIN here as not every asset has a group key, so this'll
 -- generate rows that have NULL values for the group key fields if an asset
 -- doesn't have a group key. See the comment in fetchAssetSprouts for a work
@@ -262,23 +310,145 @@ JOIN script_keys
     on assets.script_key_id = script_keys.script_key_id
 JOIN internal_keys
     ON script_keys.internal_key_id = internal_keys.key_id
-JOIN managed_utxos utxos
-    ON assets.anchor_utxo_id = utxos.utxo_id AND
-      (utxos.outpoint = sqlc.narg('anchor_point') OR
-       sqlc.narg('anchor_point') IS NULL)
-JOIN internal_keys utxo_internal_keys
+-- We use a LEFT JOIN for all the anchor information, so assets that aren't
+-- anchored yet can be returned as well.
+LEFT JOIN managed_utxos utxos
+    ON assets.anchor_utxo_id = utxos.utxo_id
+LEFT JOIN internal_keys utxo_internal_keys
     ON utxos.internal_key_id = utxo_internal_keys.key_id
-JOIN chain_txns txns
+LEFT JOIN chain_txns txns
     ON utxos.txn_id = txns.txn_id
 -- This clause is used to select specific assets for a asset ID, general
 -- channel balances, and also coin selection. We use the sqlc.narg feature to
 -- make the entire statement evaluate to true, if none of these extra args are
--- specified.
+-- specified. Unless asked for, spent assets and assets that aren't anchored
+-- yet are left out.
 WHERE (
+    (genesis_info_view.asset_id = sqlc.narg('asset_id_filter') OR
+      sqlc.narg('asset_id_filter') IS NULL) AND
+    (utxos.outpoint = sqlc.narg('anchor_point') OR
+      sqlc.narg('anchor_point') IS NULL) AND
     assets.amount >= COALESCE(sqlc.narg('min_amt'), assets.amount) AND
     (key_group_info_view.tweaked_group_key = sqlc.narg('key_group_filter') OR
-      sqlc.narg('key_group_filter') IS NULL)
-);
+      sqlc.narg('key_group_filter') IS NULL) AND
+    (genesis_info_view.asset_type = sqlc.narg('asset_type_filter') OR
+      sqlc.narg('asset_type_filter') IS NULL) AND
+    (script_keys.is_known_raw = sqlc.narg('script_key_known') OR
+      sqlc.narg('script_key_known') IS NULL) AND
+    ((assets.lock_time IS NOT NULL) = sqlc.narg('has_lock_time') OR
+      sqlc.narg('has_lock_time') IS NULL) AND
+    (assets.spent = false OR sqlc.narg('include_spent') = true) AND
+    (utxos.utxo_id IS NOT NULL OR sqlc.narg('include_unanchored') = true) AND
+    -- Assets with an amount_big exceed the range of the amount column, and
+    -- therefore any maximum amount.
+    ((assets.amount <= sqlc.narg('max_amt') AND assets.amount_big IS NULL) OR
+      sqlc.narg('max_amt') IS NULL) AND
+    -- An asset that isn't anchored yet is considered to be unconfirmed.
+    ((txns.block_hash IS NOT NULL) = sqlc.narg('confirmed') OR
+      sqlc.narg('confirmed') IS NULL) AND
+    (assets.asset_id = sqlc.narg('asset_primary_key') OR
+      sqlc.narg('asset_primary_key') IS NULL) AND
+    (assets.asset_id > sqlc.narg('after_asset_id') OR
+      sqlc.narg('after_asset_id') IS NULL) AND
+    (assets.script_version = sqlc.narg('script_version_filter') OR
+      sqlc.narg('script_version_filter') IS NULL) AND
+    (script_keys.tweaked_script_key = sqlc.narg('script_key_filter') OR
+      sqlc.narg('script_key_filter') IS NULL) AND
+    (script_keys.tweak = sqlc.narg('script_key_tweak_filter') OR
+      sqlc.narg('script_key_tweak_filter') IS NULL) AND
+    (utxo_internal_keys.key_family = sqlc.narg('anchor_key_family') OR
+      sqlc.narg('anchor_key_family') IS NULL) AND
+    (genesis_info_view.asset_tag = sqlc.narg('asset_tag_filter') OR
+      sqlc.narg('asset_tag_filter') IS NULL) AND
+    -- The lower case comparison is done on genesis_assets directly rather
+    -- than through the view, so it can make use of the
+    -- genesis_asset_lower_tags index.
+    (assets.genesis_id IN (
+        SELECT gen_asset_id
+        FROM genesis_assets
+        WHERE lower(asset_tag) = lower(sqlc.narg('asset_tag_lower_filter'))
+    ) OR sqlc.narg('asset_tag_lower_filter') IS NULL) AND
+    (assets.genesis_id IN (
+        SELECT gen_asset_id
+        FROM genesis_assets
+        WHERE meta_type = sqlc.narg('meta_type_filter')
+    ) OR sqlc.narg('meta_type_filter') IS NULL) AND
+    (length(genesis_info_view.meta_data) >= sqlc.narg('min_meta_length') OR
+      sqlc.narg('min_meta_length') IS NULL) AND
+    (length(genesis_info_view.meta_data) <= sqlc.narg('max_meta_length') OR
+      sqlc.narg('max_meta_length') IS NULL) AND
+    -- An asset of a genesis that is part of an asset group should always
+    -- reference a group sig itself.
+    ((key_group_info_view.gen_asset_id IS NOT NULL AND NOT EXISTS (
+        SELECT 1
+        FROM asset_group_sigs sigs
+        WHERE sigs.sig_id = assets.asset_group_sig_id
+    )) = sqlc.narg('grouped_without_sig') OR
+      sqlc.narg('grouped_without_sig') IS NULL) AND
+    -- Assets stored without the group sig of their genesis asset aren't
+    -- linked to their group yet, so we can only find them through the
+    -- genesis point the group key was stored with.
+    ((assets.genesis_id IN (
+        SELECT gen_assets.gen_asset_id
+        FROM genesis_assets gen_assets
+        JOIN asset_groups groups
+            ON gen_assets.genesis_point_id = groups.genesis_point_id
+        WHERE groups.tweaked_group_key = sqlc.narg('missing_sig_group_key')
+    ) AND NOT EXISTS (
+        SELECT 1
+        FROM asset_group_sigs sigs
+        WHERE sigs.gen_asset_id = assets.genesis_id
+    )) OR sqlc.narg('missing_sig_group_key') IS NULL) AND
+    -- Assets can be paged through in their canonical order, by their asset
+    -- ID, then their script key and then their primary key, by passing
+    -- those of the last asset of the previous page. The same asset can be
+    -- sent to the same script key more than once, so the primary key is
+    -- needed to tell those assets apart.
+    (genesis_info_view.asset_id > sqlc.narg('after_sort_asset_id') OR
+      (genesis_info_view.asset_id = sqlc.narg('after_sort_asset_id') AND
+        (script_keys.tweaked_script_key >
+          sqlc.narg('after_sort_script_key') OR
+          (script_keys.tweaked_script_key =
+            sqlc.narg('after_sort_script_key') AND
+            assets.asset_id > sqlc.narg('after_sort_primary_key')))) OR
+      sqlc.narg('after_sort_asset_id') IS NULL)
+)
+-- If requested, the assets are ordered by their amount. Amounts exceeding
+-- the range of the amount column are stored as the max value of the column,
+-- so those are ordered by their exact decimal amount_big, first by its length
+-- and then lexicographically. If requested, the assets are ordered
+-- canonically instead, by their asset ID, their script key and then their
+-- primary key. The primary key is always used as the last tie breaker to keep
+-- the order stable, which also makes it possible to page through the assets by
+-- their primary key.
+ORDER BY
+    CASE WHEN sqlc.narg('amount_descending') = true THEN assets.amount
+    END DESC,
+    CASE WHEN sqlc.narg('amount_descending') = true
+        THEN COALESCE(LENGTH(assets.amount_big), 0)
+    END DESC,
+    CASE WHEN sqlc.narg('amount_descending') = true
+        THEN COALESCE(assets.amount_big, '')
+    END DESC,
+    CASE WHEN sqlc.narg('amount_descending') = false THEN assets.amount
+    END,
+    CASE WHEN sqlc.narg('amount_descending') = false
+        THEN COALESCE(LENGTH(assets.amount_big), 0)
+    END,
+    CASE WHEN sqlc.narg('amount_descending') = false
+        THEN COALESCE(assets.amount_big, '')
+    END,
+    CASE WHEN sqlc.narg('canonical_order') = true
+        THEN genesis_info_view.asset_id
+    END,
+    CASE WHEN sqlc.narg('canonical_order') = true
+        THEN script_keys.tweaked_script_key
+    END,
+    assets.asset_id
+-- The limit defaults to the largest value both sqlite and postgres accept,
+-- which is the same as no limit at all.
+LIMIT COALESCE(sqlc.narg('num_limit'), 9223372036854775807)
+OFFSET COALESCE(sqlc.narg('num_offset'), 0);
 
 -- name: AllAssets :many
 SELECT * 
@@ -286,11 +456,13 @@ FROM assets;
 
 -- name: AssetsInBatch :many
 SELECT
-    gen_asset_id, asset_id, asset_tag, meta_data, output_index, asset_type,
-    genesis_points.prev_out prev_out
+    gen_asset_id, asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, genesis_points.prev_out prev_out
 FROM genesis_assets
 JOIN genesis_points
     ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
 JOIN asset_minting_batches batches
     ON genesis_points.genesis_id = batches.genesis_id
 JOIN internal_keys keys
@@ -372,7 +544,7 @@ FROM managed_utxos utxos
 JOIN internal_keys keys
     ON utxos.internal_key_id = keys.key_id;
 
--- name: AnchorPendingAssets :exec
+-- name: AnchorPendingAssets :many
 WITH assets_to_update AS (
     SELECT script_key_id
     FROM assets 
@@ -384,7 +556,19 @@ WITH assets_to_update AS (
 )
 UPDATE assets
 SET anchor_utxo_id = $2
-WHERE script_key_id in (SELECT script_key_id FROM assets_to_update);
+WHERE script_key_id in (SELECT script_key_id FROM assets_to_update)
+RETURNING asset_id;
+
+-- name: AnchorAssetsByOutputIndex :many
+UPDATE assets
+SET anchor_utxo_id = @anchor_utxo_id
+WHERE genesis_id IN (
+    SELECT gen_asset_id
+    FROM genesis_assets
+    WHERE genesis_point_id = @genesis_point_id
+        AND output_index = @output_index
+)
+RETURNING asset_id;
 
 -- name: AssetsByGenesisPoint :many
 SELECT *
@@ -403,6 +587,13 @@ FROM genesis_assets;
 SELECT * 
 FROM genesis_points;
 
+-- name: FetchGenesisPointsCreatedBetween :many
+SELECT *
+FROM genesis_points
+WHERE created_at >= @created_after
+    AND created_at <= @created_before
+ORDER BY created_at;
+
 -- name: FetchAssetsByAnchorTx :many
 SELECT *
 FROM assets
@@ -425,13 +616,33 @@ WHERE anchor_tx_id = $1;
 
 -- name: FetchGenesisByID :one
 SELECT
-    asset_id, asset_tag, meta_data, output_index, asset_type,
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
     genesis_points.prev_out prev_out
 FROM genesis_assets
 JOIN genesis_points
   ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
 WHERE gen_asset_id = $1;
 
+-- name: FetchGenesisByOutpoint :one
+-- Multiple genesis assets of a genesis point should never share an output
+-- index, but in case they do, we deterministically return the first one.
+SELECT
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+  ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE genesis_points.prev_out = @prev_out
+    AND genesis_assets.output_index = @output_index
+ORDER BY gen_asset_id
+LIMIT 1;
+
 -- name: ConfirmChainTx :exec
 WITH target_txn(txn_id) AS (
     SELECT anchor_tx_id
@@ -514,6 +725,99 @@ WHERE (
 DELETE FROM managed_utxos
 WHERE outpoint = $1;
 
+-- name: InsertFreedKeyIndex :exec
+-- An index that's freed again after it was reserved and reused can be handed
+-- out once more.
+INSERT INTO freed_key_indexes (key_family, key_index)
+VALUES ($1, $2)
+ON CONFLICT (key_family, key_index)
+    DO UPDATE SET reserved = FALSE;
+
+-- name: ReserveFreedKeyIndex :one
+-- The lowest unreserved index in the family is reserved, so gaps are filled
+-- from the bottom up. Indexes whose key was stored again in the
+-- meantime are skipped, as they're in use once more.
+UPDATE freed_key_indexes
+SET reserved = TRUE
+WHERE reserved = FALSE AND id = (
+    SELECT freed.id
+    FROM freed_key_indexes freed
+    WHERE freed.key_family = $1 AND freed.reserved = FALSE AND
+        NOT EXISTS (
+            SELECT 1 FROM internal_keys keys
+            WHERE keys.key_family = freed.key_family AND
+                keys.key_index = freed.key_index
+        )
+    ORDER BY freed.key_index
+    LIMIT 1
+)
+RETURNING key_index;
+
+-- name: DeleteAssetProofsByScriptKeyID :exec
+DELETE FROM asset_proofs
+WHERE asset_id IN (
+    SELECT asset_id
+    FROM assets
+    WHERE script_key_id = $1
+);
+
+-- name: DeleteAssetsByScriptKeyID :many
+-- The witnesses of the assets are deleted along with them, as they cascade.
+DELETE FROM assets
+WHERE script_key_id = $1
+RETURNING asset_id;
+
+-- name: DeleteOrphanScriptKey :one
+-- The script key is only deleted if nothing references it anymore, in which
+-- case the ID of the internal key it was derived from is returned, along with
+-- whether we know that raw key.
+DELETE FROM script_keys
+WHERE script_key_id = $1 AND
+    NOT EXISTS (
+        SELECT 1 FROM assets
+        WHERE assets.script_key_id = script_keys.script_key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM addrs
+        WHERE addrs.script_key_id = script_keys.script_key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM asset_deltas
+        WHERE asset_deltas.new_script_key = script_keys.script_key_id
+    )
+RETURNING internal_key_id, is_known_raw;
+
+-- name: DeleteOrphanInternalKey :one
+-- The internal key is only deleted if nothing references it anymore, such as
+-- another script key or a group key, in which case its locator is returned.
+DELETE FROM internal_keys
+WHERE key_id = $1 AND
+    NOT EXISTS (
+        SELECT 1 FROM script_keys
+        WHERE script_keys.internal_key_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM managed_utxos
+        WHERE managed_utxos.internal_key_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM asset_groups
+        WHERE asset_groups.internal_key_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM asset_minting_batches
+        WHERE asset_minting_batches.batch_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM addrs
+        WHERE addrs.taproot_key_id = internal_keys.key_id
+    ) AND
+    NOT EXISTS (
+        SELECT 1 FROM asset_transfers
+        WHERE asset_transfers.new_internal_key = internal_keys.key_id
+    )
+RETURNING key_family, key_index;
+
 -- name: ConfirmChainAnchorTx :exec
 WITH target_txn(txn_id) AS (
     SELECT chain_txns.txn_id
@@ -528,16 +832,463 @@ WHERE txn_id in (SELECT txn_id FROM target_txn);
 
 -- name: UpsertScriptKey :one
 INSERT INTO script_keys (
-    internal_key_id, tweaked_script_key, tweak
+    internal_key_id, tweaked_script_key, tweak, is_known_raw, key_type
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 )  ON CONFLICT (tweaked_script_key)
-    -- As a NOP, we just set the script key to the one that triggered the
-    -- conflict.
-    DO UPDATE SET tweaked_script_key = EXCLUDED.tweaked_script_key
+    -- A script key that was imported without knowing its raw key is
+    -- upgraded once the raw key becomes known. A known script key is never
+    -- downgraded, so otherwise this is a NOP.
+    DO UPDATE SET
+        internal_key_id = CASE WHEN EXCLUDED.is_known_raw
+            THEN EXCLUDED.internal_key_id
+            ELSE script_keys.internal_key_id
+        END,
+        tweak = CASE WHEN EXCLUDED.is_known_raw
+            THEN EXCLUDED.tweak
+            ELSE script_keys.tweak
+        END,
+        key_type = CASE WHEN EXCLUDED.is_known_raw
+            THEN EXCLUDED.key_type
+            ELSE script_keys.key_type
+        END,
+        is_known_raw = script_keys.is_known_raw OR EXCLUDED.is_known_raw
 RETURNING script_key_id;
 
 -- name: FetchScriptKeyIDByTweakedKey :one
 SELECT script_key_id
 FROM script_keys
 WHERE tweaked_script_key = $1;
+
+-- name: FetchScriptKeyByTweakedKey :one
+SELECT
+    script_keys.tweak, script_keys.key_type,
+    internal_keys.raw_key, internal_keys.key_family, internal_keys.key_index
+FROM script_keys
+JOIN internal_keys
+    ON script_keys.internal_key_id = internal_keys.key_id
+WHERE script_keys.tweaked_script_key = $1;
+
+-- name: FetchGenesisPointIDByPrevOut :one
+SELECT genesis_id
+FROM genesis_points
+WHERE prev_out = $1;
+
+-- name: FetchGenesisAssetIDByTag :one
+SELECT gen_asset_id
+FROM genesis_assets
+WHERE asset_tag = $1;
+
+-- name: FetchInternalKeyIDByRawKey :one
+SELECT key_id
+FROM internal_keys
+WHERE raw_key = $1;
+
+-- name: FetchInternalKeyByID :one
+SELECT raw_key, key_family, key_index
+FROM internal_keys
+WHERE key_id = $1;
+
+-- name: FetchInternalKeyByRawKey :one
+SELECT raw_key, key_family, key_index
+FROM internal_keys
+WHERE raw_key = $1;
+
+-- name: FetchGroupKeyIDByTweakedKey :one
+SELECT group_id
+FROM asset_groups
+WHERE tweaked_group_key = $1;
+
+-- name: FetchGenesisAssetByTag :one
+SELECT *
+FROM genesis_assets
+WHERE asset_tag = $1;
+
+-- name: FetchGroupSigIDByGenesisID :one
+SELECT sig_id
+FROM asset_group_sigs
+WHERE gen_asset_id = $1;
+
+-- name: FetchGenesisAssetsWithoutMetadata :many
+SELECT
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+  ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE reveals.meta_data IS NULL OR length(reveals.meta_data) = 0
+ORDER BY gen_asset_id;
+
+-- name: FetchAnchorUtxoAssetCounts :many
+SELECT anchor_utxo_id, COUNT(*) AS num_assets
+FROM assets
+WHERE anchor_utxo_id IS NOT NULL AND spent = false
+GROUP BY anchor_utxo_id;
+
+-- name: FetchAnchorStatusCounts :one
+SELECT
+    COUNT(CASE WHEN anchor_utxo_id IS NOT NULL THEN 1 END) AS anchored,
+    COUNT(CASE WHEN anchor_utxo_id IS NULL THEN 1 END) AS unanchored
+FROM assets
+WHERE spent = false;
+
+-- name: CountAssetsByGenesisPoint :one
+SELECT COUNT(*)
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+WHERE genesis_assets.genesis_point_id = $1;
+
+-- name: FetchGenesisPointIDByGenAssetID :one
+SELECT genesis_point_id
+FROM genesis_assets
+WHERE gen_asset_id = $1;
+
+-- name: FetchAssetAmounts :many
+SELECT amount, amount_big
+FROM assets
+WHERE spent = false;
+
+-- name: InsertQuarantinedAsset :one
+INSERT INTO quarantined_assets (
+    raw_asset, failure_reason, quarantined_at
+) VALUES (
+    $1, $2, $3
+)
+RETURNING quarantine_id;
+
+-- name: FetchQuarantinedAssets :many
+SELECT *
+FROM quarantined_assets
+ORDER BY quarantine_id;
+
+-- name: DeleteQuarantinedAsset :execrows
+DELETE FROM quarantined_assets
+WHERE quarantine_id = $1;
+
+-- name: FetchSharedScriptInternalKeys :many
+SELECT internal_keys.raw_key, COUNT(*) AS num_assets
+FROM assets
+JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+JOIN internal_keys
+    ON script_keys.internal_key_id = internal_keys.key_id
+GROUP BY internal_keys.key_id, internal_keys.raw_key
+HAVING COUNT(*) >= @min_num_assets
+ORDER BY internal_keys.key_id;
+
+-- name: SetAssetBigAmount :exec
+UPDATE assets
+SET amount = @amount, amount_big = @amount_big
+WHERE asset_id = @asset_id;
+
+-- name: FetchAssetsWithWrappedAmount :many
+-- Assets stored before their amounts were checked may have an amount that
+-- exceeded the range of the amount column, which then wrapped around to a
+-- negative value.
+SELECT asset_id, amount
+FROM assets
+WHERE amount < 0 AND amount_big IS NULL;
+
+-- name: FetchAssetAmountsByScriptKey :many
+SELECT assets.amount, assets.amount_big
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+WHERE genesis_assets.asset_id = @asset_id
+    AND script_keys.tweaked_script_key = @tweaked_script_key
+    AND assets.spent = false;
+
+-- name: FetchDistinctAssetIDs :many
+SELECT DISTINCT genesis_assets.asset_id
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+WHERE assets.spent = false
+ORDER BY genesis_assets.asset_id;
+
+-- name: BindAssetAnchor :execrows
+-- An asset is only bound to an anchor UTXO if it isn't anchored yet, or is
+-- already bound to that very UTXO, so an existing anchor is never replaced.
+UPDATE assets
+SET anchor_utxo_id = @anchor_utxo_id
+WHERE asset_id = @asset_id
+    AND (anchor_utxo_id IS NULL OR anchor_utxo_id = @anchor_utxo_id);
+
+-- name: FetchAssetAnchorUTXOID :one
+SELECT anchor_utxo_id
+FROM assets
+WHERE asset_id = $1;
+
+-- name: SetAssetSpent :execrows
+UPDATE assets
+SET spent = true
+WHERE asset_id = @asset_id AND spent = false;
+
+-- name: FetchAssetPrevID :one
+SELECT
+    utxos.outpoint AS anchor_outpoint, genesis_assets.asset_id,
+    script_keys.tweaked_script_key
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+JOIN managed_utxos utxos
+    ON assets.anchor_utxo_id = utxos.utxo_id
+WHERE assets.asset_id = $1;
+
+-- name: FetchGroupAssetAmounts :many
+-- The amounts are summed up by the caller, as the supply of a group can
+-- exceed the range of the amount column, which sqlite fails to SUM.
+SELECT
+    key_group_info_view.tweaked_group_key, assets.amount, assets.amount_big
+FROM assets
+JOIN key_group_info_view
+    ON assets.genesis_id = key_group_info_view.gen_asset_id
+WHERE assets.spent = false
+ORDER BY key_group_info_view.tweaked_group_key;
+
+-- name: FetchAssetAnchorInternalKey :one
+SELECT internal_keys.raw_key, internal_keys.key_family, internal_keys.key_index
+FROM assets
+JOIN managed_utxos utxos
+    ON assets.anchor_utxo_id = utxos.utxo_id
+JOIN internal_keys
+    ON utxos.internal_key_id = internal_keys.key_id
+WHERE assets.asset_id = $1;
+
+-- name: SetGenesisAssetMetaType :execrows
+UPDATE genesis_assets
+SET meta_type = @meta_type
+WHERE asset_id = @asset_id;
+
+-- name: FetchGenesisMetadataByAssetID :one
+SELECT reveals.meta_data
+FROM genesis_assets gen
+LEFT JOIN genesis_meta_reveals reveals
+    ON reveals.meta_hash = gen.meta_hash
+WHERE gen.asset_id = $1;
+
+-- name: FetchAssetIDsByMetadataJSONField :many
+-- The metadata is decoded as JSON by the database, which is only supported by
+-- Postgres. The path is a dot separated list of object keys and array indices,
+-- and the #>> operator returns values that aren't strings in their JSON text
+-- encoding.
+SELECT DISTINCT gen.asset_id
+FROM genesis_assets gen
+JOIN genesis_meta_reveals reveals
+    ON reveals.meta_hash = gen.meta_hash
+WHERE gen.meta_type = @meta_type AND
+    convert_from(reveals.meta_data, 'UTF8')::jsonb #>>
+        string_to_array(@json_path::TEXT, '.') = @json_value::TEXT;
+
+-- name: FetchGenesisPointByAssetID :one
+SELECT genesis_points.prev_out
+FROM genesis_assets
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+WHERE genesis_assets.asset_id = $1;
+
+-- name: UpsertImportCheckpoint :exec
+INSERT INTO import_checkpoints (
+    batch_id, last_committed_index, updated_at, batch_hash
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (batch_id)
+    DO UPDATE SET last_committed_index = EXCLUDED.last_committed_index,
+        updated_at = EXCLUDED.updated_at, batch_hash = EXCLUDED.batch_hash;
+
+-- name: FetchImportCheckpoint :one
+SELECT last_committed_index, batch_hash
+FROM import_checkpoints
+WHERE batch_id = $1;
+
+-- name: DeleteImportCheckpoint :exec
+DELETE FROM import_checkpoints
+WHERE batch_id = $1;
+
+-- name: FetchDuplicateOutputIndices :many
+SELECT
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+  ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+  ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE genesis_assets.genesis_point_id = @genesis_point_id
+    AND output_index IN (
+        SELECT output_index
+        FROM genesis_assets
+        WHERE genesis_point_id = @genesis_point_id
+        GROUP BY output_index
+        HAVING COUNT(*) > 1
+    )
+ORDER BY output_index, gen_asset_id;
+
+-- name: FetchAllGroupKeys :many
+-- Each group key is returned along with the sig of the first genesis asset
+-- that was created with it.
+SELECT
+    groups.tweaked_group_key, keys.raw_key, keys.key_family, keys.key_index,
+    sigs.genesis_sig
+FROM asset_groups groups
+JOIN internal_keys keys
+    ON groups.internal_key_id = keys.key_id
+JOIN asset_group_sigs sigs
+    ON sigs.sig_id = (
+        SELECT MIN(sig_id)
+        FROM asset_group_sigs
+        WHERE group_key_id = groups.group_id
+    )
+ORDER BY groups.group_id;
+
+-- name: FetchStaleUnanchoredAssets :many
+-- An asset is considered stale if it was never anchored in an on-chain output,
+-- even though its genesis point was first stored before the given time.
+-- Genesis points stored before their creation time was tracked are never
+-- considered stale.
+SELECT
+    assets.asset_id AS asset_primary_key, assets.amount,
+    genesis_points.created_at, genesis_assets.asset_id, asset_tag,
+    reveals.meta_data,
+    output_index, asset_type, genesis_points.prev_out prev_out
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE assets.anchor_utxo_id IS NULL
+    AND genesis_points.created_at < @older_than
+ORDER BY genesis_points.created_at, assets.asset_id;
+
+-- name: FetchAssetsWithNullScriptKey :many
+-- Every asset must reference a script key. On backends that don't enforce
+-- foreign keys, a script key may be missing nonetheless, which we detect by
+-- the LEFT JOIN not finding a matching script key.
+SELECT
+    assets.asset_id AS asset_primary_key, assets.script_key_id, assets.amount,
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type,
+    genesis_points.prev_out prev_out
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
+LEFT JOIN script_keys
+    ON assets.script_key_id = script_keys.script_key_id
+WHERE script_keys.script_key_id IS NULL
+ORDER BY assets.asset_id;
+
+-- name: FetchGroupRawKey :one
+SELECT keys.raw_key, keys.key_family, keys.key_index
+FROM asset_groups groups
+JOIN internal_keys keys
+    ON groups.internal_key_id = keys.key_id
+WHERE groups.tweaked_group_key = $1;
+
+-- name: FetchGroupSizes :many
+SELECT
+    key_group_info_view.tweaked_group_key, COUNT(*) AS num_assets
+FROM assets
+JOIN key_group_info_view
+    ON assets.genesis_id = key_group_info_view.gen_asset_id
+WHERE assets.spent = false
+GROUP BY key_group_info_view.tweaked_group_key;
+
+-- name: CountAssetsByType :many
+SELECT
+    genesis_assets.asset_type, COUNT(*) AS num_assets
+FROM assets
+JOIN genesis_assets
+    ON assets.genesis_id = genesis_assets.gen_asset_id
+WHERE (@include_spent = true OR assets.spent = false)
+GROUP BY genesis_assets.asset_type;
+
+-- name: SetGenesisReissuance :exec
+-- A genesis asset is a reissuance if another genesis asset was issued under
+-- the same group key before it, which we can tell by the order the group sigs
+-- were inserted in.
+UPDATE genesis_assets
+SET is_reissuance = EXISTS (
+    SELECT 1
+    FROM asset_group_sigs sigs
+    WHERE sigs.group_key_id = @group_key_id
+        AND sigs.sig_id < @sig_id
+)
+WHERE gen_asset_id = @gen_asset_id;
+
+-- name: FetchGroupReissuances :many
+SELECT
+    genesis_assets.asset_id, asset_tag, reveals.meta_data, output_index,
+    asset_type, meta_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
+JOIN asset_group_sigs sigs
+    ON genesis_assets.gen_asset_id = sigs.gen_asset_id
+JOIN asset_groups groups
+    ON sigs.group_key_id = groups.group_id
+WHERE groups.tweaked_group_key = @tweaked_group_key
+    AND genesis_assets.is_reissuance = true
+ORDER BY sigs.sig_id;
+
+-- name: UpsertGenesisMetaReveal :exec
+INSERT INTO genesis_meta_reveals (
+    meta_hash, meta_data
+) VALUES (
+    @meta_hash, @meta_data
+) ON CONFLICT (meta_hash)
+    -- The hash commits to the metadata, so there's nothing to update.
+    DO NOTHING;
+
+-- name: FetchGenesisAssetsWithInlineMetadata :many
+-- Genesis assets stored before their metadata was moved to the reveals still
+-- carry it inline, and may not have a metadata hash yet either.
+SELECT gen_asset_id, meta_hash, meta_data
+FROM genesis_assets
+WHERE meta_hash IS NULL OR meta_data IS NOT NULL;
+
+-- name: MoveGenesisMetadata :exec
+-- Once the metadata of a genesis asset is stored as a reveal, the genesis asset
+-- only references it by its hash.
+UPDATE genesis_assets
+SET meta_hash = @meta_hash, meta_data = NULL
+WHERE gen_asset_id = @gen_asset_id;
+
+-- name: FetchGenesesWithSharedMetaHash :many
+-- The metadata revealed for a hash is shared by all genesis assets with that
+-- hash, so the genesis fields are returned as well, which allows the caller to
+-- check whether the metadata still derives the asset ID of each of them.
+SELECT
+    gen_asset_id, genesis_assets.meta_hash, genesis_assets.asset_id,
+    asset_tag, reveals.meta_data, output_index, asset_type,
+    genesis_points.prev_out prev_out
+FROM genesis_assets
+JOIN genesis_points
+    ON genesis_assets.genesis_point_id = genesis_points.genesis_id
+LEFT JOIN genesis_meta_reveals reveals
+    ON genesis_assets.meta_hash = reveals.meta_hash
+WHERE genesis_assets.meta_hash IN (
+    SELECT meta_hash
+    FROM genesis_assets
+    WHERE meta_hash IS NOT NULL
+    GROUP BY meta_hash
+    HAVING COUNT(*) > 1
+)
+ORDER BY genesis_assets.meta_hash, gen_asset_id;
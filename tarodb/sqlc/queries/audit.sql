@@ -0,0 +1,29 @@
+-- name: InsertAuditLogEntry :one
+INSERT INTO audit_log (
+    prev_hash, op, args_hash, new_hash, row_id
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING entry_id;
+
+-- name: FetchAuditLogTip :one
+SELECT new_hash
+FROM audit_log
+ORDER BY entry_id DESC
+LIMIT 1;
+
+-- name: FetchAuditLog :many
+SELECT entry_id, prev_hash, op, args_hash, new_hash, row_id
+FROM audit_log
+ORDER BY entry_id;
+
+-- name: FetchAuditedAssets :many
+-- The columns of an asset that are checked against the audit log. All writes
+-- to these columns are recorded in the audit log, so each asset can be checked
+-- against the latest entry that refers to it.
+SELECT asset_id, genesis_id, version, script_key_id, asset_group_sig_id,
+    script_version, amount, amount_big, lock_time, relative_lock_time,
+    anchor_utxo_id, spent
+FROM assets
+WHERE (asset_id = sqlc.narg('asset_id_filter') OR
+    sqlc.narg('asset_id_filter') IS NULL)
+ORDER BY asset_id;
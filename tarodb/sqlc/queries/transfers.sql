@@ -95,7 +95,7 @@ SELECT sender_proof, receiver_proof
 FROM transfer_proofs
 WHERE transfer_id = $1;
 
--- name: ReanchorAssets :exec
+-- name: ReanchorAssets :many
 WITH assets_to_update AS (
     SELECT asset_id
     FROM assets
@@ -105,7 +105,8 @@ WITH assets_to_update AS (
 )
 UPDATE assets
 SET anchor_utxo_id = sqlc.arg('new_outpoint_utxo_id')
-WHERE asset_id IN (SELECT asset_id FROM assets_to_update);
+WHERE asset_id IN (SELECT asset_id FROM assets_to_update)
+RETURNING asset_id;
 
 -- name: ApplySpendDelta :one
 WITH old_script_key_id AS (
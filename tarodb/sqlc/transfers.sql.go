@@ -407,7 +407,7 @@ func (q *Queries) QueryAssetTransfers(ctx context.Context, arg QueryAssetTransfe
 	return items, nil
 }
 
-const reanchorAssets = `-- name: ReanchorAssets :exec
+const reanchorAssets = `-- name: ReanchorAssets :many
 WITH assets_to_update AS (
     SELECT asset_id
     FROM assets
@@ -418,6 +418,7 @@ WITH assets_to_update AS (
 UPDATE assets
 SET anchor_utxo_id = $1
 WHERE asset_id IN (SELECT asset_id FROM assets_to_update)
+RETURNING asset_id
 `
 
 type ReanchorAssetsParams struct {
@@ -425,7 +426,25 @@ type ReanchorAssetsParams struct {
 	OldOutpoint       []byte
 }
 
-func (q *Queries) ReanchorAssets(ctx context.Context, arg ReanchorAssetsParams) error {
-	_, err := q.db.ExecContext(ctx, reanchorAssets, arg.NewOutpointUtxoID, arg.OldOutpoint)
-	return err
+func (q *Queries) ReanchorAssets(ctx context.Context, arg ReanchorAssetsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, reanchorAssets, arg.NewOutpointUtxoID, arg.OldOutpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var asset_id int32
+		if err := rows.Scan(&asset_id); err != nil {
+			return nil, err
+		}
+		items = append(items, asset_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
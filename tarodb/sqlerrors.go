@@ -1,8 +1,10 @@
 package tarodb
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
@@ -35,11 +37,27 @@ func MapSQLError(err error) error {
 func parseSqliteError(sqliteErr *sqlite.Error) error {
 	switch sqliteErr.Code() {
 	// Handle unique constraint violation error.
-	case sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE,
+		sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+
 		return &ErrSqlUniqueConstraintViolation{
 			DbError: sqliteErr,
 		}
 
+	// Handle foreign key constraint violation error.
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return &ErrSqlForeignKeyViolation{
+			DbError: sqliteErr,
+		}
+
+	// Handle the database being locked by another writer.
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_BUSY_SNAPSHOT,
+		sqlite3.SQLITE_BUSY_RECOVERY:
+
+		return &ErrSqlBusy{
+			DbError: sqliteErr,
+		}
+
 	default:
 		return fmt.Errorf("unknown sqlite error: %w", sqliteErr)
 	}
@@ -55,11 +73,64 @@ func parsePostgresError(pqErr *pgconn.PgError) error {
 			DbError: pqErr,
 		}
 
+	// Handle foreign key constraint violation error.
+	case pgerrcode.ForeignKeyViolation:
+		return &ErrSqlForeignKeyViolation{
+			DbError: pqErr,
+		}
+
+	// Handle a transaction that conflicted with a concurrent one, which
+	// can only succeed if it's retried from the start.
+	case pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected:
+		return &ErrSerializationError{
+			DbError: pqErr,
+		}
+
 	default:
 		return fmt.Errorf("unknown postgres error: %w", pqErr)
 	}
 }
 
+var (
+	// ErrUniqueViolation is matched by all errors that signal a unique
+	// constraint violation, regardless of the database backend.
+	ErrUniqueViolation = errors.New("unique constraint violation")
+
+	// ErrForeignKeyViolation is matched by all errors that signal a
+	// foreign key constraint violation, regardless of the database
+	// backend.
+	ErrForeignKeyViolation = errors.New("foreign key constraint violation")
+
+	// ErrSerializationFailure is matched by all errors that signal a
+	// transaction conflicted with a concurrent one, regardless of the
+	// database backend.
+	ErrSerializationFailure = errors.New("serialization failure")
+
+	// ErrBusy is matched by all errors that signal the database is busy,
+	// regardless of the database backend.
+	ErrBusy = errors.New("database busy")
+)
+
+// normalizeDBError maps the native error of either database backend to one of
+// the database agnostic error types, which match the common sentinel errors
+// such as ErrUniqueViolation while still wrapping the native error. Any error
+// that can't be classified is returned unchanged.
+func normalizeDBError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch mappedErr := MapSQLError(err); mappedErr.(type) {
+	case *ErrSqlUniqueConstraintViolation, *ErrSqlForeignKeyViolation,
+		*ErrSerializationError, *ErrSqlBusy:
+
+		return mappedErr
+
+	default:
+		return err
+	}
+}
+
 // ErrSqlUniqueConstraintViolation is an error type which represents a database
 // agnostic SQL unique constraint violation.
 type ErrSqlUniqueConstraintViolation struct {
@@ -69,3 +140,236 @@ type ErrSqlUniqueConstraintViolation struct {
 func (e ErrSqlUniqueConstraintViolation) Error() string {
 	return fmt.Sprintf("sql unique constraint violation: %v", e.DbError)
 }
+
+// Is returns true if the target is ErrUniqueViolation.
+func (e ErrSqlUniqueConstraintViolation) Is(target error) bool {
+	return target == ErrUniqueViolation
+}
+
+// Unwrap returns the native error of the database backend.
+func (e ErrSqlUniqueConstraintViolation) Unwrap() error {
+	return e.DbError
+}
+
+// ErrSqlForeignKeyViolation is an error type which represents a database
+// agnostic SQL foreign key constraint violation.
+type ErrSqlForeignKeyViolation struct {
+	DbError error
+}
+
+func (e ErrSqlForeignKeyViolation) Error() string {
+	return fmt.Sprintf("sql foreign key constraint violation: %v",
+		e.DbError)
+}
+
+// Is returns true if the target is ErrForeignKeyViolation.
+func (e ErrSqlForeignKeyViolation) Is(target error) bool {
+	return target == ErrForeignKeyViolation
+}
+
+// Unwrap returns the native error of the database backend.
+func (e ErrSqlForeignKeyViolation) Unwrap() error {
+	return e.DbError
+}
+
+// ErrSqlBusy is an error type which represents a database agnostic SQL error
+// that signals the database is currently busy (locked by another writer), and
+// the operation can be retried.
+type ErrSqlBusy struct {
+	DbError error
+}
+
+func (e ErrSqlBusy) Error() string {
+	return fmt.Sprintf("sql database busy: %v", e.DbError)
+}
+
+// Is returns true if the target is ErrBusy.
+func (e ErrSqlBusy) Is(target error) bool {
+	return target == ErrBusy
+}
+
+// Unwrap returns the native error of the database backend.
+func (e ErrSqlBusy) Unwrap() error {
+	return e.DbError
+}
+
+// ErrSerializationError is an error type which represents a database agnostic
+// SQL error that signals a transaction conflicted with a concurrent one, and
+// the transaction can be retried from the start.
+type ErrSerializationError struct {
+	DbError error
+}
+
+func (e ErrSerializationError) Error() string {
+	return fmt.Sprintf("sql serialization error: %v", e.DbError)
+}
+
+// Is returns true if the target is ErrSerializationFailure.
+func (e ErrSerializationError) Is(target error) bool {
+	return target == ErrSerializationFailure
+}
+
+// Unwrap returns the native error of the database backend.
+func (e ErrSerializationError) Unwrap() error {
+	return e.DbError
+}
+
+// IsSerializationError returns true if the given error signals that a
+// transaction conflicted with a concurrent one and can be retried.
+func IsSerializationError(err error) bool {
+	var serializationErr *ErrSerializationError
+	return errors.As(MapSQLError(err), &serializationErr)
+}
+
+// IsBusyError returns true if the given error signals that the database was
+// busy and the operation can be retried.
+func IsBusyError(err error) bool {
+	var busyErr *ErrSqlBusy
+	return errors.As(MapSQLError(err), &busyErr)
+}
+
+const (
+	// DefaultNumBusyRetries is the default number of times an operation is
+	// retried if the database is busy.
+	DefaultNumBusyRetries = 10
+
+	// DefaultInitialBusyBackoff is the default amount of time we wait
+	// before the first retry of an operation that failed because the
+	// database was busy.
+	DefaultInitialBusyBackoff = 50 * time.Millisecond
+
+	// DefaultMaxBusyBackoff is the default upper bound of the time we
+	// wait between two retries.
+	DefaultMaxBusyBackoff = time.Second
+)
+
+// BusyRetryConfig houses the parameters that control how an operation is
+// retried if the database is busy.
+type BusyRetryConfig struct {
+	// NumRetries is the max number of times an operation is retried.
+	NumRetries int
+
+	// InitialBackoff is the time we wait before the first retry. The
+	// backoff doubles with each subsequent retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the upper bound of the time we wait between two
+	// retries.
+	MaxBackoff time.Duration
+}
+
+// DefaultBusyRetryConfig returns the default busy retry config.
+func DefaultBusyRetryConfig() *BusyRetryConfig {
+	return &BusyRetryConfig{
+		NumRetries:     DefaultNumBusyRetries,
+		InitialBackoff: DefaultInitialBusyBackoff,
+		MaxBackoff:     DefaultMaxBusyBackoff,
+	}
+}
+
+// RetryOnBusy executes the passed function, and retries it with an
+// exponential backoff as long as it fails because the database is busy, up to
+// the configured number of retries. Any other error is returned immediately.
+// This can be used around the upsert helpers, or around an entire database
+// transaction.
+func RetryOnBusy(ctx context.Context, cfg *BusyRetryConfig,
+	f func() error) error {
+
+	return retryOnError(
+		ctx, cfg.NumRetries, cfg.InitialBackoff, cfg.MaxBackoff,
+		IsBusyError, "Database busy", f,
+	)
+}
+
+// retryOnError executes the passed function, and retries it with an
+// exponential backoff as long as it fails with an error the passed predicate
+// deems retryable, up to the given number of retries.
+func retryOnError(ctx context.Context, numRetries int, initialBackoff,
+	maxBackoff time.Duration, isRetryable func(error) bool, reason string,
+	f func() error) error {
+
+	backoff := initialBackoff
+	for i := 0; ; i++ {
+		err := f()
+		if err == nil || !isRetryable(err) || i >= numRetries {
+			return err
+		}
+
+		log.Debugf("%v, retrying in %v (attempt %d of %d)", reason,
+			backoff, i+1, numRetries)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+const (
+	// DefaultNumSerializationRetries is the default number of times a
+	// transaction is retried if it conflicted with a concurrent one.
+	DefaultNumSerializationRetries = 10
+
+	// DefaultInitialSerializationBackoff is the default amount of time we
+	// wait before the first retry of a transaction that conflicted with a
+	// concurrent one.
+	DefaultInitialSerializationBackoff = 10 * time.Millisecond
+
+	// DefaultMaxSerializationBackoff is the default upper bound of the
+	// time we wait between two retries of a transaction.
+	DefaultMaxSerializationBackoff = time.Second
+)
+
+// SerializationRetryConfig houses the parameters that control how a
+// transaction is retried if it fails with a serialization failure or a
+// deadlock.
+type SerializationRetryConfig struct {
+	// NumRetries is the max number of times a transaction is retried.
+	NumRetries int
+
+	// InitialBackoff is the time we wait before the first retry. The
+	// backoff doubles with each subsequent retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the upper bound of the time we wait between two
+	// retries.
+	MaxBackoff time.Duration
+}
+
+// DefaultSerializationRetryConfig returns the default serialization retry
+// config.
+func DefaultSerializationRetryConfig() *SerializationRetryConfig {
+	return &SerializationRetryConfig{
+		NumRetries:     DefaultNumSerializationRetries,
+		InitialBackoff: DefaultInitialSerializationBackoff,
+		MaxBackoff:     DefaultMaxSerializationBackoff,
+	}
+}
+
+// ExecTxWithSerializationRetry executes the passed txBody in a single
+// transaction of the given database, and transparently retries the whole
+// transaction with an exponential backoff if it fails with a serialization
+// failure or a deadlock. Postgres reports these under SERIALIZABLE isolation
+// when concurrent transactions conflict. Any other error is passed through
+// unchanged. SQLite never reports these errors, so for SQLite this is a mere
+// pass-through.
+//
+// NOTE: As the whole transaction body is executed again on retry, it must not
+// leave any state behind outside of the transaction on failure.
+func ExecTxWithSerializationRetry[Q any](ctx context.Context,
+	db BatchedTx[Q], cfg *SerializationRetryConfig, txOptions TxOptions,
+	txBody func(Q) error) error {
+
+	return retryOnError(
+		ctx, cfg.NumRetries, cfg.InitialBackoff, cfg.MaxBackoff,
+		IsSerializationError, "Serialization failure", func() error {
+			return db.ExecTx(ctx, txOptions, txBody)
+		},
+	)
+}
@@ -0,0 +1,258 @@
+package tarodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRetryOnBusy tests that an operation failing because the database is
+// busy is retried, while any other error is returned immediately.
+func TestRetryOnBusy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := &BusyRetryConfig{
+		NumRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+
+	nativeErr := errors.New("database is locked")
+	busyErr := &ErrSqlBusy{
+		DbError: nativeErr,
+	}
+
+	// An operation that is busy for a couple of times should succeed once
+	// the database is no longer busy.
+	var numCalls int
+	err := RetryOnBusy(ctx, cfg, func() error {
+		numCalls++
+		if numCalls <= 2 {
+			return busyErr
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, numCalls)
+
+	// If the database stays busy, we should give up after the configured
+	// number of retries.
+	numCalls = 0
+	err = RetryOnBusy(ctx, cfg, func() error {
+		numCalls++
+		return busyErr
+	})
+	require.ErrorIs(t, err, busyErr)
+	require.Equal(t, cfg.NumRetries+1, numCalls)
+
+	// The error should match the common sentinel error, while still
+	// wrapping the native error.
+	require.ErrorIs(t, err, ErrBusy)
+	require.ErrorIs(t, err, nativeErr)
+
+	// Any other error shouldn't be retried at all.
+	numCalls = 0
+	otherErr := errors.New("other error")
+	err = RetryOnBusy(ctx, cfg, func() error {
+		numCalls++
+		return otherErr
+	})
+	require.ErrorIs(t, err, otherErr)
+	require.Equal(t, 1, numCalls)
+
+	// Finally, a cancelled context should abort the retries.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = RetryOnBusy(cancelCtx, cfg, func() error {
+		return busyErr
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// stubBatchedTx is a BatchedTx that fails each transaction with the next of
+// its errors, and succeeds once it runs out of errors.
+type stubBatchedTx struct {
+	errs     []error
+	numCalls int
+}
+
+// ExecTx executes the passed txBody, unless the transaction is set to fail.
+func (s *stubBatchedTx) ExecTx(_ context.Context, _ TxOptions,
+	txBody func(struct{}) error) error {
+
+	s.numCalls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return err
+	}
+
+	return txBody(struct{}{})
+}
+
+// TestExecTxWithSerializationRetry tests that transactions failing with a
+// serialization failure or a deadlock are retried, while any other error is
+// returned immediately.
+func TestExecTxWithSerializationRetry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := &SerializationRetryConfig{
+		NumRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+	txOpts := NewAssetStoreReadTx()
+	txBody := func(struct{}) error {
+		return nil
+	}
+
+	serializationErr := &pgconn.PgError{
+		Code: pgerrcode.SerializationFailure,
+	}
+	deadlockErr := &pgconn.PgError{
+		Code: pgerrcode.DeadlockDetected,
+	}
+	require.True(t, IsSerializationError(serializationErr))
+	require.True(t, IsSerializationError(deadlockErr))
+
+	// A transaction that conflicts a couple of times should succeed once
+	// it no longer conflicts with a concurrent one.
+	db := &stubBatchedTx{
+		errs: []error{serializationErr, deadlockErr},
+	}
+	err := ExecTxWithSerializationRetry[struct{}](
+		ctx, db, cfg, &txOpts, txBody,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 3, db.numCalls)
+
+	// If the transaction keeps conflicting, we should give up after the
+	// configured number of retries.
+	db = &stubBatchedTx{
+		errs: []error{
+			serializationErr, serializationErr, serializationErr,
+			serializationErr,
+		},
+	}
+	err = ExecTxWithSerializationRetry[struct{}](
+		ctx, db, cfg, &txOpts, txBody,
+	)
+	require.ErrorIs(t, err, serializationErr)
+	require.Equal(t, cfg.NumRetries+1, db.numCalls)
+
+	// Any other error, including SQLite's busy errors, shouldn't be
+	// retried at all.
+	otherErrs := []error{
+		errors.New("other error"),
+		&ErrSqlBusy{DbError: errors.New("database is locked")},
+		&pgconn.PgError{Code: pgerrcode.UniqueViolation},
+	}
+	for _, otherErr := range otherErrs {
+		db = &stubBatchedTx{
+			errs: []error{otherErr},
+		}
+		err = ExecTxWithSerializationRetry[struct{}](
+			ctx, db, cfg, &txOpts, txBody,
+		)
+		require.ErrorIs(t, err, otherErr)
+		require.Equal(t, 1, db.numCalls)
+	}
+}
+
+// TestNormalizeDBErrorPostgres tests that the native errors of the postgres
+// backend are mapped to the common sentinel errors, while still wrapping the
+// native error.
+func TestNormalizeDBErrorPostgres(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		code        string
+		expectedErr error
+	}{
+		{
+			code:        pgerrcode.UniqueViolation,
+			expectedErr: ErrUniqueViolation,
+		},
+		{
+			code:        pgerrcode.ForeignKeyViolation,
+			expectedErr: ErrForeignKeyViolation,
+		},
+		{
+			code:        pgerrcode.SerializationFailure,
+			expectedErr: ErrSerializationFailure,
+		},
+		{
+			code:        pgerrcode.DeadlockDetected,
+			expectedErr: ErrSerializationFailure,
+		},
+	}
+	for _, testCase := range testCases {
+		pgErr := &pgconn.PgError{
+			Code: testCase.code,
+		}
+		err := normalizeDBError(pgErr)
+		require.ErrorIs(t, err, testCase.expectedErr)
+
+		var nativeErr *pgconn.PgError
+		require.ErrorAs(t, err, &nativeErr)
+		require.Equal(t, testCase.code, nativeErr.Code)
+	}
+
+	// Errors that can't be classified should be returned unchanged.
+	pgErr := &pgconn.PgError{
+		Code: pgerrcode.NotNullViolation,
+	}
+	require.Equal(t, pgErr, normalizeDBError(pgErr))
+
+	otherErr := errors.New("other error")
+	require.Equal(t, otherErr, normalizeDBError(otherErr))
+	require.NoError(t, normalizeDBError(nil))
+}
+
+// TestNormalizeDBErrorBackend tests that the native errors of the database
+// backend under test are mapped to the common sentinel errors, both when
+// normalized directly and when returned by the upsert helpers.
+func TestNormalizeDBErrorBackend(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+
+	// Inserting the same genesis point twice violates its unique
+	// constraint.
+	prevOut := test.RandBytes(36)
+	insertPoint := func() error {
+		_, err := rawDB.ExecContext(
+			ctx, "INSERT INTO genesis_points (prev_out) "+
+				"VALUES ($1)", prevOut,
+		)
+		return err
+	}
+	require.NoError(t, insertPoint())
+
+	err := normalizeDBError(insertPoint())
+	require.ErrorIs(t, err, ErrUniqueViolation)
+
+	// A genesis asset referencing an unknown genesis point violates its
+	// foreign key constraint, which the upsert helper should report as
+	// such.
+	gen := asset.RandGenesis(t, asset.Normal)
+	_, err = upsertGenesis(
+		ctx, db, newUpsertOptions(), 1_000_000, gen,
+		MetadataKeepExisting,
+	)
+	require.ErrorIs(t, err, ErrForeignKeyViolation)
+}
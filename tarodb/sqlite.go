@@ -1,11 +1,13 @@
 package tarodb
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/url"
 	"path/filepath"
 	"testing"
+	"time"
 
 	sqlite_migrate "github.com/golang-migrate/migrate/v4/database/sqlite"
 	"github.com/lightninglabs/taro/tarodb/sqlc"
@@ -30,12 +32,34 @@ type SqliteConfig struct {
 	// DatabaseFileName is the full file path where the database file can be
 	// found.
 	DatabaseFileName string `long:"dbfile" description:"The full path to the database."`
+
+	// ReadOnlyTxns if true, then all read transactions are begun on a
+	// read-only connection, so any write within them fails. Postgres
+	// always begins read transactions in read-only mode.
+	ReadOnlyTxns bool `long:"readonlytxns" description:"Begin all read transactions in read-only mode, so any accidental write within them fails."`
+
+	// MaxOpenConnections is the maximum number of open connections of
+	// each connection pool. If 0, the number isn't limited.
+	MaxOpenConnections int32 `long:"maxconnections" description:"Max open connections to the database. If 0, the number isn't limited."`
+
+	// MaxIdleConnections is the maximum number of idle connections each
+	// connection pool keeps. If 0, the default of 2 is used.
+	MaxIdleConnections int32 `long:"maxidleconnections" description:"Max idle connections to keep in the connection pool. If 0, the default of 2 is used."`
+
+	// ConnMaxLifetime is the maximum amount of time a connection is
+	// reused for. If 0, connections are reused forever.
+	ConnMaxLifetime time.Duration `long:"connmaxlifetime" description:"Max amount of time a connection is reused for. If 0, connections are reused forever."`
 }
 
 // SqliteStore is a sqlite3 based database for the taro daemon.
 type SqliteStore struct {
 	cfg *SqliteConfig
 
+	// readOnlyDB is a separate connection pool to the same database that
+	// only allows reads. This is only set if read-only transactions are
+	// enabled.
+	readOnlyDB *sql.DB
+
 	*BaseDB
 }
 
@@ -82,6 +106,18 @@ func NewSqliteStore(cfg *SqliteConfig) (*SqliteStore, error) {
 		return nil, err
 	}
 
+	// From here on, we'll close the database on any error, so its
+	// connections don't leak if the store can't be created.
+	closeOnErr := func(err error) (*SqliteStore, error) {
+		_ = db.Close()
+		return nil, err
+	}
+
+	configureConnectionPool(
+		db, cfg.MaxOpenConnections, cfg.MaxIdleConnections,
+		cfg.ConnMaxLifetime,
+	)
+
 	if !cfg.SkipMigrations {
 		// Now that the database is open, populate the database with
 		// our set of schemas based on our embedded in-memory file
@@ -93,21 +129,48 @@ func NewSqliteStore(cfg *SqliteConfig) (*SqliteStore, error) {
 			db, &sqlite_migrate.Config{},
 		)
 		if err != nil {
-			return nil, err
+			return closeOnErr(err)
 		}
 
 		err = applyMigrations(
 			sqlSchemas, driver, "sqlc/migrations", "sqlc",
 		)
 		if err != nil {
-			return nil, err
+			return closeOnErr(err)
+		}
+
+		err = applyPostMigrationSteps(db)
+		if err != nil {
+			return closeOnErr(err)
 		}
 	}
 
-	queries := sqlc.New(db)
+	// Unlike postgres, sqlite ignores the read-only flag of a
+	// transaction. So if read-only transactions are enabled, we'll open a
+	// second connection pool for all read transactions, on which the
+	// query_only pragma prevents any writes.
+	var readOnlyDB *sql.DB
+	if cfg.ReadOnlyTxns {
+		sqliteOptions.Add(sqliteOptionPrefix, "query_only=on")
+		readOnlyDSN := fmt.Sprintf(
+			"%v?%v", cfg.DatabaseFileName, sqliteOptions.Encode(),
+		)
+		readOnlyDB, err = sql.Open("sqlite", readOnlyDSN)
+		if err != nil {
+			return closeOnErr(err)
+		}
+
+		configureConnectionPool(
+			readOnlyDB, cfg.MaxOpenConnections,
+			cfg.MaxIdleConnections, cfg.ConnMaxLifetime,
+		)
+	}
+
+	queries := sqlc.NewForType(db, sqlc.BackendTypeSqlite)
 
 	return &SqliteStore{
-		cfg: cfg,
+		cfg:        cfg,
+		readOnlyDB: readOnlyDB,
 		BaseDB: &BaseDB{
 			DB:      db,
 			Queries: queries,
@@ -115,9 +178,49 @@ func NewSqliteStore(cfg *SqliteConfig) (*SqliteStore, error) {
 	}, nil
 }
 
+// BeginTx wraps the normal sql specific BeginTx method with the TxOptions
+// interface. If read-only transactions are enabled, read transactions are
+// begun on the read-only connection pool.
+func (s *SqliteStore) BeginTx(ctx context.Context,
+	opts TxOptions) (*sql.Tx, error) {
+
+	if s.readOnlyDB == nil || !opts.ReadOnly() {
+		return s.BaseDB.BeginTx(ctx, opts)
+	}
+
+	return s.readOnlyDB.BeginTx(ctx, &sql.TxOptions{
+		ReadOnly: true,
+	})
+}
+
+// PoolStats returns the statistics of the connection pools of the database,
+// including the read-only connection pool if read-only transactions are
+// enabled.
+func (s *SqliteStore) PoolStats() DBPoolStats {
+	stats := s.BaseDB.PoolStats()
+	if s.readOnlyDB != nil {
+		readOnlyStats := s.readOnlyDB.Stats()
+		stats.ReadOnly = &readOnlyStats
+	}
+
+	return stats
+}
+
+// Close closes the database, including the read-only connection pool if
+// read-only transactions are enabled.
+func (s *SqliteStore) Close() error {
+	if s.readOnlyDB != nil {
+		if err := s.readOnlyDB.Close(); err != nil {
+			return err
+		}
+	}
+
+	return s.DB.Close()
+}
+
 // NewTestSqliteDB is a helper function that creates an SQLite database for
 // testing.
-func NewTestSqliteDB(t *testing.T) *SqliteStore {
+func NewTestSqliteDB(t testing.TB) *SqliteStore {
 	t.Helper()
 
 	t.Logf("Creating new SQLite DB for testing")
@@ -132,7 +235,7 @@ func NewTestSqliteDB(t *testing.T) *SqliteStore {
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
-		require.NoError(t, sqlDB.DB.Close())
+		require.NoError(t, sqlDB.Close())
 	})
 
 	return sqlDB
@@ -30,6 +30,17 @@ func sqlInt32[T constraints.Integer](num T) sql.NullInt32 {
 	}
 }
 
+// sqlOptInt32 turns a numerical integer type into the NullInt32 that sql/sqlc
+// uses when an integer field can be permitted to be NULL. Unlike sqlInt32, the
+// zero value is mapped to NULL, for fields where zero denotes an unset value.
+func sqlOptInt32[T constraints.Integer](num T) sql.NullInt32 {
+	if num == 0 {
+		return sql.NullInt32{}
+	}
+
+	return sqlInt32(num)
+}
+
 // extractSqlInt32 turns a NullInt32 into a numerical type. This can be useful
 // when reading directly from the database, as this function handles extracting
 // the inner value from the "option"-like struct.
@@ -59,6 +70,24 @@ func extractSqlInt16[T constraints.Integer](num sql.NullInt16) T {
 	return T(num.Int16)
 }
 
+// sqlInt64 turns a numerical integer type into the NullInt64 that sql/sqlc
+// uses when an integer field can be permitted to be NULL.
+func sqlInt64[T constraints.Integer](num T) sql.NullInt64 {
+	return sql.NullInt64{
+		Int64: int64(num),
+		Valid: true,
+	}
+}
+
+// sqlBool turns a boolean into the NullBool that sql/sqlc uses when a boolean
+// field can be permitted to be NULL.
+func sqlBool(b bool) sql.NullBool {
+	return sql.NullBool{
+		Bool:  b,
+		Valid: true,
+	}
+}
+
 // readOutPoint reads the next sequence of bytes from r as an OutPoint.
 //
 // NOTE: This function is intended to be used along with the wire.WriteOutPoint
@@ -0,0 +1,66 @@
+package tarodb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/lightninglabs/taro/asset"
+)
+
+// ErrGenesisIDMismatch is returned in strict mode when the asset ID stored for
+// a genesis asset doesn't match the one derived from its other fields, which
+// indicates that the genesis record is corrupted.
+var ErrGenesisIDMismatch = errors.New("genesis asset ID mismatch")
+
+// StrictGenesisStore is an optional interface a FetchGenesisStore can implement
+// to verify the asset ID of each genesis asset fetched through it.
+type StrictGenesisStore interface {
+	// VerifyGenesisID verifies that the given asset ID stored for a
+	// genesis asset matches the one derived from the parsed genesis.
+	VerifyGenesisID(genesis asset.Genesis, storedID []byte) error
+}
+
+// strictGenesisAssetsStore wraps an ActiveAssetsStore and verifies the asset
+// ID of each genesis asset fetched from it. Deriving the asset ID requires
+// hashing the genesis, so this is only done for deployments that prefer
+// detecting corrupted records over the cost of doing so.
+type strictGenesisAssetsStore struct {
+	ActiveAssetsStore
+}
+
+// NewStrictGenesisAssetsStore returns a new ActiveAssetsStore that verifies
+// the asset ID of each genesis asset it fetches, returning
+// ErrGenesisIDMismatch if a stored asset ID doesn't match the genesis.
+func NewStrictGenesisAssetsStore(q ActiveAssetsStore) ActiveAssetsStore {
+	return &strictGenesisAssetsStore{
+		ActiveAssetsStore: q,
+	}
+}
+
+// VerifyGenesisID verifies that the given asset ID stored for a genesis asset
+// matches the one derived from the parsed genesis.
+//
+// NOTE: This implements the StrictGenesisStore interface.
+func (s *strictGenesisAssetsStore) VerifyGenesisID(genesis asset.Genesis,
+	storedID []byte) error {
+
+	return verifyGenesisID(genesis, storedID)
+}
+
+// verifyGenesisID returns ErrGenesisIDMismatch if the asset ID derived from the
+// given genesis doesn't match the stored asset ID.
+func verifyGenesisID(genesis asset.Genesis, storedID []byte) error {
+	assetID := genesis.ID()
+	if !bytes.Equal(assetID[:], storedID) {
+		return fmt.Errorf("%w: stored %x, derived %x",
+			ErrGenesisIDMismatch, storedID, assetID[:])
+	}
+
+	return nil
+}
+
+// A compile-time assertion to ensure that strictGenesisAssetsStore meets the
+// ActiveAssetsStore and StrictGenesisStore interfaces.
+var _ ActiveAssetsStore = (*strictGenesisAssetsStore)(nil)
+var _ StrictGenesisStore = (*strictGenesisAssetsStore)(nil)
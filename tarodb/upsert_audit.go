@@ -0,0 +1,607 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lightninglabs/taro/tarodb/sqlc"
+)
+
+const (
+	// UpsertOpGenesisPoints is the operation name used when upserting a
+	// set of genesis points with a single statement.
+	UpsertOpGenesisPoints = "genesis_points"
+
+	// UpsertOpGenesisAssets is the operation name used when upserting a
+	// set of genesis assets with a single statement.
+	UpsertOpGenesisAssets = "genesis_assets"
+
+	// UpsertOpInternalKeys is the operation name used when upserting a
+	// set of internal keys with a single statement.
+	UpsertOpInternalKeys = "internal_keys"
+
+	// UpsertOpGenesisReissuance is the operation name used when marking a
+	// genesis asset as a reissuance.
+	UpsertOpGenesisReissuance = "genesis_reissuance"
+
+	// UpsertOpGenesisMetaReveal is the operation name used when storing
+	// the full metadata of genesis assets.
+	UpsertOpGenesisMetaReveal = "genesis_meta_reveal"
+
+	// UpsertOpGenesisMetaType is the operation name used when setting the
+	// type of the metadata of a genesis asset.
+	UpsertOpGenesisMetaType = "genesis_meta_type"
+
+	// UpsertOpFreedKeyReserve is the operation name used when reserving
+	// a freed key index.
+	UpsertOpFreedKeyReserve = "freed_key_reserve"
+
+	// UpsertOpAsset is the operation name used when inserting an asset.
+	UpsertOpAsset = "asset"
+
+	// UpsertOpAssetUpdate is the operation name used when any of the
+	// audited columns of an existing asset are written to, for example
+	// when it's anchored, transferred, archived or touched.
+	UpsertOpAssetUpdate = "asset_update"
+
+	// UpsertOpAssetDelete is the operation name used when deleting an
+	// asset.
+	UpsertOpAssetDelete = "asset_delete"
+
+	// UpsertOpAssetTransfer is the operation name used when logging a new
+	// outbound asset transfer.
+	UpsertOpAssetTransfer = "asset_transfer"
+
+	// UpsertOpAssetDelta is the operation name used when logging the spend
+	// delta of an asset transfer.
+	UpsertOpAssetDelta = "asset_delta"
+
+	// UpsertOpSpendProof is the operation name used when storing the
+	// sender and receiver proofs of an asset transfer.
+	UpsertOpSpendProof = "spend_proof"
+
+	// UpsertOpAssetProof is the operation name used when updating the
+	// proof file of an asset.
+	UpsertOpAssetProof = "asset_proof"
+
+	// UpsertOpAnchorTxConf is the operation name used when storing the
+	// confirmation of an anchor transaction.
+	UpsertOpAnchorTxConf = "anchor_tx_conf"
+)
+
+// ErrAuditLogDisabled is returned when verifying the audit log of a store
+// that wasn't created with the WithAuditLog option.
+var ErrAuditLogDisabled = errors.New("audit log not enabled")
+
+// AuditLogStore is the set of queries needed to append entries to the audit
+// log.
+type AuditLogStore interface {
+	// InsertAuditLogEntry appends a new entry to the audit log, and
+	// returns its primary key.
+	InsertAuditLogEntry(ctx context.Context,
+		arg NewAuditLogEntry) (int32, error)
+
+	// FetchAuditLogTip fetches the hash of the last entry of the audit
+	// log.
+	FetchAuditLogTip(ctx context.Context) ([]byte, error)
+
+	// FetchAuditedAssets fetches the columns of all assets that are
+	// checked against the audit log, or only those of the asset with the
+	// given primary key.
+	FetchAuditedAssets(ctx context.Context,
+		assetIDFilter sql.NullInt32) ([]AuditedAsset, error)
+}
+
+// ErrAuditChainBroken is returned when the hash chain of the audit log doesn't
+// verify, which means that an entry was modified or removed.
+type ErrAuditChainBroken struct {
+	// EntryID is the primary key of the first entry that doesn't verify.
+	EntryID int32
+
+	// Reason describes why the entry doesn't verify.
+	Reason string
+}
+
+func (e ErrAuditChainBroken) Error() string {
+	return fmt.Sprintf("audit chain broken at entry %d: %s", e.EntryID,
+		e.Reason)
+}
+
+// auditUpsertStore wraps an UpsertAssetStore and extends the hash chain of the
+// audit log with each successful write made through it. The audit log is
+// appended to within the same transaction, so a write and its audit log entry
+// are committed atomically.
+type auditUpsertStore struct {
+	UpsertAssetStore
+
+	key []byte
+}
+
+// auditStore wraps the passed store so all writes made through it are
+// recorded in the audit log, if the options enable it.
+func (o *upsertOptions) auditStore(q UpsertAssetStore) UpsertAssetStore {
+	if o.auditKey == nil {
+		return q
+	}
+
+	return &auditUpsertStore{
+		UpsertAssetStore: q,
+		key:              o.auditKey,
+	}
+}
+
+// auditHash computes the hash of an audit log entry, which commits to the
+// hash of the previous entry, the operation and the hash of its arguments.
+// The hash is keyed, so the chain can't be recomputed by anyone with write
+// access to the database alone.
+func auditHash(key, prevHash []byte, op string, argsHash []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write(prevHash)
+	_, _ = h.Write([]byte(op))
+	_, _ = h.Write(argsHash)
+
+	return h.Sum(nil)
+}
+
+// auditArgsHash returns the hash of the arguments of an audit log entry.
+func auditArgsHash(args interface{}) ([]byte, error) {
+	// The arguments are all plain sqlc param and row structs, so their
+	// JSON encoding is deterministic.
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode audit args: %w", err)
+	}
+	argsHash := sha256.Sum256(argsBytes)
+
+	return argsHash[:], nil
+}
+
+// auditAssetRow extends the audit log with an entry for the given operation,
+// which commits to the current columns of the asset with the given primary
+// key, so the asset can be checked against the entry later on. False is
+// returned if there's no such asset.
+func auditAssetRow(ctx context.Context, auditLog AuditLogStore, key []byte,
+	op string, assetID int32) (bool, error) {
+
+	dbAssets, err := auditLog.FetchAuditedAssets(ctx, sqlInt32(assetID))
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch audited asset: %w",
+			err)
+	}
+	if len(dbAssets) == 0 {
+		return false, nil
+	}
+
+	err = appendAuditEntry(
+		ctx, auditLog, key, op, dbAssets[0], sqlInt32(assetID),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// auditedAssetDelete holds the arguments of the audit log entry of a deleted
+// asset.
+type auditedAssetDelete struct {
+	AssetID int32
+}
+
+// appendAuditEntry extends the audit log with an entry for the given
+// operation, which refers to the row with the given primary key, if any.
+func appendAuditEntry(ctx context.Context, auditLog AuditLogStore, key []byte,
+	op string, args interface{}, rowID sql.NullInt32) error {
+
+	argsHash, err := auditArgsHash(args)
+	if err != nil {
+		return err
+	}
+
+	// The very first entry commits to an all-zero previous hash.
+	prevHash, err := auditLog.FetchAuditLogTip(ctx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		prevHash = make([]byte, sha256.Size)
+
+	case err != nil:
+		return fmt.Errorf("unable to fetch audit log tip: %w", err)
+	}
+
+	_, err = auditLog.InsertAuditLogEntry(ctx, NewAuditLogEntry{
+		PrevHash: prevHash,
+		Op:       op,
+		ArgsHash: argsHash,
+		NewHash:  auditHash(key, prevHash, op, argsHash),
+		RowID:    rowID,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to append audit log entry: %w", err)
+	}
+
+	return nil
+}
+
+// appendEntry extends the audit log with an entry for the given operation if
+// the write succeeded.
+func (s *auditUpsertStore) appendEntry(ctx context.Context, op string,
+	args interface{}, err error) error {
+
+	if err != nil {
+		return err
+	}
+
+	return appendAuditEntry(
+		ctx, s.UpsertAssetStore, s.key, op, args, sql.NullInt32{},
+	)
+}
+
+// auditWrite extends the audit log with an entry for the given operation if
+// the write succeeded, and passes through the primary key of the write.
+func (s *auditUpsertStore) auditWrite(ctx context.Context, op string,
+	args interface{}, id int32, err error) (int32, error) {
+
+	if err := s.appendEntry(ctx, op, args, err); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpsertGenesisPoint inserts a new or updates an existing genesis point on
+// disk, and returns the primary key.
+func (s *auditUpsertStore) UpsertGenesisPoint(ctx context.Context,
+	arg NewGenesisPoint) (int32, error) {
+
+	id, err := s.UpsertAssetStore.UpsertGenesisPoint(ctx, arg)
+	return s.auditWrite(ctx, UpsertOpGenesisPoint, arg, id, err)
+}
+
+// UpsertGenesisPoints inserts new or updates existing genesis points on disk
+// with a single statement, and extends the audit log with a single entry for
+// all of them.
+func (s *auditUpsertStore) UpsertGenesisPoints(ctx context.Context,
+	arg NewGenesisPoints) ([]UpsertedGenesisPoint, error) {
+
+	points, err := s.UpsertAssetStore.UpsertGenesisPoints(ctx, arg)
+	err = s.appendEntry(ctx, UpsertOpGenesisPoints, arg, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// UpsertGenesisAsset inserts a new or updates an existing genesis asset in
+// the DB, and returns the primary key.
+func (s *auditUpsertStore) UpsertGenesisAsset(ctx context.Context,
+	arg GenesisAsset) (int32, error) {
+
+	id, err := s.UpsertAssetStore.UpsertGenesisAsset(ctx, arg)
+	return s.auditWrite(ctx, UpsertOpGenesisAsset, arg, id, err)
+}
+
+// UpsertGenesisAssets inserts new or resolves existing genesis assets in the
+// DB with a single statement, and extends the audit log with a single entry
+// for all of them.
+func (s *auditUpsertStore) UpsertGenesisAssets(ctx context.Context,
+	arg NewGenesisAssets) ([]UpsertedGenesisAsset, error) {
+
+	genAssets, err := s.UpsertAssetStore.UpsertGenesisAssets(ctx, arg)
+	err = s.appendEntry(ctx, UpsertOpGenesisAssets, arg, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return genAssets, nil
+}
+
+// UpsertInternalKey inserts a new or updates an existing internal key into
+// the database.
+func (s *auditUpsertStore) UpsertInternalKey(ctx context.Context,
+	arg InternalKey) (int32, error) {
+
+	id, err := s.UpsertAssetStore.UpsertInternalKey(ctx, arg)
+	return s.auditWrite(ctx, UpsertOpInternalKey, arg, id, err)
+}
+
+// UpsertInternalKeys inserts new or updates existing internal keys into the
+// database with a single statement, and extends the audit log with a single
+// entry for all of them.
+func (s *auditUpsertStore) UpsertInternalKeys(ctx context.Context,
+	keys []InternalKey) ([]UpsertedInternalKey, error) {
+
+	dbKeys, err := s.UpsertAssetStore.UpsertInternalKeys(ctx, keys)
+	err = s.appendEntry(ctx, UpsertOpInternalKeys, keys, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbKeys, nil
+}
+
+// UpsertScriptKey inserts a new script key on disk into the DB.
+func (s *auditUpsertStore) UpsertScriptKey(ctx context.Context,
+	arg NewScriptKey) (int32, error) {
+
+	id, err := s.UpsertAssetStore.UpsertScriptKey(ctx, arg)
+	return s.auditWrite(ctx, UpsertOpScriptKey, arg, id, err)
+}
+
+// UpsertAssetGroupSig inserts a new asset group sig into the DB.
+func (s *auditUpsertStore) UpsertAssetGroupSig(ctx context.Context,
+	arg AssetGroupSig) (int32, error) {
+
+	id, err := s.UpsertAssetStore.UpsertAssetGroupSig(ctx, arg)
+	return s.auditWrite(ctx, UpsertOpGroupSig, arg, id, err)
+}
+
+// UpsertAssetGroupKey inserts a new or updates an existing group key on disk,
+// and returns the primary key.
+func (s *auditUpsertStore) UpsertAssetGroupKey(ctx context.Context,
+	arg AssetGroupKey) (int32, error) {
+
+	id, err := s.UpsertAssetStore.UpsertAssetGroupKey(ctx, arg)
+	return s.auditWrite(ctx, UpsertOpGroupKey, arg, id, err)
+}
+
+// SetGenesisReissuance marks a genesis asset as a reissuance if another
+// genesis asset was issued under its group key before it.
+func (s *auditUpsertStore) SetGenesisReissuance(ctx context.Context,
+	arg GenesisReissuance) error {
+
+	err := s.UpsertAssetStore.SetGenesisReissuance(ctx, arg)
+	return s.appendEntry(ctx, UpsertOpGenesisReissuance, arg, err)
+}
+
+// UpsertGenesisMetaReveal stores the full metadata of genesis assets with the
+// given metadata hash, unless it's already known.
+func (s *auditUpsertStore) UpsertGenesisMetaReveal(ctx context.Context,
+	arg GenesisMetaReveal) error {
+
+	err := s.UpsertAssetStore.UpsertGenesisMetaReveal(ctx, arg)
+	return s.appendEntry(ctx, UpsertOpGenesisMetaReveal, arg, err)
+}
+
+// UpsertChainTx inserts a new or updates an existing chain tx into the DB.
+func (s *auditUpsertStore) UpsertChainTx(ctx context.Context,
+	arg ChainTx) (int32, error) {
+
+	id, err := s.UpsertAssetStore.UpsertChainTx(ctx, arg)
+	return s.auditWrite(ctx, UpsertOpChainTx, arg, id, err)
+}
+
+// InsertNewAsset inserts a new asset on disk.
+func (s *auditUpsertStore) InsertNewAsset(ctx context.Context,
+	arg sqlc.InsertNewAssetParams) (int32, error) {
+
+	id, err := s.UpsertAssetStore.InsertNewAsset(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	// Assets are checked against their latest audit log entry when
+	// verifying the chain, so the entry refers to the new row.
+	_, err = auditAssetRow(
+		ctx, s.UpsertAssetStore, s.key, UpsertOpAsset, id,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// SetAssetBigAmount stores the exact amount of an asset that doesn't fit into
+// the amount column.
+func (s *auditUpsertStore) SetAssetBigAmount(ctx context.Context,
+	arg sqlc.SetAssetBigAmountParams) error {
+
+	err := s.UpsertAssetStore.SetAssetBigAmount(ctx, arg)
+	if err != nil {
+		return err
+	}
+
+	_, err = auditAssetRow(
+		ctx, s.UpsertAssetStore, s.key, UpsertOpAssetUpdate,
+		arg.AssetID,
+	)
+	return err
+}
+
+// auditEntry extends the audit log with an entry for the given operation, if
+// the audit log is enabled. This is used for the writes that aren't made
+// through the UpsertAssetStore returned by auditStore.
+func (o *upsertOptions) auditEntry(ctx context.Context, auditLog AuditLogStore,
+	op string, args interface{}, rowID sql.NullInt32) error {
+
+	if o.auditKey == nil {
+		return nil
+	}
+
+	return appendAuditEntry(ctx, auditLog, o.auditKey, op, args, rowID)
+}
+
+// auditAssetUpdates extends the audit log with an entry for each of the given
+// updated assets, if the audit log is enabled. Each entry commits to the
+// columns of the asset after the update. Unknown primary keys are ignored.
+func (o *upsertOptions) auditAssetUpdates(ctx context.Context,
+	auditLog AuditLogStore, assetIDs []int32) error {
+
+	if o.auditKey == nil {
+		return nil
+	}
+
+	for _, assetID := range assetIDs {
+		_, err := auditAssetRow(
+			ctx, auditLog, o.auditKey, UpsertOpAssetUpdate, assetID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// auditAssetDelete extends the audit log with an entry for each of the given
+// deleted assets, if the audit log is enabled.
+func (o *upsertOptions) auditAssetDelete(ctx context.Context,
+	auditLog AuditLogStore, assetIDs []int32) error {
+
+	if o.auditKey == nil {
+		return nil
+	}
+
+	for _, assetID := range assetIDs {
+		err := appendAuditEntry(
+			ctx, auditLog, o.auditKey,
+			UpsertOpAssetDelete, auditedAssetDelete{
+				AssetID: assetID,
+			}, sqlInt32(assetID),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VerifyAuditChain walks the audit log from its first entry, and verifies
+// that each entry commits to the entry before it under the audit key of the
+// store. Each asset that was inserted or updated while the audit log was
+// enabled is also checked against the columns recorded in the latest entry
+// that refers to it, unless the asset was deleted through the store.
+// ErrAuditChainBroken is returned for the first entry that doesn't verify. As
+// the chain has no external anchor, removing entries from the end of the log
+// can't be detected.
+func (a *AssetStore) VerifyAuditChain(ctx context.Context) error {
+	key := a.upsertOpts.auditKey
+	if key == nil {
+		return ErrAuditLogDisabled
+	}
+
+	var (
+		entries []AuditLogEntry
+		assets  []AuditedAsset
+	)
+
+	readOpts := NewAssetStoreReadTx()
+	dbErr := a.db.ExecTx(ctx, &readOpts, func(q ActiveAssetsStore) error {
+		var err error
+		entries, err = q.FetchAuditLog(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to fetch audit log: %w", err)
+		}
+
+		assets, err = q.FetchAuditedAssets(ctx, sql.NullInt32{})
+		if err != nil {
+			return fmt.Errorf("unable to fetch assets: %w", err)
+		}
+
+		return nil
+	})
+	if dbErr != nil {
+		return dbErr
+	}
+
+	// First, we'll make sure the chain itself is intact, and collect the
+	// latest entry of each asset along the way. An asset that was deleted
+	// through the store has no entry to be checked against anymore.
+	latestEntries := make(map[int32]int32)
+	prevHash := make([]byte, sha256.Size)
+	for _, entry := range entries {
+		if !bytes.Equal(entry.PrevHash, prevHash) {
+			return &ErrAuditChainBroken{
+				EntryID: entry.EntryID,
+				Reason:  "previous hash mismatch",
+			}
+		}
+
+		newHash := auditHash(
+			key, entry.PrevHash, entry.Op, entry.ArgsHash,
+		)
+		if !hmac.Equal(entry.NewHash, newHash) {
+			return &ErrAuditChainBroken{
+				EntryID: entry.EntryID,
+				Reason:  "entry hash mismatch",
+			}
+		}
+
+		prevHash = entry.NewHash
+
+		switch entry.Op {
+		case UpsertOpAsset, UpsertOpAssetUpdate:
+			if !entry.RowID.Valid {
+				return &ErrAuditChainBroken{
+					EntryID: entry.EntryID,
+					Reason:  "asset row missing",
+				}
+			}
+
+			latestEntries[entry.RowID.Int32] = entry.EntryID
+
+		case UpsertOpAssetDelete:
+			argsHash, err := auditArgsHash(auditedAssetDelete{
+				AssetID: entry.RowID.Int32,
+			})
+			if err != nil {
+				return err
+			}
+			validArgs := bytes.Equal(entry.ArgsHash, argsHash)
+			if !entry.RowID.Valid || !validArgs {
+				return &ErrAuditChainBroken{
+					EntryID: entry.EntryID,
+					Reason:  "deleted asset mismatch",
+				}
+			}
+
+			delete(latestEntries, entry.RowID.Int32)
+		}
+	}
+
+	// With the chain verified, we'll check that each audited asset still
+	// matches the columns its latest entry committed to.
+	assetsByID := make(map[int32]AuditedAsset, len(assets))
+	for _, dbAsset := range assets {
+		assetsByID[dbAsset.AssetID] = dbAsset
+	}
+	for _, entry := range entries {
+		latestEntry, ok := latestEntries[entry.RowID.Int32]
+		if !ok || latestEntry != entry.EntryID {
+			continue
+		}
+
+		dbAsset, ok := assetsByID[entry.RowID.Int32]
+		if !ok {
+			return &ErrAuditChainBroken{
+				EntryID: entry.EntryID,
+				Reason:  "asset row removed",
+			}
+		}
+
+		argsHash, err := auditArgsHash(dbAsset)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(entry.ArgsHash, argsHash) {
+			return &ErrAuditChainBroken{
+				EntryID: entry.EntryID,
+				Reason:  "asset row mismatch",
+			}
+		}
+	}
+
+	return nil
+}
+
+// A compile-time assertion to ensure that auditUpsertStore meets the
+// UpsertAssetStore interface.
+var _ UpsertAssetStore = (*auditUpsertStore)(nil)
@@ -0,0 +1,382 @@
+package tarodb
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// rawExecutor is implemented by the test databases, which expose the
+// underlying database connection.
+type rawExecutor interface {
+	ExecContext(ctx context.Context, query string,
+		args ...interface{}) (sql.Result, error)
+}
+
+// testAuditKey is the key the audit log of the test stores is keyed with.
+var testAuditKey = []byte("test audit key")
+
+// insertAuditedAssets imports a few batches of assets through an asset store
+// that keeps an audit log, and returns the imported assets.
+func insertAuditedAssets(t *testing.T, assetStore *AssetStore) []*asset.Asset {
+	ctx := context.Background()
+
+	const (
+		numBatches     = 3
+		assetsPerBatch = 2
+	)
+	var allAssets []*asset.Asset
+	for i := 0; i < numBatches; i++ {
+		genesisPoint := test.RandOp(t)
+		anchor := randAnchorUTXO(t)
+
+		assets := make([]*asset.Asset, assetsPerBatch)
+		anchors := make([]AnchorUTXO, assetsPerBatch)
+		for j := range assets {
+			assets[j] = randAsset(
+				t, withAssetGenPoint(genesisPoint),
+			)
+			anchors[j] = anchor
+		}
+
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, genesisPoint, assets, anchors,
+		)
+		require.NoError(t, err)
+
+		allAssets = append(allAssets, assets...)
+	}
+
+	return allAssets
+}
+
+// TestAuditUpsertStore tests that all writes made while importing assets into
+// a store that keeps an audit log extend the hash chain of the audit log, and
+// that the chain verifies.
+func TestAuditUpsertStore(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t, WithAuditLog(testAuditKey))
+	ctx := context.Background()
+
+	// An empty audit log trivially verifies.
+	require.NoError(t, assetStore.VerifyAuditChain(ctx))
+
+	numAssets := len(insertAuditedAssets(t, assetStore))
+
+	entries, err := db.FetchAuditLog(ctx)
+	require.NoError(t, err)
+	require.Greater(t, len(entries), numAssets)
+
+	// The first entry commits to an all-zero hash, and each entry after
+	// it commits to the entry before it. Each asset entry refers to the
+	// asset row it inserted.
+	require.Equal(t, make([]byte, sha256.Size), entries[0].PrevHash)
+	var numAssetEntries int
+	for i, entry := range entries {
+		if i > 0 {
+			require.Equal(t, entries[i-1].NewHash, entry.PrevHash)
+		}
+		if entry.Op == UpsertOpAsset {
+			require.True(t, entry.RowID.Valid)
+			numAssetEntries++
+		}
+	}
+	require.Equal(t, numAssets, numAssetEntries)
+
+	require.NoError(t, assetStore.VerifyAuditChain(ctx))
+
+	// Writes made without the audit log option aren't recorded.
+	newAsset := randAsset(t)
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), newAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{newAsset}, nil,
+	)
+	require.NoError(t, err)
+
+	newEntries, err := db.FetchAuditLog(ctx)
+	require.NoError(t, err)
+	require.Equal(t, entries, newEntries)
+}
+
+// TestAuditLogOptions tests that the audit log can only be verified with the
+// key it was created with.
+func TestAuditLogOptions(t *testing.T) {
+	t.Parallel()
+
+	db := NewTestDB(t)
+	activeTxCreator := func(tx *sql.Tx) ActiveAssetsStore {
+		return db.WithTx(tx)
+	}
+	newStore := func(opts ...UpsertOption) *AssetStore {
+		return NewAssetStore(NewTransactionExecutor[ActiveAssetsStore](
+			db, activeTxCreator,
+		), opts...)
+	}
+
+	ctx := context.Background()
+
+	// A store without an audit key can't verify the audit log.
+	err := newStore().VerifyAuditChain(ctx)
+	require.ErrorIs(t, err, ErrAuditLogDisabled)
+
+	// The log written with one key doesn't verify under another.
+	assetStore := newStore(WithAuditLog(testAuditKey))
+	insertAuditedAssets(t, assetStore)
+	require.NoError(t, assetStore.VerifyAuditChain(ctx))
+
+	err = newStore(WithAuditLog([]byte("other key"))).VerifyAuditChain(ctx)
+	var brokenErr *ErrAuditChainBroken
+	require.ErrorAs(t, err, &brokenErr)
+}
+
+// TestAuditLogDeletedAssets tests that assets deleted through the store are
+// recorded in the audit log, so the chain still verifies afterwards.
+func TestAuditLogDeletedAssets(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t, WithAuditLog(testAuditKey))
+	ctx := context.Background()
+
+	assets := insertAuditedAssets(t, assetStore)
+
+	scriptKey := assets[0].ScriptKey.PubKey
+	err := assetStore.DeleteAssetByScriptKey(
+		ctx, scriptKey.SerializeCompressed(),
+	)
+	require.NoError(t, err)
+
+	entries, err := db.FetchAuditLog(ctx)
+	require.NoError(t, err)
+	require.Equal(t, UpsertOpAssetDelete, entries[len(entries)-1].Op)
+
+	require.NoError(t, assetStore.VerifyAuditChain(ctx))
+}
+
+// TestAuditLogAssetUpdates tests that all updates of existing assets made
+// through the store are recorded in the audit log, so the chain still
+// verifies afterwards, and each asset is checked against its latest entry.
+func TestAuditLogAssetUpdates(t *testing.T) {
+	t.Parallel()
+
+	_, assetStore, db := newAssetStore(t, WithAuditLog(testAuditKey))
+	ctx := context.Background()
+
+	insertAuditedAssets(t, assetStore)
+
+	dbAssets, err := db.FetchAuditedAssets(ctx, sql.NullInt32{})
+	require.NoError(t, err)
+	require.Len(t, dbAssets, 6)
+
+	// assertUpdates asserts that the given operation appended an update
+	// entry for each of the given assets, and that the chain verifies.
+	assertUpdates := func(op func(), assetIDs ...int32) {
+		t.Helper()
+
+		entries, err := db.FetchAuditLog(ctx)
+		require.NoError(t, err)
+
+		op()
+
+		newEntries, err := db.FetchAuditLog(ctx)
+		require.NoError(t, err)
+
+		var updatedIDs []int32
+		for _, entry := range newEntries[len(entries):] {
+			if entry.Op != UpsertOpAssetUpdate {
+				continue
+			}
+
+			updatedIDs = append(updatedIDs, entry.RowID.Int32)
+		}
+		require.Equal(t, assetIDs, updatedIDs)
+
+		require.NoError(t, assetStore.VerifyAuditChain(ctx))
+	}
+
+	// Touching assets doesn't change their audited columns, but is still
+	// recorded.
+	assertUpdates(func() {
+		err := assetStore.TouchAssets(ctx, []int32{
+			dbAssets[0].AssetID, dbAssets[1].AssetID,
+		})
+		require.NoError(t, err)
+	}, dbAssets[0].AssetID, dbAssets[1].AssetID)
+
+	// Archiving an asset flips its spent flag.
+	assertUpdates(func() {
+		_, err := assetStore.ArchiveAssetsByIDs(ctx, []int32{
+			dbAssets[2].AssetID,
+		})
+		require.NoError(t, err)
+	}, dbAssets[2].AssetID)
+
+	// Transferring an asset archives its old state, and inserts the new
+	// one, which gets an asset entry of its own.
+	newAnchorUtxoID, err := upsertAnchorUTXO(
+		ctx, db, newUpsertOptions(), randAnchorUTXO(t),
+	)
+	require.NoError(t, err)
+	assertUpdates(func() {
+		err := assetStore.TransferAsset(
+			ctx, dbAssets[3].AssetID, randAsset(t),
+			newAnchorUtxoID,
+		)
+		require.NoError(t, err)
+	}, dbAssets[3].AssetID)
+
+	// Binding an asset that isn't anchored yet sets its anchor.
+	newAsset := randAsset(t)
+	_, newAssetIDs, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(WithAuditLog(testAuditKey)),
+		newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset}, nil,
+	)
+	require.NoError(t, err)
+	assertUpdates(func() {
+		err := assetStore.BindAssetAnchor(
+			ctx, newAssetIDs[0], newAnchorUtxoID,
+		)
+		require.NoError(t, err)
+	}, newAssetIDs[0])
+
+	// An asset that was updated behind the back of the store is checked
+	// against its latest entry, and no longer verifies.
+	rawDB, ok := db.(rawExecutor)
+	require.True(t, ok)
+	_, err = rawDB.ExecContext(
+		ctx, "UPDATE assets SET spent = false WHERE asset_id = $1",
+		dbAssets[2].AssetID,
+	)
+	require.NoError(t, err)
+
+	err = assetStore.VerifyAuditChain(ctx)
+	var brokenErr *ErrAuditChainBroken
+	require.ErrorAs(t, err, &brokenErr)
+	require.Equal(t, "asset row mismatch", brokenErr.Reason)
+}
+
+// TestVerifyAuditChainTampered tests that modifying or removing entries of
+// the audit log, or the assets it records, is detected when verifying the
+// chain.
+func TestVerifyAuditChainTampered(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		tamper string
+
+		// assetRow indicates whether the tamper statement targets the
+		// asset row referenced by the entry instead of the entry.
+		assetRow bool
+	}{
+		{
+			name: "modified op",
+			tamper: "UPDATE audit_log SET op = 'tampered' " +
+				"WHERE entry_id = $1",
+		},
+		{
+			name: "modified args hash",
+			tamper: "UPDATE audit_log SET args_hash = new_hash " +
+				"WHERE entry_id = $1",
+		},
+		{
+			name: "modified entry hash",
+			tamper: "UPDATE audit_log SET new_hash = args_hash " +
+				"WHERE entry_id = $1",
+		},
+		{
+			name:   "removed entry",
+			tamper: "DELETE FROM audit_log WHERE entry_id = $1",
+		},
+		{
+			name: "modified asset row",
+			tamper: "UPDATE assets SET version = version + 1 " +
+				"WHERE asset_id = $1",
+			assetRow: true,
+		},
+		{
+			name: "modified asset amount",
+			tamper: "UPDATE assets SET amount = amount + 1 " +
+				"WHERE asset_id = $1",
+			assetRow: true,
+		},
+		{
+			name: "modified asset script key",
+			tamper: "UPDATE assets SET script_key_id = (" +
+				"SELECT MIN(script_key_id) FROM script_keys " +
+				"WHERE script_key_id != assets.script_key_id" +
+				") WHERE asset_id = $1",
+			assetRow: true,
+		},
+		{
+			name: "modified asset anchor",
+			tamper: "UPDATE assets SET anchor_utxo_id = NULL " +
+				"WHERE asset_id = $1",
+			assetRow: true,
+		},
+		{
+			name: "modified asset spent flag",
+			tamper: "UPDATE assets SET spent = NOT spent " +
+				"WHERE asset_id = $1",
+			assetRow: true,
+		},
+		{
+			name:     "removed asset row",
+			tamper:   "DELETE FROM assets WHERE asset_id = $1",
+			assetRow: true,
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, assetStore, db := newAssetStore(
+				t, WithAuditLog(testAuditKey),
+			)
+			ctx := context.Background()
+
+			insertAuditedAssets(t, assetStore)
+			require.NoError(t, assetStore.VerifyAuditChain(ctx))
+
+			// We'll tamper with an entry in the middle of the
+			// chain, or the asset it inserted.
+			entries, err := db.FetchAuditLog(ctx)
+			require.NoError(t, err)
+
+			tampered := entries[len(entries)/2]
+			tamperArg := tampered.EntryID
+			if testCase.assetRow {
+				var assetEntries []AuditLogEntry
+				for _, entry := range entries {
+					if entry.Op == UpsertOpAsset {
+						assetEntries = append(
+							assetEntries, entry,
+						)
+					}
+				}
+				tampered = assetEntries[len(assetEntries)/2]
+				tamperArg = tampered.RowID.Int32
+			}
+
+			rawDB, ok := db.(rawExecutor)
+			require.True(t, ok)
+			_, err = rawDB.ExecContext(
+				ctx, testCase.tamper, tamperArg,
+			)
+			require.NoError(t, err)
+
+			err = assetStore.VerifyAuditChain(ctx)
+			var brokenErr *ErrAuditChainBroken
+			require.ErrorAs(t, err, &brokenErr)
+			require.GreaterOrEqual(
+				t, brokenErr.EntryID, tampered.EntryID,
+			)
+		})
+	}
+}
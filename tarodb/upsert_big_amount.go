@@ -0,0 +1,126 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/lightninglabs/taro/tarodb/sqlc"
+)
+
+// MaxAssetAmount is the maximum amount of a single asset that can be stored in
+// the amount column. The column is a signed 64-bit integer, so this caps the
+// effective supply of an asset minted in a single output at 2^63-1 units, half
+// of what the asset encoding itself allows. Stores created with the
+// WithBigAmounts option store the exact amount of larger assets separately.
+const MaxAssetAmount = math.MaxInt64
+
+// ErrAssetAmountOverflow is returned when the amount of an asset exceeds the
+// maximum amount the store can represent.
+type ErrAssetAmountOverflow struct {
+	// Amount is the amount of the asset.
+	Amount uint64
+}
+
+func (e ErrAssetAmountOverflow) Error() string {
+	return fmt.Sprintf("asset amount %d exceeds max of %d", e.Amount,
+		uint64(MaxAssetAmount))
+}
+
+// checkAssetAmount returns ErrAssetAmountOverflow if the given amount can't be
+// stored in the amount column, unless the options allow the exact amount to be
+// stored separately.
+func checkAssetAmount(opts *upsertOptions, amount uint64) error {
+	if amount <= MaxAssetAmount || opts.bigAmounts {
+		return nil
+	}
+
+	return &ErrAssetAmountOverflow{
+		Amount: amount,
+	}
+}
+
+// assetAmountColumns returns the values of the amount and amount_big columns
+// of an asset with the given amount. Amounts exceeding the range of the amount
+// column are stored as MaxAssetAmount, so they still sort after all other
+// amounts, while their exact decimal amount is stored in amount_big.
+func assetAmountColumns(amount uint64) (int64, sql.NullString) {
+	if amount <= MaxAssetAmount {
+		return int64(amount), sql.NullString{}
+	}
+
+	return MaxAssetAmount, sql.NullString{
+		String: strconv.FormatUint(amount, 10),
+		Valid:  true,
+	}
+}
+
+// splitRootValueColumn returns the value of the split commitment root value
+// column for the given split commitment root sum. Unlike asset amounts, the
+// sum has no separate column for larger values, so ErrAssetAmountOverflow is
+// returned if it doesn't fit into the column.
+func splitRootValueColumn(sum uint64) (sql.NullInt64, error) {
+	if sum > MaxAssetAmount {
+		return sql.NullInt64{}, &ErrAssetAmountOverflow{
+			Amount: sum,
+		}
+	}
+
+	return sql.NullInt64{
+		Int64: int64(sum),
+		Valid: true,
+	}, nil
+}
+
+// parseAssetAmount returns the exact amount of an asset from the values of its
+// amount and amount_big columns.
+func parseAssetAmount(amount int64, amountBig sql.NullString) (uint64, error) {
+	if !amountBig.Valid {
+		// Assets stored before amounts were checked may still have a
+		// wrapped, negative amount, which we convert back the same way
+		// it was stored.
+		return uint64(amount), nil
+	}
+
+	exactAmount, err := strconv.ParseUint(amountBig.String, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid big amount %v: %w",
+			amountBig.String, err)
+	}
+
+	return exactAmount, nil
+}
+
+// backfillWrappedAmounts stores the exact amount of all assets that were
+// stored with an amount that wrapped around the range of the amount column,
+// and clamps their amount to MaxAssetAmount. Afterwards, these assets are
+// ordered and filtered by their amount like any other asset.
+func backfillWrappedAmounts(ctx context.Context, q sqlc.Querier) error {
+	wrappedAssets, err := q.FetchAssetsWithWrappedAmount(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to fetch wrapped amounts: %w", err)
+	}
+
+	for _, wrappedAsset := range wrappedAssets {
+		amount, amountBig := assetAmountColumns(
+			uint64(wrappedAsset.Amount),
+		)
+		err := q.SetAssetBigAmount(ctx, sqlc.SetAssetBigAmountParams{
+			Amount:    amount,
+			AmountBig: amountBig,
+			AssetID:   wrappedAsset.AssetID,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to store big amount: %w", err)
+		}
+	}
+
+	if len(wrappedAssets) > 0 {
+		log.Infof("Stored the exact amount of %d assets with a "+
+			"wrapped amount", len(wrappedAssets))
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrGenesisImmutable is returned when an upsert would modify the core
+	// fields of an existing genesis asset.
+	ErrGenesisImmutable = errors.New("genesis asset is immutable")
+)
+
+// immutableGenesisUpsertStore wraps an UpsertAssetStore and refuses to upsert
+// a genesis asset that would modify the core fields of an existing genesis
+// asset with the same tag. Its metadata and asset ID are already protected by
+// the upsert itself, see MetadataPolicy.
+type immutableGenesisUpsertStore struct {
+	UpsertAssetStore
+}
+
+// NewImmutableGenesisUpsertStore returns a new UpsertAssetStore that enforces
+// that existing genesis assets are never modified.
+func NewImmutableGenesisUpsertStore(q UpsertAssetStore) UpsertAssetStore {
+	return &immutableGenesisUpsertStore{
+		UpsertAssetStore: q,
+	}
+}
+
+// UpsertGenesisAsset inserts a new genesis asset, or resolves an existing one,
+// and returns the primary key. ErrGenesisImmutable is
+// returned if the upsert would modify the core fields of an existing genesis
+// asset.
+func (s *immutableGenesisUpsertStore) UpsertGenesisAsset(ctx context.Context,
+	arg GenesisAsset) (int32, error) {
+
+	existing, err := s.FetchGenesisAssetByTag(ctx, arg.AssetTag)
+	switch {
+	// There's no genesis asset with this tag yet, so there's nothing that
+	// could be modified.
+	case errors.Is(err, sql.ErrNoRows):
+		return s.UpsertAssetStore.UpsertGenesisAsset(ctx, arg)
+
+	case err != nil:
+		return 0, fmt.Errorf("unable to fetch genesis asset: %w", err)
+	}
+
+	if err := checkGenesisImmutable(existing, arg); err != nil {
+		return 0, err
+	}
+
+	// The upsert itself refuses to change the metadata of the existing
+	// genesis asset, in which case no row is returned.
+	genAssetID, err := s.UpsertAssetStore.UpsertGenesisAsset(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: metadata of %v would be replaced",
+			ErrGenesisImmutable, arg.AssetTag)
+	}
+
+	return genAssetID, err
+}
+
+// UpsertGenesisAssets inserts new or resolves existing genesis assets with a
+// single statement. ErrGenesisImmutable is returned if the upsert would modify
+// the core fields or the metadata of any existing genesis asset.
+func (s *immutableGenesisUpsertStore) UpsertGenesisAssets(
+	ctx context.Context,
+	arg NewGenesisAssets) ([]UpsertedGenesisAsset, error) {
+
+	for _, genAsset := range arg.Assets {
+		existing, err := s.FetchGenesisAssetByTag(
+			ctx, genAsset.AssetTag,
+		)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+
+		case err != nil:
+			return nil, fmt.Errorf("unable to fetch genesis "+
+				"asset: %w", err)
+		}
+
+		err = checkGenesisImmutable(existing, genAsset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The upsert itself refuses to change the metadata of an existing
+	// genesis asset, in which case no row is returned for it.
+	genAssets, err := s.UpsertAssetStore.UpsertGenesisAssets(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	upserted := make(map[string]struct{}, len(genAssets))
+	for _, genAsset := range genAssets {
+		upserted[genAsset.AssetTag] = struct{}{}
+	}
+	for _, genAsset := range arg.Assets {
+		if _, ok := upserted[genAsset.AssetTag]; !ok {
+			return nil, fmt.Errorf("%w: metadata of %v would be "+
+				"replaced", ErrGenesisImmutable,
+				genAsset.AssetTag)
+		}
+	}
+
+	return genAssets, nil
+}
+
+// checkGenesisImmutable returns ErrGenesisImmutable if upserting the given
+// genesis asset would modify the core fields of the existing one.
+func checkGenesisImmutable(existing StoredGenesisAsset,
+	arg GenesisAsset) error {
+
+	// The output index, type and genesis point are never written on
+	// conflict, so a mismatch would silently be dropped. We reject it
+	// instead, as it means we're looking at a different genesis.
+	switch {
+	case existing.OutputIndex != arg.OutputIndex:
+		return fmt.Errorf("%w: output index of %v changes from %d to "+
+			"%d", ErrGenesisImmutable, arg.AssetTag,
+			existing.OutputIndex, arg.OutputIndex)
+
+	case existing.AssetType != arg.AssetType:
+		return fmt.Errorf("%w: type of %v changes from %d to %d",
+			ErrGenesisImmutable, arg.AssetTag, existing.AssetType,
+			arg.AssetType)
+
+	case existing.GenesisPointID != arg.GenesisPointID:
+		return fmt.Errorf("%w: genesis point of %v changes from %d "+
+			"to %d", ErrGenesisImmutable, arg.AssetTag,
+			existing.GenesisPointID, arg.GenesisPointID)
+	}
+
+	return nil
+}
+
+// A compile-time assertion to ensure that immutableGenesisUpsertStore meets
+// the UpsertAssetStore interface.
+var _ UpsertAssetStore = (*immutableGenesisUpsertStore)(nil)
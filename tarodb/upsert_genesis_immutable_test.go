@@ -0,0 +1,115 @@
+package tarodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImmutableGenesisUpsertStore tests that the immutable genesis store
+// rejects upserts that would modify the core fields or the metadata of an
+// existing genesis asset.
+func TestImmutableGenesisUpsertStore(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	immutableStore := NewImmutableGenesisUpsertStore(db)
+
+	genesisPoint := test.RandOp(t)
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	// We'll start with a genesis asset that doesn't have any metadata.
+	gen := asset.RandGenesis(t, asset.Normal)
+	gen.FirstPrevOut = genesisPoint
+	gen.Metadata = nil
+
+	upsertGen := func(gen asset.Genesis, policy MetadataPolicy) error {
+		if err := upsertMetaReveals(ctx, db, gen); err != nil {
+			return err
+		}
+
+		_, err := immutableStore.UpsertGenesisAsset(
+			ctx, newGenesisAsset(genesisPointID, gen, policy),
+		)
+		return err
+	}
+	assertStored := func(gen asset.Genesis) {
+		stored, err := db.FetchGenesisAssetByTag(ctx, gen.Tag)
+		require.NoError(t, err)
+
+		assetID := gen.ID()
+		require.Equal(t, assetID[:], stored.AssetID)
+		require.Equal(t, int32(gen.OutputIndex), stored.OutputIndex)
+		require.Equal(t, int16(gen.Type), stored.AssetType)
+		metaHash := gen.MetadataHash()
+		require.Equal(t, metaHash[:], stored.MetaHash)
+	}
+
+	require.NoError(t, upsertGen(gen, MetadataReplace))
+	assertStored(gen)
+
+	// Upserting the very same genesis asset again is fine.
+	require.NoError(t, upsertGen(gen, MetadataReplace))
+	assertStored(gen)
+
+	// Filling in the missing metadata is rejected, as it would change
+	// the asset ID. The same goes for replacing the metadata, unless the
+	// policy keeps the existing metadata anyway.
+	filledGen := gen
+	filledGen.Metadata = test.RandBytes(32)
+	err = upsertGen(filledGen, MetadataReplace)
+	require.ErrorIs(t, err, ErrGenesisImmutable)
+	err = upsertGen(filledGen, MetadataPreferLonger)
+	require.ErrorIs(t, err, ErrGenesisImmutable)
+	require.NoError(t, upsertGen(filledGen, MetadataKeepExisting))
+	assertStored(gen)
+
+	// Changing any of the core fields is rejected, independent of the
+	// metadata policy.
+	changedIndexGen := gen
+	changedIndexGen.OutputIndex++
+	err = upsertGen(changedIndexGen, MetadataKeepExisting)
+	require.ErrorIs(t, err, ErrGenesisImmutable)
+
+	changedTypeGen := gen
+	changedTypeGen.Type = asset.Collectible
+	err = upsertGen(changedTypeGen, MetadataKeepExisting)
+	require.ErrorIs(t, err, ErrGenesisImmutable)
+
+	assertStored(gen)
+
+	// The same goes for upserting several genesis assets at once, where a
+	// single modified genesis asset rejects the whole set.
+	newGen := asset.RandGenesis(t, asset.Normal)
+	newGen.FirstPrevOut = genesisPoint
+	upsertGens := func(policy MetadataPolicy, gens ...asset.Genesis) error {
+		genAssets := make([]GenesisAsset, len(gens))
+		for i, gen := range gens {
+			genAssets[i] = newGenesisAsset(
+				genesisPointID, gen, policy,
+			)
+		}
+
+		_, err := immutableStore.UpsertGenesisAssets(
+			ctx, NewGenesisAssets{
+				Assets:         genAssets,
+				MetadataPolicy: int16(policy),
+			},
+		)
+		return err
+	}
+	err = upsertGens(MetadataKeepExisting, newGen, changedIndexGen)
+	require.ErrorIs(t, err, ErrGenesisImmutable)
+	err = upsertGens(MetadataReplace, newGen, filledGen)
+	require.ErrorIs(t, err, ErrGenesisImmutable)
+	require.NoError(t, upsertGens(MetadataKeepExisting, newGen, gen))
+
+	assertStored(gen)
+	assertStored(newGen)
+}
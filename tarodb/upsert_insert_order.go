@@ -0,0 +1,60 @@
+package tarodb
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/lightninglabs/taro/asset"
+)
+
+// AssetInsertOrder determines the order in which the assets of a batch are
+// inserted into the database.
+type AssetInsertOrder uint8
+
+const (
+	// InsertInputOrder inserts the assets in the order they were passed
+	// in.
+	InsertInputOrder AssetInsertOrder = iota
+
+	// InsertByAssetID inserts the assets sorted by their asset ID.
+	InsertByAssetID
+
+	// InsertByScriptKey inserts the assets sorted by their script key.
+	InsertByScriptKey
+)
+
+// sortedAssetIndexes returns the indexes of the given assets in the given
+// insert order. Assets with an equal sort key keep their input order.
+func sortedAssetIndexes(assets []*asset.Asset,
+	order AssetInsertOrder) []int {
+
+	indexes := make([]int, len(assets))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	var sortKeys [][]byte
+	switch order {
+	case InsertByAssetID:
+		sortKeys = fMap(assets, func(a *asset.Asset) []byte {
+			assetID := a.ID()
+			return assetID[:]
+		})
+
+	case InsertByScriptKey:
+		sortKeys = fMap(assets, func(a *asset.Asset) []byte {
+			return a.ScriptKey.PubKey.SerializeCompressed()
+		})
+
+	default:
+		return indexes
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return bytes.Compare(
+			sortKeys[indexes[i]], sortKeys[indexes[j]],
+		) < 0
+	})
+
+	return indexes
+}
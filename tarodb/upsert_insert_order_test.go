@@ -0,0 +1,177 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInsertOrderUpsertStore tests that the assets of a batch are inserted in
+// the order requested by the store, while the returned IDs are still aligned
+// with the passed assets.
+func TestInsertOrderUpsertStore(t *testing.T) {
+	t.Parallel()
+
+	assetIDKey := func(a *asset.Asset) []byte {
+		assetID := a.ID()
+		return assetID[:]
+	}
+	scriptKey := func(a *asset.Asset) []byte {
+		return a.ScriptKey.PubKey.SerializeCompressed()
+	}
+
+	testCases := []struct {
+		name    string
+		order   AssetInsertOrder
+		sortKey func(*asset.Asset) []byte
+	}{
+		{
+			name:  "input order",
+			order: InsertInputOrder,
+		},
+		{
+			name:    "by asset ID",
+			order:   InsertByAssetID,
+			sortKey: assetIDKey,
+		},
+		{
+			name:    "by script key",
+			order:   InsertByScriptKey,
+			sortKey: scriptKey,
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			testInsertOrder(t, testCase.order, testCase.sortKey)
+		})
+	}
+}
+
+// testInsertOrder inserts a batch of assets with the given insert order, and
+// asserts that the assets were inserted sorted by the given sort key, or in
+// their input order if no sort key is given.
+func testInsertOrder(t *testing.T, order AssetInsertOrder,
+	sortKey func(*asset.Asset) []byte) {
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	assets := make([]*asset.Asset, 5)
+	for i := range assets {
+		assets[i] = randAsset(t, withAssetGenPoint(genesisPoint))
+	}
+
+	_, assetIDs, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(WithInsertOrder(order)), genesisPoint,
+		assets, nil,
+	)
+	require.NoError(t, err)
+
+	// The returned IDs should line up with the assets we passed in.
+	dbAssets, err := db.AllAssets(ctx)
+	require.NoError(t, err)
+
+	scriptKeyIDs := make(map[int32]int32, len(dbAssets))
+	for _, dbAsset := range dbAssets {
+		scriptKeyIDs[dbAsset.AssetID] = dbAsset.ScriptKeyID
+	}
+	for i, a := range assets {
+		scriptKeyID, err := db.FetchScriptKeyIDByTweakedKey(
+			ctx, a.ScriptKey.PubKey.SerializeCompressed(),
+		)
+		require.NoError(t, err)
+		require.Equal(t, scriptKeyID, scriptKeyIDs[assetIDs[i]])
+	}
+
+	// The assets should've been inserted in the expected order, so
+	// sorting them by their primary key should result in that order.
+	expectedOrder := make([]*asset.Asset, len(assets))
+	copy(expectedOrder, assets)
+	if sortKey != nil {
+		sort.Slice(expectedOrder, func(i, j int) bool {
+			return bytes.Compare(
+				sortKey(expectedOrder[i]),
+				sortKey(expectedOrder[j]),
+			) < 0
+		})
+	}
+
+	primaryKeys := make(map[*asset.Asset]int32, len(assets))
+	for i, a := range assets {
+		primaryKeys[a] = assetIDs[i]
+	}
+	insertOrder := make([]*asset.Asset, len(assets))
+	copy(insertOrder, assets)
+	sort.Slice(insertOrder, func(i, j int) bool {
+		return primaryKeys[insertOrder[i]] < primaryKeys[insertOrder[j]]
+	})
+	require.Equal(t, expectedOrder, insertOrder)
+}
+
+// BenchmarkInsertOrder compares inserting a large batch of assets sorted by
+// different keys against inserting them in their input order.
+func BenchmarkInsertOrder(b *testing.B) {
+	const numAssets = 50_000
+
+	genesisPoint := test.RandOp(b)
+	assets := make([]*asset.Asset, numAssets)
+	for i := range assets {
+		assets[i] = randAsset(
+			b, withAssetGenPoint(genesisPoint), withNoGroupKey(),
+		)
+	}
+
+	orders := []struct {
+		name  string
+		order AssetInsertOrder
+	}{
+		{
+			name:  "unsorted",
+			order: InsertInputOrder,
+		},
+		{
+			name:  "by asset ID",
+			order: InsertByAssetID,
+		},
+		{
+			name:  "by script key",
+			order: InsertByScriptKey,
+		},
+	}
+	ctx := context.Background()
+	for _, order := range orders {
+		order := order
+		opts := newUpsertOptions(WithInsertOrder(order.order))
+		insertAssets := func(q ActiveAssetsStore) error {
+			_, _, err := upsertAssetsWithGenesis(
+				ctx, q, opts, genesisPoint, assets, nil,
+			)
+			return err
+		}
+
+		b.Run(order.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// Each iteration inserts the batch into a new
+				// database.
+				b.StopTimer()
+				_, assetStore, _ := newAssetStore(b)
+				b.StartTimer()
+
+				var writeTxOpts AssetStoreTxOptions
+				err := assetStore.db.ExecTx(
+					ctx, &writeTxOpts, insertAssets,
+				)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
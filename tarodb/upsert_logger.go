@@ -0,0 +1,16 @@
+package tarodb
+
+import (
+	"github.com/btcsuite/btclog"
+	"github.com/lightninglabs/taro/asset"
+)
+
+// logAssetUpsertFailure logs that upserting the asset with the given index
+// within a batch of numAssets assets failed, so it's obvious which of the
+// assets of a failed import tripped.
+func logAssetUpsertFailure(logger btclog.Logger, idx, numAssets int,
+	a *asset.Asset, err error) {
+
+	logger.Debugf("Unable to upsert asset %d of %d (asset_id=%v): %v",
+		idx, numAssets, a.ID(), err)
+}
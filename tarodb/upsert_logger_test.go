@@ -0,0 +1,158 @@
+package tarodb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btclog"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/lightninglabs/taro/tarodb/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// failingInsertStore is an UpsertAssetStore that fails inserting the asset
+// after the given number of successful inserts.
+type failingInsertStore struct {
+	UpsertAssetStore
+
+	numInserts int
+	failAfter  int
+}
+
+// InsertNewAsset inserts a new asset on disk, unless the store is supposed to
+// fail this insert.
+func (f *failingInsertStore) InsertNewAsset(ctx context.Context,
+	arg sqlc.InsertNewAssetParams) (int32, error) {
+
+	if f.numInserts == f.failAfter {
+		return 0, fmt.Errorf("insert failed")
+	}
+	f.numInserts++
+
+	return f.UpsertAssetStore.InsertNewAsset(ctx, arg)
+}
+
+// newTraceLogger returns a new logger that logs at trace level to the
+// returned buffer.
+func newTraceLogger() (btclog.Logger, *bytes.Buffer) {
+	var logBuf bytes.Buffer
+	logger := btclog.NewBackend(&logBuf).Logger(Subsystem)
+	logger.SetLevel(btclog.LevelTrace)
+
+	return logger, &logBuf
+}
+
+// TestUpsertLogger tests that importing assets with a logger logs each genesis
+// point, group key and asset, and that a failed import logs which of the
+// assets failed.
+func TestUpsertLogger(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	newAssets := func() []*asset.Asset {
+		assets := make([]*asset.Asset, 3)
+		for i := range assets {
+			assets[i] = randAsset(
+				t, withAssetGenPoint(genesisPoint),
+				withAssetGenKeyGroup(test.RandPrivKey(t)),
+			)
+		}
+
+		return assets
+	}
+
+	// Importing a batch of assets should log the genesis point, and the
+	// group key and asset ID of each of the assets along with its index.
+	logger, logBuf := newTraceLogger()
+	assets := newAssets()
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(WithUpsertLogger(logger)),
+		genesisPoint, assets, nil,
+	)
+	require.NoError(t, err)
+
+	logs := logBuf.String()
+	require.Contains(t, logs, fmt.Sprintf(
+		"Upserted genesis point %v", genesisPoint,
+	))
+	for i, a := range assets {
+		prefix := fmt.Sprintf("Asset %d of %d (asset_id=%v): ", i,
+			len(assets), a.ID())
+
+		groupKey := a.GroupKey.GroupPubKey.SerializeCompressed()
+		require.Contains(t, logs, fmt.Sprintf(
+			"%supserted group key %x", prefix, groupKey,
+		))
+		require.Contains(t, logs, prefix+"inserted asset")
+	}
+
+	// If inserting the second asset fails, the log should tell us which
+	// one it was.
+	logger, logBuf = newTraceLogger()
+	assets = newAssets()
+	failingStore := &failingInsertStore{
+		UpsertAssetStore: db,
+		failAfter:        1,
+	}
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, failingStore, newUpsertOptions(WithUpsertLogger(logger)),
+		genesisPoint, assets, nil,
+	)
+	require.ErrorContains(t, err, "insert failed")
+
+	logs = logBuf.String()
+	require.Contains(t, logs, fmt.Sprintf(
+		"Unable to upsert asset 1 of 3 (asset_id=%v): insert failed",
+		assets[1].ID(),
+	))
+	require.NotContains(t, logs, "Asset 2 of 3")
+
+	// Nothing should be logged to a logger that doesn't log trace lines.
+	logger, logBuf = newTraceLogger()
+	logger.SetLevel(btclog.LevelDebug)
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(WithUpsertLogger(logger)),
+		genesisPoint, newAssets(), nil,
+	)
+	require.NoError(t, err)
+	require.Empty(t, logBuf.String())
+}
+
+// TestUpsertLoggerOption tests that the stores log their asset imports to the
+// logger they were created with.
+func TestUpsertLoggerOption(t *testing.T) {
+	t.Parallel()
+
+	logger, logBuf := newTraceLogger()
+	mintingStore, assetStore, _ := newAssetStore(
+		t, WithUpsertLogger(logger),
+	)
+	ctx := context.Background()
+
+	// Importing an asset through the asset store should log it.
+	newAsset := randAsset(t)
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, newAsset.Genesis.FirstPrevOut, []*asset.Asset{newAsset},
+		[]AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+	require.Contains(t, logBuf.String(), fmt.Sprintf(
+		"Asset 0 of 1 (asset_id=%v): inserted asset", newAsset.ID(),
+	))
+
+	// The same goes for the sprouts added to a minting batch.
+	logBuf.Reset()
+	_, _, _, assetRoot := addRandAssets(t, ctx, mintingStore, 2)
+	logs := logBuf.String()
+	for _, sprout := range assetRoot.CommittedAssets() {
+		require.Contains(t, logs, fmt.Sprintf(
+			"(asset_id=%v): inserted asset", sprout.ID(),
+		))
+	}
+}
@@ -0,0 +1,39 @@
+package tarodb
+
+import (
+	"fmt"
+)
+
+// DefaultMaxMetadataSize is the maximum size of the metadata of a genesis
+// asset that's accepted if the store doesn't specify a limit itself. It
+// matches the maximum size of the metadata an asset can commit to.
+const DefaultMaxMetadataSize = 1024 * 1024
+
+// ErrMetadataTooLarge is returned when the metadata of a genesis asset exceeds
+// the maximum metadata size accepted by the store.
+type ErrMetadataTooLarge struct {
+	// Size is the actual size of the metadata.
+	Size int
+
+	// MaxSize is the maximum size of the metadata.
+	MaxSize int
+}
+
+func (e ErrMetadataTooLarge) Error() string {
+	return fmt.Sprintf("metadata too large: %d bytes exceeds max of %d "+
+		"bytes", e.Size, e.MaxSize)
+}
+
+// checkMetadataSize returns ErrMetadataTooLarge if the given metadata exceeds
+// the maximum metadata size of the passed options.
+func checkMetadataSize(opts *upsertOptions, metadata []byte) error {
+	maxSize := opts.maxMetadataSize
+	if len(metadata) > maxSize {
+		return &ErrMetadataTooLarge{
+			Size:    len(metadata),
+			MaxSize: maxSize,
+		}
+	}
+
+	return nil
+}
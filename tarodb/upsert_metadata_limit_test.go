@@ -0,0 +1,94 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMaxMetadataSize tests that genesis assets with metadata
+// exceeding the maximum metadata size are refused before anything is written.
+func TestMaxMetadataSize(t *testing.T) {
+	t.Parallel()
+
+	_, _, db := newAssetStore(t)
+	ctx := context.Background()
+
+	const maxMetadataSize = 10
+	limitOpts := newUpsertOptions(WithMaxMetadataSize(maxMetadataSize))
+
+	// metadataAsset returns a new asset of the given genesis point with
+	// the given size of metadata.
+	metadataAsset := func(genesisPoint wire.OutPoint,
+		metadataSize int) *asset.Asset {
+
+		gen := asset.RandGenesis(t, asset.Normal)
+		gen.Metadata = test.RandBytes(metadataSize)
+
+		return randAsset(
+			t, withAssetGen(gen), withAssetGenPoint(genesisPoint),
+		)
+	}
+
+	// A batch with one asset exceeding the limit should be refused as a
+	// whole, without even storing the genesis point.
+	genesisPoint := test.RandOp(t)
+	_, _, err := upsertAssetsWithGenesis(
+		ctx, db, limitOpts, genesisPoint, []*asset.Asset{
+			metadataAsset(genesisPoint, maxMetadataSize),
+			metadataAsset(genesisPoint, maxMetadataSize+1),
+		}, nil,
+	)
+	var tooLargeErr *ErrMetadataTooLarge
+	require.ErrorAs(t, err, &tooLargeErr)
+	require.Equal(t, maxMetadataSize+1, tooLargeErr.Size)
+	require.Equal(t, maxMetadataSize, tooLargeErr.MaxSize)
+
+	prevOut, err := encodeOutpoint(genesisPoint)
+	require.NoError(t, err)
+	_, err = db.FetchGenesisPointIDByPrevOut(ctx, prevOut)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	genesisAssets, err := db.GenesisAssets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, genesisAssets)
+
+	// Metadata that fits the limit exactly should be accepted.
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, limitOpts, genesisPoint, []*asset.Asset{
+			metadataAsset(genesisPoint, maxMetadataSize),
+		}, nil,
+	)
+	require.NoError(t, err)
+
+	// A single genesis exceeding the limit should be refused as well.
+	genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+	require.NoError(t, err)
+
+	largeAsset := metadataAsset(genesisPoint, maxMetadataSize+1)
+	_, err = upsertGenesis(
+		ctx, db, limitOpts, genesisPointID, largeAsset.Genesis,
+		MetadataKeepExisting,
+	)
+	require.ErrorAs(t, err, &tooLargeErr)
+
+	// Without a custom limit, the default limit applies.
+	_, err = upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, largeAsset.Genesis,
+		MetadataKeepExisting,
+	)
+	require.NoError(t, err)
+
+	largeAsset = metadataAsset(genesisPoint, DefaultMaxMetadataSize+1)
+	_, err = upsertGenesis(
+		ctx, db, newUpsertOptions(), genesisPointID, largeAsset.Genesis,
+		MetadataKeepExisting,
+	)
+	require.ErrorAs(t, err, &tooLargeErr)
+	require.Equal(t, DefaultMaxMetadataSize, tooLargeErr.MaxSize)
+}
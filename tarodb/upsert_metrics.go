@@ -0,0 +1,144 @@
+package tarodb
+
+import (
+	"sync"
+)
+
+const (
+	// TableGenesisPoints is the name of the table that stores genesis
+	// points.
+	TableGenesisPoints = "genesis_points"
+
+	// TableGenesisAssets is the name of the table that stores the base
+	// genesis information of an asset.
+	TableGenesisAssets = "genesis_assets"
+
+	// TableScriptKeys is the name of the table that stores script keys.
+	TableScriptKeys = "script_keys"
+
+	// TableInternalKeys is the name of the table that stores internal
+	// keys.
+	TableInternalKeys = "internal_keys"
+
+	// TableAssetGroups is the name of the table that stores asset group
+	// keys.
+	TableAssetGroups = "asset_groups"
+
+	// TableAssetGroupSigs is the name of the table that stores asset group
+	// sigs.
+	TableAssetGroupSigs = "asset_group_sigs"
+
+	// TableAssets is the name of the table that stores assets.
+	TableAssets = "assets"
+
+	// TableChainTxns is the name of the table that stores chain
+	// transactions.
+	TableChainTxns = "chain_txns"
+
+	// TableManagedUTXOs is the name of the table that stores managed
+	// UTXOs.
+	TableManagedUTXOs = "managed_utxos"
+)
+
+// upsertOpTables maps each upsert operation reported to an UpsertObserver to
+// the table it writes to.
+var upsertOpTables = map[string]string{
+	UpsertOpGenesisPoint: TableGenesisPoints,
+	UpsertOpGenesisAsset: TableGenesisAssets,
+	UpsertOpInternalKey:  TableInternalKeys,
+	UpsertOpScriptKey:    TableScriptKeys,
+	UpsertOpGroupKey:     TableAssetGroups,
+	UpsertOpGroupSig:     TableAssetGroupSigs,
+	UpsertOpAsset:        TableAssets,
+	UpsertOpChainTx:      TableChainTxns,
+	UpsertOpManagedUTXO:  TableManagedUTXOs,
+}
+
+// TableWrites is the number of rows written to a single table.
+type TableWrites struct {
+	// Inserts is the number of new rows inserted into the table.
+	Inserts uint64
+
+	// Updates is the number of existing rows that were updated instead.
+	Updates uint64
+}
+
+// TableWriteMetrics tallies the number of rows inserted and updated in each
+// table. It's an UpsertObserver, so it can be attached to the queries of a
+// store by wrapping them in the transaction creator passed to
+// NewTransactionExecutor:
+//
+//	metrics := NewTableWriteMetrics()
+//	txCreator := func(tx *sql.Tx) ActiveAssetsStore {
+//		return NewObservedAssetsStore(db.WithTx(tx), metrics)
+//	}
+//
+// A single instance is meant to be shared by all the transactions it should
+// collect metrics for.
+type TableWriteMetrics struct {
+	mu     sync.Mutex
+	writes map[string]TableWrites
+}
+
+// NewTableWriteMetrics creates a new, empty TableWriteMetrics instance.
+func NewTableWriteMetrics() *TableWriteMetrics {
+	return &TableWriteMetrics{
+		writes: make(map[string]TableWrites),
+	}
+}
+
+// tableName returns the table written to by the given upsert operation.
+// Unknown operations are tallied under their own name.
+func tableName(op string) string {
+	if table, ok := upsertOpTables[op]; ok {
+		return table
+	}
+
+	return op
+}
+
+// OnInsert is called when an upsert inserted a new row for the given
+// operation.
+//
+// NOTE: This is part of the UpsertObserver interface.
+func (m *TableWriteMetrics) OnInsert(op string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table := tableName(op)
+	writes := m.writes[table]
+	writes.Inserts++
+	m.writes[table] = writes
+}
+
+// OnConflict is called when an upsert hit an existing row for the given
+// operation, which is then updated.
+//
+// NOTE: This is part of the UpsertObserver interface.
+func (m *TableWriteMetrics) OnConflict(op string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table := tableName(op)
+	writes := m.writes[table]
+	writes.Updates++
+	m.writes[table] = writes
+}
+
+// Snapshot returns a copy of the number of rows written to each table so
+// far. Tables that weren't written to aren't included.
+func (m *TableWriteMetrics) Snapshot() map[string]TableWrites {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]TableWrites, len(m.writes))
+	for table, writes := range m.writes {
+		snapshot[table] = writes
+	}
+
+	return snapshot
+}
+
+// A compile-time assertion to ensure that TableWriteMetrics meets the
+// UpsertObserver interface.
+var _ UpsertObserver = (*TableWriteMetrics)(nil)
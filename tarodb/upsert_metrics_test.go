@@ -0,0 +1,77 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTableWriteMetrics tests that importing a set of grouped assets through
+// an asset store that reports to a TableWriteMetrics instance tallies the
+// inserts and updates made to each table.
+func TestTableWriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := NewTableWriteMetrics()
+
+	db := NewTestDB(t)
+	activeTxCreator := func(tx *sql.Tx) ActiveAssetsStore {
+		return NewObservedAssetsStore(db.WithTx(tx), metrics)
+	}
+	assetStore := NewAssetStore(NewTransactionExecutor[ActiveAssetsStore](
+		db, activeTxCreator,
+	))
+
+	ctx := context.Background()
+
+	// We'll import two assets that share the same genesis point and are
+	// part of the same group, but have a distinct genesis each.
+	genesisPoint := test.RandOp(t)
+	groupPriv := test.RandPrivKey(t)
+	assets := []*asset.Asset{
+		randAsset(
+			t, withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		),
+		randAsset(
+			t, withAssetGenPoint(genesisPoint),
+			withAssetGenKeyGroup(groupPriv),
+		),
+	}
+	anchor := randAnchorUTXO(t)
+	anchors := []AnchorUTXO{anchor, anchor}
+
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, genesisPoint, assets, anchors,
+	)
+	require.NoError(t, err)
+
+	// The genesis point is only inserted once, while all other tables are
+	// inserted into once per asset. As the group key is tweaked with the
+	// genesis of each asset, we also end up with a group key per asset.
+	// The internal keys are those of the anchor, the group key and the
+	// script key of each asset. The anchor is shared by both assets, so
+	// it's updated when inserting it for the second time.
+	expectedWrites := map[string]TableWrites{
+		TableGenesisPoints:  {Inserts: 1},
+		TableGenesisAssets:  {Inserts: 2},
+		TableInternalKeys:   {Inserts: 5, Updates: 1},
+		TableScriptKeys:     {Inserts: 2},
+		TableAssetGroups:    {Inserts: 2},
+		TableAssetGroupSigs: {Inserts: 2},
+		TableAssets:         {Inserts: 2},
+		TableChainTxns:      {Inserts: 1, Updates: 1},
+		TableManagedUTXOs:   {Inserts: 1, Updates: 1},
+	}
+	snapshot := metrics.Snapshot()
+	require.Equal(t, expectedWrites, snapshot)
+
+	// The snapshot is a copy, so modifying it shouldn't affect the
+	// metrics.
+	snapshot[TableAssets] = TableWrites{}
+	require.Equal(t, expectedWrites, metrics.Snapshot())
+}
@@ -0,0 +1,662 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lightninglabs/taro/tarodb/sqlc"
+)
+
+const (
+	// UpsertOpGenesisPoint is the operation name used when upserting a
+	// genesis point.
+	UpsertOpGenesisPoint = "genesis_point"
+
+	// UpsertOpGenesisAsset is the operation name used when upserting a
+	// genesis asset.
+	UpsertOpGenesisAsset = "genesis_asset"
+
+	// UpsertOpInternalKey is the operation name used when upserting an
+	// internal key.
+	UpsertOpInternalKey = "internal_key"
+
+	// UpsertOpScriptKey is the operation name used when upserting a
+	// script key.
+	UpsertOpScriptKey = "script_key"
+
+	// UpsertOpGroupKey is the operation name used when upserting an asset
+	// group key.
+	UpsertOpGroupKey = "group_key"
+
+	// UpsertOpGroupSig is the operation name used when upserting an asset
+	// group sig.
+	UpsertOpGroupSig = "group_sig"
+
+	// UpsertOpChainTx is the operation name used when upserting a chain
+	// transaction.
+	UpsertOpChainTx = "chain_tx"
+
+	// UpsertOpManagedUTXO is the operation name used when upserting a
+	// managed UTXO.
+	UpsertOpManagedUTXO = "managed_utxo"
+)
+
+// UpsertObserver is notified each time an upsert either inserts a fresh row,
+// or hits an existing one and takes the conflict (update) path instead. This
+// can be used to collect metrics about how often data is re-imported.
+type UpsertObserver interface {
+	// OnInsert is called when an upsert inserted a new row for the given
+	// operation.
+	OnInsert(op string)
+
+	// OnConflict is called when an upsert hit an existing row for the
+	// given operation.
+	OnConflict(op string)
+}
+
+// UpsertLookupStore houses the lookups needed to determine whether an upsert
+// will hit an existing row, keyed by the unique field of each table.
+type UpsertLookupStore interface {
+	// FetchGenesisPointIDByPrevOut fetches the primary key of the genesis
+	// point with the given outpoint.
+	FetchGenesisPointIDByPrevOut(ctx context.Context,
+		prevOut []byte) (int32, error)
+
+	// FetchGenesisAssetIDByTag fetches the primary key of the genesis
+	// asset with the given tag.
+	FetchGenesisAssetIDByTag(ctx context.Context,
+		assetTag string) (int32, error)
+
+	// FetchInternalKeyIDByRawKey fetches the primary key of the internal
+	// key with the given raw key.
+	FetchInternalKeyIDByRawKey(ctx context.Context,
+		rawKey []byte) (int32, error)
+
+	// FetchScriptKeyIDByTweakedKey determines the database ID of a script
+	// key by querying it by the tweaked key.
+	FetchScriptKeyIDByTweakedKey(ctx context.Context,
+		tweakedScriptKey []byte) (int32, error)
+
+	// FetchGroupKeyIDByTweakedKey fetches the primary key of the group
+	// key with the given tweaked key.
+	FetchGroupKeyIDByTweakedKey(ctx context.Context,
+		tweakedGroupKey []byte) (int32, error)
+
+	// FetchGroupSigIDByGenesisID fetches the primary key of the group sig
+	// of the given genesis asset.
+	FetchGroupSigIDByGenesisID(ctx context.Context,
+		genAssetID int32) (int32, error)
+
+	// FetchChainTx fetches a chain tx from the DB.
+	FetchChainTx(ctx context.Context, txid []byte) (sqlc.ChainTxn, error)
+
+	// FetchManagedUTXO fetches a managed UTXO based on either the outpoint
+	// or the transaction that anchors it.
+	FetchManagedUTXO(context.Context, UtxoQuery) (AnchorPoint, error)
+}
+
+// ObservablePendingAssetStore is a PendingAssetStore that can also look up
+// the rows its upserts write to, so they can be reported to an
+// UpsertObserver.
+type ObservablePendingAssetStore interface {
+	PendingAssetStore
+
+	UpsertLookupStore
+}
+
+// upsertObservation reports the outcome of upserts to an UpsertObserver. As
+// the upsert queries themselves don't report whether they hit a conflict, we
+// look up the unique field of each row before it's written.
+type upsertObservation struct {
+	lookups  UpsertLookupStore
+	observer UpsertObserver
+}
+
+// observeUpsert checks whether the row to be upserted already exists using
+// the passed lookup, then executes the upsert and notifies the observer
+// accordingly.
+func observeUpsert(observer UpsertObserver, op string, lookup func() error,
+	upsert func() (int32, error)) (int32, error) {
+
+	err := lookup()
+	exists := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("unable to look up %v: %w", op, err)
+	}
+
+	id, err := upsert()
+	if err != nil {
+		return 0, err
+	}
+
+	if exists {
+		observer.OnConflict(op)
+	} else {
+		observer.OnInsert(op)
+	}
+
+	return id, nil
+}
+
+// genesisPoint observes the upsert of a single genesis point.
+func (o *upsertObservation) genesisPoint(ctx context.Context,
+	arg NewGenesisPoint, upsert func(context.Context,
+		NewGenesisPoint) (int32, error)) (int32, error) {
+
+	return observeUpsert(o.observer, UpsertOpGenesisPoint, func() error {
+		_, err := o.lookups.FetchGenesisPointIDByPrevOut(
+			ctx, arg.PrevOut,
+		)
+		return err
+	}, func() (int32, error) {
+		return upsert(ctx, arg)
+	})
+}
+
+// genesisPoints observes the upsert of a set of genesis points with a single
+// statement, notifying the observer once for each of them.
+func (o *upsertObservation) genesisPoints(ctx context.Context,
+	arg NewGenesisPoints, upsert func(context.Context,
+		NewGenesisPoints) ([]UpsertedGenesisPoint, error)) (
+	[]UpsertedGenesisPoint, error) {
+
+	exists := make([]bool, len(arg.PrevOuts))
+	for i, prevOut := range arg.PrevOuts {
+		_, err := o.lookups.FetchGenesisPointIDByPrevOut(ctx, prevOut)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("unable to look up %v: %w",
+				UpsertOpGenesisPoint, err)
+		}
+
+		exists[i] = err == nil
+	}
+
+	points, err := upsert(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pointExists := range exists {
+		if pointExists {
+			o.observer.OnConflict(UpsertOpGenesisPoint)
+		} else {
+			o.observer.OnInsert(UpsertOpGenesisPoint)
+		}
+	}
+
+	return points, nil
+}
+
+// genesisAsset observes the upsert of a genesis asset.
+func (o *upsertObservation) genesisAsset(ctx context.Context,
+	arg GenesisAsset, upsert func(context.Context,
+		GenesisAsset) (int32, error)) (int32, error) {
+
+	return observeUpsert(o.observer, UpsertOpGenesisAsset, func() error {
+		_, err := o.lookups.FetchGenesisAssetIDByTag(ctx, arg.AssetTag)
+		return err
+	}, func() (int32, error) {
+		return upsert(ctx, arg)
+	})
+}
+
+// genesisAssets observes the upsert of a set of genesis assets with a single
+// statement, notifying the observer once for each of them.
+func (o *upsertObservation) genesisAssets(ctx context.Context,
+	arg NewGenesisAssets, upsert func(context.Context,
+		NewGenesisAssets) ([]UpsertedGenesisAsset, error)) (
+	[]UpsertedGenesisAsset, error) {
+
+	exists := make([]bool, len(arg.Assets))
+	for i, genAsset := range arg.Assets {
+		_, err := o.lookups.FetchGenesisAssetIDByTag(
+			ctx, genAsset.AssetTag,
+		)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("unable to look up %v: %w",
+				UpsertOpGenesisAsset, err)
+		}
+
+		exists[i] = err == nil
+	}
+
+	genAssets, err := upsert(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, genAssetExists := range exists {
+		if genAssetExists {
+			o.observer.OnConflict(UpsertOpGenesisAsset)
+		} else {
+			o.observer.OnInsert(UpsertOpGenesisAsset)
+		}
+	}
+
+	return genAssets, nil
+}
+
+// internalKey observes the upsert of an internal key.
+func (o *upsertObservation) internalKey(ctx context.Context,
+	arg InternalKey, upsert func(context.Context,
+		InternalKey) (int32, error)) (int32, error) {
+
+	return observeUpsert(o.observer, UpsertOpInternalKey, func() error {
+		_, err := o.lookups.FetchInternalKeyIDByRawKey(ctx, arg.RawKey)
+		return err
+	}, func() (int32, error) {
+		return upsert(ctx, arg)
+	})
+}
+
+// internalKeys observes the upsert of a set of internal keys with a single
+// statement, notifying the observer once for each of them.
+func (o *upsertObservation) internalKeys(ctx context.Context,
+	keys []InternalKey, upsert func(context.Context,
+		[]InternalKey) ([]UpsertedInternalKey, error)) (
+	[]UpsertedInternalKey, error) {
+
+	exists := make([]bool, len(keys))
+	for i, key := range keys {
+		_, err := o.lookups.FetchInternalKeyIDByRawKey(ctx, key.RawKey)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("unable to look up %v: %w",
+				UpsertOpInternalKey, err)
+		}
+
+		exists[i] = err == nil
+	}
+
+	dbKeys, err := upsert(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, keyExists := range exists {
+		if keyExists {
+			o.observer.OnConflict(UpsertOpInternalKey)
+		} else {
+			o.observer.OnInsert(UpsertOpInternalKey)
+		}
+	}
+
+	return dbKeys, nil
+}
+
+// scriptKey observes the upsert of a script key.
+func (o *upsertObservation) scriptKey(ctx context.Context,
+	arg NewScriptKey, upsert func(context.Context,
+		NewScriptKey) (int32, error)) (int32, error) {
+
+	return observeUpsert(o.observer, UpsertOpScriptKey, func() error {
+		_, err := o.lookups.FetchScriptKeyIDByTweakedKey(
+			ctx, arg.TweakedScriptKey,
+		)
+		return err
+	}, func() (int32, error) {
+		return upsert(ctx, arg)
+	})
+}
+
+// groupKey observes the upsert of an asset group key.
+func (o *upsertObservation) groupKey(ctx context.Context,
+	arg AssetGroupKey, upsert func(context.Context,
+		AssetGroupKey) (int32, error)) (int32, error) {
+
+	return observeUpsert(o.observer, UpsertOpGroupKey, func() error {
+		_, err := o.lookups.FetchGroupKeyIDByTweakedKey(
+			ctx, arg.TweakedGroupKey,
+		)
+		return err
+	}, func() (int32, error) {
+		return upsert(ctx, arg)
+	})
+}
+
+// groupSig observes the upsert of an asset group sig.
+func (o *upsertObservation) groupSig(ctx context.Context,
+	arg AssetGroupSig, upsert func(context.Context,
+		AssetGroupSig) (int32, error)) (int32, error) {
+
+	return observeUpsert(o.observer, UpsertOpGroupSig, func() error {
+		_, err := o.lookups.FetchGroupSigIDByGenesisID(
+			ctx, arg.GenAssetID,
+		)
+		return err
+	}, func() (int32, error) {
+		return upsert(ctx, arg)
+	})
+}
+
+// newAsset observes the insertion of an asset. Assets are never upserted, so
+// this always results in an insert.
+func (o *upsertObservation) newAsset(ctx context.Context,
+	arg sqlc.InsertNewAssetParams, insert func(context.Context,
+		sqlc.InsertNewAssetParams) (int32, error)) (int32, error) {
+
+	id, err := insert(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	o.observer.OnInsert(UpsertOpAsset)
+
+	return id, nil
+}
+
+// chainTx observes the upsert of a chain tx.
+func (o *upsertObservation) chainTx(ctx context.Context, arg ChainTx,
+	upsert func(context.Context, ChainTx) (int32, error)) (int32, error) {
+
+	return observeUpsert(o.observer, UpsertOpChainTx, func() error {
+		_, err := o.lookups.FetchChainTx(ctx, arg.Txid)
+		return err
+	}, func() (int32, error) {
+		return upsert(ctx, arg)
+	})
+}
+
+// managedUTXO observes the upsert of a managed UTXO.
+func (o *upsertObservation) managedUTXO(ctx context.Context,
+	arg RawManagedUTXO, upsert func(context.Context,
+		RawManagedUTXO) (int32, error)) (int32, error) {
+
+	return observeUpsert(o.observer, UpsertOpManagedUTXO, func() error {
+		_, err := o.lookups.FetchManagedUTXO(ctx, UtxoQuery{
+			Outpoint: arg.Outpoint,
+		})
+		return err
+	}, func() (int32, error) {
+		return upsert(ctx, arg)
+	})
+}
+
+// observedAssetsStore wraps an ActiveAssetsStore and notifies an UpsertObserver
+// of the outcome of each upsert.
+type observedAssetsStore struct {
+	ActiveAssetsStore
+
+	observation *upsertObservation
+}
+
+// NewObservedAssetsStore returns a new ActiveAssetsStore that reports the
+// outcome of all upserts made through it to the passed observer.
+func NewObservedAssetsStore(q ActiveAssetsStore,
+	observer UpsertObserver) ActiveAssetsStore {
+
+	return &observedAssetsStore{
+		ActiveAssetsStore: q,
+		observation: &upsertObservation{
+			lookups:  q,
+			observer: observer,
+		},
+	}
+}
+
+// UpsertGenesisPoint inserts a new or updates an existing genesis point on
+// disk, and returns the primary key.
+func (o *observedAssetsStore) UpsertGenesisPoint(ctx context.Context,
+	arg NewGenesisPoint) (int32, error) {
+
+	return o.observation.genesisPoint(
+		ctx, arg, o.ActiveAssetsStore.UpsertGenesisPoint,
+	)
+}
+
+// UpsertGenesisPoints inserts new or updates existing genesis points on disk
+// with a single statement, and notifies the observer once for each of them.
+func (o *observedAssetsStore) UpsertGenesisPoints(ctx context.Context,
+	arg NewGenesisPoints) ([]UpsertedGenesisPoint, error) {
+
+	return o.observation.genesisPoints(
+		ctx, arg, o.ActiveAssetsStore.UpsertGenesisPoints,
+	)
+}
+
+// UpsertGenesisAsset inserts a new or updates an existing genesis asset in
+// the DB, and returns the primary key.
+func (o *observedAssetsStore) UpsertGenesisAsset(ctx context.Context,
+	arg GenesisAsset) (int32, error) {
+
+	return o.observation.genesisAsset(
+		ctx, arg, o.ActiveAssetsStore.UpsertGenesisAsset,
+	)
+}
+
+// UpsertGenesisAssets inserts new or resolves existing genesis assets in the
+// DB with a single statement, and notifies the observer once for each of
+// them.
+func (o *observedAssetsStore) UpsertGenesisAssets(ctx context.Context,
+	arg NewGenesisAssets) ([]UpsertedGenesisAsset, error) {
+
+	return o.observation.genesisAssets(
+		ctx, arg, o.ActiveAssetsStore.UpsertGenesisAssets,
+	)
+}
+
+// UpsertInternalKey inserts a new or updates an existing internal key into
+// the database.
+func (o *observedAssetsStore) UpsertInternalKey(ctx context.Context,
+	arg InternalKey) (int32, error) {
+
+	return o.observation.internalKey(
+		ctx, arg, o.ActiveAssetsStore.UpsertInternalKey,
+	)
+}
+
+// UpsertInternalKeys inserts new or updates existing internal keys into the
+// database with a single statement, and notifies the observer once for each
+// of them.
+func (o *observedAssetsStore) UpsertInternalKeys(ctx context.Context,
+	keys []InternalKey) ([]UpsertedInternalKey, error) {
+
+	return o.observation.internalKeys(
+		ctx, keys, o.ActiveAssetsStore.UpsertInternalKeys,
+	)
+}
+
+// UpsertScriptKey inserts a new script key on disk into the DB.
+func (o *observedAssetsStore) UpsertScriptKey(ctx context.Context,
+	arg NewScriptKey) (int32, error) {
+
+	return o.observation.scriptKey(
+		ctx, arg, o.ActiveAssetsStore.UpsertScriptKey,
+	)
+}
+
+// UpsertAssetGroupKey inserts a new or updates an existing group key on disk,
+// and returns the primary key.
+func (o *observedAssetsStore) UpsertAssetGroupKey(ctx context.Context,
+	arg AssetGroupKey) (int32, error) {
+
+	return o.observation.groupKey(
+		ctx, arg, o.ActiveAssetsStore.UpsertAssetGroupKey,
+	)
+}
+
+// UpsertAssetGroupSig inserts a new asset group sig into the DB.
+func (o *observedAssetsStore) UpsertAssetGroupSig(ctx context.Context,
+	arg AssetGroupSig) (int32, error) {
+
+	return o.observation.groupSig(
+		ctx, arg, o.ActiveAssetsStore.UpsertAssetGroupSig,
+	)
+}
+
+// InsertNewAsset inserts a new asset on disk.
+func (o *observedAssetsStore) InsertNewAsset(ctx context.Context,
+	arg sqlc.InsertNewAssetParams) (int32, error) {
+
+	return o.observation.newAsset(
+		ctx, arg, o.ActiveAssetsStore.InsertNewAsset,
+	)
+}
+
+// UpsertChainTx inserts a new or updates an existing chain tx into the DB.
+func (o *observedAssetsStore) UpsertChainTx(ctx context.Context,
+	arg ChainTx) (int32, error) {
+
+	return o.observation.chainTx(
+		ctx, arg, o.ActiveAssetsStore.UpsertChainTx,
+	)
+}
+
+// UpsertManagedUTXO inserts a new or updates an existing managed UTXO to disk
+// and returns the primary key.
+func (o *observedAssetsStore) UpsertManagedUTXO(ctx context.Context,
+	arg RawManagedUTXO) (int32, error) {
+
+	return o.observation.managedUTXO(
+		ctx, arg, o.ActiveAssetsStore.UpsertManagedUTXO,
+	)
+}
+
+// A compile-time assertion to ensure that observedAssetsStore meets the
+// ActiveAssetsStore interface.
+var _ ActiveAssetsStore = (*observedAssetsStore)(nil)
+
+// observedPendingAssetStore wraps the PendingAssetStore of the minting store
+// and notifies an UpsertObserver of the outcome of each upsert.
+type observedPendingAssetStore struct {
+	ObservablePendingAssetStore
+
+	observation *upsertObservation
+}
+
+// NewObservedPendingAssetStore returns a new PendingAssetStore that reports
+// the outcome of all upserts made through it to the passed observer.
+func NewObservedPendingAssetStore(q ObservablePendingAssetStore,
+	observer UpsertObserver) PendingAssetStore {
+
+	return &observedPendingAssetStore{
+		ObservablePendingAssetStore: q,
+		observation: &upsertObservation{
+			lookups:  q,
+			observer: observer,
+		},
+	}
+}
+
+// UpsertGenesisPoint inserts a new or updates an existing genesis point on
+// disk, and returns the primary key.
+func (o *observedPendingAssetStore) UpsertGenesisPoint(ctx context.Context,
+	arg NewGenesisPoint) (int32, error) {
+
+	return o.observation.genesisPoint(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertGenesisPoint,
+	)
+}
+
+// UpsertGenesisPoints inserts new or updates existing genesis points on disk
+// with a single statement, and notifies the observer once for each of them.
+func (o *observedPendingAssetStore) UpsertGenesisPoints(ctx context.Context,
+	arg NewGenesisPoints) ([]UpsertedGenesisPoint, error) {
+
+	return o.observation.genesisPoints(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertGenesisPoints,
+	)
+}
+
+// UpsertGenesisAsset inserts a new or updates an existing genesis asset in
+// the DB, and returns the primary key.
+func (o *observedPendingAssetStore) UpsertGenesisAsset(ctx context.Context,
+	arg GenesisAsset) (int32, error) {
+
+	return o.observation.genesisAsset(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertGenesisAsset,
+	)
+}
+
+// UpsertGenesisAssets inserts new or resolves existing genesis assets in the
+// DB with a single statement, and notifies the observer once for each of
+// them.
+func (o *observedPendingAssetStore) UpsertGenesisAssets(ctx context.Context,
+	arg NewGenesisAssets) ([]UpsertedGenesisAsset, error) {
+
+	return o.observation.genesisAssets(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertGenesisAssets,
+	)
+}
+
+// UpsertInternalKey inserts a new or updates an existing internal key into
+// the database.
+func (o *observedPendingAssetStore) UpsertInternalKey(ctx context.Context,
+	arg InternalKey) (int32, error) {
+
+	return o.observation.internalKey(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertInternalKey,
+	)
+}
+
+// UpsertInternalKeys inserts new or updates existing internal keys into the
+// database with a single statement, and notifies the observer once for each
+// of them.
+func (o *observedPendingAssetStore) UpsertInternalKeys(ctx context.Context,
+	keys []InternalKey) ([]UpsertedInternalKey, error) {
+
+	return o.observation.internalKeys(
+		ctx, keys, o.ObservablePendingAssetStore.UpsertInternalKeys,
+	)
+}
+
+// UpsertScriptKey inserts a new script key on disk into the DB.
+func (o *observedPendingAssetStore) UpsertScriptKey(ctx context.Context,
+	arg NewScriptKey) (int32, error) {
+
+	return o.observation.scriptKey(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertScriptKey,
+	)
+}
+
+// UpsertAssetGroupKey inserts a new or updates an existing group key on disk,
+// and returns the primary key.
+func (o *observedPendingAssetStore) UpsertAssetGroupKey(ctx context.Context,
+	arg AssetGroupKey) (int32, error) {
+
+	return o.observation.groupKey(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertAssetGroupKey,
+	)
+}
+
+// UpsertAssetGroupSig inserts a new asset group sig into the DB.
+func (o *observedPendingAssetStore) UpsertAssetGroupSig(ctx context.Context,
+	arg AssetGroupSig) (int32, error) {
+
+	return o.observation.groupSig(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertAssetGroupSig,
+	)
+}
+
+// InsertNewAsset inserts a new asset on disk.
+func (o *observedPendingAssetStore) InsertNewAsset(ctx context.Context,
+	arg sqlc.InsertNewAssetParams) (int32, error) {
+
+	return o.observation.newAsset(
+		ctx, arg, o.ObservablePendingAssetStore.InsertNewAsset,
+	)
+}
+
+// UpsertChainTx inserts a new or updates an existing chain tx into the DB.
+func (o *observedPendingAssetStore) UpsertChainTx(ctx context.Context,
+	arg ChainTx) (int32, error) {
+
+	return o.observation.chainTx(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertChainTx,
+	)
+}
+
+// UpsertManagedUTXO inserts a new or updates an existing managed UTXO to disk
+// and returns the primary key.
+func (o *observedPendingAssetStore) UpsertManagedUTXO(ctx context.Context,
+	arg RawManagedUTXO) (int32, error) {
+
+	return o.observation.managedUTXO(
+		ctx, arg, o.ObservablePendingAssetStore.UpsertManagedUTXO,
+	)
+}
+
+// A compile-time assertion to ensure that observedPendingAssetStore meets the
+// PendingAssetStore interface.
+var _ PendingAssetStore = (*observedPendingAssetStore)(nil)
@@ -0,0 +1,203 @@
+package tarodb
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// mockUpsertObserver is an UpsertObserver that counts all events it receives.
+type mockUpsertObserver struct {
+	sync.Mutex
+
+	inserts   map[string]int
+	conflicts map[string]int
+}
+
+func newMockUpsertObserver() *mockUpsertObserver {
+	return &mockUpsertObserver{
+		inserts:   make(map[string]int),
+		conflicts: make(map[string]int),
+	}
+}
+
+func (m *mockUpsertObserver) OnInsert(op string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.inserts[op]++
+}
+
+func (m *mockUpsertObserver) OnConflict(op string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.conflicts[op]++
+}
+
+// reset clears all events counted so far.
+func (m *mockUpsertObserver) reset() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.inserts = make(map[string]int)
+	m.conflicts = make(map[string]int)
+}
+
+// TestUpsertObserverConflicts tests that re-importing the same asset results
+// in conflict events for all the upserts involved, while the initial import
+// only results in insert events.
+func TestUpsertObserverConflicts(t *testing.T) {
+	t.Parallel()
+
+	observer := newMockUpsertObserver()
+
+	db := NewTestDB(t)
+	activeTxCreator := func(tx *sql.Tx) ActiveAssetsStore {
+		return NewObservedAssetsStore(db.WithTx(tx), observer)
+	}
+	assetStore := NewAssetStore(NewTransactionExecutor[ActiveAssetsStore](
+		db, activeTxCreator,
+	))
+
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	newAsset := randAsset(
+		t, withAssetGenPoint(genesisPoint),
+		withAssetGenKeyGroup(test.RandPrivKey(t)),
+	)
+	anchor := randAnchorUTXO(t)
+
+	importAsset := func() {
+		err := assetStore.ImportAssetsWithAnchors(
+			ctx, genesisPoint, []*asset.Asset{newAsset},
+			[]AnchorUTXO{anchor},
+		)
+		require.NoError(t, err)
+	}
+
+	// On the initial import, every upsert should insert a new row. The
+	// internal keys are those of the anchor UTXO, the script key and the
+	// group key.
+	importAsset()
+
+	expectedEvents := map[string]int{
+		UpsertOpGenesisPoint: 1,
+		UpsertOpGenesisAsset: 1,
+		UpsertOpInternalKey:  3,
+		UpsertOpScriptKey:    1,
+		UpsertOpGroupKey:     1,
+		UpsertOpGroupSig:     1,
+		UpsertOpChainTx:      1,
+		UpsertOpManagedUTXO:  1,
+	}
+	expectedInserts := map[string]int{UpsertOpAsset: 1}
+	for op, count := range expectedEvents {
+		expectedInserts[op] = count
+	}
+	require.Equal(t, expectedInserts, observer.inserts)
+	require.Empty(t, observer.conflicts)
+
+	// If we import the very same asset again, then all the upserts should
+	// hit an existing row instead. Only the asset itself is inserted once
+	// more, as assets are never upserted.
+	observer.reset()
+	importAsset()
+
+	require.Equal(t, map[string]int{UpsertOpAsset: 1}, observer.inserts)
+	require.Equal(t, expectedEvents, observer.conflicts)
+}
+
+// TestUpsertObserverGenesisPoints tests that upserting a set of genesis points
+// through the asset store with a single statement reports an event for each
+// of them.
+func TestUpsertObserverGenesisPoints(t *testing.T) {
+	t.Parallel()
+
+	observer := newMockUpsertObserver()
+
+	db := NewTestDB(t)
+	activeTxCreator := func(tx *sql.Tx) ActiveAssetsStore {
+		return NewObservedAssetsStore(db.WithTx(tx), observer)
+	}
+	assetStore := NewAssetStore(NewTransactionExecutor[ActiveAssetsStore](
+		db, activeTxCreator,
+	))
+
+	ctx := context.Background()
+
+	genesisPoints := []wire.OutPoint{
+		test.RandOp(t), test.RandOp(t), test.RandOp(t),
+	}
+
+	// The first two points are new, so both of them should be reported
+	// as inserted.
+	_, err := assetStore.UpsertGenesisPoints(ctx, genesisPoints[:2])
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]int{
+		UpsertOpGenesisPoint: 2,
+	}, observer.inserts)
+	require.Empty(t, observer.conflicts)
+
+	// Upserting the second point along with the third one should result
+	// in a conflict for the second, and an insert for the third point.
+	observer.reset()
+	_, err = assetStore.UpsertGenesisPoints(ctx, genesisPoints[1:])
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]int{
+		UpsertOpGenesisPoint: 1,
+	}, observer.inserts)
+	require.Equal(t, map[string]int{
+		UpsertOpGenesisPoint: 1,
+	}, observer.conflicts)
+}
+
+// TestUpsertObserverMintingStore tests that the upserts made by the minting
+// store when adding the sprouts of a batch are reported to the observer.
+func TestUpsertObserverMintingStore(t *testing.T) {
+	t.Parallel()
+
+	observer := newMockUpsertObserver()
+
+	db := NewTestDB(t)
+	txCreator := func(tx *sql.Tx) PendingAssetStore {
+		return NewObservedPendingAssetStore(db.WithTx(tx), observer)
+	}
+	mintingStore := NewAssetMintingStore(
+		NewTransactionExecutor[PendingAssetStore](db, txCreator),
+	)
+
+	ctx := context.Background()
+
+	const numSeedlings = 5
+	addRandAssets(t, ctx, mintingStore, numSeedlings)
+
+	// Each seedling results in a new genesis asset, script key and asset,
+	// all of them sharing the genesis point of the batch. Only some of
+	// the seedlings are grouped, with a group sig for each group key.
+	// Next to the internal keys of the script and group keys, the batch
+	// key was inserted when the batch was committed.
+	numGroups := observer.inserts[UpsertOpGroupKey]
+	expectedInserts := map[string]int{
+		UpsertOpGenesisPoint: 1,
+		UpsertOpGenesisAsset: numSeedlings,
+		UpsertOpInternalKey:  numSeedlings + numGroups + 1,
+		UpsertOpScriptKey:    numSeedlings,
+		UpsertOpAsset:        numSeedlings,
+	}
+	if numGroups > 0 {
+		expectedInserts[UpsertOpGroupKey] = numGroups
+		expectedInserts[UpsertOpGroupSig] = numGroups
+	}
+	require.Equal(t, expectedInserts, observer.inserts)
+	require.Empty(t, observer.conflicts)
+}
@@ -0,0 +1,121 @@
+package tarodb
+
+import "github.com/btcsuite/btclog"
+
+// upsertOptions houses the policies that are applied when importing assets
+// into the database. The options of a store are passed explicitly to each of
+// the upsert helpers, so they apply no matter how the per-transaction queries
+// are wrapped.
+type upsertOptions struct {
+	// maxAssetsPerGenesisPoint is the maximum number of assets that can
+	// be created from a single genesis point. Zero means there's no
+	// limit.
+	maxAssetsPerGenesisPoint int64
+
+	// maxMetadataSize is the maximum size of the metadata of a genesis
+	// asset in bytes.
+	maxMetadataSize int
+
+	// insertOrder is the order in which the assets of a batch are
+	// inserted.
+	insertOrder AssetInsertOrder
+
+	// bigAmounts indicates whether assets with an amount exceeding the
+	// range of the amount column are stored with their exact amount in
+	// the amount_big column, instead of being rejected.
+	bigAmounts bool
+
+	// logger is the logger the progress of asset imports is logged to. If
+	// it isn't set, the package logger is used.
+	logger btclog.Logger
+
+	// auditKey is the key the audit log entries of all writes made while
+	// importing assets are keyed with. If it isn't set, no audit log is
+	// kept.
+	auditKey []byte
+}
+
+// UpsertOption is a functional option that modifies the policies a store
+// applies when importing assets.
+type UpsertOption func(*upsertOptions)
+
+// newUpsertOptions returns the default upsert options, modified by the passed
+// functional options.
+func newUpsertOptions(opts ...UpsertOption) *upsertOptions {
+	upsertOpts := &upsertOptions{
+		maxMetadataSize: DefaultMaxMetadataSize,
+		insertOrder:     InsertInputOrder,
+	}
+	for _, opt := range opts {
+		opt(upsertOpts)
+	}
+
+	return upsertOpts
+}
+
+// upsertLogger returns the logger the progress of asset imports is logged to.
+func (o *upsertOptions) upsertLogger() btclog.Logger {
+	if o.logger == nil {
+		return log
+	}
+
+	return o.logger
+}
+
+// WithAssetQuota limits the number of assets that can be created from a
+// single genesis point, which prevents a single (malicious) mint from filling
+// up the database. Imports exceeding the quota fail with
+// ErrAssetQuotaExceeded.
+func WithAssetQuota(maxAssetsPerGenesisPoint int64) UpsertOption {
+	return func(o *upsertOptions) {
+		o.maxAssetsPerGenesisPoint = maxAssetsPerGenesisPoint
+	}
+}
+
+// WithMaxMetadataSize overrides the maximum size of the metadata of the
+// genesis assets a store accepts, which defaults to DefaultMaxMetadataSize.
+func WithMaxMetadataSize(maxMetadataSize int) UpsertOption {
+	return func(o *upsertOptions) {
+		o.maxMetadataSize = maxMetadataSize
+	}
+}
+
+// WithInsertOrder sets the order in which the assets of a batch are inserted.
+// On large imports, inserting the assets sorted by an indexed key can improve
+// the locality of the index updates.
+func WithInsertOrder(order AssetInsertOrder) UpsertOption {
+	return func(o *upsertOptions) {
+		o.insertOrder = order
+	}
+}
+
+// WithBigAmounts allows assets with an amount exceeding MaxAssetAmount to be
+// stored. Their amount column is clamped to MaxAssetAmount, and their exact
+// amount is stored in the amount_big column, which the readers of the store
+// take into account when ordering, filtering and summing amounts.
+func WithBigAmounts() UpsertOption {
+	return func(o *upsertOptions) {
+		o.bigAmounts = true
+	}
+}
+
+// WithUpsertLogger logs the genesis point, group keys and assets of each asset
+// import to the passed logger instead of the package logger. The lines for
+// individual rows are logged at trace level, and failures at debug level.
+func WithUpsertLogger(logger btclog.Logger) UpsertOption {
+	return func(o *upsertOptions) {
+		o.logger = logger
+	}
+}
+
+// WithAuditLog appends an entry to the audit log for each write made while
+// importing or minting assets, and for each asset deleted through the store.
+// The entries form a hash chain keyed with HMAC-SHA256 under the passed key,
+// which VerifyAuditChain checks along with the audited assets. The key should
+// be kept outside of the database, otherwise the chain can simply be
+// recomputed after tampering with it.
+func WithAuditLog(key []byte) UpsertOption {
+	return func(o *upsertOptions) {
+		o.auditKey = key
+	}
+}
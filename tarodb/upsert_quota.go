@@ -0,0 +1,38 @@
+package tarodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrAssetQuotaExceeded is returned when importing a set of assets
+	// would exceed the maximum number of assets allowed per genesis point.
+	ErrAssetQuotaExceeded = errors.New("asset quota of genesis point " +
+		"exceeded")
+)
+
+// checkAssetQuota returns ErrAssetQuotaExceeded if adding the given number of
+// assets to the genesis point would exceed the quota of the passed options.
+func checkAssetQuota(ctx context.Context, q UpsertAssetStore,
+	opts *upsertOptions, genesisPointID int32, numNewAssets int) error {
+
+	maxAssets := opts.maxAssetsPerGenesisPoint
+	if maxAssets == 0 {
+		return nil
+	}
+
+	numAssets, err := q.CountAssetsByGenesisPoint(ctx, genesisPointID)
+	if err != nil {
+		return fmt.Errorf("unable to count assets: %w", err)
+	}
+
+	if numAssets+int64(numNewAssets) > maxAssets {
+		return fmt.Errorf("%w: genesis point has %d assets, unable "+
+			"to add %d more (max=%d)", ErrAssetQuotaExceeded,
+			numAssets, numNewAssets, maxAssets)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package tarodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lightninglabs/taro/asset"
+	"github.com/lightninglabs/taro/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAssetQuota tests that a store with an asset quota refuses to import a
+// batch of assets that would exceed the number of assets allowed per genesis
+// point.
+func TestAssetQuota(t *testing.T) {
+	t.Parallel()
+
+	const maxAssets = 3
+	_, assetStore, db := newAssetStore(t, WithAssetQuota(maxAssets))
+	ctx := context.Background()
+
+	genesisPoint := test.RandOp(t)
+	importAssets := func(numAssets int) error {
+		assets := make([]*asset.Asset, numAssets)
+		anchors := make([]AnchorUTXO, numAssets)
+		for i := range assets {
+			assets[i] = randAsset(t, withAssetGenPoint(genesisPoint))
+			anchors[i] = randAnchorUTXO(t)
+		}
+
+		return assetStore.ImportAssetsWithAnchors(
+			ctx, genesisPoint, assets, anchors,
+		)
+	}
+	numAssets := func() int64 {
+		genesisPointID, err := upsertGenesisPoint(ctx, db, genesisPoint)
+		require.NoError(t, err)
+
+		count, err := db.CountAssetsByGenesisPoint(ctx, genesisPointID)
+		require.NoError(t, err)
+
+		return count
+	}
+
+	// The first batch fits within the quota.
+	require.NoError(t, importAssets(2))
+	require.EqualValues(t, 2, numAssets())
+
+	// The second batch would exceed the quota, so none of its assets
+	// should be imported.
+	require.ErrorIs(t, importAssets(2), ErrAssetQuotaExceeded)
+	require.EqualValues(t, 2, numAssets())
+
+	// A batch that fills up the quota exactly is still accepted, but any
+	// further asset is refused.
+	require.NoError(t, importAssets(1))
+	require.EqualValues(t, maxAssets, numAssets())
+	require.ErrorIs(t, importAssets(1), ErrAssetQuotaExceeded)
+
+	// Other genesis points aren't affected by the quota of this one.
+	otherAsset := randAsset(t)
+	err := assetStore.ImportAssetsWithAnchors(
+		ctx, otherAsset.Genesis.FirstPrevOut,
+		[]*asset.Asset{otherAsset}, []AnchorUTXO{randAnchorUTXO(t)},
+	)
+	require.NoError(t, err)
+
+	// Without a quota, the upsert helpers don't limit the number of assets
+	// of a genesis point.
+	_, _, err = upsertAssetsWithGenesis(
+		ctx, db, newUpsertOptions(), genesisPoint,
+		[]*asset.Asset{randAsset(t, withAssetGenPoint(genesisPoint))},
+		nil,
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, maxAssets+1, numAssets())
+}